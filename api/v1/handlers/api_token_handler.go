@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// ListAPITokens 列出当前用户的 API token
+// @Summary 列出 API token
+// @Description 列出当前用户的 API token，不包含令牌明文
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.APIToken
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/auth/tokens [get]
+func (h *AuthHandler) ListAPITokens(c *gin.Context) {
+	userID, ok := h.interactiveUser(c)
+	if !ok {
+		return
+	}
+	h.listAPITokens(c, userID)
+}
+
+// CreateAPIToken 为当前用户创建 API token
+// @Summary 创建 API token
+// @Description 创建用于自动化调用的 API token，以 Authorization: Bearer <token> 使用。明文只返回一次
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param token body models.CreateAPITokenRequest true "名称、scope 与过期时间"
+// @Success 200 {object} models.CreateAPITokenResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/auth/tokens [post]
+func (h *AuthHandler) CreateAPIToken(c *gin.Context) {
+	userID, ok := h.interactiveUser(c)
+	if !ok {
+		return
+	}
+	var req models.CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+	h.respondAPIToken(c, func() (*models.CreateAPITokenResponse, error) {
+		return h.authService.CreateAPIToken(userID, &req)
+	})
+}
+
+// RevokeAPIToken 吊销当前用户的 API token
+// @Summary 吊销 API token
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API token ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/auth/tokens/{id} [delete]
+func (h *AuthHandler) RevokeAPIToken(c *gin.Context) {
+	userID, ok := h.interactiveUser(c)
+	if !ok {
+		return
+	}
+	h.revokeAPIToken(c, userID, c.Param("id"))
+}
+
+// CreateRobot 创建机器人用户（管理员）
+// @Summary 创建机器人用户
+// @Description 机器人用户不能使用密码登录，通过管理员为其创建的 API token 调用接口，权限由绑定的角色决定
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param robot body models.CreateRobotRequest true "机器人信息"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/auth/robots [post]
+func (h *AuthHandler) CreateRobot(c *gin.Context) {
+	var req models.CreateRobotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	response, err := h.authService.CreateRobot(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "创建成功",
+		"data":    response,
+	})
+}
+
+// ListUserAPITokens 列出指定用户的 API token（管理员）
+// @Summary 列出用户的 API token
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {array} models.APIToken
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/auth/users/{id}/tokens [get]
+func (h *AuthHandler) ListUserAPITokens(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	h.listAPITokens(c, userID)
+}
+
+// CreateUserAPIToken 为机器人用户创建 API token（管理员）
+// @Summary 为机器人用户创建 API token
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "机器人用户ID"
+// @Param token body models.CreateAPITokenRequest true "名称、scope 与过期时间"
+// @Success 200 {object} models.CreateAPITokenResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/auth/users/{id}/tokens [post]
+func (h *AuthHandler) CreateUserAPIToken(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	var req models.CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+	h.respondAPIToken(c, func() (*models.CreateAPITokenResponse, error) {
+		return h.authService.CreateRobotAPIToken(userID, &req)
+	})
+}
+
+// RevokeUserAPIToken 吊销指定用户的 API token（管理员）
+// @Summary 吊销用户的 API token
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Param tokenId path int true "API token ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/auth/users/{id}/tokens/{tokenId} [delete]
+func (h *AuthHandler) RevokeUserAPIToken(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	h.revokeAPIToken(c, userID, c.Param("tokenId"))
+}
+
+// interactiveUser 返回当前用户。API token 不能用来管理 API token，避免泄露的令牌为自己续期
+func (h *AuthHandler) interactiveUser(c *gin.Context) (uint, bool) {
+	userID, _, _, ok := auth.GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "用户信息不存在",
+		})
+		return 0, false
+	}
+	if claims, ok := auth.GetClaims(c); ok && claims.APITokenID != 0 {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "不能使用 API token 管理 API token，请先登录",
+		})
+		return 0, false
+	}
+	return userID, true
+}
+
+func (h *AuthHandler) listAPITokens(c *gin.Context, userID uint) {
+	tokens, err := h.authService.ListAPITokens(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取 API token 失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    tokens,
+	})
+}
+
+func (h *AuthHandler) respondAPIToken(c *gin.Context, create func() (*models.CreateAPITokenResponse, error)) {
+	response, err := create()
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrNotRobot) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "创建成功，请妥善保存令牌，它不会再次显示",
+		"data":    response,
+	})
+}
+
+func (h *AuthHandler) revokeAPIToken(c *gin.Context, userID uint, tokenParam string) {
+	tokenID, err := strconv.ParseUint(tokenParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的 API token ID",
+		})
+		return
+	}
+
+	if err := h.authService.RevokeAPIToken(userID, uint(tokenID)); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrAPITokenNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "API token 已吊销",
+	})
+}
+
+func userIDParam(c *gin.Context) (uint, bool) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的用户ID",
+		})
+		return 0, false
+	}
+	return uint(userID), true
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// maxManifestSize 清单大小上限
+const maxManifestSize = 10 << 20 // 10MiB
+
+// ApplyHandler 多文档清单的 server-side apply 接口
+type ApplyHandler struct{}
+
+// NewApplyHandler ...
+func NewApplyHandler() *ApplyHandler {
+	return &ApplyHandler{}
+}
+
+// service 返回绑定到当前请求目标集群的 ApplyService
+func (h *ApplyHandler) service(c *gin.Context) *service.ApplyService {
+	client := clusterClient(c)
+	return service.NewApplyService(client.Dynamic, client.RESTMapper())
+}
+
+// Apply 请求体为多文档 YAML 或 JSON，可混合多种资源。
+// 查询参数: namespace (默认命名空间)、force=true (强制获取字段所有权)、dryRun=All (仅校验)
+func (h *ApplyHandler) Apply(c *gin.Context) {
+	namespace := strings.TrimSpace(c.Query("namespace"))
+	if namespace != "" && !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+
+	manifest, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxManifestSize))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "读取清单失败: "+err.Error())
+		return
+	}
+
+	result, err := h.service(c).Apply(manifest, service.ApplyOptions{
+		Namespace: namespace,
+		Force:     c.Query("force") == "true",
+		DryRun:    len(dryRunOption(c)) > 0,
+		Authorize: applyAuthorizer(c),
+	})
+	if err != nil {
+		respondResourceError(c, "应用清单失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, result)
+}
+
+// applyAuthorizer 路由上的授权只检查了 apply 本身，清单中的每个对象还需按其集群、命名空间和资源单独授权
+func applyAuthorizer(c *gin.Context) func(namespace, resource, verb string) error {
+	cluster := k8s.ClusterNameFromContext(c)
+	return func(namespace, resource, verb string) error {
+		allowed, err := auth.Authorize(c, auth.RequestAttributes{Cluster: cluster, Namespace: namespace, Resource: resource, Verb: verb})
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return apierrors.NewForbidden(schema.GroupResource{Resource: resource}, "",
+				fmt.Errorf("没有权限在命名空间 %q 中 %s %s", namespace, verb, resource))
+		}
+		return nil
+	}
+}
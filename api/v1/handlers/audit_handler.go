@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// ListAuditEvents 查询审计记录（管理员）
+// @Summary 查询审计记录
+// @Description 分页查询经由 CiliKube 执行的写操作以及 Pod exec、日志访问，按时间倒序
+// @Tags Audit
+// @Produce json
+// @Security BearerAuth
+// @Param username query string false "用户名"
+// @Param cluster query string false "集群"
+// @Param namespace query string false "命名空间"
+// @Param resource query string false "资源，例如 deployments、pods/exec"
+// @Param verb query string false "动作，例如 create、delete"
+// @Param since query string false "起始时间 (RFC 3339)"
+// @Param until query string false "结束时间 (RFC 3339)"
+// @Param failed query bool false "只返回失败 (状态码 >= 400) 的操作"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页大小" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /api/v1/audit [get]
+func (h *AuditHandler) ListAuditEvents(c *gin.Context) {
+	var query models.AuditQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 || query.PageSize > 100 {
+		query.PageSize = 20
+	}
+
+	events, total, err := h.auditService.ListAuditEvents(&query)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrAuditQueryUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": "获取审计记录失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"events":    events,
+			"total":     total,
+			"page":      query.Page,
+			"page_size": query.PageSize,
+		},
+	})
+}
@@ -14,15 +14,15 @@ type AuthHandler struct {
 	authService *service.AuthService
 }
 
-func NewAuthHandler() *AuthHandler {
+func NewAuthHandler(authService *service.AuthService) *AuthHandler {
 	return &AuthHandler{
-		authService: &service.AuthService{},
+		authService: authService,
 	}
 }
 
 // Login 用户登录
 // @Summary 用户登录
-// @Description 用户通过用户名和密码登录系统
+// @Description 用户通过用户名和密码登录系统；需要两步验证时 data.two_factor 返回登录挑战，凭挑战调用 /auth/login/2fa 获取 token
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -30,6 +30,7 @@ func NewAuthHandler() *AuthHandler {
 // @Success 200 {object} models.LoginResponse
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
 // @Router /api/v1/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req models.LoginRequest
@@ -41,8 +42,11 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	response, err := h.authService.Login(&req)
+	response, err := h.authService.Login(&req, loginClient(c))
 	if err != nil {
+		if respondLoginLocked(c, err) {
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"code":    401,
 			"message": err.Error(),
@@ -50,9 +54,13 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	message := "登录成功"
+	if response.TwoFactor != nil {
+		message = "需要两步验证"
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "登录成功",
+		"message": message,
 		"data":    response,
 	})
 }
@@ -222,22 +230,112 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	})
 }
 
+// Refresh 刷新 token
+// @Summary 刷新 token
+// @Description 使用刷新令牌换取新的 access token 与刷新令牌，旧的刷新令牌随即失效
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param refresh body models.RefreshTokenRequest true "刷新令牌"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	response, err := h.authService.Refresh(req.RefreshToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "刷新成功",
+		"data":    response,
+	})
+}
+
 // Logout 用户登出
 // @Summary 用户登出
-// @Description 用户登出系统（前端清除token即可）
+// @Description 吊销当前 access token 及当前会话的刷新令牌
 // @Tags Auth
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
 // @Router /api/v1/auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
+	claims, ok := auth.GetClaims(c)
+	if !ok {
+		// 关闭认证时没有可吊销的 token
+		c.JSON(http.StatusOK, gin.H{
+			"code":    200,
+			"message": "登出成功",
+		})
+		return
+	}
+
+	if err := h.authService.Logout(claims); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "登出失败: " + err.Error(),
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
 		"message": "登出成功",
 	})
 }
 
+// LogoutAll 退出所有会话
+// @Summary 退出所有会话
+// @Description 使当前用户在所有设备上签发的 token 与刷新令牌全部失效
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, _, _, ok := auth.GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "用户信息不存在",
+		})
+		return
+	}
+
+	if err := h.authService.LogoutAll(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "登出失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已退出所有会话",
+	})
+}
+
 // GetUserList 获取用户列表（管理员）
 // @Summary 获取用户列表
 // @Description 管理员获取系统中所有用户列表
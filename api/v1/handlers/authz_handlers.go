@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// AuthzHandler 角色、规则与角色绑定管理接口 (管理员)
+type AuthzHandler struct {
+	service *service.AuthzService
+}
+
+// NewAuthzHandler ...
+func NewAuthzHandler(svc *service.AuthzService) *AuthzHandler {
+	return &AuthzHandler{service: svc}
+}
+
+// ListRoles 列出所有角色及其规则和绑定
+func (h *AuthzHandler) ListRoles(c *gin.Context) {
+	roles, err := h.service.ListRoles()
+	if err != nil {
+		respondAuthzError(c, "获取角色列表失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, roles)
+}
+
+// GetRole 获取单个角色
+func (h *AuthzHandler) GetRole(c *gin.Context) {
+	role, err := h.service.GetRole(c.Param("role"))
+	if err != nil {
+		respondAuthzError(c, "获取角色失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, role)
+}
+
+// DeleteRole 删除角色的所有规则和绑定
+func (h *AuthzHandler) DeleteRole(c *gin.Context) {
+	if err := h.service.DeleteRole(c.Param("role")); err != nil {
+		respondAuthzError(c, "删除角色失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// ListPolicies 列出规则，?role= 按角色过滤
+func (h *AuthzHandler) ListPolicies(c *gin.Context) {
+	policies, err := h.service.ListPolicies(c.Query("role"))
+	if err != nil {
+		respondAuthzError(c, "获取规则失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, policies)
+}
+
+// AddPolicies 为角色添加规则，角色不存在时自动创建
+func (h *AuthzHandler) AddPolicies(c *gin.Context) {
+	var req models.PolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "无效的请求格式: "+err.Error())
+		return
+	}
+
+	policies, err := h.service.AddPolicies(&req)
+	if err != nil {
+		respondAuthzError(c, "添加规则失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, policies)
+}
+
+// RemovePolicies 删除角色的规则
+func (h *AuthzHandler) RemovePolicies(c *gin.Context) {
+	var req models.PolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "无效的请求格式: "+err.Error())
+		return
+	}
+
+	if err := h.service.RemovePolicies(&req); err != nil {
+		respondAuthzError(c, "删除规则失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// ListRoleBindings 列出角色绑定，?user= 与 ?role= 过滤
+func (h *AuthzHandler) ListRoleBindings(c *gin.Context) {
+	bindings, err := h.service.ListRoleBindings(c.Query("user"), c.Query("role"))
+	if err != nil {
+		respondAuthzError(c, "获取角色绑定失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, bindings)
+}
+
+// AddRoleBinding 将用户绑定到角色，cluster 为空表示所有集群
+func (h *AuthzHandler) AddRoleBinding(c *gin.Context) {
+	var req models.RoleBinding
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "无效的请求格式: "+err.Error())
+		return
+	}
+
+	if err := h.service.AddRoleBinding(&req); err != nil {
+		respondAuthzError(c, "添加角色绑定失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, req)
+}
+
+// RemoveRoleBinding 解除角色绑定
+func (h *AuthzHandler) RemoveRoleBinding(c *gin.Context) {
+	var req models.RoleBinding
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "无效的请求格式: "+err.Error())
+		return
+	}
+
+	if err := h.service.RemoveRoleBinding(&req); err != nil {
+		respondAuthzError(c, "删除角色绑定失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// Check 判断用户能否执行某个操作
+func (h *AuthzHandler) Check(c *gin.Context) {
+	var req models.AuthzCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "无效的请求格式: "+err.Error())
+		return
+	}
+
+	allowed, err := h.service.Check(&req)
+	if err != nil {
+		respondAuthzError(c, "权限检查失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.AuthzCheckResponse{Allowed: allowed})
+}
+
+// respondAuthzError 将授权管理服务错误映射为 HTTP 状态码
+func respondAuthzError(c *gin.Context, prefix string, err error) {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		respondError(c, http.StatusBadRequest, prefix+": "+err.Error())
+	case errors.Is(err, service.ErrRoleNotFound), errors.Is(err, service.ErrPolicyNotFound), errors.Is(err, service.ErrBindingNotFound):
+		respondError(c, http.StatusNotFound, prefix+": "+err.Error())
+	default:
+		respondError(c, http.StatusInternalServerError, prefix+": "+err.Error())
+	}
+}
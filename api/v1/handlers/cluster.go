@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/gin-gonic/gin"
+)
+
+// clusterClient 返回 ClusterMiddleware 为当前请求解析出的集群客户端
+func clusterClient(c *gin.Context) *k8s.Client {
+	client, _ := k8s.ClientFromContext(c)
+	return client
+}
+
+// clusterCache 返回当前请求可使用的 informer 缓存，nil 表示直接读取 API Server
+func clusterCache(c *gin.Context) *k8s.ResourceCache {
+	return k8s.CacheFromContext(c)
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/gin-gonic/gin"
+)
+
+// ClusterHandler 集群登记表相关接口
+type ClusterHandler struct {
+	service *service.ClusterService
+}
+
+// NewClusterHandler ...
+func NewClusterHandler(svc *service.ClusterService) *ClusterHandler {
+	return &ClusterHandler{service: svc}
+}
+
+// ListClusters 列出所有已注册集群
+func (h *ClusterHandler) ListClusters(c *gin.Context) {
+	respondSuccess(c, http.StatusOK, h.service.List())
+}
+
+// GetCluster 获取集群详情及实时连接状态
+func (h *ClusterHandler) GetCluster(c *gin.Context) {
+	cluster, err := h.service.Get(c.Param(k8s.ClusterParam))
+	if err != nil {
+		respondClusterError(c, "获取集群失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, cluster)
+}
+
+// GetClusterHealth 获取集群健康状态，?refresh=true 时立即重新探测
+func (h *ClusterHandler) GetClusterHealth(c *gin.Context) {
+	health, err := h.service.Health(c.Param(k8s.ClusterParam), c.Query("refresh") == "true")
+	if err != nil {
+		respondClusterError(c, "获取集群健康状态失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, health)
+}
+
+// AddCluster 上传 kubeconfig 添加集群，连通性校验通过后立即可用
+func (h *ClusterHandler) AddCluster(c *gin.Context) {
+	var req models.AddClusterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "无效的请求格式: "+err.Error())
+		return
+	}
+
+	cluster, err := h.service.Add(&req)
+	if err != nil {
+		respondClusterError(c, "添加集群失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, cluster)
+}
+
+// ValidateCluster 校验 kubeconfig 能否连通集群，不保存
+func (h *ClusterHandler) ValidateCluster(c *gin.Context) {
+	var req models.ValidateClusterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "无效的请求格式: "+err.Error())
+		return
+	}
+
+	result, err := h.service.Validate(req.KubeconfigContent)
+	if err != nil {
+		respondClusterError(c, "校验集群失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, result)
+}
+
+// UpdateCluster 重命名集群或修改描述
+func (h *ClusterHandler) UpdateCluster(c *gin.Context) {
+	var req models.UpdateClusterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "无效的请求格式: "+err.Error())
+		return
+	}
+
+	cluster, err := h.service.Update(c.Param(k8s.ClusterParam), &req)
+	if err != nil {
+		respondClusterError(c, "更新集群失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, cluster)
+}
+
+// DeleteCluster 移除集群
+func (h *ClusterHandler) DeleteCluster(c *gin.Context) {
+	if err := h.service.Delete(c.Param(k8s.ClusterParam)); err != nil {
+		respondClusterError(c, "删除集群失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// ActivateCluster 设置当前激活集群
+func (h *ClusterHandler) ActivateCluster(c *gin.Context) {
+	cluster, err := h.service.Activate(c.Param(k8s.ClusterParam))
+	if err != nil {
+		respondClusterError(c, "切换集群失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, cluster)
+}
+
+// respondClusterError 将集群服务错误映射为 HTTP 状态码
+func respondClusterError(c *gin.Context, prefix string, err error) {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		respondError(c, http.StatusBadRequest, prefix+": "+err.Error())
+	case errors.Is(err, service.ErrClusterNotFound):
+		respondError(c, http.StatusNotFound, prefix+": "+err.Error())
+	case errors.Is(err, service.ErrClusterExists):
+		respondError(c, http.StatusConflict, prefix+": "+err.Error())
+	default:
+		respondError(c, http.StatusInternalServerError, prefix+": "+err.Error())
+	}
+}
@@ -10,14 +10,21 @@ import (
 	"github.com/gin-gonic/gin"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 )
 
-type ConfigMapHandler struct {
-	service *service.ConfigMapService
+type ConfigMapHandler struct{}
+
+func NewConfigMapHandler() *ConfigMapHandler {
+	return &ConfigMapHandler{}
 }
 
-func NewConfigMapHandler(svc *service.ConfigMapService) *ConfigMapHandler {
-	return &ConfigMapHandler{service: svc}
+// service 返回绑定到当前请求目标集群的 ConfigMapService
+func (h *ConfigMapHandler) service(c *gin.Context) *service.ConfigMapService {
+	client := clusterClient(c)
+	svc := service.NewConfigMapService(client.Clientset, clusterCache(c))
+	svc.SetDryRun(dryRunOption(c))
+	return svc
 }
 
 // ListConfigMaps godoc
@@ -28,7 +35,12 @@ func NewConfigMapHandler(svc *service.ConfigMapService) *ConfigMapHandler {
 // @Produce json
 // @Param namespace path string true "Namespace"
 // @Param labelSelector query string false "Label selector for filtering"
-// @Param limit query int false "Maximum number of items to return" default(100)
+// @Param fieldSelector query string false "Field selector for filtering"
+// @Param search query string false "Case-insensitive name substring"
+// @Param sortBy query string false "Sort field" Enums(name, namespace, creationTimestamp)
+// @Param order query string false "Sort order" Enums(asc, desc)
+// @Param limit query int false "Maximum number of items to return (0 = all)"
+// @Param continue query string false "Continue token from the previous page"
 // @Success 200 {object} models.ConfigMapListResponse "List of ConfigMaps"
 // @Failure 400 {object} handlers.ErrorResponse "Bad Request - Invalid Namespace"
 // @Failure 500 {object} handlers.ErrorResponse "Internal Server Error"
@@ -40,18 +52,20 @@ func (h *ConfigMapHandler) ListConfigMaps(c *gin.Context) {
 		return
 	}
 
-	labelSelector := c.Query("labelSelector")
-	limit := utils.ParseInt(c.DefaultQuery("limit", "100"), 100)
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
 
-	cmList, err := h.service.List(namespace, labelSelector, int64(limit))
+	cmList, meta, err := h.service(c).List(namespace, query)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "获取ConfigMap列表失败: "+err.Error())
+		respondListError(c, "获取ConfigMap列表失败", err)
 		return
 	}
 
 	response := models.ConfigMapListResponse{
-		Items: make([]models.ConfigMapResponse, 0, len(cmList.Items)),
-		Total: len(cmList.Items), // Adjust if backend provides actual total
+		Items:    make([]models.ConfigMapResponse, 0, len(cmList.Items)),
+		ListMeta: meta,
 	}
 	for _, cm := range cmList.Items {
 		response.Items = append(response.Items, models.ToConfigMapResponse(&cm))
@@ -81,7 +95,7 @@ func (h *ConfigMapHandler) GetConfigMap(c *gin.Context) {
 		return
 	}
 
-	cm, err := h.service.Get(namespace, name)
+	cm, err := h.service(c).Get(namespace, name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "ConfigMap不存在")
@@ -128,7 +142,7 @@ func (h *ConfigMapHandler) CreateConfigMap(c *gin.Context) {
 		cm.APIVersion = "v1"
 	}
 
-	createdCM, err := h.service.Create(namespace, &cm)
+	createdCM, err := h.service(c).Create(namespace, &cm)
 	if err != nil {
 		if errors.IsAlreadyExists(err) {
 			respondError(c, http.StatusConflict, "ConfigMap已存在")
@@ -184,7 +198,7 @@ func (h *ConfigMapHandler) UpdateConfigMap(c *gin.Context) {
 		cm.APIVersion = "v1"
 	}
 
-	updatedCM, err := h.service.Update(namespace, &cm)
+	updatedCM, err := h.service(c).Update(namespace, &cm)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "ConfigMap不存在")
@@ -204,6 +218,45 @@ func (h *ConfigMapHandler) UpdateConfigMap(c *gin.Context) {
 	respondSuccess(c, http.StatusOK, models.ToConfigMapResponse(updatedCM)) // Return basic info
 }
 
+// PatchConfigMap godoc
+// @Summary Patch a ConfigMap
+// @Description Partially update a ConfigMap; the patch type is selected by Content-Type (strategic merge by default)
+// @Tags ConfigMaps
+// @Accept json
+// @Produce json
+// @Param namespace path string true "Namespace"
+// @Param name path string true "ConfigMap Name"
+// @Param dryRun query string false "Set to All to validate without persisting"
+// @Param force query bool false "Force field ownership for apply patches"
+// @Param body body object true "Patch document"
+// @Success 200 {object} models.ConfigMapResponse "Patched ConfigMap"
+// @Failure 400 {object} handlers.ErrorResponse "Bad Request"
+// @Failure 404 {object} handlers.ErrorResponse "Not Found"
+// @Failure 415 {object} handlers.ErrorResponse "Unsupported patch type"
+// @Failure 500 {object} handlers.ErrorResponse "Internal Server Error"
+// @Router /api/v1/namespaces/{namespace}/configmaps/{name} [patch]
+func (h *ConfigMapHandler) PatchConfigMap(c *gin.Context) {
+	namespace := strings.TrimSpace(c.Param("namespace"))
+	name := strings.TrimSpace(c.Param("name"))
+	if !utils.ValidateNamespace(namespace) || !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间或ConfigMap名称格式")
+		return
+	}
+	patchType, data, ok := readPatchRequest(c, types.StrategicMergePatchType)
+	if !ok {
+		return
+	}
+
+	svc := h.service(c)
+	svc.SetForce(c.Query("force") == "true")
+	patched, err := svc.Patch(namespace, name, patchType, data)
+	if err != nil {
+		respondResourceError(c, "修改ConfigMap失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToConfigMapResponse(patched))
+}
+
 // DeleteConfigMap godoc
 // @Summary Delete a ConfigMap
 // @Description Delete a specific ConfigMap by namespace and name
@@ -225,7 +278,7 @@ func (h *ConfigMapHandler) DeleteConfigMap(c *gin.Context) {
 		return
 	}
 
-	err := h.service.Delete(namespace, name)
+	err := h.service(c).Delete(namespace, name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			c.Status(http.StatusNoContent)
@@ -243,3 +296,21 @@ type ErrorResponse struct { Code int `json:"code"`; Message string `json:"messag
 func respondSuccess(c *gin.Context, code int, data interface{}) { ... }
 func respondError(c *gin.Context, code int, message string) { ... }
 */
+
+// DiffConfigMap godoc
+// @Summary Preview changes to a ConfigMap
+// @Description Server-side dry-run of the submitted ConfigMap and a structured/unified diff against the live object
+// @Tags ConfigMaps
+// @Accept json
+// @Produce json
+// @Param namespace path string true "Namespace"
+// @Param name path string true "ConfigMap Name"
+// @Param mode query string false "Dry-run mode" Enums(update, apply)
+// @Param body body object true "ConfigMap manifest (JSON or YAML)"
+// @Success 200 {object} models.DiffResponse "Diff result"
+// @Failure 400 {object} handlers.ErrorResponse "Bad Request"
+// @Failure 500 {object} handlers.ErrorResponse "Internal Server Error"
+// @Router /api/v1/namespaces/{namespace}/configmaps/{name}/diff [post]
+func (h *ConfigMapHandler) DiffConfigMap(c *gin.Context) {
+	respondDiff(c, corev1.SchemeGroupVersion.WithKind("ConfigMap"), "configmaps", true)
+}
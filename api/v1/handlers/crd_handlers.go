@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// CRDHandler CustomResourceDefinition 浏览接口
+type CRDHandler struct{}
+
+// NewCRDHandler ...
+func NewCRDHandler() *CRDHandler {
+	return &CRDHandler{}
+}
+
+// service 返回绑定到当前请求目标集群的 CRDService
+func (h *CRDHandler) service(c *gin.Context) *service.CRDService {
+	client := clusterClient(c)
+	return service.NewCRDService(client.Dynamic)
+}
+
+// ListCRDs ...
+func (h *CRDHandler) ListCRDs(c *gin.Context) {
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+
+	crds, meta, err := h.service(c).List(query)
+	if err != nil {
+		respondResourceError(c, "获取CRD列表失败", err)
+		return
+	}
+
+	response := models.CRDListResponse{
+		Items:    make([]models.CRDResponse, 0, len(crds)),
+		ListMeta: meta,
+	}
+	for i := range crds {
+		response.Items = append(response.Items, models.ToCRDResponse(&crds[i]))
+	}
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// GetCRD 返回 CRD 的版本、作用域、打印列及 OpenAPI schema
+func (h *CRDHandler) GetCRD(c *gin.Context) {
+	name := strings.TrimSpace(c.Param("name"))
+	if name == "" {
+		respondError(c, http.StatusBadRequest, "CRD名称不能为空")
+		return
+	}
+
+	crd, err := h.service(c).Get(name)
+	if err != nil {
+		respondResourceError(c, "获取CRD失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToCRDDetailResponse(crd))
+}
+
+// ListCustomResources 按 CRD 的 additionalPrinterColumns 返回自定义资源表格，?version= 指定版本
+func (h *CRDHandler) ListCustomResources(c *gin.Context) {
+	name := strings.TrimSpace(c.Param("name"))
+	if name == "" {
+		respondError(c, http.StatusBadRequest, "CRD名称不能为空")
+		return
+	}
+	namespace := strings.TrimSpace(c.Param("namespace"))
+	if namespace != "" && !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+
+	table, err := h.service(c).ListCustomResources(name, strings.TrimSpace(c.Query("version")), namespace, query)
+	if err != nil {
+		respondResourceError(c, "获取自定义资源列表失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, table)
+}
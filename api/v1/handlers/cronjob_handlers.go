@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// CronJobHandler ...
+type CronJobHandler struct{}
+
+// NewCronJobHandler ...
+func NewCronJobHandler() *CronJobHandler {
+	return &CronJobHandler{}
+}
+
+// service 返回绑定到当前请求目标集群的 CronJobService
+func (h *CronJobHandler) service(c *gin.Context) *service.CronJobService {
+	client := clusterClient(c)
+	svc := service.NewCronJobService(client.Clientset, clusterCache(c))
+	svc.SetDryRun(dryRunOption(c))
+	return svc
+}
+
+// ListCronJobs ...
+func (h *CronJobHandler) ListCronJobs(c *gin.Context) {
+	namespace := c.Param("namespace")
+	// 1. 参数校验
+	if !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+
+	// 2. 调用服务层获取CronJob列表
+	cronJobs, meta, err := h.service(c).List(namespace, query)
+	if err != nil {
+		respondListError(c, "获取CronJob列表失败", err)
+		return
+	}
+
+	// 3. 返回结果
+	respondSuccess(c, http.StatusOK, models.NewListResponse(cronJobs.Items, meta))
+}
+
+// CreateCronJob ...
+func (h *CronJobHandler) CreateCronJob(c *gin.Context) {
+	namespace := c.Param("namespace")
+	var req models.CreateCronJobRequest
+
+	// 1. 参数校验
+	if !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "无效的CronJob格式: "+err.Error())
+		return
+	}
+
+	// 2. 调用服务层创建CronJob
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        req.Name,
+			Namespace:   req.Namespace,
+			Labels:      req.Labels,
+			Annotations: req.Annotations,
+		},
+		Spec: req.Spec,
+	}
+
+	createdCronJob, err := h.service(c).Create(namespace, cronJob)
+	if err != nil {
+		respondResourceError(c, "创建CronJob失败", err)
+		return
+	}
+
+	// 3. 返回结果
+	respondSuccess(c, http.StatusOK, models.ToCronJobResponse(createdCronJob))
+}
+
+// GetCronJob ...
+func (h *CronJobHandler) GetCronJob(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	// 1. 参数校验
+	if !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+
+	if !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的CronJob名称格式")
+		return
+	}
+
+	// 2. 调用服务层获取CronJob详情
+	cronJob, err := h.service(c).Get(namespace, name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			respondError(c, http.StatusNotFound, "CronJob不存在")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "获取CronJob失败: "+err.Error())
+		return
+	}
+
+	// 3. 返回结果
+	respondSuccess(c, http.StatusOK, models.ToCronJobResponse(cronJob))
+}
+
+// UpdateCronJob ...
+func (h *CronJobHandler) UpdateCronJob(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	var req models.UpdateCronJobRequest
+
+	// 1. 参数校验
+	if !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+
+	if !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的CronJob名称格式")
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "无效的CronJob格式: "+err.Error())
+		return
+	}
+
+	// 2. 调用服务层更新CronJob
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Labels:      req.Labels,
+			Annotations: req.Annotations,
+		},
+		Spec: req.Spec,
+	}
+
+	updatedCronJob, err := h.service(c).Update(namespace, cronJob)
+	if err != nil {
+		respondResourceError(c, "更新CronJob失败", err)
+		return
+	}
+
+	// 3. 返回结果
+	respondSuccess(c, http.StatusOK, models.ToCronJobResponse(updatedCronJob))
+}
+
+// PatchCronJob 按 Content-Type 选择 patch 类型，默认 strategic merge patch
+func (h *CronJobHandler) PatchCronJob(c *gin.Context) {
+	namespace := strings.TrimSpace(c.Param("namespace"))
+	name := strings.TrimSpace(c.Param("name"))
+	if !utils.ValidateNamespace(namespace) || !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间或CronJob名称格式")
+		return
+	}
+	patchType, data, ok := readPatchRequest(c, types.StrategicMergePatchType)
+	if !ok {
+		return
+	}
+
+	svc := h.service(c)
+	svc.SetForce(c.Query("force") == "true")
+	patched, err := svc.Patch(namespace, name, patchType, data)
+	if err != nil {
+		respondResourceError(c, "修改CronJob失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToCronJobResponse(patched))
+}
+
+// DeleteCronJob ...
+func (h *CronJobHandler) DeleteCronJob(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	// 1. 参数校验
+	if !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+
+	if !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的CronJob名称格式")
+		return
+	}
+
+	// 2. 调用服务层删除CronJob
+	if err := h.service(c).Delete(namespace, name); err != nil {
+		if errors.IsNotFound(err) {
+			respondError(c, http.StatusNotFound, "CronJob不存在")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "删除CronJob失败: "+err.Error())
+		return
+	}
+
+	// 3. 返回结果
+	respondSuccess(c, http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// WatchCronJobs ...
+func (h *CronJobHandler) WatchCronJobs(c *gin.Context) {
+	namespace := c.Param("namespace")
+	// 1. 参数校验
+	if !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+
+	// 2. 调用服务层Watch CronJobs
+	watcher, err := h.service(c).Watch(namespace, c.Query("selector"))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Watch CronJobs失败: "+err.Error())
+		return
+	}
+
+	// 3. 返回结果
+	c.Stream(func(w io.Writer) bool {
+		event, ok := <-watcher.ResultChan()
+		if !ok {
+			return false
+		}
+		c.SSEvent("message", event)
+		return true
+	})
+}
+
+// DiffCronJob 服务端 dry-run 提交的 CronJob，返回与当前对象的差异
+func (h *CronJobHandler) DiffCronJob(c *gin.Context) {
+	respondDiff(c, batchv1.SchemeGroupVersion.WithKind("CronJob"), "cronjobs", true)
+}
+
+// TriggerCronJob godoc
+// @Summary Run a CronJob now
+// @Description 与 kubectl create job --from=cronjob/<name> 相同，按 jobTemplate 立即创建一个 Job
+// @Tags CronJobs
+// @Accept json
+// @Produce json
+// @Param namespace path string true "Namespace"
+// @Param name path string true "CronJob Name"
+// @Param request body models.TriggerCronJobRequest false "可选的 Job 名称"
+// @Param dryRun query string false "Set to All to validate without persisting"
+// @Success 201 {object} models.JobResponse
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/namespaces/{namespace}/cronjobs/{name}/trigger [post]
+func (h *CronJobHandler) TriggerCronJob(c *gin.Context) {
+	namespace, name, ok := workloadParams(c, "CronJob")
+	if !ok {
+		return
+	}
+	var req models.TriggerCronJobRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, "无效的请求格式: "+err.Error())
+			return
+		}
+	}
+	if req.JobName != "" && !utils.ValidateResourceName(req.JobName) {
+		respondError(c, http.StatusBadRequest, "无效的Job名称格式")
+		return
+	}
+
+	job, err := h.service(c).Trigger(namespace, name, req.JobName)
+	if err != nil {
+		respondResourceError(c, "触发CronJob失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, models.ToJobResponse(job))
+}
+
+// SuspendCronJob 暂停调度，已经在运行的 Job 不受影响
+func (h *CronJobHandler) SuspendCronJob(c *gin.Context) {
+	h.setSuspend(c, true)
+}
+
+// ResumeCronJob 恢复调度
+func (h *CronJobHandler) ResumeCronJob(c *gin.Context) {
+	h.setSuspend(c, false)
+}
+
+func (h *CronJobHandler) setSuspend(c *gin.Context, suspend bool) {
+	namespace, name, ok := workloadParams(c, "CronJob")
+	if !ok {
+		return
+	}
+	cronJob, err := h.service(c).SetSuspend(namespace, name, suspend)
+	if err != nil {
+		respondResourceError(c, "修改CronJob的暂停状态失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToCronJobResponse(cronJob))
+}
+
+// GetCronJobHistory godoc
+// @Summary CronJob run history
+// @Description 列出 CronJob 创建的 Job (含手动触发)，最近的在前，并统计运行中、成功和失败的数量
+// @Tags CronJobs
+// @Produce json
+// @Param namespace path string true "Namespace"
+// @Param name path string true "CronJob Name"
+// @Success 200 {object} models.CronJobHistoryResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/namespaces/{namespace}/cronjobs/{name}/history [get]
+func (h *CronJobHandler) GetCronJobHistory(c *gin.Context) {
+	namespace, name, ok := workloadParams(c, "CronJob")
+	if !ok {
+		return
+	}
+	jobs, err := h.service(c).History(namespace, name)
+	if err != nil {
+		respondResourceError(c, "获取CronJob历史失败", err)
+		return
+	}
+
+	history := models.CronJobHistoryResponse{Jobs: make([]models.JobResponse, 0, len(jobs))}
+	for i := range jobs {
+		job := models.ToJobResponse(&jobs[i])
+		switch job.Phase {
+		case models.JobPhaseComplete:
+			history.Succeeded++
+		case models.JobPhaseFailed:
+			history.Failed++
+		default:
+			history.Running++
+		}
+		history.Jobs = append(history.Jobs, job)
+	}
+	respondSuccess(c, http.StatusOK, history)
+}
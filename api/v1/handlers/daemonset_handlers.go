@@ -3,6 +3,7 @@ package handlers
 import (
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/ciliverse/cilikube/api/v1/models"
 	"github.com/ciliverse/cilikube/internal/service"
@@ -12,16 +13,23 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // DaemonSetHandler ...
-type DaemonSetHandler struct {
-	service *service.DaemonSetService
-}
+type DaemonSetHandler struct{}
 
 // NewDaemonSetHandler ...
-func NewDaemonSetHandler(svc *service.DaemonSetService) *DaemonSetHandler {
-	return &DaemonSetHandler{service: svc}
+func NewDaemonSetHandler() *DaemonSetHandler {
+	return &DaemonSetHandler{}
+}
+
+// service 返回绑定到当前请求目标集群的 DaemonSetService
+func (h *DaemonSetHandler) service(c *gin.Context) *service.DaemonSetService {
+	client := clusterClient(c)
+	svc := service.NewDaemonSetService(client.Clientset, clusterCache(c))
+	svc.SetDryRun(dryRunOption(c))
+	return svc
 }
 
 // ListDaemonSets ...
@@ -33,15 +41,20 @@ func (h *DaemonSetHandler) ListDaemonSets(c *gin.Context) {
 		return
 	}
 
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+
 	// 2. 调用服务层获取DaemonSet列表
-	daemonsets, err := h.service.List(namespace, c.Query("selector"))
+	daemonsets, meta, err := h.service(c).List(namespace, query)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "获取DaemonSet列表失败: "+err.Error())
+		respondListError(c, "获取DaemonSet列表失败", err)
 		return
 	}
 
 	// 3. 返回结果
-	respondSuccess(c, http.StatusOK, daemonsets)
+	respondSuccess(c, http.StatusOK, models.NewListResponse(daemonsets.Items, meta))
 }
 
 // CreateDaemonSet ...
@@ -71,7 +84,7 @@ func (h *DaemonSetHandler) CreateDaemonSet(c *gin.Context) {
 		Spec: req.Spec,
 	}
 
-	createdDaemonset, err := h.service.Create(namespace, daemonset)
+	createdDaemonset, err := h.service(c).Create(namespace, daemonset)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "创建DaemonSet失败: "+err.Error())
 		return
@@ -97,7 +110,7 @@ func (h *DaemonSetHandler) GetDaemonSet(c *gin.Context) {
 	}
 
 	// 2. 调用服务层获取DaemonSet详情
-	daemonset, err := h.service.Get(namespace, name)
+	daemonset, err := h.service(c).Get(namespace, name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "DaemonSet不存在")
@@ -144,7 +157,7 @@ func (h *DaemonSetHandler) UpdateDaemonSet(c *gin.Context) {
 		Spec: req.Spec,
 	}
 
-	updatedDaemonset, err := h.service.Update(namespace, daemonset)
+	updatedDaemonset, err := h.service(c).Update(namespace, daemonset)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "更新DaemonSet失败: "+err.Error())
 		return
@@ -154,6 +167,29 @@ func (h *DaemonSetHandler) UpdateDaemonSet(c *gin.Context) {
 	respondSuccess(c, http.StatusOK, models.ToDaemonSetResponse(updatedDaemonset))
 }
 
+// PatchDaemonSet 按 Content-Type 选择 patch 类型，默认 strategic merge patch
+func (h *DaemonSetHandler) PatchDaemonSet(c *gin.Context) {
+	namespace := strings.TrimSpace(c.Param("namespace"))
+	name := strings.TrimSpace(c.Param("name"))
+	if !utils.ValidateNamespace(namespace) || !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间或DaemonSet名称格式")
+		return
+	}
+	patchType, data, ok := readPatchRequest(c, types.StrategicMergePatchType)
+	if !ok {
+		return
+	}
+
+	svc := h.service(c)
+	svc.SetForce(c.Query("force") == "true")
+	patched, err := svc.Patch(namespace, name, patchType, data)
+	if err != nil {
+		respondResourceError(c, "修改DaemonSet失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToDaemonSetResponse(patched))
+}
+
 // DeleteDaemonSet ...
 func (h *DaemonSetHandler) DeleteDaemonSet(c *gin.Context) {
 	namespace := c.Param("namespace")
@@ -171,7 +207,7 @@ func (h *DaemonSetHandler) DeleteDaemonSet(c *gin.Context) {
 	}
 
 	// 2. 调用服务层删除DaemonSet
-	if err := h.service.Delete(namespace, name); err != nil {
+	if err := h.service(c).Delete(namespace, name); err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "DaemonSet不存在")
 			return
@@ -194,7 +230,7 @@ func (h *DaemonSetHandler) WatchDaemonSets(c *gin.Context) {
 	}
 
 	// 2. 调用服务层Watch DaemonSets
-	watcher, err := h.service.Watch(namespace, c.Query("selector"))
+	watcher, err := h.service(c).Watch(namespace, c.Query("selector"))
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Watch DaemonSets失败: "+err.Error())
 		return
@@ -210,3 +246,8 @@ func (h *DaemonSetHandler) WatchDaemonSets(c *gin.Context) {
 		return true
 	})
 }
+
+// DiffDaemonSet 服务端 dry-run 提交的 DaemonSet，返回与当前对象的差异
+func (h *DaemonSetHandler) DiffDaemonSet(c *gin.Context) {
+	respondDiff(c, appsv1.SchemeGroupVersion.WithKind("DaemonSet"), "daemonsets", true)
+}
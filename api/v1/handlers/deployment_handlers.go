@@ -10,20 +10,26 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"net/http"
-	"strconv"
 	"strings"
 )
 
 // DeploymentHandler ...
-type DeploymentHandler struct {
-	service *service.DeploymentService
-}
+type DeploymentHandler struct{}
 
 // NewDeploymentHandler ...
-func NewDeploymentHandler(svc *service.DeploymentService) *DeploymentHandler {
-	return &DeploymentHandler{service: svc}
+func NewDeploymentHandler() *DeploymentHandler {
+	return &DeploymentHandler{}
+}
+
+// service 返回绑定到当前请求目标集群的 DeploymentService
+func (h *DeploymentHandler) service(c *gin.Context) *service.DeploymentService {
+	client := clusterClient(c)
+	svc := service.NewDeploymentService(client.Clientset, clusterCache(c))
+	svc.SetDryRun(dryRunOption(c))
+	return svc
 }
 
 // ListDeployments ...
@@ -35,20 +41,20 @@ func (h *DeploymentHandler) ListDeployments(c *gin.Context) {
 		return
 	}
 
-	// 2. 调用服务层获取Deployment列表
-	deployments, err := h.service.List(namespace)
-	if err != nil {
-		respondError(c, http.StatusInternalServerError, "获取Deployment列表失败: "+err.Error())
+	query, ok := parseListQuery(c)
+	if !ok {
 		return
 	}
 
-	// 无数据的话slice未初始化，返回前端会是null，导致前端报错，特处理。如果前端可以处理，这个判断可删除
-	if len(deployments.Items) == 0 {
-		deployments.Items = make([]appsv1.Deployment, 0)
+	// 2. 调用服务层获取Deployment列表
+	deployments, meta, err := h.service(c).List(namespace, query)
+	if err != nil {
+		respondListError(c, "获取Deployment列表失败", err)
+		return
 	}
 
 	// 3. 返回结果
-	respondSuccess(c, http.StatusOK, deployments)
+	respondSuccess(c, http.StatusOK, models.NewListResponse(deployments.Items, meta))
 
 }
 
@@ -88,7 +94,7 @@ func (h *DeploymentHandler) CreateDeployment(c *gin.Context) {
 	}
 
 	// 调用服务层创建Deployment
-	createdDeployment, err := h.service.Create(namespace, deployment)
+	createdDeployment, err := h.service(c).Create(namespace, deployment)
 	if err != nil {
 		if errors.IsAlreadyExists(err) {
 			respondError(c, http.StatusConflict, "Deployment已存在")
@@ -117,7 +123,7 @@ func (h *DeploymentHandler) GetDeployment(c *gin.Context) {
 	}
 
 	// 2. 调用服务层获取Deployment详情
-	deployment, err := h.service.Get(namespace, name)
+	deployment, err := h.service(c).Get(namespace, name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "Deployment不存在")
@@ -127,9 +133,14 @@ func (h *DeploymentHandler) GetDeployment(c *gin.Context) {
 		return
 	}
 
-	// 3. 返回结果
-	respondSuccess(c, http.StatusOK, models.ToDeploymentResponse(deployment))
-
+	// 3. 返回结果，附带以该 Deployment 为目标的 HPA
+	resp := models.ToDeploymentResponse(deployment)
+	hpa, warning := targetingHPA(c, namespace, "Deployment", name)
+	if hpa != nil {
+		resp.HPA = models.ToHPAReference(hpa)
+	}
+	resp.HPAWarning = warning
+	respondSuccess(c, http.StatusOK, resp)
 }
 
 // UpdateDeployment ...
@@ -174,7 +185,7 @@ func (h *DeploymentHandler) UpdateDeployment(c *gin.Context) {
 	}
 
 	// 调用服务层更新Deployment
-	resultDeployment, err := h.service.Update(namespace, name, updateDeployment)
+	resultDeployment, err := h.service(c).Update(namespace, name, updateDeployment)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "Deployment不存在 (可能在更新期间被删除)")
@@ -191,6 +202,29 @@ func (h *DeploymentHandler) UpdateDeployment(c *gin.Context) {
 	respondSuccess(c, http.StatusOK, models.ToDeploymentResponse(resultDeployment))
 }
 
+// PatchDeployment 按 Content-Type 选择 patch 类型，默认 strategic merge patch
+func (h *DeploymentHandler) PatchDeployment(c *gin.Context) {
+	namespace := strings.TrimSpace(c.Param("namespace"))
+	name := strings.TrimSpace(c.Param("name"))
+	if !utils.ValidateNamespace(namespace) || !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间或Deployment名称格式")
+		return
+	}
+	patchType, data, ok := readPatchRequest(c, types.StrategicMergePatchType)
+	if !ok {
+		return
+	}
+
+	svc := h.service(c)
+	svc.SetForce(c.Query("force") == "true")
+	patched, err := svc.Patch(namespace, name, patchType, data)
+	if err != nil {
+		respondResourceError(c, "修改Deployment失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToDeploymentResponse(patched))
+}
+
 // DeleteDeployment ...
 func (h *DeploymentHandler) DeleteDeployment(c *gin.Context) {
 	namespace := c.Param("namespace")
@@ -206,7 +240,7 @@ func (h *DeploymentHandler) DeleteDeployment(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.Delete(namespace, name); err != nil {
+	if err := h.service(c).Delete(namespace, name); err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "Deployment不存在")
 			return
@@ -230,7 +264,7 @@ func (h *DeploymentHandler) WatchDeployments(c *gin.Context) {
 	labelSelector := c.Query("labelSelector")
 
 	// 创建 Deployment Watcher
-	watcher, err := h.service.Watch(namespace, labelSelector)
+	watcher, err := h.service(c).Watch(namespace, labelSelector)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "开始监听Deployment失败: "+err.Error())
 		return
@@ -292,18 +326,21 @@ func (h *DeploymentHandler) ScaleDeployment(c *gin.Context) {
 	}
 
 	// 2. 调用服务层修改Deployment的副本数
-	deployment, err := h.service.Scale(namespace, name, req.Replicas)
+	deployment, err := h.service(c).Scale(namespace, name, *req.Replicas)
 	if err != nil {
-		if errors.IsNotFound(err) {
-			respondError(c, http.StatusNotFound, "Deployment不存在")
-			return
-		}
-		respondError(c, http.StatusInternalServerError, "修改Deployment的副本数失败: "+err.Error())
+		respondResourceError(c, "修改Deployment的副本数失败", err)
 		return
 	}
 
-	// 3. 返回结果
-	respondSuccess(c, http.StatusOK, models.ToDeploymentResponse(deployment))
+	// 3. 返回结果；存在 HPA 时手动扩缩只是临时的，提示调用方
+	resp := models.ToDeploymentResponse(deployment)
+	hpa, warning := targetingHPA(c, namespace, "Deployment", name)
+	if hpa != nil {
+		resp.HPA = models.ToHPAReference(hpa)
+		resp.ScaleWarning = hpaScaleWarning(hpa, *req.Replicas)
+	}
+	resp.HPAWarning = warning
+	respondSuccess(c, http.StatusOK, resp)
 }
 
 // GetDeploymentPods ...
@@ -322,22 +359,24 @@ func (h *DeploymentHandler) GetDeploymentPods(c *gin.Context) {
 		return
 	}
 
-	limitStr := c.DefaultQuery("limit", "500") // Sensible default limit
-	limit, err := strconv.ParseInt(limitStr, 10, 64)
-	if err != nil || limit <= 0 {
-		limit = 500 // Fallback
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
 	}
 
-	pods, err := h.service.PodList(namespace, name, limit)
+	pods, meta, err := h.service(c).PodList(namespace, name, query)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "获取Pod列表失败: "+err.Error())
+		if errors.IsNotFound(err) {
+			respondError(c, http.StatusNotFound, "Deployment不存在")
+			return
+		}
+		respondListError(c, "获取Pod列表失败", err)
 		return
 	}
 
 	response := models.PodListResponse{
-		Items: make([]models.PodResponse, 0, len(pods.Items)),
-		// Total reflects items *in this batch*. K8s list doesn't give total count easily.
-		Total: len(pods.Items),
+		Items:    make([]models.PodResponse, 0, len(pods.Items)),
+		ListMeta: meta,
 	}
 
 	for _, pod := range pods.Items {
@@ -368,3 +407,8 @@ func toWatchDeploymentEvent(event watch.Event) interface{} {
 	}
 	return resp
 }
+
+// DiffDeployment 服务端 dry-run 提交的 Deployment，返回与当前对象的差异
+func (h *DeploymentHandler) DiffDeployment(c *gin.Context) {
+	respondDiff(c, appsv1.SchemeGroupVersion.WithKind("Deployment"), "deployments", true)
+}
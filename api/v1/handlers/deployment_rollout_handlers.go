@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// GetDeploymentHistory godoc
+// @Summary Deployment rollout history
+// @Description 列出由该 Deployment 创建的 ReplicaSet 对应的版本：版本号、变更原因、镜像及相对上一版本的镜像变化
+// @Tags Deployments
+// @Produce json
+// @Param namespace path string true "Namespace"
+// @Param name path string true "Deployment Name"
+// @Success 200 {object} models.DeploymentHistoryResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/namespaces/{namespace}/deployments/{name}/history [get]
+func (h *DeploymentHandler) GetDeploymentHistory(c *gin.Context) {
+	namespace, name, ok := deploymentParams(c)
+	if !ok {
+		return
+	}
+
+	revisions, err := h.service(c).History(namespace, name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			respondError(c, http.StatusNotFound, "Deployment不存在")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "获取Deployment历史版本失败: "+err.Error())
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.DeploymentHistoryResponse{Revisions: revisions})
+}
+
+// RollbackDeployment godoc
+// @Summary Roll back a Deployment
+// @Description 与 kubectl rollout undo 相同：把 Pod 模板恢复为指定版本，toRevision 为空或 0 时回滚到上一个版本；模板已相同时不做修改
+// @Tags Deployments
+// @Produce json
+// @Param namespace path string true "Namespace"
+// @Param name path string true "Deployment Name"
+// @Param toRevision query int false "目标版本号"
+// @Param dryRun query string false "Set to All to validate without persisting"
+// @Success 200 {object} models.RollbackDeploymentResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/namespaces/{namespace}/deployments/{name}/rollback [post]
+func (h *DeploymentHandler) RollbackDeployment(c *gin.Context) {
+	namespace, name, ok := deploymentParams(c)
+	if !ok {
+		return
+	}
+	toRevision, err := strconv.ParseInt(c.DefaultQuery("toRevision", "0"), 10, 64)
+	if err != nil || toRevision < 0 {
+		respondError(c, http.StatusBadRequest, "无效的toRevision，应为非负整数")
+		return
+	}
+
+	deployment, revision, skipped, err := h.service(c).Rollback(namespace, name, toRevision)
+	if err != nil {
+		switch {
+		case errors.IsNotFound(err):
+			respondError(c, http.StatusNotFound, "Deployment不存在")
+		case stderrors.Is(err, service.ErrRevisionNotFound), stderrors.Is(err, service.ErrNoRolloutHistory):
+			respondError(c, http.StatusNotFound, err.Error())
+		case stderrors.Is(err, service.ErrDeploymentPaused), errors.IsConflict(err):
+			respondError(c, http.StatusConflict, err.Error())
+		default:
+			respondError(c, http.StatusInternalServerError, "回滚Deployment失败: "+err.Error())
+		}
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.RollbackDeploymentResponse{
+		Revision:   revision,
+		Skipped:    skipped,
+		Deployment: models.ToDeploymentResponse(deployment),
+	})
+}
+
+// deploymentParams 读取并校验路径中的命名空间和 Deployment 名称，校验失败时已写入响应
+func deploymentParams(c *gin.Context) (string, string, bool) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	if !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return "", "", false
+	}
+	if !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的Deployment名称格式")
+		return "", "", false
+	}
+	return namespace, name, true
+}
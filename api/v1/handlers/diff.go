@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// dryRunOption 解析写操作的 ?dryRun=All (兼容 ?dryRun=true)，其它值原样交给 API Server 校验
+func dryRunOption(c *gin.Context) []string {
+	value := strings.TrimSpace(c.Query("dryRun"))
+	switch strings.ToLower(value) {
+	case "", "false":
+		return nil
+	case "true", "all":
+		return []string{metav1.DryRunAll}
+	default:
+		return []string{value}
+	}
+}
+
+// respondDiff 处理各资源的 POST .../:name/diff：请求体为完整对象 (JSON 或 YAML)，
+// 缺少 apiVersion/kind 时按 gvk 补全；?mode=apply 时请求体可只包含需要修改的字段。
+// namespaced 为 false 表示集群级资源
+func respondDiff(c *gin.Context, gvk schema.GroupVersionKind, resource string, namespaced bool) {
+	namespace := ""
+	if namespaced {
+		namespace = strings.TrimSpace(c.Param("namespace"))
+		if !utils.ValidateNamespace(namespace) {
+			respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+			return
+		}
+	}
+	name := strings.TrimSpace(c.Param("name"))
+	if !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的资源名称格式")
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "读取请求体失败: "+err.Error())
+		return
+	}
+	obj, err := service.DecodeUnstructured(body)
+	if err != nil {
+		respondResourceError(c, "生成差异失败", err)
+		return
+	}
+	if obj.GetAPIVersion() == "" {
+		obj.SetAPIVersion(gvk.GroupVersion().String())
+	}
+	if obj.GetKind() == "" {
+		obj.SetKind(gvk.Kind)
+	}
+
+	diffService := service.NewDiffService(clusterClient(c).Dynamic)
+	result, err := diffService.Diff(gvk.GroupVersion().WithResource(resource), namespace, name, obj, c.Query("mode"))
+	if err != nil {
+		respondResourceError(c, "生成差异失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, result)
+}
@@ -8,14 +8,16 @@ import (
 	"strings"
 )
 
-type EventsHandler struct {
-	service *service.EventsService
+type EventsHandler struct{}
+
+func NewEventsHandler() *EventsHandler {
+	return &EventsHandler{}
 }
 
-func NewEventsHandler(svc *service.EventsService) *EventsHandler {
-	return &EventsHandler{
-		service: svc,
-	}
+// service 返回绑定到当前请求目标集群的 EventsService
+func (h *EventsHandler) service(c *gin.Context) *service.EventsService {
+	client := clusterClient(c)
+	return service.NewEventsService(client.Clientset)
 }
 
 func (h *EventsHandler) ListEventsHandler(c *gin.Context) {
@@ -24,7 +26,15 @@ func (h *EventsHandler) ListEventsHandler(c *gin.Context) {
 		respondError(c, http.StatusBadRequest, "无效的命名空间")
 		return
 	}
-	events := h.service.List(namespace)
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+	events, err := h.service(c).List(namespace, query)
+	if err != nil {
+		respondListError(c, "获取事件列表失败", err)
+		return
+	}
 	respondSuccess(c, http.StatusOK, events)
 }
 
@@ -39,6 +49,6 @@ func (h *EventsHandler) GetEventsHandler(c *gin.Context) {
 		respondError(c, http.StatusBadRequest, "事件名称不能为空")
 		return
 	}
-	event := h.service.Get(namespace, name)
+	event := h.service(c).Get(namespace, name)
 	respondSuccess(c, http.StatusOK, event)
 }
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// HPAHandler ...
+type HPAHandler struct{}
+
+// NewHPAHandler ...
+func NewHPAHandler() *HPAHandler {
+	return &HPAHandler{}
+}
+
+// service 返回绑定到当前请求目标集群的 HPAService
+func (h *HPAHandler) service(c *gin.Context) *service.HPAService {
+	client := clusterClient(c)
+	svc := service.NewHPAService(client.Clientset, clusterCache(c))
+	svc.SetDryRun(dryRunOption(c))
+	return svc
+}
+
+// ListHPAs ...
+func (h *HPAHandler) ListHPAs(c *gin.Context) {
+	namespace := c.Param("namespace")
+	// 1. 参数校验
+	if !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+
+	// 2. 调用服务层获取HPA列表
+	hpas, meta, err := h.service(c).List(namespace, query)
+	if err != nil {
+		respondListError(c, "获取HPA列表失败", err)
+		return
+	}
+
+	// 3. 返回结果
+	response := models.HPAListResponse{
+		Items:    make([]models.HPAResponse, 0, len(hpas.Items)),
+		ListMeta: meta,
+	}
+	for i := range hpas.Items {
+		response.Items = append(response.Items, models.ToHPAResponse(&hpas.Items[i]))
+	}
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// CreateHPA ...
+func (h *HPAHandler) CreateHPA(c *gin.Context) {
+	namespace := c.Param("namespace")
+	var req models.CreateHPARequest
+
+	// 1. 参数校验
+	if !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "无效的HPA格式: "+err.Error())
+		return
+	}
+
+	// 2. 调用服务层创建HPA
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        req.Name,
+			Namespace:   req.Namespace,
+			Labels:      req.Labels,
+			Annotations: req.Annotations,
+		},
+		Spec: req.Spec,
+	}
+
+	createdHPA, err := h.service(c).Create(namespace, hpa)
+	if err != nil {
+		respondResourceError(c, "创建HPA失败", err)
+		return
+	}
+
+	// 3. 返回结果
+	respondSuccess(c, http.StatusOK, models.ToHPAResponse(createdHPA))
+}
+
+// GetHPA ...
+func (h *HPAHandler) GetHPA(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	// 1. 参数校验
+	if !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+
+	if !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的HPA名称格式")
+		return
+	}
+
+	// 2. 调用服务层获取HPA详情
+	hpa, err := h.service(c).Get(namespace, name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			respondError(c, http.StatusNotFound, "HPA不存在")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "获取HPA失败: "+err.Error())
+		return
+	}
+
+	// 3. 返回结果
+	respondSuccess(c, http.StatusOK, models.ToHPAResponse(hpa))
+}
+
+// UpdateHPA ...
+func (h *HPAHandler) UpdateHPA(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	var req models.UpdateHPARequest
+
+	// 1. 参数校验
+	if !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+
+	if !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的HPA名称格式")
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "无效的HPA格式: "+err.Error())
+		return
+	}
+
+	// 2. 调用服务层更新HPA
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Labels:      req.Labels,
+			Annotations: req.Annotations,
+		},
+		Spec: req.Spec,
+	}
+
+	updatedHPA, err := h.service(c).Update(namespace, hpa)
+	if err != nil {
+		respondResourceError(c, "更新HPA失败", err)
+		return
+	}
+
+	// 3. 返回结果
+	respondSuccess(c, http.StatusOK, models.ToHPAResponse(updatedHPA))
+}
+
+// PatchHPA 按 Content-Type 选择 patch 类型，默认 strategic merge patch
+func (h *HPAHandler) PatchHPA(c *gin.Context) {
+	namespace := strings.TrimSpace(c.Param("namespace"))
+	name := strings.TrimSpace(c.Param("name"))
+	if !utils.ValidateNamespace(namespace) || !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间或HPA名称格式")
+		return
+	}
+	patchType, data, ok := readPatchRequest(c, types.StrategicMergePatchType)
+	if !ok {
+		return
+	}
+
+	svc := h.service(c)
+	svc.SetForce(c.Query("force") == "true")
+	patched, err := svc.Patch(namespace, name, patchType, data)
+	if err != nil {
+		respondResourceError(c, "修改HPA失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToHPAResponse(patched))
+}
+
+// DeleteHPA ...
+func (h *HPAHandler) DeleteHPA(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	// 1. 参数校验
+	if !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+
+	if !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的HPA名称格式")
+		return
+	}
+
+	// 2. 调用服务层删除HPA
+	if err := h.service(c).Delete(namespace, name); err != nil {
+		if errors.IsNotFound(err) {
+			respondError(c, http.StatusNotFound, "HPA不存在")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "删除HPA失败: "+err.Error())
+		return
+	}
+
+	// 3. 返回结果
+	respondSuccess(c, http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// WatchHPAs ...
+func (h *HPAHandler) WatchHPAs(c *gin.Context) {
+	namespace := c.Param("namespace")
+	// 1. 参数校验
+	if !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+
+	// 2. 调用服务层Watch HPAs
+	watcher, err := h.service(c).Watch(namespace, c.Query("selector"))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Watch HPAs失败: "+err.Error())
+		return
+	}
+
+	// 3. 返回结果
+	c.Stream(func(w io.Writer) bool {
+		event, ok := <-watcher.ResultChan()
+		if !ok {
+			return false
+		}
+		c.SSEvent("message", event)
+		return true
+	})
+}
+
+// DiffHPA 服务端 dry-run 提交的 HPA，返回与当前对象的差异
+func (h *HPAHandler) DiffHPA(c *gin.Context) {
+	respondDiff(c, autoscalingv2.SchemeGroupVersion.WithKind("HorizontalPodAutoscaler"), "horizontalpodautoscalers", true)
+}
+
+// targetingHPA 查找以该工作负载为目标的 HPA。查询失败 (例如没有 autoscaling/v2 的权限) 不影响工作负载本身的接口，
+// 返回的提示放入响应的 hpaWarning 字段，告知调用方 HPA 状态未知
+func targetingHPA(c *gin.Context, namespace, kind, name string) (*autoscalingv2.HorizontalPodAutoscaler, string) {
+	hpa, err := service.NewHPAService(clusterClient(c).Clientset, clusterCache(c)).ForTarget(namespace, kind, name)
+	if err != nil {
+		log.Printf("查询%s %s/%s 的HPA失败: %v", kind, namespace, name, err)
+		return nil, "无法确认是否有HPA管理该" + kind + ": " + err.Error()
+	}
+	return hpa, ""
+}
+
+// hpaScaleWarning 工作负载受 HPA 控制时，手动设置的副本数会在 HPA 下一次同步时被覆盖
+func hpaScaleWarning(hpa *autoscalingv2.HorizontalPodAutoscaler, replicas int32) string {
+	return fmt.Sprintf("HPA %s 管理该工作负载的副本数 (%d-%d)，手动设置的 %d 个副本会被自动扩缩覆盖",
+		hpa.Name, models.ToHPAReference(hpa).MinReplicas, hpa.Spec.MaxReplicas, replicas)
+}
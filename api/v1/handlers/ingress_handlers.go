@@ -3,6 +3,7 @@ package handlers
 import (
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/ciliverse/cilikube/api/v1/models"
 	"github.com/ciliverse/cilikube/internal/service"
@@ -11,16 +12,23 @@ import (
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // IngressHandler ...
-type IngressHandler struct {
-	service *service.IngressService
-}
+type IngressHandler struct{}
 
 // NewIngressHandler ...
-func NewIngressHandler(svc *service.IngressService) *IngressHandler {
-	return &IngressHandler{service: svc}
+func NewIngressHandler() *IngressHandler {
+	return &IngressHandler{}
+}
+
+// service 返回绑定到当前请求目标集群的 IngressService
+func (h *IngressHandler) service(c *gin.Context) *service.IngressService {
+	client := clusterClient(c)
+	svc := service.NewIngressService(client.Clientset, clusterCache(c))
+	svc.SetDryRun(dryRunOption(c))
+	return svc
 }
 
 // ListIngresses ...
@@ -32,20 +40,20 @@ func (h *IngressHandler) ListIngresses(c *gin.Context) {
 		return
 	}
 
-	// 2. 调用服务层获取Ingress列表
-	ingresses, err := h.service.List(namespace, c.Query("selector"), 0)
-	if err != nil {
-		respondError(c, http.StatusInternalServerError, "获取Ingress列表失败: "+err.Error())
+	query, ok := parseListQuery(c)
+	if !ok {
 		return
 	}
 
-	// 修复空列表报错问题
-	if ingresses.Items == nil {
-		ingresses.Items = make([]networkingv1.Ingress, 0)
+	// 2. 调用服务层获取Ingress列表
+	ingresses, meta, err := h.service(c).List(namespace, query)
+	if err != nil {
+		respondListError(c, "获取Ingress列表失败", err)
+		return
 	}
 
 	// 3. 返回结果
-	respondSuccess(c, http.StatusOK, ingresses)
+	respondSuccess(c, http.StatusOK, models.NewListResponse(ingresses.Items, meta))
 }
 
 // CreateIngress ...
@@ -75,7 +83,7 @@ func (h *IngressHandler) CreateIngress(c *gin.Context) {
 		Spec: req.Spec,
 	}
 
-	createdIngress, err := h.service.Create(namespace, ingress)
+	createdIngress, err := h.service(c).Create(namespace, ingress)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "创建Ingress失败: "+err.Error())
 		return
@@ -101,7 +109,7 @@ func (h *IngressHandler) GetIngress(c *gin.Context) {
 	}
 
 	// 2. 调用服务层获取Ingress详情
-	ingress, err := h.service.Get(namespace, name)
+	ingress, err := h.service(c).Get(namespace, name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "Ingress不存在")
@@ -148,7 +156,7 @@ func (h *IngressHandler) UpdateIngress(c *gin.Context) {
 		Spec: req.Spec,
 	}
 
-	updatedIngress, err := h.service.Update(namespace, ingress)
+	updatedIngress, err := h.service(c).Update(namespace, ingress)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "更新Ingress失败: "+err.Error())
 		return
@@ -158,6 +166,29 @@ func (h *IngressHandler) UpdateIngress(c *gin.Context) {
 	respondSuccess(c, http.StatusOK, models.ToIngressResponse(updatedIngress))
 }
 
+// PatchIngress 按 Content-Type 选择 patch 类型，默认 strategic merge patch
+func (h *IngressHandler) PatchIngress(c *gin.Context) {
+	namespace := strings.TrimSpace(c.Param("namespace"))
+	name := strings.TrimSpace(c.Param("name"))
+	if !utils.ValidateNamespace(namespace) || !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间或Ingress名称格式")
+		return
+	}
+	patchType, data, ok := readPatchRequest(c, types.StrategicMergePatchType)
+	if !ok {
+		return
+	}
+
+	svc := h.service(c)
+	svc.SetForce(c.Query("force") == "true")
+	patched, err := svc.Patch(namespace, name, patchType, data)
+	if err != nil {
+		respondResourceError(c, "修改Ingress失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToIngressResponse(patched))
+}
+
 // DeleteIngress ...
 func (h *IngressHandler) DeleteIngress(c *gin.Context) {
 	namespace := c.Param("namespace")
@@ -175,7 +206,7 @@ func (h *IngressHandler) DeleteIngress(c *gin.Context) {
 	}
 
 	// 2. 调用服务层删除Ingress
-	if err := h.service.Delete(namespace, name); err != nil {
+	if err := h.service(c).Delete(namespace, name); err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "Ingress不存在")
 			return
@@ -198,7 +229,7 @@ func (h *IngressHandler) WatchIngresses(c *gin.Context) {
 	}
 
 	// 2. 调用服务层Watch Ingresses
-	watcher, err := h.service.Watch(namespace, c.Query("selector"))
+	watcher, err := h.service(c).Watch(namespace, c.Query("selector"))
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Watch Ingresses失败: "+err.Error())
 		return
@@ -214,3 +245,8 @@ func (h *IngressHandler) WatchIngresses(c *gin.Context) {
 		return true
 	})
 }
+
+// DiffIngress 服务端 dry-run 提交的 Ingress，返回与当前对象的差异
+func (h *IngressHandler) DiffIngress(c *gin.Context) {
+	respondDiff(c, networkingv1.SchemeGroupVersion.WithKind("Ingress"), "ingresses", true)
+}
@@ -0,0 +1,399 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// JobHandler ...
+type JobHandler struct{}
+
+// NewJobHandler ...
+func NewJobHandler() *JobHandler {
+	return &JobHandler{}
+}
+
+// service 返回绑定到当前请求目标集群的 JobService
+func (h *JobHandler) service(c *gin.Context) *service.JobService {
+	client := clusterClient(c)
+	svc := service.NewJobService(client.Clientset)
+	svc.SetDryRun(dryRunOption(c))
+	return svc
+}
+
+// ListJobs ...
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	namespace := c.Param("namespace")
+	// 1. 参数校验
+	if !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+
+	// 2. 调用服务层获取Job列表
+	jobs, meta, err := h.service(c).List(namespace, query)
+	if err != nil {
+		respondListError(c, "获取Job列表失败", err)
+		return
+	}
+
+	// 3. 返回结果
+	respondSuccess(c, http.StatusOK, models.NewListResponse(jobs.Items, meta))
+}
+
+// CreateJob ...
+func (h *JobHandler) CreateJob(c *gin.Context) {
+	namespace := c.Param("namespace")
+	var req models.CreateJobRequest
+
+	// 1. 参数校验
+	if !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "无效的Job格式: "+err.Error())
+		return
+	}
+
+	// 2. 调用服务层创建Job
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        req.Name,
+			Namespace:   req.Namespace,
+			Labels:      req.Labels,
+			Annotations: req.Annotations,
+		},
+		Spec: req.Spec,
+	}
+
+	createdJob, err := h.service(c).Create(namespace, job)
+	if err != nil {
+		respondResourceError(c, "创建Job失败", err)
+		return
+	}
+
+	// 3. 返回结果
+	respondSuccess(c, http.StatusOK, models.ToJobResponse(createdJob))
+}
+
+// GetJob ...
+func (h *JobHandler) GetJob(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	// 1. 参数校验
+	if !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+
+	if !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的Job名称格式")
+		return
+	}
+
+	// 2. 调用服务层获取Job详情
+	job, err := h.service(c).Get(namespace, name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			respondError(c, http.StatusNotFound, "Job不存在")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "获取Job失败: "+err.Error())
+		return
+	}
+
+	// 3. 返回结果
+	respondSuccess(c, http.StatusOK, models.ToJobResponse(job))
+}
+
+// UpdateJob ...
+func (h *JobHandler) UpdateJob(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	var req models.UpdateJobRequest
+
+	// 1. 参数校验
+	if !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+
+	if !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的Job名称格式")
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "无效的Job格式: "+err.Error())
+		return
+	}
+
+	// 2. 调用服务层更新Job
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Labels:      req.Labels,
+			Annotations: req.Annotations,
+		},
+		Spec: req.Spec,
+	}
+
+	updatedJob, err := h.service(c).Update(namespace, job)
+	if err != nil {
+		respondResourceError(c, "更新Job失败", err)
+		return
+	}
+
+	// 3. 返回结果
+	respondSuccess(c, http.StatusOK, models.ToJobResponse(updatedJob))
+}
+
+// PatchJob 按 Content-Type 选择 patch 类型，默认 strategic merge patch
+func (h *JobHandler) PatchJob(c *gin.Context) {
+	namespace := strings.TrimSpace(c.Param("namespace"))
+	name := strings.TrimSpace(c.Param("name"))
+	if !utils.ValidateNamespace(namespace) || !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间或Job名称格式")
+		return
+	}
+	patchType, data, ok := readPatchRequest(c, types.StrategicMergePatchType)
+	if !ok {
+		return
+	}
+
+	svc := h.service(c)
+	svc.SetForce(c.Query("force") == "true")
+	patched, err := svc.Patch(namespace, name, patchType, data)
+	if err != nil {
+		respondResourceError(c, "修改Job失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToJobResponse(patched))
+}
+
+// DeleteJob ...
+func (h *JobHandler) DeleteJob(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	// 1. 参数校验
+	if !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+
+	if !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的Job名称格式")
+		return
+	}
+
+	// 2. 调用服务层删除Job
+	if err := h.service(c).Delete(namespace, name); err != nil {
+		if errors.IsNotFound(err) {
+			respondError(c, http.StatusNotFound, "Job不存在")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "删除Job失败: "+err.Error())
+		return
+	}
+
+	// 3. 返回结果
+	respondSuccess(c, http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// WatchJobs ...
+func (h *JobHandler) WatchJobs(c *gin.Context) {
+	namespace := c.Param("namespace")
+	// 1. 参数校验
+	if !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+
+	// 2. 调用服务层Watch Jobs
+	watcher, err := h.service(c).Watch(namespace, c.Query("selector"))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Watch Jobs失败: "+err.Error())
+		return
+	}
+
+	// 3. 返回结果
+	c.Stream(func(w io.Writer) bool {
+		event, ok := <-watcher.ResultChan()
+		if !ok {
+			return false
+		}
+		c.SSEvent("message", event)
+		return true
+	})
+}
+
+// DiffJob 服务端 dry-run 提交的 Job，返回与当前对象的差异
+func (h *JobHandler) DiffJob(c *gin.Context) {
+	respondDiff(c, batchv1.SchemeGroupVersion.WithKind("Job"), "jobs", true)
+}
+
+// GetJobPods 列出 Job 创建的 Pod，最近一次尝试在前
+func (h *JobHandler) GetJobPods(c *gin.Context) {
+	namespace, name, ok := workloadParams(c, "Job")
+	if !ok {
+		return
+	}
+	pods, err := h.service(c).Pods(namespace, name)
+	if err != nil {
+		respondResourceError(c, "获取Job的Pod列表失败", err)
+		return
+	}
+	response := models.PodListResponse{
+		Items:    make([]models.PodResponse, 0, len(pods)),
+		ListMeta: models.ListMeta{Total: len(pods)},
+	}
+	for i := range pods {
+		response.Items = append(response.Items, models.ToPodResponse(&pods[i]))
+	}
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// GetJobLogs godoc
+// @Summary Stream logs of a Job pod
+// @Description 与 kubectl logs job/<name> 类似，默认读取最近创建的 Pod 的第一个容器；可用 pod、container 指定
+// @Tags Jobs
+// @Produce text/event-stream
+// @Param namespace path string true "Namespace"
+// @Param name path string true "Job Name"
+// @Param pod query string false "Pod 名称，必须属于该 Job"
+// @Param container query string false "容器名称"
+// @Param tailLines query int false "返回末尾的行数，默认 100"
+// @Param timestamps query bool false "是否带时间戳"
+// @Router /api/v1/namespaces/{namespace}/jobs/{name}/logs [get]
+func (h *JobHandler) GetJobLogs(c *gin.Context) {
+	namespace, name, ok := workloadParams(c, "Job")
+	if !ok {
+		return
+	}
+	tailLinesStr := c.Query("tailLines")
+	if tailLinesStr != "" {
+		if tailLines, err := strconv.ParseInt(tailLinesStr, 10, 64); err != nil || tailLines <= 0 {
+			respondError(c, http.StatusBadRequest, "无效的 'tailLines' 参数")
+			return
+		}
+	}
+
+	pods, err := h.service(c).Pods(namespace, name)
+	if err != nil {
+		respondResourceError(c, "获取Job的Pod列表失败", err)
+		return
+	}
+	if len(pods) == 0 {
+		respondError(c, http.StatusNotFound, "Job还没有创建Pod")
+		return
+	}
+	pod := &pods[0]
+	if podName := c.Query("pod"); podName != "" {
+		pod = nil
+		for i := range pods {
+			if pods[i].Name == podName {
+				pod = &pods[i]
+				break
+			}
+		}
+		if pod == nil {
+			respondError(c, http.StatusNotFound, fmt.Sprintf("Pod '%s' 不属于Job '%s'", podName, name))
+			return
+		}
+	}
+	container := c.Query("container")
+	if container == "" && len(pod.Spec.Containers) > 0 {
+		container = pod.Spec.Containers[0].Name
+	}
+
+	client := clusterClient(c)
+	podService := service.NewPodService(client.Clientset, client.Config, clusterCache(c))
+	streamPodLogs(c, podService, namespace, pod.Name, buildLogOptions(container, c.Query("timestamps") == "true", tailLinesStr))
+}
+
+// SuspendJob 暂停 Job，控制器会终止正在运行的 Pod
+func (h *JobHandler) SuspendJob(c *gin.Context) {
+	h.setSuspend(c, true)
+}
+
+// ResumeJob 恢复已暂停的 Job
+func (h *JobHandler) ResumeJob(c *gin.Context) {
+	h.setSuspend(c, false)
+}
+
+func (h *JobHandler) setSuspend(c *gin.Context, suspend bool) {
+	namespace, name, ok := workloadParams(c, "Job")
+	if !ok {
+		return
+	}
+	job, err := h.service(c).SetSuspend(namespace, name, suspend)
+	if err != nil {
+		respondResourceError(c, "修改Job的暂停状态失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToJobResponse(job))
+}
+
+// CleanupJobs godoc
+// @Summary Delete finished Jobs
+// @Description 删除命名空间中已结束 (成功或失败) 的 Job 及其 Pod，运行中和暂停的 Job 不受影响。中途失败时错误响应的 data.deleted 为已经删除的 Job
+// @Tags Jobs
+// @Produce json
+// @Param namespace path string true "Namespace"
+// @Param status query string false "succeeded 或 failed，为空时两者都清理"
+// @Param olderThan query string false "只清理结束时间早于该时长的 Job，例如 24h"
+// @Param labelSelector query string false "标签选择器"
+// @Param dryRun query string false "Set to All to validate without persisting"
+// @Success 200 {object} models.CleanupJobsResponse
+// @Router /api/v1/namespaces/{namespace}/jobs [delete]
+func (h *JobHandler) CleanupJobs(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return
+	}
+	var query models.CleanupJobsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondError(c, http.StatusBadRequest, "无效的清理条件: "+err.Error())
+		return
+	}
+
+	deleted, err := h.service(c).Cleanup(namespace, query)
+	if err != nil {
+		if deleted == nil {
+			respondResourceError(c, "清理Job失败", err)
+			return
+		}
+		// 中途失败时前面的 Job 已经删除，错误响应中同样带上它们
+		code := resourceErrorStatus(err)
+		c.AbortWithStatusJSON(code, gin.H{
+			"code":    code,
+			"data":    models.CleanupJobsResponse{Deleted: deleted},
+			"message": "清理Job失败: " + err.Error(),
+		})
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.CleanupJobsResponse{Deleted: deleted})
+}
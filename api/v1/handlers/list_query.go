@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// parseListQuery 解析所有列表接口共用的查询参数:
+// limit, continue, labelSelector (兼容旧参数 selector), fieldSelector, sortBy, order, search。
+// 未指定 limit 时使用 models.DefaultListLimit，limit=0 表示不分页。
+// 参数无效时已写入 400 响应，返回 false
+func parseListQuery(c *gin.Context) (models.ListQuery, bool) {
+	query := models.ListQuery{
+		Limit:         models.DefaultListLimit,
+		Continue:      strings.TrimSpace(c.Query("continue")),
+		LabelSelector: strings.TrimSpace(c.Query("labelSelector")),
+		FieldSelector: strings.TrimSpace(c.Query("fieldSelector")),
+		SortBy:        strings.TrimSpace(c.Query("sortBy")),
+		Order:         strings.ToLower(strings.TrimSpace(c.Query("order"))),
+		Search:        strings.TrimSpace(c.Query("search")),
+	}
+	if query.LabelSelector == "" {
+		query.LabelSelector = strings.TrimSpace(c.Query("selector"))
+	}
+	if limitStr := strings.TrimSpace(c.Query("limit")); limitStr != "" {
+		limit, err := strconv.ParseInt(limitStr, 10, 64)
+		if err != nil || limit < 0 {
+			respondError(c, http.StatusBadRequest, "无效的 limit 参数: "+limitStr)
+			return query, false
+		}
+		query.Limit = limit
+	}
+	if err := service.ValidateListQuery(query); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return query, false
+	}
+	return query, true
+}
+
+// respondListError 列表查询失败：参数错误 (如无效的选择器或 continue) 返回 400，其余返回 500
+func respondListError(c *gin.Context, message string, err error) {
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		respondError(c, http.StatusBadRequest, message+": "+err.Error())
+		return
+	}
+	respondError(c, http.StatusInternalServerError, message+": "+err.Error())
+}
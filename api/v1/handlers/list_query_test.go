@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseListQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name   string
+		query  string
+		want   models.ListQuery
+		status int // 非 0 时期望解析失败并返回该状态码
+	}{
+		{
+			name: "defaults",
+			want: models.ListQuery{Limit: models.DefaultListLimit},
+		},
+		{
+			name:  "all parameters",
+			query: "?limit=20&continue=offset:40&labelSelector=app%3Dweb&fieldSelector=status.phase%3DRunning&sortBy=creationTimestamp&order=DESC&search=+web+",
+			want: models.ListQuery{
+				Limit: 20, Continue: "offset:40", LabelSelector: "app=web", FieldSelector: "status.phase=Running",
+				SortBy: models.SortByCreationTimestamp, Order: models.SortOrderDesc, Search: "web",
+			},
+		},
+		{
+			name:  "limit 0 disables paging",
+			query: "?limit=0",
+			want:  models.ListQuery{},
+		},
+		{
+			name:  "legacy selector parameter",
+			query: "?selector=app%3Dweb",
+			want:  models.ListQuery{Limit: models.DefaultListLimit, LabelSelector: "app=web"},
+		},
+		{
+			name:  "labelSelector wins over selector",
+			query: "?selector=app%3Dold&labelSelector=app%3Dnew",
+			want:  models.ListQuery{Limit: models.DefaultListLimit, LabelSelector: "app=new"},
+		},
+		{name: "non-numeric limit", query: "?limit=ten", status: http.StatusBadRequest},
+		{name: "negative limit", query: "?limit=-1", status: http.StatusBadRequest},
+		{name: "unknown sortBy", query: "?sortBy=size", status: http.StatusBadRequest},
+		{name: "unknown order", query: "?order=up", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/pods"+tt.query, nil)
+
+			query, ok := parseListQuery(c)
+			if tt.status != 0 {
+				assert.False(t, ok)
+				assert.Equal(t, tt.status, w.Code)
+				return
+			}
+			assert.True(t, ok, w.Body.String())
+			assert.Equal(t, tt.want, query)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ListLoginEvents 查询登录审计（管理员）
+// @Summary 查询登录审计
+// @Description 按用户名、来源 IP、是否成功分页查询登录记录，按时间倒序
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Param username query string false "用户名"
+// @Param ip query string false "来源 IP"
+// @Param success query bool false "是否成功"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页大小" default(10)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/auth/login-events [get]
+func (h *AuthHandler) ListLoginEvents(c *gin.Context) {
+	var query models.LoginEventQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 || query.PageSize > 100 {
+		query.PageSize = 10
+	}
+
+	events, total, err := h.authService.ListLoginEvents(&query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取登录审计失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"events":    events,
+			"total":     total,
+			"page":      query.Page,
+			"page_size": query.PageSize,
+		},
+	})
+}
+
+func loginClient(c *gin.Context) models.LoginClient {
+	return models.LoginClient{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
+// respondLoginLocked 账号或来源 IP 被锁定时返回 429 和 Retry-After
+func respondLoginLocked(c *gin.Context, err error) bool {
+	var locked *service.LoginLockedError
+	if !errors.As(err, &locked) {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"code":    429,
+		"message": err.Error(),
+	})
+	return true
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/ciliverse/cilikube/api/v1/models"
 	"github.com/ciliverse/cilikube/internal/service"
@@ -11,29 +12,41 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // NamespaceHandler ...
-type NamespaceHandler struct {
-	service *service.NamespaceService
-}
+type NamespaceHandler struct{}
 
 // NewNamespaceHandler ...
-func NewNamespaceHandler(svc *service.NamespaceService) *NamespaceHandler {
-	return &NamespaceHandler{service: svc}
+func NewNamespaceHandler() *NamespaceHandler {
+	return &NamespaceHandler{}
+}
+
+// service 返回绑定到当前请求目标集群的 NamespaceService
+func (h *NamespaceHandler) service(c *gin.Context) *service.NamespaceService {
+	client := clusterClient(c)
+	svc := service.NewNamespaceService(client.Clientset, clusterCache(c))
+	svc.SetDryRun(dryRunOption(c))
+	return svc
 }
 
 // ListNamespaces ...
 func (h *NamespaceHandler) ListNamespaces(c *gin.Context) {
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+
 	// 1. 调用服务层获取Namespace列表
-	namespaces, err := h.service.List(c.Query("selector"), 0)
+	namespaces, meta, err := h.service(c).List(query)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "获取Namespace列表失败: "+err.Error())
+		respondListError(c, "获取Namespace列表失败", err)
 		return
 	}
 
 	// 2. 返回结果
-	respondSuccess(c, http.StatusOK, namespaces)
+	respondSuccess(c, http.StatusOK, models.NewListResponse(namespaces.Items, meta))
 }
 
 // CreateNamespace ...
@@ -55,7 +68,7 @@ func (h *NamespaceHandler) CreateNamespace(c *gin.Context) {
 		},
 	}
 
-	createdNamespace, err := h.service.Create(namespace)
+	createdNamespace, err := h.service(c).Create(namespace)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "创建Namespace失败: "+err.Error())
 		return
@@ -75,7 +88,7 @@ func (h *NamespaceHandler) GetNamespace(c *gin.Context) {
 	}
 
 	// 2. 调用服务层获取Namespace详情
-	namespace, err := h.service.Get(name)
+	namespace, err := h.service(c).Get(name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "Namespace不存在")
@@ -114,7 +127,7 @@ func (h *NamespaceHandler) UpdateNamespace(c *gin.Context) {
 		},
 	}
 
-	updatedNamespace, err := h.service.Update(namespace)
+	updatedNamespace, err := h.service(c).Update(namespace)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "更新Namespace失败: "+err.Error())
 		return
@@ -124,6 +137,28 @@ func (h *NamespaceHandler) UpdateNamespace(c *gin.Context) {
 	respondSuccess(c, http.StatusOK, models.ToNamespaceResponse(updatedNamespace))
 }
 
+// PatchNamespace 按 Content-Type 选择 patch 类型，默认 strategic merge patch
+func (h *NamespaceHandler) PatchNamespace(c *gin.Context) {
+	name := strings.TrimSpace(c.Param("name"))
+	if !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的Namespace名称格式")
+		return
+	}
+	patchType, data, ok := readPatchRequest(c, types.StrategicMergePatchType)
+	if !ok {
+		return
+	}
+
+	svc := h.service(c)
+	svc.SetForce(c.Query("force") == "true")
+	patched, err := svc.Patch(name, patchType, data)
+	if err != nil {
+		respondResourceError(c, "修改Namespace失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToNamespaceResponse(patched))
+}
+
 // DeleteNamespace ...
 func (h *NamespaceHandler) DeleteNamespace(c *gin.Context) {
 	name := c.Param("name")
@@ -135,7 +170,7 @@ func (h *NamespaceHandler) DeleteNamespace(c *gin.Context) {
 	}
 
 	// 2. 调用服务层删除Namespace
-	if err := h.service.Delete(name); err != nil {
+	if err := h.service(c).Delete(name); err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "Namespace不存在")
 			return
@@ -151,7 +186,7 @@ func (h *NamespaceHandler) DeleteNamespace(c *gin.Context) {
 // WatchNamespaces ...
 func (h *NamespaceHandler) WatchNamespaces(c *gin.Context) {
 	// 1. 调用服务层Watch Namespaces
-	watcher, err := h.service.Watch(c.Query("selector"))
+	watcher, err := h.service(c).Watch(c.Query("selector"))
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Watch Namespaces失败: "+err.Error())
 		return
@@ -167,3 +202,8 @@ func (h *NamespaceHandler) WatchNamespaces(c *gin.Context) {
 		return true
 	})
 }
+
+// DiffNamespace 服务端 dry-run 提交的 Namespace，返回与当前对象的差异
+func (h *NamespaceHandler) DiffNamespace(c *gin.Context) {
+	respondDiff(c, corev1.SchemeGroupVersion.WithKind("Namespace"), "namespaces", false)
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/ciliverse/cilikube/api/v1/models"
 	"github.com/ciliverse/cilikube/internal/service"
@@ -12,16 +13,23 @@ import (
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // NetworkPolicyHandler ...
-type NetworkPolicyHandler struct {
-	service *service.NetworkPolicyService
-}
+type NetworkPolicyHandler struct{}
 
 // NewNetworkPolicyHandler ...
-func NewNetworkPolicyHandler(svc *service.NetworkPolicyService) *NetworkPolicyHandler {
-	return &NetworkPolicyHandler{service: svc}
+func NewNetworkPolicyHandler() *NetworkPolicyHandler {
+	return &NetworkPolicyHandler{}
+}
+
+// service 返回绑定到当前请求目标集群的 NetworkPolicyService
+func (h *NetworkPolicyHandler) service(c *gin.Context) *service.NetworkPolicyService {
+	client := clusterClient(c)
+	svc := service.NewNetworkPolicyService(client.Clientset)
+	svc.SetDryRun(dryRunOption(c))
+	return svc
 }
 
 // ListNetworkPolicies ...
@@ -33,15 +41,20 @@ func (h *NetworkPolicyHandler) ListNetworkPolicies(c *gin.Context) {
 		return
 	}
 
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+
 	// 2. 调用服务层获取NetworkPolicy列表
-	networkPolicies, err := h.service.List(namespace, c.Query("selector"), 0)
+	networkPolicies, meta, err := h.service(c).List(namespace, query)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "获取NetworkPolicy列表失败: "+err.Error())
+		respondListError(c, "获取NetworkPolicy列表失败", err)
 		return
 	}
 
 	// 3. 返回结果
-	respondSuccess(c, http.StatusOK, networkPolicies)
+	respondSuccess(c, http.StatusOK, models.NewListResponse(networkPolicies.Items, meta))
 }
 
 // CreateNetworkPolicy ...
@@ -71,7 +84,7 @@ func (h *NetworkPolicyHandler) CreateNetworkPolicy(c *gin.Context) {
 		Spec: req.Spec,
 	}
 
-	createdNetworkPolicy, err := h.service.Create(namespace, networkPolicy)
+	createdNetworkPolicy, err := h.service(c).Create(namespace, networkPolicy)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "创建NetworkPolicy失败: "+err.Error())
 		return
@@ -97,7 +110,7 @@ func (h *NetworkPolicyHandler) GetNetworkPolicy(c *gin.Context) {
 	}
 
 	// 2. 调用服务层获取NetworkPolicy详情
-	networkPolicy, err := h.service.Get(namespace, name)
+	networkPolicy, err := h.service(c).Get(namespace, name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "NetworkPolicy不存在")
@@ -144,7 +157,7 @@ func (h *NetworkPolicyHandler) UpdateNetworkPolicy(c *gin.Context) {
 		Spec: req.Spec,
 	}
 
-	updatedNetworkPolicy, err := h.service.Update(namespace, networkPolicy)
+	updatedNetworkPolicy, err := h.service(c).Update(namespace, networkPolicy)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "更新NetworkPolicy失败: "+err.Error())
 		return
@@ -154,6 +167,29 @@ func (h *NetworkPolicyHandler) UpdateNetworkPolicy(c *gin.Context) {
 	respondSuccess(c, http.StatusOK, models.ToNetworkPolicyResponse(updatedNetworkPolicy))
 }
 
+// PatchNetworkPolicy 按 Content-Type 选择 patch 类型，默认 strategic merge patch
+func (h *NetworkPolicyHandler) PatchNetworkPolicy(c *gin.Context) {
+	namespace := strings.TrimSpace(c.Param("namespace"))
+	name := strings.TrimSpace(c.Param("name"))
+	if !utils.ValidateNamespace(namespace) || !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间或NetworkPolicy名称格式")
+		return
+	}
+	patchType, data, ok := readPatchRequest(c, types.StrategicMergePatchType)
+	if !ok {
+		return
+	}
+
+	svc := h.service(c)
+	svc.SetForce(c.Query("force") == "true")
+	patched, err := svc.Patch(namespace, name, patchType, data)
+	if err != nil {
+		respondResourceError(c, "修改NetworkPolicy失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToNetworkPolicyResponse(patched))
+}
+
 // DeleteNetworkPolicy ...
 func (h *NetworkPolicyHandler) DeleteNetworkPolicy(c *gin.Context) {
 	namespace := c.Param("namespace")
@@ -171,7 +207,7 @@ func (h *NetworkPolicyHandler) DeleteNetworkPolicy(c *gin.Context) {
 	}
 
 	// 2. 调用服务层删除NetworkPolicy
-	if err := h.service.Delete(namespace, name); err != nil {
+	if err := h.service(c).Delete(namespace, name); err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "NetworkPolicy不存在")
 			return
@@ -194,7 +230,7 @@ func (h *NetworkPolicyHandler) WatchNetworkPolicies(c *gin.Context) {
 	}
 
 	// 2. 调用服务层Watch NetworkPolicies
-	watcher, err := h.service.Watch(namespace, c.Query("selector"))
+	watcher, err := h.service(c).Watch(namespace, c.Query("selector"))
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Watch NetworkPolicies失败: "+err.Error())
 		return
@@ -210,3 +246,8 @@ func (h *NetworkPolicyHandler) WatchNetworkPolicies(c *gin.Context) {
 		return true
 	})
 }
+
+// DiffNetworkPolicy 服务端 dry-run 提交的 NetworkPolicy，返回与当前对象的差异
+func (h *NetworkPolicyHandler) DiffNetworkPolicy(c *gin.Context) {
+	respondDiff(c, networkingv1.SchemeGroupVersion.WithKind("NetworkPolicy"), "networkpolicies", true)
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/ciliverse/cilikube/api/v1/models"
 	"github.com/ciliverse/cilikube/internal/service"
@@ -11,29 +12,41 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // NodeHandler ...
-type NodeHandler struct {
-	service *service.NodeService
-}
+type NodeHandler struct{}
 
 // NewNodeHandler ...
-func NewNodeHandler(svc *service.NodeService) *NodeHandler {
-	return &NodeHandler{service: svc}
+func NewNodeHandler() *NodeHandler {
+	return &NodeHandler{}
+}
+
+// service 返回绑定到当前请求目标集群的 NodeService
+func (h *NodeHandler) service(c *gin.Context) *service.NodeService {
+	client := clusterClient(c)
+	svc := service.NewNodeService(client.Clientset, clusterCache(c))
+	svc.SetDryRun(dryRunOption(c))
+	return svc
 }
 
 // ListNodes ...
 func (h *NodeHandler) ListNodes(c *gin.Context) {
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+
 	// 1. 调用服务层获取Node列表
-	nodes, err := h.service.List(c.Query("selector"), 0)
+	nodes, meta, err := h.service(c).List(query)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "获取Node列表失败: "+err.Error())
+		respondListError(c, "获取Node列表失败", err)
 		return
 	}
 
 	// 2. 返回结果
-	respondSuccess(c, http.StatusOK, nodes)
+	respondSuccess(c, http.StatusOK, models.NewListResponse(nodes.Items, meta))
 }
 
 // CreateNode ...
@@ -56,7 +69,7 @@ func (h *NodeHandler) CreateNode(c *gin.Context) {
 		Spec: req.Spec,
 	}
 
-	createdNode, err := h.service.Create(node)
+	createdNode, err := h.service(c).Create(node)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "创建Node失败: "+err.Error())
 		return
@@ -76,7 +89,7 @@ func (h *NodeHandler) GetNode(c *gin.Context) {
 	}
 
 	// 2. 调用服务层获取Node详情
-	node, err := h.service.Get(name)
+	node, err := h.service(c).Get(name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "Node不存在")
@@ -116,7 +129,7 @@ func (h *NodeHandler) UpdateNode(c *gin.Context) {
 		Spec: req.Spec,
 	}
 
-	updatedNode, err := h.service.Update(node)
+	updatedNode, err := h.service(c).Update(node)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "更新Node失败: "+err.Error())
 		return
@@ -126,6 +139,28 @@ func (h *NodeHandler) UpdateNode(c *gin.Context) {
 	respondSuccess(c, http.StatusOK, models.ToNodeResponse(updatedNode))
 }
 
+// PatchNode 按 Content-Type 选择 patch 类型，默认 strategic merge patch
+func (h *NodeHandler) PatchNode(c *gin.Context) {
+	name := strings.TrimSpace(c.Param("name"))
+	if !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的Node名称格式")
+		return
+	}
+	patchType, data, ok := readPatchRequest(c, types.StrategicMergePatchType)
+	if !ok {
+		return
+	}
+
+	svc := h.service(c)
+	svc.SetForce(c.Query("force") == "true")
+	patched, err := svc.Patch(name, patchType, data)
+	if err != nil {
+		respondResourceError(c, "修改Node失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToNodeResponse(patched))
+}
+
 // DeleteNode ...
 func (h *NodeHandler) DeleteNode(c *gin.Context) {
 	name := c.Param("name")
@@ -137,7 +172,7 @@ func (h *NodeHandler) DeleteNode(c *gin.Context) {
 	}
 
 	// 2. 调用服务层删除Node
-	if err := h.service.Delete(name); err != nil {
+	if err := h.service(c).Delete(name); err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "Node不存在")
 			return
@@ -153,7 +188,7 @@ func (h *NodeHandler) DeleteNode(c *gin.Context) {
 // WatchNodes ...
 func (h *NodeHandler) WatchNodes(c *gin.Context) {
 	// 1. 调用服务层Watch Nodes
-	watcher, err := h.service.Watch(c.Query("selector"))
+	watcher, err := h.service(c).Watch(c.Query("selector"))
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Watch Nodes失败: "+err.Error())
 		return
@@ -169,3 +204,8 @@ func (h *NodeHandler) WatchNodes(c *gin.Context) {
 		return true
 	})
 }
+
+// DiffNode 服务端 dry-run 提交的 Node，返回与当前对象的差异
+func (h *NodeHandler) DiffNode(c *gin.Context) {
+	respondDiff(c, corev1.SchemeGroupVersion.WithKind("Node"), "nodes", false)
+}
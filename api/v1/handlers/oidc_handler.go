@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// oidcStateCookie 保存发起登录时生成的 state/nonce/PKCE verifier，回调时校验
+	oidcStateCookie = "cilikube_oidc"
+	oidcCookiePath  = "/api/v1/auth/oidc"
+	oidcStateMaxAge = 10 * time.Minute
+)
+
+type OIDCHandler struct {
+	oidcService *service.OIDCService
+}
+
+func NewOIDCHandler(oidcService *service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{oidcService: oidcService}
+}
+
+// Login 跳转到身份提供方登录
+// @Summary OIDC 登录
+// @Description 重定向到身份提供方的授权页面，登录完成后回调 /api/v1/auth/oidc/callback
+// @Tags Auth
+// @Success 302
+// @Failure 502 {object} map[string]interface{}
+// @Router /api/v1/auth/oidc/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	loginState, authURL, err := h.oidcService.BeginLogin()
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"code":    502,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	data, _ := json.Marshal(loginState)
+	c.SetSameSite(http.SameSiteLaxMode) // 身份提供方跳转回来的顶级 GET 请求需要带上该 cookie
+	c.SetCookie(oidcStateCookie, base64.RawURLEncoding.EncodeToString(data), int(oidcStateMaxAge.Seconds()), oidcCookiePath, "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback 身份提供方回调：校验授权结果，创建或更新用户并签发 token
+// @Summary OIDC 回调
+// @Description 配置了 postLoginRedirect 时重定向到前端并在 URL fragment 中携带 token，否则返回 JSON
+// @Tags Auth
+// @Produce json
+// @Param code query string true "授权码"
+// @Param state query string true "登录状态"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/auth/oidc/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if errCode := c.Query("error"); errCode != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "身份提供方拒绝登录: " + errCode + " " + c.Query("error_description"),
+		})
+		return
+	}
+
+	loginState, ok := readOIDCState(c)
+	// 无论成功与否，state 只能使用一次
+	c.SetCookie(oidcStateCookie, "", -1, oidcCookiePath, "", c.Request.TLS != nil, true)
+	if !ok || loginState.State == "" || loginState.State != c.Query("state") {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "登录状态无效或已过期，请重新登录",
+		})
+		return
+	}
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "缺少授权码",
+		})
+		return
+	}
+
+	response, err := h.oidcService.CompleteLogin(c.Request.Context(), loginState, code, loginClient(c))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrOIDCLogin):
+			status = http.StatusUnauthorized
+		case errors.Is(err, service.ErrUserInactive):
+			status = http.StatusForbidden
+		case errors.Is(err, service.ErrExternalUserConflict):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if target := h.oidcService.PostLoginRedirect(); target != "" {
+		fragment := url.Values{}
+		fragment.Set("token", response.Token)
+		fragment.Set("expires_at", response.ExpiresAt.Format(time.RFC3339))
+		fragment.Set("refresh_token", response.RefreshToken)
+		c.Redirect(http.StatusFound, target+"#"+fragment.Encode())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "登录成功",
+		"data":    response,
+	})
+}
+
+func readOIDCState(c *gin.Context) (*service.OIDCLoginState, bool) {
+	value, err := c.Cookie(oidcStateCookie)
+	if err != nil || value == "" {
+		return nil, false
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, false
+	}
+	var loginState service.OIDCLoginState
+	if err := json.Unmarshal(data, &loginState); err != nil {
+		return nil, false
+	}
+	return &loginState, true
+}
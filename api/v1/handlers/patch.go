@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// patchTypeFromRequest 根据 Content-Type 选择 patch 类型，未指定或 application/json 时使用 defaultType
+func patchTypeFromRequest(c *gin.Context, defaultType types.PatchType) (types.PatchType, bool) {
+	contentType := c.GetHeader("Content-Type")
+	if contentType == "" {
+		return defaultType, true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		respondError(c, http.StatusUnsupportedMediaType, "无效的 Content-Type: "+contentType)
+		return "", false
+	}
+	switch mediaType {
+	case "application/json":
+		return defaultType, true
+	case string(types.MergePatchType):
+		return types.MergePatchType, true
+	case string(types.JSONPatchType):
+		return types.JSONPatchType, true
+	case string(types.StrategicMergePatchType):
+		return types.StrategicMergePatchType, true
+	case string(types.ApplyPatchType):
+		return types.ApplyPatchType, true
+	}
+	respondError(c, http.StatusUnsupportedMediaType, "不支持的 patch 类型: "+mediaType)
+	return "", false
+}
+
+// readPatchRequest 读取 PATCH 请求的 patch 类型和内容，失败时已写入响应
+func readPatchRequest(c *gin.Context, defaultType types.PatchType) (types.PatchType, []byte, bool) {
+	patchType, ok := patchTypeFromRequest(c, defaultType)
+	if !ok {
+		return "", nil, false
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "读取请求体失败: "+err.Error())
+		return "", nil, false
+	}
+	if len(body) == 0 {
+		respondError(c, http.StatusBadRequest, "patch 内容不能为空")
+		return "", nil, false
+	}
+	return patchType, body, true
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestReadPatchRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        types.PatchType
+		status      int // 非 0 时期望读取失败并返回该状态码
+	}{
+		{name: "no content type uses default", want: types.StrategicMergePatchType},
+		{name: "plain json uses default", contentType: "application/json", want: types.StrategicMergePatchType},
+		{name: "parameters are ignored", contentType: "application/json; charset=utf-8", want: types.StrategicMergePatchType},
+		{name: "merge patch", contentType: "application/merge-patch+json", want: types.MergePatchType},
+		{name: "json patch", contentType: "application/json-patch+json", want: types.JSONPatchType},
+		{name: "strategic merge patch", contentType: "application/strategic-merge-patch+json", want: types.StrategicMergePatchType},
+		{name: "apply patch", contentType: "application/apply-patch+yaml", want: types.ApplyPatchType},
+		{name: "unsupported media type", contentType: "text/plain", status: http.StatusUnsupportedMediaType},
+		{name: "malformed content type", contentType: "application/json;;", status: http.StatusUnsupportedMediaType},
+		{name: "empty body", contentType: "application/merge-patch+json", body: "-", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"data":{"key":"value"}}`
+			if tt.body == "-" {
+				body = ""
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPatch, "/configmaps/settings", strings.NewReader(body))
+			if tt.contentType != "" {
+				c.Request.Header.Set("Content-Type", tt.contentType)
+			}
+
+			patchType, data, ok := readPatchRequest(c, types.StrategicMergePatchType)
+			if tt.status != 0 {
+				assert.False(t, ok)
+				assert.Equal(t, tt.status, w.Code)
+				return
+			}
+			assert.True(t, ok, w.Body.String())
+			assert.Equal(t, tt.want, patchType)
+			assert.Equal(t, body, string(data))
+		})
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strconv"
 	"strings"
 
 	// Keep for potential future use (like WebSocket ping)
@@ -17,12 +16,11 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 )
 
-type PodHandler struct {
-	service *service.PodService
-}
+type PodHandler struct{}
 
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
@@ -33,13 +31,21 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
-func NewPodHandler(svc *service.PodService) *PodHandler {
-	return &PodHandler{service: svc}
+func NewPodHandler() *PodHandler {
+	return &PodHandler{}
+}
+
+// service 返回绑定到当前请求目标集群的 PodService
+func (h *PodHandler) service(c *gin.Context) *service.PodService {
+	client := clusterClient(c)
+	svc := service.NewPodService(client.Clientset, client.Config, clusterCache(c))
+	svc.SetDryRun(dryRunOption(c))
+	return svc
 }
 
 // ListNamespaces ... (保持不变)
 func (h *PodHandler) ListNamespaces(c *gin.Context) {
-	namespaces, err := h.service.ListNamespaces()
+	namespaces, err := h.service(c).ListNamespaces()
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "获取命名空间失败: "+err.Error())
 		return
@@ -62,7 +68,7 @@ func (h *PodHandler) GetPod(c *gin.Context) {
 		return
 	}
 
-	pod, err := h.service.Get(namespace, name)
+	pod, err := h.service(c).Get(namespace, name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "Pod不存在")
@@ -97,7 +103,7 @@ func (h *PodHandler) CreatePod(c *gin.Context) {
 			respondError(c, http.StatusBadRequest, "请求体不能为空 (YAML)")
 			return
 		}
-		createdPod, err = h.service.CreateFromYAML(namespace, yamlBody)
+		createdPod, err = h.service(c).CreateFromYAML(namespace, yamlBody)
 
 	} else if strings.Contains(contentType, "json") { // Explicitly check for JSON
 		var req models.CreatePodRequest
@@ -121,7 +127,7 @@ func (h *PodHandler) CreatePod(c *gin.Context) {
 			Spec: req.Spec,
 		}
 		// Use the original service.Create method for JSON objects
-		createdPod, err = h.service.Create(namespace, pod)
+		createdPod, err = h.service(c).Create(namespace, pod)
 	} else {
 		respondError(c, http.StatusUnsupportedMediaType, "不支持的 Content-Type，请使用 application/json 或 application/yaml")
 		return
@@ -168,12 +174,12 @@ func (h *PodHandler) UpdatePod(c *gin.Context) {
 			respondError(c, http.StatusBadRequest, "请求体不能为空 (YAML)")
 			return
 		}
-		result, err = h.service.UpdateFromYAML(namespace, name, yamlBody)
+		result, err = h.service(c).UpdateFromYAML(namespace, name, yamlBody)
 
 	} else if strings.Contains(contentType, "json") { // Explicitly check for JSON
 		// --- Handle JSON Input ---
 		// Get the existing Pod first to apply changes correctly
-		existingPod, errGet := h.service.Get(namespace, name)
+		existingPod, errGet := h.service(c).Get(namespace, name)
 		if errGet != nil {
 			if errors.IsNotFound(errGet) {
 				respondError(c, http.StatusNotFound, "Pod不存在，无法更新")
@@ -197,7 +203,7 @@ func (h *PodHandler) UpdatePod(c *gin.Context) {
 		updatedPod.Spec = req.Spec               // Replace the entire spec
 
 		// *** Call the correct Update method in the service ***
-		result, err = h.service.Update(namespace, updatedPod) // Use the method taking a Pod object
+		result, err = h.service(c).Update(namespace, updatedPod) // Use the method taking a Pod object
 
 	} else {
 		respondError(c, http.StatusUnsupportedMediaType, "不支持的 Content-Type，请使用 application/json 或 application/yaml")
@@ -225,6 +231,29 @@ func (h *PodHandler) UpdatePod(c *gin.Context) {
 	respondSuccess(c, http.StatusOK, models.ToPodResponse(result))
 }
 
+// PatchPod 按 Content-Type 选择 patch 类型，默认 strategic merge patch
+func (h *PodHandler) PatchPod(c *gin.Context) {
+	namespace := strings.TrimSpace(c.Param("namespace"))
+	name := strings.TrimSpace(c.Param("name"))
+	if !utils.ValidateNamespace(namespace) || !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间或Pod名称格式")
+		return
+	}
+	patchType, data, ok := readPatchRequest(c, types.StrategicMergePatchType)
+	if !ok {
+		return
+	}
+
+	svc := h.service(c)
+	svc.SetForce(c.Query("force") == "true")
+	patched, err := svc.Patch(namespace, name, patchType, data)
+	if err != nil {
+		respondResourceError(c, "修改Pod失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToPodResponse(patched))
+}
+
 // DeletePod ... (保持不变, 使用 204)
 func (h *PodHandler) DeletePod(c *gin.Context) {
 	namespace := strings.TrimSpace(c.Param("namespace"))
@@ -235,7 +264,7 @@ func (h *PodHandler) DeletePod(c *gin.Context) {
 		return
 	}
 
-	err := h.service.Delete(namespace, name)
+	err := h.service(c).Delete(namespace, name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Idempotent: Return success even if not found
@@ -257,23 +286,20 @@ func (h *PodHandler) ListPods(c *gin.Context) {
 		return
 	}
 
-	labelSelector := c.Query("labelSelector")
-	limitStr := c.DefaultQuery("limit", "500") // Sensible default limit
-	limit, err := strconv.ParseInt(limitStr, 10, 64)
-	if err != nil || limit <= 0 {
-		limit = 500 // Fallback
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
 	}
 
-	pods, err := h.service.List(namespace, labelSelector, limit)
+	pods, meta, err := h.service(c).List(namespace, query)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "获取Pod列表失败: "+err.Error())
+		respondListError(c, "获取Pod列表失败", err)
 		return
 	}
 
 	response := models.PodListResponse{
-		Items: make([]models.PodResponse, 0, len(pods.Items)),
-		// Total reflects items *in this batch*. K8s list doesn't give total count easily.
-		Total: len(pods.Items),
+		Items:    make([]models.PodResponse, 0, len(pods.Items)),
+		ListMeta: meta,
 	}
 	for _, pod := range pods.Items {
 		response.Items = append(response.Items, models.ToPodResponse(&pod))
@@ -297,7 +323,7 @@ func (h *PodHandler) WatchPods(c *gin.Context) {
 	}
 	labelSelector := c.Query("labelSelector")
 
-	watcher, err := h.service.Watch(namespace, labelSelector)
+	watcher, err := h.service(c).Watch(namespace, labelSelector)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "开始监听Pod失败: "+err.Error())
 		return
@@ -392,7 +418,7 @@ func (h *PodHandler) ExecIntoPod(c *gin.Context) {
 	go func() {
 		defer close(execDone)
 		fmt.Printf("Executing command: %v in %s/%s/%s\n", command, namespace, name, container)
-		execErr = h.service.ExecIntoPod(ctx, execOptions)
+		execErr = h.service(c).ExecIntoPod(ctx, execOptions)
 		if execErr != nil {
 			// Attempt to send error back via WebSocket
 			errMsg := fmt.Sprintf("\r\n--- Command Execution Failed ---\r\nError: %v\r\n", execErr)
@@ -435,7 +461,7 @@ func (h *PodHandler) GetPodYAML(c *gin.Context) {
 		return
 	}
 
-	yamlBytes, err := h.service.GetPodYAML(namespace, name)
+	yamlBytes, err := h.service(c).GetPodYAML(namespace, name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "Pod 不存在")
@@ -475,7 +501,7 @@ func (h *PodHandler) UpdatePodYAML(c *gin.Context) {
 		return
 	}
 
-	updatedPod, err := h.service.UpdateFromYAML(namespace, name, yamlBody)
+	updatedPod, err := h.service(c).UpdateFromYAML(namespace, name, yamlBody)
 	if err != nil {
 		if e, ok := err.(*service.ValidationError); ok {
 			respondError(c, http.StatusBadRequest, e.Error())
@@ -686,3 +712,8 @@ func (h *WebSocketStreamHandler) Close() {
 	<-h.writeDone
 	fmt.Println("WebSocket stream handlers fully closed")
 }
+
+// DiffPod 服务端 dry-run 提交的 Pod，返回与当前对象的差异
+func (h *PodHandler) DiffPod(c *gin.Context) {
+	respondDiff(c, corev1.SchemeGroupVersion.WithKind("Pod"), "pods", true)
+}
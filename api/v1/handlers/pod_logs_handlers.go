@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"github.com/ciliverse/cilikube/internal/service"
 	"github.com/ciliverse/cilikube/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"io"
@@ -33,7 +34,7 @@ func (h *PodHandler) GetPodLogs(c *gin.Context) {
 	}
 
 	// Optional: Check container exists
-	pod, err := h.service.Get(namespace, name)
+	pod, err := h.service(c).Get(namespace, name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "Pod 不存在")
@@ -66,8 +67,13 @@ func (h *PodHandler) GetPodLogs(c *gin.Context) {
 		logOptions.TailLines = &tailLines
 	}
 
+	streamPodLogs(c, h.service(c), namespace, name, logOptions)
+}
+
+// streamPodLogs 以 SSE 输出 Pod 日志，每行一个 data 事件，直到日志结束或客户端断开
+func streamPodLogs(c *gin.Context, podService *service.PodService, namespace, name string, logOptions *corev1.PodLogOptions) {
 	// 获取日志流
-	logStream, err := h.service.GetPodLogs(namespace, name, logOptions)
+	logStream, err := podService.GetPodLogs(namespace, name, logOptions)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "获取日志失败: "+err.Error())
 		return
@@ -4,13 +4,14 @@ import (
 	"net/http"
 	"strings"
 
-	// "github.com/ciliverse/cilikube/api/v1/models" // Assuming models are defined here
+	"github.com/ciliverse/cilikube/api/v1/models"
 	"github.com/ciliverse/cilikube/internal/service"
 	"github.com/ciliverse/cilikube/pkg/utils" // Assuming utils package exists
 	"github.com/gin-gonic/gin"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	// metav1 "k8s.io/apimachinery/pkg/apis/meta/v1" // Might need if using req models
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // --- Define Response Models (Example - Ideally in models package) ---
@@ -34,7 +35,7 @@ type PVResponse struct {
 
 type PVListResponse struct {
 	Items []PVResponse `json:"items"`
-	Total int          `json:"total"` // Total matching items
+	models.ListMeta
 }
 
 // Mapping function (Ideally in models package)
@@ -74,12 +75,18 @@ func ToPVResponse(pv *corev1.PersistentVolume) PVResponse {
 
 // --- Handler ---
 
-type PVHandler struct {
-	service *service.PVService
+type PVHandler struct{}
+
+func NewPVHandler() *PVHandler {
+	return &PVHandler{}
 }
 
-func NewPVHandler(svc *service.PVService) *PVHandler {
-	return &PVHandler{service: svc}
+// service 返回绑定到当前请求目标集群的 PVService
+func (h *PVHandler) service(c *gin.Context) *service.PVService {
+	client := clusterClient(c)
+	svc := service.NewPVService(client.Clientset, clusterCache(c))
+	svc.SetDryRun(dryRunOption(c))
+	return svc
 }
 
 // ListPVs godoc
@@ -89,29 +96,31 @@ func NewPVHandler(svc *service.PVService) *PVHandler {
 // @Accept json
 // @Produce json
 // @Param labelSelector query string false "Label selector for filtering"
-// @Param limit query int false "Maximum number of items to return" default(500)
+// @Param fieldSelector query string false "Field selector for filtering"
+// @Param search query string false "Case-insensitive name substring"
+// @Param sortBy query string false "Sort field" Enums(name, namespace, creationTimestamp)
+// @Param order query string false "Sort order" Enums(asc, desc)
+// @Param limit query int false "Maximum number of items to return (0 = all)"
+// @Param continue query string false "Continue token from the previous page"
 // @Success 200 {object} PVListResponse "List of Persistent Volumes"
 // @Failure 500 {object} handlers.ErrorResponse "Internal Server Error"
 // @Router /api/v1/persistentvolumes [get]
 func (h *PVHandler) ListPVs(c *gin.Context) {
-	labelSelector := c.Query("labelSelector")
-	// Limit query param, default to a reasonable number, e.g., 500
-	// Frontend pagination will handle displaying pageSize items from this list.
-	limit := utils.ParseInt(c.DefaultQuery("limit", "500"), 500)
+	// limit / continue 为服务端分页参数，total 为符合条件的总数
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
 
-	pvList, err := h.service.List(labelSelector, int64(limit))
+	pvList, meta, err := h.service(c).List(query)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "获取PV列表失败: "+err.Error())
+		respondListError(c, "获取PV列表失败", err)
 		return
 	}
 
 	response := PVListResponse{
-		Items: make([]PVResponse, 0, len(pvList.Items)),
-		// Note: The 'total' here reflects the number *returned* by the List call (due to limit),
-		//       not necessarily the *absolute* total in the cluster unless limit wasn't hit.
-		//       For accurate total with server-side pagination, list without limit first (expensive)
-		//       or rely on frontend count if limit is high enough for most cases.
-		Total: len(pvList.Items),
+		Items:    make([]PVResponse, 0, len(pvList.Items)),
+		ListMeta: meta,
 	}
 	for _, pv := range pvList.Items {
 		response.Items = append(response.Items, ToPVResponse(&pv))
@@ -139,7 +148,7 @@ func (h *PVHandler) GetPV(c *gin.Context) {
 		return
 	}
 
-	pv, err := h.service.Get(name)
+	pv, err := h.service(c).Get(name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "PV不存在")
@@ -181,7 +190,7 @@ func (h *PVHandler) CreatePV(c *gin.Context) {
 		pv.APIVersion = "v1"
 	} // Default if missing
 
-	createdPV, err := h.service.Create(&pv)
+	createdPV, err := h.service(c).Create(&pv)
 	if err != nil {
 		if errors.IsAlreadyExists(err) {
 			respondError(c, http.StatusConflict, "PV已存在")
@@ -239,7 +248,7 @@ func (h *PVHandler) UpdatePV(c *gin.Context) {
 		pv.APIVersion = "v1"
 	}
 
-	updatedPV, err := h.service.Update(&pv) // Service needs to handle potential conflicts
+	updatedPV, err := h.service(c).Update(&pv) // Service needs to handle potential conflicts
 	if err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "PV不存在")
@@ -259,6 +268,43 @@ func (h *PVHandler) UpdatePV(c *gin.Context) {
 	respondSuccess(c, http.StatusOK, ToPVResponse(updatedPV))
 }
 
+// PatchPV godoc
+// @Summary Patch a PV
+// @Description Partially update a PV; the patch type is selected by Content-Type (strategic merge by default)
+// @Tags PersistentVolumes
+// @Accept json
+// @Produce json
+// @Param name path string true "PV Name"
+// @Param dryRun query string false "Set to All to validate without persisting"
+// @Param force query bool false "Force field ownership for apply patches"
+// @Param body body object true "Patch document"
+// @Success 200 {object} models.PVResponse "Patched PV"
+// @Failure 400 {object} handlers.ErrorResponse "Bad Request"
+// @Failure 404 {object} handlers.ErrorResponse "Not Found"
+// @Failure 415 {object} handlers.ErrorResponse "Unsupported patch type"
+// @Failure 500 {object} handlers.ErrorResponse "Internal Server Error"
+// @Router /api/v1/persistentvolumes/{name} [patch]
+func (h *PVHandler) PatchPV(c *gin.Context) {
+	name := strings.TrimSpace(c.Param("name"))
+	if !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的PV名称格式")
+		return
+	}
+	patchType, data, ok := readPatchRequest(c, types.StrategicMergePatchType)
+	if !ok {
+		return
+	}
+
+	svc := h.service(c)
+	svc.SetForce(c.Query("force") == "true")
+	patched, err := svc.Patch(name, patchType, data)
+	if err != nil {
+		respondResourceError(c, "修改PV失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, ToPVResponse(patched))
+}
+
 // DeletePV godoc
 // @Summary Delete a Persistent Volume
 // @Description Delete a specific Persistent Volume by name
@@ -278,7 +324,7 @@ func (h *PVHandler) DeletePV(c *gin.Context) {
 		return
 	}
 
-	err := h.service.Delete(name)
+	err := h.service(c).Delete(name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Consider returning 204 even if not found, idempotent delete
@@ -298,3 +344,20 @@ type ErrorResponse struct { Code int `json:"code"`; Message string `json:"messag
 func respondSuccess(c *gin.Context, code int, data interface{}) { ... }
 func respondError(c *gin.Context, code int, message string) { ... }
 */
+
+// DiffPV godoc
+// @Summary Preview changes to a PV
+// @Description Server-side dry-run of the submitted PV and a structured/unified diff against the live object
+// @Tags PersistentVolumes
+// @Accept json
+// @Produce json
+// @Param name path string true "PV Name"
+// @Param mode query string false "Dry-run mode" Enums(update, apply)
+// @Param body body object true "PV manifest (JSON or YAML)"
+// @Success 200 {object} models.DiffResponse "Diff result"
+// @Failure 400 {object} handlers.ErrorResponse "Bad Request"
+// @Failure 500 {object} handlers.ErrorResponse "Internal Server Error"
+// @Router /api/v1/persistentvolumes/{name}/diff [post]
+func (h *PVHandler) DiffPV(c *gin.Context) {
+	respondDiff(c, corev1.SchemeGroupVersion.WithKind("PersistentVolume"), "persistentvolumes", false)
+}
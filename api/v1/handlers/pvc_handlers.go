@@ -221,14 +221,21 @@ import (
 	"github.com/gin-gonic/gin"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 )
 
-type PVCHandler struct {
-	service *service.PVCService
+type PVCHandler struct{}
+
+func NewPVCHandler() *PVCHandler {
+	return &PVCHandler{}
 }
 
-func NewPVCHandler(svc *service.PVCService) *PVCHandler {
-	return &PVCHandler{service: svc}
+// service 返回绑定到当前请求目标集群的 PVCService
+func (h *PVCHandler) service(c *gin.Context) *service.PVCService {
+	client := clusterClient(c)
+	svc := service.NewPVCService(client.Clientset, clusterCache(c))
+	svc.SetDryRun(dryRunOption(c))
+	return svc
 }
 
 // ListPVCs godoc
@@ -239,7 +246,12 @@ func NewPVCHandler(svc *service.PVCService) *PVCHandler {
 // @Produce json
 // @Param namespace path string true "Namespace"
 // @Param labelSelector query string false "Label selector for filtering"
-// @Param limit query int false "Maximum number of items to return" default(100)
+// @Param fieldSelector query string false "Field selector for filtering"
+// @Param search query string false "Case-insensitive name substring"
+// @Param sortBy query string false "Sort field" Enums(name, namespace, creationTimestamp)
+// @Param order query string false "Sort order" Enums(asc, desc)
+// @Param limit query int false "Maximum number of items to return (0 = all)"
+// @Param continue query string false "Continue token from the previous page"
 // @Success 200 {object} models.PVCListResponse "List of Persistent Volume Claims"
 // @Failure 400 {object} handlers.ErrorResponse "Bad Request - Invalid Namespace"
 // @Failure 500 {object} handlers.ErrorResponse "Internal Server Error"
@@ -251,18 +263,20 @@ func (h *PVCHandler) ListPVCs(c *gin.Context) {
 		return
 	}
 
-	labelSelector := c.Query("labelSelector")
-	limit := utils.ParseInt(c.DefaultQuery("limit", "100"), 100)
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
 
-	pvcList, err := h.service.List(namespace, labelSelector, int64(limit))
+	pvcList, meta, err := h.service(c).List(namespace, query)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "获取PVC列表失败: "+err.Error())
+		respondListError(c, "获取PVC列表失败", err)
 		return
 	}
 
 	response := models.PVCListResponse{
-		Items: make([]models.PVCResponse, 0, len(pvcList.Items)),
-		Total: len(pvcList.Items), // See notes in PV handlers about total vs returned
+		Items:    make([]models.PVCResponse, 0, len(pvcList.Items)),
+		ListMeta: meta,
 	}
 	for _, pvc := range pvcList.Items {
 		response.Items = append(response.Items, models.ToPVCResponse(&pvc))
@@ -293,7 +307,7 @@ func (h *PVCHandler) GetPVC(c *gin.Context) {
 		return
 	}
 
-	pvc, err := h.service.Get(namespace, name)
+	pvc, err := h.service(c).Get(namespace, name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "PVC不存在")
@@ -341,7 +355,7 @@ func (h *PVCHandler) CreatePVC(c *gin.Context) {
 	}
 
 	// Let service handle namespace assignment/validation based on path param
-	createdPVC, err := h.service.Create(namespace, &pvc)
+	createdPVC, err := h.service(c).Create(namespace, &pvc)
 	if err != nil {
 		if errors.IsAlreadyExists(err) {
 			respondError(c, http.StatusConflict, "PVC已存在")
@@ -402,7 +416,7 @@ func (h *PVCHandler) UpdatePVC(c *gin.Context) {
 	}
 
 	// Service Update handles the actual call, API server enforces immutability
-	updatedPVC, err := h.service.Update(namespace, &pvc)
+	updatedPVC, err := h.service(c).Update(namespace, &pvc)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "PVC不存在")
@@ -422,6 +436,45 @@ func (h *PVCHandler) UpdatePVC(c *gin.Context) {
 	respondSuccess(c, http.StatusOK, models.ToPVCResponse(updatedPVC))
 }
 
+// PatchPVC godoc
+// @Summary Patch a PVC
+// @Description Partially update a PVC; the patch type is selected by Content-Type (strategic merge by default)
+// @Tags PersistentVolumeClaims
+// @Accept json
+// @Produce json
+// @Param namespace path string true "Namespace"
+// @Param name path string true "PVC Name"
+// @Param dryRun query string false "Set to All to validate without persisting"
+// @Param force query bool false "Force field ownership for apply patches"
+// @Param body body object true "Patch document"
+// @Success 200 {object} models.PVCResponse "Patched PVC"
+// @Failure 400 {object} handlers.ErrorResponse "Bad Request"
+// @Failure 404 {object} handlers.ErrorResponse "Not Found"
+// @Failure 415 {object} handlers.ErrorResponse "Unsupported patch type"
+// @Failure 500 {object} handlers.ErrorResponse "Internal Server Error"
+// @Router /api/v1/namespaces/{namespace}/persistentvolumeclaims/{name} [patch]
+func (h *PVCHandler) PatchPVC(c *gin.Context) {
+	namespace := strings.TrimSpace(c.Param("namespace"))
+	name := strings.TrimSpace(c.Param("name"))
+	if !utils.ValidateNamespace(namespace) || !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间或PVC名称格式")
+		return
+	}
+	patchType, data, ok := readPatchRequest(c, types.StrategicMergePatchType)
+	if !ok {
+		return
+	}
+
+	svc := h.service(c)
+	svc.SetForce(c.Query("force") == "true")
+	patched, err := svc.Patch(namespace, name, patchType, data)
+	if err != nil {
+		respondResourceError(c, "修改PVC失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToPVCResponse(patched))
+}
+
 // DeletePVC godoc
 // @Summary Delete a Persistent Volume Claim
 // @Description Delete a specific PVC by namespace and name
@@ -444,7 +497,7 @@ func (h *PVCHandler) DeletePVC(c *gin.Context) {
 		return
 	}
 
-	err := h.service.Delete(namespace, name)
+	err := h.service(c).Delete(namespace, name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// respondError(c, http.StatusNotFound, "PVC不存在")
@@ -463,3 +516,21 @@ type ErrorResponse struct { Code int `json:"code"`; Message string `json:"messag
 func respondSuccess(c *gin.Context, code int, data interface{}) { ... }
 func respondError(c *gin.Context, code int, message string) { ... }
 */
+
+// DiffPVC godoc
+// @Summary Preview changes to a PVC
+// @Description Server-side dry-run of the submitted PVC and a structured/unified diff against the live object
+// @Tags PersistentVolumeClaims
+// @Accept json
+// @Produce json
+// @Param namespace path string true "Namespace"
+// @Param name path string true "PVC Name"
+// @Param mode query string false "Dry-run mode" Enums(update, apply)
+// @Param body body object true "PVC manifest (JSON or YAML)"
+// @Success 200 {object} models.DiffResponse "Diff result"
+// @Failure 400 {object} handlers.ErrorResponse "Bad Request"
+// @Failure 500 {object} handlers.ErrorResponse "Internal Server Error"
+// @Router /api/v1/namespaces/{namespace}/persistentvolumeclaims/{name}/diff [post]
+func (h *PVCHandler) DiffPVC(c *gin.Context) {
+	respondDiff(c, corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"), "persistentvolumeclaims", true)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"github.com/ciliverse/cilikube/api/v1/models"
 	"github.com/ciliverse/cilikube/internal/service"
 	"github.com/ciliverse/cilikube/pkg/utils"
 	"github.com/gin-gonic/gin"
@@ -8,11 +9,17 @@ import (
 	"strings"
 )
 
-type RbacHandler struct {
-	service *service.RbacService
+type RbacHandler struct{}
+
+func NewRbacHandler() *RbacHandler {
+	return &RbacHandler{}
 }
 
-func NewRbacHandler(svc *service.RbacService) *RbacHandler { return &RbacHandler{service: svc} }
+// service 返回绑定到当前请求目标集群的 RbacService
+func (h *RbacHandler) service(c *gin.Context) *service.RbacService {
+	client := clusterClient(c)
+	return service.NewRbacService(client.Clientset)
+}
 
 // Roles
 func (h *RbacHandler) ListRoles(c *gin.Context) {
@@ -21,12 +28,16 @@ func (h *RbacHandler) ListRoles(c *gin.Context) {
 		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
 		return
 	}
-	roles, err := h.service.ListRoles(namespace)
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+	roles, meta, err := h.service(c).ListRoles(namespace, query)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "获取Role列表失败: "+err.Error())
+		respondListError(c, "获取Role列表失败", err)
 		return
 	}
-	respondSuccess(c, http.StatusOK, roles)
+	respondSuccess(c, http.StatusOK, models.NewListResponse(roles, meta))
 }
 
 func (h *RbacHandler) GetRole(c *gin.Context) {
@@ -40,7 +51,7 @@ func (h *RbacHandler) GetRole(c *gin.Context) {
 		respondError(c, http.StatusBadRequest, "无效的资源名称格式")
 		return
 	}
-	role, err := h.service.GetRole(namespace, name)
+	role, err := h.service(c).GetRole(namespace, name)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "获取Role失败: "+err.Error())
 		return
@@ -55,12 +66,16 @@ func (h *RbacHandler) ListRoleBindings(c *gin.Context) {
 		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
 		return
 	}
-	roleBindings, err := h.service.ListRoleBindings(namespace)
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+	roleBindings, meta, err := h.service(c).ListRoleBindings(namespace, query)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "获取RoleBinding列表失败: "+err.Error())
+		respondListError(c, "获取RoleBinding列表失败", err)
 		return
 	}
-	respondSuccess(c, http.StatusOK, roleBindings)
+	respondSuccess(c, http.StatusOK, models.NewListResponse(roleBindings, meta))
 }
 
 func (h *RbacHandler) GetRoleBindings(c *gin.Context) {
@@ -74,7 +89,7 @@ func (h *RbacHandler) GetRoleBindings(c *gin.Context) {
 		respondError(c, http.StatusBadRequest, "无效的资源名称格式")
 		return
 	}
-	roleBinding, err := h.service.GetRoleBinding(namespace, name)
+	roleBinding, err := h.service(c).GetRoleBinding(namespace, name)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "获取RoleBinding失败: "+err.Error())
 		return
@@ -84,12 +99,16 @@ func (h *RbacHandler) GetRoleBindings(c *gin.Context) {
 
 // ClusterRoles
 func (h *RbacHandler) ListClusterRoles(c *gin.Context) {
-	clusterRoles, err := h.service.ListClusterRoles()
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+	clusterRoles, meta, err := h.service(c).ListClusterRoles(query)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "获取ClusterRole列表失败: "+err.Error())
+		respondListError(c, "获取ClusterRole列表失败", err)
 		return
 	}
-	respondSuccess(c, http.StatusOK, clusterRoles)
+	respondSuccess(c, http.StatusOK, models.NewListResponse(clusterRoles, meta))
 }
 
 func (h *RbacHandler) GetClusterRoles(c *gin.Context) {
@@ -98,7 +117,7 @@ func (h *RbacHandler) GetClusterRoles(c *gin.Context) {
 		respondError(c, http.StatusBadRequest, "无效的资源名称格式")
 		return
 	}
-	clusterRole, err := h.service.GetClusterRole(name)
+	clusterRole, err := h.service(c).GetClusterRole(name)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "获取ClusterRole失败: "+err.Error())
 		return
@@ -108,12 +127,16 @@ func (h *RbacHandler) GetClusterRoles(c *gin.Context) {
 
 // ClusterRoleBindings
 func (h *RbacHandler) ListClusterRoleBindings(c *gin.Context) {
-	clusterRoleBindings, err := h.service.ListClusterRoleBindings()
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+	clusterRoleBindings, meta, err := h.service(c).ListClusterRoleBindings(query)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "获取ClusterRoleBinding列表失败: "+err.Error())
+		respondListError(c, "获取ClusterRoleBinding列表失败", err)
 		return
 	}
-	respondSuccess(c, http.StatusOK, clusterRoleBindings)
+	respondSuccess(c, http.StatusOK, models.NewListResponse(clusterRoleBindings, meta))
 }
 
 func (h *RbacHandler) GetClusterRoleBindings(c *gin.Context) {
@@ -122,7 +145,7 @@ func (h *RbacHandler) GetClusterRoleBindings(c *gin.Context) {
 		respondError(c, http.StatusBadRequest, "无效的资源名称格式")
 		return
 	}
-	clusterRoleBinding, err := h.service.GetClusterRoleBinding(name)
+	clusterRoleBinding, err := h.service(c).GetClusterRoleBinding(name)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "获取ClusterRoleBinding失败: "+err.Error())
 		return
@@ -137,12 +160,16 @@ func (h *RbacHandler) ListServiceAccounts(c *gin.Context) {
 		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
 		return
 	}
-	serviceAccounts, err := h.service.ListServiceAccounts(namespace)
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+	serviceAccounts, meta, err := h.service(c).ListServiceAccounts(namespace, query)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "获取ServiceAccount列表失败: "+err.Error())
+		respondListError(c, "获取ServiceAccount列表失败", err)
 		return
 	}
-	respondSuccess(c, http.StatusOK, serviceAccounts)
+	respondSuccess(c, http.StatusOK, models.NewListResponse(serviceAccounts, meta))
 }
 
 func (h *RbacHandler) GetServiceAccounts(c *gin.Context) {
@@ -156,7 +183,7 @@ func (h *RbacHandler) GetServiceAccounts(c *gin.Context) {
 		respondError(c, http.StatusBadRequest, "无效的资源名称格式")
 		return
 	}
-	serviceAccount, err := h.service.GetServiceAccounts(namespace, name)
+	serviceAccount, err := h.service(c).GetServiceAccounts(namespace, name)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "获取ServiceAccount失败: "+err.Error())
 		return
@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// ResourceHandler 通用资源接口，通过 dynamic client 访问任意 GroupVersionResource (包括 CRD)。
+// 路由: /resources/:group/:version/:resource[/namespaces/:namespace][/:name]，核心组使用 "core"
+type ResourceHandler struct{}
+
+// NewResourceHandler ...
+func NewResourceHandler() *ResourceHandler {
+	return &ResourceHandler{}
+}
+
+// service 返回绑定到当前请求目标集群的 ResourceService
+func (h *ResourceHandler) service(c *gin.Context) *service.ResourceService {
+	client := clusterClient(c)
+	svc := service.NewResourceService(client.Dynamic, client.Discovery())
+	svc.SetDryRun(dryRunOption(c))
+	return svc
+}
+
+// DiscoverResources 列出集群提供的所有 API 资源及其支持的操作
+func (h *ResourceHandler) DiscoverResources(c *gin.Context) {
+	resources, err := h.service(c).Discover()
+	if err != nil {
+		respondResourceError(c, "获取API资源列表失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, resources)
+}
+
+// ListResources ...
+func (h *ResourceHandler) ListResources(c *gin.Context) {
+	gvr, namespace, ok := resourcePathParams(c)
+	if !ok {
+		return
+	}
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+
+	list, meta, err := h.service(c).List(gvr, namespace, query)
+	if err != nil {
+		respondResourceError(c, "获取"+gvr.Resource+"列表失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.NewListResponse(list.Items, meta))
+}
+
+// GetResource ...
+func (h *ResourceHandler) GetResource(c *gin.Context) {
+	gvr, namespace, ok := resourcePathParams(c)
+	if !ok {
+		return
+	}
+	name, ok := resourceName(c)
+	if !ok {
+		return
+	}
+
+	obj, err := h.service(c).Get(gvr, namespace, name)
+	if err != nil {
+		respondResourceError(c, "获取"+gvr.Resource+"失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, obj)
+}
+
+// CreateResource 请求体为 JSON 或 YAML
+func (h *ResourceHandler) CreateResource(c *gin.Context) {
+	gvr, namespace, ok := resourcePathParams(c)
+	if !ok {
+		return
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "读取请求体失败: "+err.Error())
+		return
+	}
+	obj, err := service.DecodeUnstructured(body)
+	if err != nil {
+		respondResourceError(c, "创建"+gvr.Resource+"失败", err)
+		return
+	}
+
+	created, err := h.service(c).Create(gvr, namespace, obj)
+	if err != nil {
+		respondResourceError(c, "创建"+gvr.Resource+"失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, created)
+}
+
+// UpdateResource 请求体为 JSON 或 YAML 的完整对象
+func (h *ResourceHandler) UpdateResource(c *gin.Context) {
+	gvr, namespace, ok := resourcePathParams(c)
+	if !ok {
+		return
+	}
+	name, ok := resourceName(c)
+	if !ok {
+		return
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "读取请求体失败: "+err.Error())
+		return
+	}
+	obj, err := service.DecodeUnstructured(body)
+	if err != nil {
+		respondResourceError(c, "更新"+gvr.Resource+"失败", err)
+		return
+	}
+
+	updated, err := h.service(c).Update(gvr, namespace, name, obj)
+	if err != nil {
+		respondResourceError(c, "更新"+gvr.Resource+"失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, updated)
+}
+
+// DiffResource 服务端 dry-run 请求体中的对象并返回与当前对象的差异，?mode=apply 时按 server-side apply 计算
+func (h *ResourceHandler) DiffResource(c *gin.Context) {
+	gvr, namespace, ok := resourcePathParams(c)
+	if !ok {
+		return
+	}
+	name, ok := resourceName(c)
+	if !ok {
+		return
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "读取请求体失败: "+err.Error())
+		return
+	}
+	obj, err := service.DecodeUnstructured(body)
+	if err != nil {
+		respondResourceError(c, "生成"+gvr.Resource+"差异失败", err)
+		return
+	}
+
+	result, err := h.service(c).Diff(gvr, namespace, name, obj, c.Query("mode"))
+	if err != nil {
+		respondResourceError(c, "生成"+gvr.Resource+"差异失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, result)
+}
+
+// PatchResource patch 类型由 Content-Type 决定，默认 merge patch；apply patch 可通过 ?force=true 强制获取字段所有权
+func (h *ResourceHandler) PatchResource(c *gin.Context) {
+	gvr, namespace, ok := resourcePathParams(c)
+	if !ok {
+		return
+	}
+	name, ok := resourceName(c)
+	if !ok {
+		return
+	}
+	// CRD 不支持 strategic merge patch，默认使用 merge patch
+	patchType, body, ok := readPatchRequest(c, types.MergePatchType)
+	if !ok {
+		return
+	}
+
+	svc := h.service(c)
+	svc.SetForce(c.Query("force") == "true")
+	patched, err := svc.Patch(gvr, namespace, name, patchType, body)
+	if err != nil {
+		respondResourceError(c, "修改"+gvr.Resource+"失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, patched)
+}
+
+// DeleteResource 可通过 ?propagationPolicy=Foreground|Background|Orphan 指定级联策略
+func (h *ResourceHandler) DeleteResource(c *gin.Context) {
+	gvr, namespace, ok := resourcePathParams(c)
+	if !ok {
+		return
+	}
+	name, ok := resourceName(c)
+	if !ok {
+		return
+	}
+
+	propagation := metav1.DeletionPropagation(c.Query("propagationPolicy"))
+	switch propagation {
+	case "", metav1.DeletePropagationForeground, metav1.DeletePropagationBackground, metav1.DeletePropagationOrphan:
+	default:
+		respondError(c, http.StatusBadRequest, "无效的 propagationPolicy: "+string(propagation))
+		return
+	}
+
+	if err := h.service(c).Delete(gvr, namespace, name, propagation); err != nil {
+		respondResourceError(c, "删除"+gvr.Resource+"失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// WatchResources 通过 SSE 推送资源变化
+func (h *ResourceHandler) WatchResources(c *gin.Context) {
+	gvr, namespace, ok := resourcePathParams(c)
+	if !ok {
+		return
+	}
+
+	watcher, err := h.service(c).Watch(gvr, namespace, c.Query("labelSelector"), c.Query("fieldSelector"))
+	if err != nil {
+		respondResourceError(c, "开始监听"+gvr.Resource+"失败", err)
+		return
+	}
+	defer watcher.Stop()
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				c.SSEvent("close", gin.H{"message": "Watcher channel closed"})
+				return false
+			}
+			c.SSEvent("message", toWatchResourceEvent(event))
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// --- Helper Functions ---
+
+// resourcePathParams 解析 group/version/resource 及可选的命名空间
+func resourcePathParams(c *gin.Context) (schema.GroupVersionResource, string, bool) {
+	group := strings.TrimSpace(c.Param("group"))
+	if group == models.CoreGroupAlias {
+		group = ""
+	}
+	gvr := schema.GroupVersionResource{
+		Group:    group,
+		Version:  strings.TrimSpace(c.Param("version")),
+		Resource: strings.TrimSpace(c.Param("resource")),
+	}
+	if gvr.Version == "" || gvr.Resource == "" {
+		respondError(c, http.StatusBadRequest, "version 和 resource 不能为空")
+		return gvr, "", false
+	}
+
+	namespace := strings.TrimSpace(c.Param("namespace"))
+	if namespace != "" && !utils.ValidateNamespace(namespace) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间格式")
+		return gvr, "", false
+	}
+	return gvr, namespace, true
+}
+
+func resourceName(c *gin.Context) (string, bool) {
+	name := strings.TrimSpace(c.Param("name"))
+	if name == "" {
+		respondError(c, http.StatusBadRequest, "资源名称不能为空")
+		return "", false
+	}
+	return name, true
+}
+
+// respondResourceError 将 service 和 Kubernetes API 的错误映射为对应的 HTTP 状态码
+func respondResourceError(c *gin.Context, message string, err error) {
+	respondError(c, resourceErrorStatus(err), message+": "+err.Error())
+}
+
+// resourceErrorStatus 资源操作错误对应的 HTTP 状态码
+func resourceErrorStatus(err error) int {
+	var validationErr *service.ValidationError
+	var status apierrors.APIStatus
+	switch {
+	case errors.Is(err, service.ErrResourceNotFound):
+		return http.StatusNotFound
+	case errors.As(err, &validationErr):
+		return http.StatusBadRequest
+	case errors.As(err, &status) && status.Status().Code >= http.StatusBadRequest:
+		return int(status.Status().Code)
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func toWatchResourceEvent(event watch.Event) interface{} {
+	resp := gin.H{
+		"type": string(event.Type),
+	}
+	if event.Type == watch.Error {
+		resp["error"] = fmt.Sprintf("K8s API Error: %s", apierrors.FromObject(event.Object).Error())
+		resp["status"] = event.Object
+		return resp
+	}
+	resp["object"] = event.Object
+	return resp
+}
@@ -10,14 +10,21 @@ import (
 	"github.com/gin-gonic/gin"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 )
 
-type SecretHandler struct {
-	service *service.SecretService
+type SecretHandler struct{}
+
+func NewSecretHandler() *SecretHandler {
+	return &SecretHandler{}
 }
 
-func NewSecretHandler(svc *service.SecretService) *SecretHandler {
-	return &SecretHandler{service: svc}
+// service 返回绑定到当前请求目标集群的 SecretService
+func (h *SecretHandler) service(c *gin.Context) *service.SecretService {
+	client := clusterClient(c)
+	svc := service.NewSecretService(client.Clientset)
+	svc.SetDryRun(dryRunOption(c))
+	return svc
 }
 
 // ListSecrets godoc
@@ -28,7 +35,12 @@ func NewSecretHandler(svc *service.SecretService) *SecretHandler {
 // @Produce json
 // @Param namespace path string true "Namespace"
 // @Param labelSelector query string false "Label selector for filtering"
-// @Param limit query int false "Maximum number of items to return" default(100)
+// @Param fieldSelector query string false "Field selector for filtering"
+// @Param search query string false "Case-insensitive name substring"
+// @Param sortBy query string false "Sort field" Enums(name, namespace, creationTimestamp)
+// @Param order query string false "Sort order" Enums(asc, desc)
+// @Param limit query int false "Maximum number of items to return (0 = all)"
+// @Param continue query string false "Continue token from the previous page"
 // @Success 200 {object} models.SecretListResponse "List of Secrets (metadata only)"
 // @Failure 400 {object} handlers.ErrorResponse "Bad Request - Invalid Namespace"
 // @Failure 500 {object} handlers.ErrorResponse "Internal Server Error"
@@ -40,18 +52,20 @@ func (h *SecretHandler) ListSecrets(c *gin.Context) {
 		return
 	}
 
-	labelSelector := c.Query("labelSelector")
-	limit := utils.ParseInt(c.DefaultQuery("limit", "100"), 100)
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
 
-	secretList, err := h.service.List(namespace, labelSelector, int64(limit))
+	secretList, meta, err := h.service(c).List(namespace, query)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "获取Secret列表失败: "+err.Error())
+		respondListError(c, "获取Secret列表失败", err)
 		return
 	}
 
 	response := models.SecretListResponse{
-		Items: make([]models.SecretResponse, 0, len(secretList.Items)),
-		Total: len(secretList.Items), // Adjust if needed
+		Items:    make([]models.SecretResponse, 0, len(secretList.Items)),
+		ListMeta: meta,
 	}
 	for _, secret := range secretList.Items {
 		response.Items = append(response.Items, models.ToSecretResponse(&secret))
@@ -81,7 +95,7 @@ func (h *SecretHandler) GetSecret(c *gin.Context) {
 		return
 	}
 
-	secret, err := h.service.Get(namespace, name)
+	secret, err := h.service(c).Get(namespace, name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "Secret不存在")
@@ -133,7 +147,7 @@ func (h *SecretHandler) CreateSecret(c *gin.Context) {
 	// If not, you might need manual decoding here based on how the frontend sends it.
 	// However, K8s usually handles encoding StringData into Data automatically. Prefer using StringData for text.
 
-	createdSecret, err := h.service.Create(namespace, &secret)
+	createdSecret, err := h.service(c).Create(namespace, &secret)
 	if err != nil {
 		if errors.IsAlreadyExists(err) {
 			respondError(c, http.StatusConflict, "Secret已存在")
@@ -189,7 +203,7 @@ func (h *SecretHandler) UpdateSecret(c *gin.Context) {
 		secret.APIVersion = "v1"
 	}
 
-	updatedSecret, err := h.service.Update(namespace, &secret)
+	updatedSecret, err := h.service(c).Update(namespace, &secret)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "Secret不存在")
@@ -209,6 +223,45 @@ func (h *SecretHandler) UpdateSecret(c *gin.Context) {
 	respondSuccess(c, http.StatusOK, models.ToSecretResponse(updatedSecret)) // Return basic info
 }
 
+// PatchSecret godoc
+// @Summary Patch a Secret
+// @Description Partially update a Secret; the patch type is selected by Content-Type (strategic merge by default)
+// @Tags Secrets
+// @Accept json
+// @Produce json
+// @Param namespace path string true "Namespace"
+// @Param name path string true "Secret Name"
+// @Param dryRun query string false "Set to All to validate without persisting"
+// @Param force query bool false "Force field ownership for apply patches"
+// @Param body body object true "Patch document"
+// @Success 200 {object} models.SecretResponse "Patched Secret"
+// @Failure 400 {object} handlers.ErrorResponse "Bad Request"
+// @Failure 404 {object} handlers.ErrorResponse "Not Found"
+// @Failure 415 {object} handlers.ErrorResponse "Unsupported patch type"
+// @Failure 500 {object} handlers.ErrorResponse "Internal Server Error"
+// @Router /api/v1/namespaces/{namespace}/secrets/{name} [patch]
+func (h *SecretHandler) PatchSecret(c *gin.Context) {
+	namespace := strings.TrimSpace(c.Param("namespace"))
+	name := strings.TrimSpace(c.Param("name"))
+	if !utils.ValidateNamespace(namespace) || !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间或Secret名称格式")
+		return
+	}
+	patchType, data, ok := readPatchRequest(c, types.StrategicMergePatchType)
+	if !ok {
+		return
+	}
+
+	svc := h.service(c)
+	svc.SetForce(c.Query("force") == "true")
+	patched, err := svc.Patch(namespace, name, patchType, data)
+	if err != nil {
+		respondResourceError(c, "修改Secret失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToSecretResponse(patched))
+}
+
 // DeleteSecret godoc
 // @Summary Delete a Secret
 // @Description Delete a specific Secret by namespace and name.
@@ -230,7 +283,7 @@ func (h *SecretHandler) DeleteSecret(c *gin.Context) {
 		return
 	}
 
-	err := h.service.Delete(namespace, name)
+	err := h.service(c).Delete(namespace, name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			c.Status(http.StatusNoContent)
@@ -248,3 +301,21 @@ type ErrorResponse struct { Code int `json:"code"`; Message string `json:"messag
 func respondSuccess(c *gin.Context, code int, data interface{}) { ... }
 func respondError(c *gin.Context, code int, message string) { ... }
 */
+
+// DiffSecret godoc
+// @Summary Preview changes to a Secret
+// @Description Server-side dry-run of the submitted Secret and a structured/unified diff against the live object
+// @Tags Secrets
+// @Accept json
+// @Produce json
+// @Param namespace path string true "Namespace"
+// @Param name path string true "Secret Name"
+// @Param mode query string false "Dry-run mode" Enums(update, apply)
+// @Param body body object true "Secret manifest (JSON or YAML)"
+// @Success 200 {object} models.DiffResponse "Diff result"
+// @Failure 400 {object} handlers.ErrorResponse "Bad Request"
+// @Failure 500 {object} handlers.ErrorResponse "Internal Server Error"
+// @Router /api/v1/namespaces/{namespace}/secrets/{name}/diff [post]
+func (h *SecretHandler) DiffSecret(c *gin.Context) {
+	respondDiff(c, corev1.SchemeGroupVersion.WithKind("Secret"), "secrets", true)
+}
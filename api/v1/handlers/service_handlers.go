@@ -3,6 +3,7 @@ package handlers
 import (
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/ciliverse/cilikube/api/v1/models"
 	"github.com/ciliverse/cilikube/internal/service"
@@ -12,16 +13,23 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // ServiceHandler ...
-type ServiceHandler struct {
-	service *service.ServiceService
-}
+type ServiceHandler struct{}
 
 // NewServiceHandler ...
-func NewServiceHandler(svc *service.ServiceService) *ServiceHandler {
-	return &ServiceHandler{service: svc}
+func NewServiceHandler() *ServiceHandler {
+	return &ServiceHandler{}
+}
+
+// service 返回绑定到当前请求目标集群的 ServiceService
+func (h *ServiceHandler) service(c *gin.Context) *service.ServiceService {
+	client := clusterClient(c)
+	svc := service.NewServiceService(client.Clientset, clusterCache(c))
+	svc.SetDryRun(dryRunOption(c))
+	return svc
 }
 
 // ListServices ...
@@ -33,20 +41,20 @@ func (h *ServiceHandler) ListServices(c *gin.Context) {
 		return
 	}
 
-	// 2. 调用服务层获取Service列表
-	services, err := h.service.List(namespace)
-	if err != nil {
-		respondError(c, http.StatusInternalServerError, "获取Service列表失败: "+err.Error())
+	query, ok := parseListQuery(c)
+	if !ok {
 		return
 	}
 
-	// 修复空列表报错问题
-	if services.Items == nil {
-		services.Items = make([]corev1.Service, 0)
+	// 2. 调用服务层获取Service列表
+	services, meta, err := h.service(c).List(namespace, query)
+	if err != nil {
+		respondListError(c, "获取Service列表失败", err)
+		return
 	}
 
 	// 3. 返回结果
-	respondSuccess(c, http.StatusOK, services)
+	respondSuccess(c, http.StatusOK, models.NewListResponse(services.Items, meta))
 }
 
 // CreateService ...
@@ -76,7 +84,7 @@ func (h *ServiceHandler) CreateService(c *gin.Context) {
 		Spec: req.Spec,
 	}
 
-	createdService, err := h.service.Create(namespace, service)
+	createdService, err := h.service(c).Create(namespace, service)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "创建Service失败: "+err.Error())
 		return
@@ -102,7 +110,7 @@ func (h *ServiceHandler) GetService(c *gin.Context) {
 	}
 
 	// 2. 调用服务层获取Service详情
-	service, err := h.service.Get(namespace, name)
+	service, err := h.service(c).Get(namespace, name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "Service不存在")
@@ -149,7 +157,7 @@ func (h *ServiceHandler) UpdateService(c *gin.Context) {
 		Spec: req.Spec,
 	}
 
-	updatedService, err := h.service.Update(namespace, service)
+	updatedService, err := h.service(c).Update(namespace, service)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "更新Service失败: "+err.Error())
 		return
@@ -159,6 +167,29 @@ func (h *ServiceHandler) UpdateService(c *gin.Context) {
 	respondSuccess(c, http.StatusOK, models.ToServiceResponse(updatedService))
 }
 
+// PatchService 按 Content-Type 选择 patch 类型，默认 strategic merge patch
+func (h *ServiceHandler) PatchService(c *gin.Context) {
+	namespace := strings.TrimSpace(c.Param("namespace"))
+	name := strings.TrimSpace(c.Param("name"))
+	if !utils.ValidateNamespace(namespace) || !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间或Service名称格式")
+		return
+	}
+	patchType, data, ok := readPatchRequest(c, types.StrategicMergePatchType)
+	if !ok {
+		return
+	}
+
+	svc := h.service(c)
+	svc.SetForce(c.Query("force") == "true")
+	patched, err := svc.Patch(namespace, name, patchType, data)
+	if err != nil {
+		respondResourceError(c, "修改Service失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToServiceResponse(patched))
+}
+
 // DeleteService ...
 func (h *ServiceHandler) DeleteService(c *gin.Context) {
 	namespace := c.Param("namespace")
@@ -176,7 +207,7 @@ func (h *ServiceHandler) DeleteService(c *gin.Context) {
 	}
 
 	// 2. 调用服务层删除Service
-	if err := h.service.Delete(namespace, name); err != nil {
+	if err := h.service(c).Delete(namespace, name); err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "Service不存在")
 			return
@@ -199,7 +230,7 @@ func (h *ServiceHandler) WatchServices(c *gin.Context) {
 	}
 
 	// 2. 调用服务层Watch Services
-	watcher, err := h.service.Watch(namespace, c.Query("selector"))
+	watcher, err := h.service(c).Watch(namespace, c.Query("selector"))
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Watch Services失败: "+err.Error())
 		return
@@ -215,3 +246,8 @@ func (h *ServiceHandler) WatchServices(c *gin.Context) {
 		return true
 	})
 }
+
+// DiffService 服务端 dry-run 提交的 Service，返回与当前对象的差异
+func (h *ServiceHandler) DiffService(c *gin.Context) {
+	respondDiff(c, corev1.SchemeGroupVersion.WithKind("Service"), "services", true)
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/ciliverse/cilikube/api/v1/models"
 	"github.com/ciliverse/cilikube/internal/service"
@@ -11,16 +12,23 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // StatefulSetHandler ...
-type StatefulSetHandler struct {
-	service *service.StatefulSetService
-}
+type StatefulSetHandler struct{}
 
 // NewStatefulSetHandler ...
-func NewStatefulSetHandler(svc *service.StatefulSetService) *StatefulSetHandler {
-	return &StatefulSetHandler{service: svc}
+func NewStatefulSetHandler() *StatefulSetHandler {
+	return &StatefulSetHandler{}
+}
+
+// service 返回绑定到当前请求目标集群的 StatefulSetService
+func (h *StatefulSetHandler) service(c *gin.Context) *service.StatefulSetService {
+	client := clusterClient(c)
+	svc := service.NewStatefulSetService(client.Clientset, clusterCache(c))
+	svc.SetDryRun(dryRunOption(c))
+	return svc
 }
 
 // ListStatefulSets ...
@@ -32,15 +40,20 @@ func (h *StatefulSetHandler) ListStatefulSets(c *gin.Context) {
 		return
 	}
 
+	query, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+
 	// 2. 调用服务层获取StatefulSet列表
-	statefulSets, err := h.service.List(namespace, c.Query("selector"), 0)
+	statefulSets, meta, err := h.service(c).List(namespace, query)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "获取StatefulSet列表失败: "+err.Error())
+		respondListError(c, "获取StatefulSet列表失败", err)
 		return
 	}
 
 	// 3. 返回结果
-	respondSuccess(c, http.StatusOK, statefulSets)
+	respondSuccess(c, http.StatusOK, models.NewListResponse(statefulSets.Items, meta))
 }
 
 // CreateStatefulSet ...
@@ -70,7 +83,7 @@ func (h *StatefulSetHandler) CreateStatefulSet(c *gin.Context) {
 		Spec: req.Spec,
 	}
 
-	createdStatefulSet, err := h.service.Create(namespace, statefulSet)
+	createdStatefulSet, err := h.service(c).Create(namespace, statefulSet)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "创建StatefulSet失败: "+err.Error())
 		return
@@ -96,7 +109,7 @@ func (h *StatefulSetHandler) GetStatefulSet(c *gin.Context) {
 	}
 
 	// 2. 调用服务层获取StatefulSet详情
-	statefulSet, err := h.service.Get(namespace, name)
+	statefulSet, err := h.service(c).Get(namespace, name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "StatefulSet不存在")
@@ -106,8 +119,14 @@ func (h *StatefulSetHandler) GetStatefulSet(c *gin.Context) {
 		return
 	}
 
-	// 3. 返回结果
-	respondSuccess(c, http.StatusOK, models.ToStatefulSetResponse(statefulSet))
+	// 3. 返回结果，附带以该 StatefulSet 为目标的 HPA
+	resp := models.ToStatefulSetResponse(statefulSet)
+	hpa, warning := targetingHPA(c, namespace, "StatefulSet", name)
+	if hpa != nil {
+		resp.HPA = models.ToHPAReference(hpa)
+	}
+	resp.HPAWarning = warning
+	respondSuccess(c, http.StatusOK, resp)
 }
 
 // UpdateStatefulSet ...
@@ -143,7 +162,7 @@ func (h *StatefulSetHandler) UpdateStatefulSet(c *gin.Context) {
 		Spec: req.Spec,
 	}
 
-	updatedStatefulSet, err := h.service.Update(namespace, statefulSet)
+	updatedStatefulSet, err := h.service(c).Update(namespace, statefulSet)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "更新StatefulSet失败: "+err.Error())
 		return
@@ -153,6 +172,56 @@ func (h *StatefulSetHandler) UpdateStatefulSet(c *gin.Context) {
 	respondSuccess(c, http.StatusOK, models.ToStatefulSetResponse(updatedStatefulSet))
 }
 
+// PatchStatefulSet 按 Content-Type 选择 patch 类型，默认 strategic merge patch
+func (h *StatefulSetHandler) PatchStatefulSet(c *gin.Context) {
+	namespace := strings.TrimSpace(c.Param("namespace"))
+	name := strings.TrimSpace(c.Param("name"))
+	if !utils.ValidateNamespace(namespace) || !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间或StatefulSet名称格式")
+		return
+	}
+	patchType, data, ok := readPatchRequest(c, types.StrategicMergePatchType)
+	if !ok {
+		return
+	}
+
+	svc := h.service(c)
+	svc.SetForce(c.Query("force") == "true")
+	patched, err := svc.Patch(namespace, name, patchType, data)
+	if err != nil {
+		respondResourceError(c, "修改StatefulSet失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToStatefulSetResponse(patched))
+}
+
+// ScaleStatefulSet 通过 scale 子资源修改副本数
+func (h *StatefulSetHandler) ScaleStatefulSet(c *gin.Context) {
+	namespace, name, ok := workloadParams(c, "StatefulSet")
+	if !ok {
+		return
+	}
+	var req models.ScaleStatefulSetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "无效的Replicas格式: "+err.Error())
+		return
+	}
+
+	statefulSet, err := h.service(c).Scale(namespace, name, *req.Replicas)
+	if err != nil {
+		respondResourceError(c, "修改StatefulSet的副本数失败", err)
+		return
+	}
+	resp := models.ToStatefulSetResponse(statefulSet)
+	hpa, warning := targetingHPA(c, namespace, "StatefulSet", name)
+	if hpa != nil {
+		resp.HPA = models.ToHPAReference(hpa)
+		resp.ScaleWarning = hpaScaleWarning(hpa, *req.Replicas)
+	}
+	resp.HPAWarning = warning
+	respondSuccess(c, http.StatusOK, resp)
+}
+
 // DeleteStatefulSet ...
 func (h *StatefulSetHandler) DeleteStatefulSet(c *gin.Context) {
 	namespace := c.Param("namespace")
@@ -170,7 +239,7 @@ func (h *StatefulSetHandler) DeleteStatefulSet(c *gin.Context) {
 	}
 
 	// 2. 调用服务层删除StatefulSet
-	if err := h.service.Delete(namespace, name); err != nil {
+	if err := h.service(c).Delete(namespace, name); err != nil {
 		if errors.IsNotFound(err) {
 			respondError(c, http.StatusNotFound, "StatefulSet不存在")
 			return
@@ -193,7 +262,7 @@ func (h *StatefulSetHandler) WatchStatefulSets(c *gin.Context) {
 	}
 
 	// 2. 调用服务层Watch StatefulSets
-	watcher, err := h.service.Watch(namespace, c.Query("selector"))
+	watcher, err := h.service(c).Watch(namespace, c.Query("selector"))
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "Watch StatefulSets失败: "+err.Error())
 		return
@@ -209,3 +278,8 @@ func (h *StatefulSetHandler) WatchStatefulSets(c *gin.Context) {
 		return true
 	})
 }
+
+// DiffStatefulSet 服务端 dry-run 提交的 StatefulSet，返回与当前对象的差异
+func (h *StatefulSetHandler) DiffStatefulSet(c *gin.Context) {
+	respondDiff(c, appsv1.SchemeGroupVersion.WithKind("StatefulSet"), "statefulsets", true)
+}
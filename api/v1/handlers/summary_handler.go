@@ -8,17 +8,21 @@ import (
 )
 
 // Existing SummaryHandler struct...
-type SummaryHandler struct {
-	service *service.SummaryService
+type SummaryHandler struct{}
+
+func NewSummaryHandler() *SummaryHandler {
+	return &SummaryHandler{}
 }
 
-func NewSummaryHandler(svc *service.SummaryService) *SummaryHandler {
-	return &SummaryHandler{service: svc}
+// service 返回绑定到当前请求目标集群的 SummaryService
+func (h *SummaryHandler) service(c *gin.Context) *service.SummaryService {
+	client := clusterClient(c)
+	return service.NewSummaryService(client.Clientset, clusterCache(c))
 }
 
 // Existing GetResourceSummary handlers...
 func (h *SummaryHandler) GetResourceSummary(c *gin.Context) { /* ... as before ... */
-	summary, _ := h.service.GetResourceSummary()
+	summary, _ := h.service(c).GetResourceSummary()
 	respondSuccess(c, http.StatusOK, summary)
 }
 
@@ -34,7 +38,7 @@ func (h *SummaryHandler) GetResourceSummary(c *gin.Context) { /* ... as before .
 // @Failure 500 {object} handlers.ErrorResponse "Internal Server Error - Failed to read/parse go.mod"
 // @Router /api/v1/summary/backend-dependencies [get]
 func (h *SummaryHandler) GetBackendDependencies(c *gin.Context) {
-	dependencies, err := h.service.GetBackendDependencies()
+	dependencies, err := h.service(c).GetBackendDependencies()
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "获取后端依赖失败: "+err.Error())
 		return
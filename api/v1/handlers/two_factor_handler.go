@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// CompleteTwoFactorLogin 登录第二步
+// @Summary 完成两步验证登录
+// @Description 提交登录挑战与验证码 (或恢复码) 换取 token；挑战要求绑定时，验证码同时用于确认绑定并返回恢复码
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param login body models.TwoFactorLoginRequest true "挑战与验证码"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Router /api/v1/auth/login/2fa [post]
+func (h *AuthHandler) CompleteTwoFactorLogin(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+	if !bindTwoFactorRequest(c, &req) {
+		return
+	}
+
+	response, err := h.authService.CompleteTwoFactorLogin(&req, loginClient(c))
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "登录成功",
+		"data":    response,
+	})
+}
+
+// EnrollTwoFactorLogin 登录过程中绑定两步验证
+// @Summary 登录时绑定两步验证
+// @Description 角色要求两步验证但尚未启用时，凭登录挑战生成 TOTP 密钥
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param enroll body models.TwoFactorEnrollRequest true "登录挑战"
+// @Success 200 {object} models.TwoFactorEnrollment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/auth/login/2fa/enroll [post]
+func (h *AuthHandler) EnrollTwoFactorLogin(c *gin.Context) {
+	var req models.TwoFactorEnrollRequest
+	if !bindTwoFactorRequest(c, &req) {
+		return
+	}
+
+	response, err := h.authService.EnrollWithChallenge(req.ChallengeToken)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "请使用认证器应用扫描并提交验证码",
+		"data":    response,
+	})
+}
+
+// BeginTwoFactorEnrollment 生成 TOTP 密钥
+// @Summary 开始绑定两步验证
+// @Description 生成 TOTP 密钥与 otpauth URI，提交验证码确认后生效
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.TwoFactorEnrollment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/auth/2fa/enroll [post]
+func (h *AuthHandler) BeginTwoFactorEnrollment(c *gin.Context) {
+	userID, ok := h.interactiveUser(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.authService.BeginTwoFactorEnrollment(userID)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "请使用认证器应用扫描并提交验证码",
+		"data":    response,
+	})
+}
+
+// ConfirmTwoFactorEnrollment 确认绑定
+// @Summary 确认绑定两步验证
+// @Description 校验验证码后启用两步验证，返回一次性恢复码
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code body models.TwoFactorCodeRequest true "验证码"
+// @Success 200 {object} models.RecoveryCodesResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/auth/2fa/verify [post]
+func (h *AuthHandler) ConfirmTwoFactorEnrollment(c *gin.Context) {
+	userID, ok := h.interactiveUser(c)
+	if !ok {
+		return
+	}
+	var req models.TwoFactorCodeRequest
+	if !bindTwoFactorRequest(c, &req) {
+		return
+	}
+
+	codes, err := h.authService.ConfirmTwoFactorEnrollment(userID, req.Code)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "两步验证已启用，请妥善保存恢复码",
+		"data":    models.RecoveryCodesResponse{RecoveryCodes: codes},
+	})
+}
+
+// DisableTwoFactor 关闭两步验证
+// @Summary 关闭两步验证
+// @Description 需要密码以及验证码或恢复码；角色要求两步验证时不能关闭
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param disable body models.DisableTwoFactorRequest true "密码与验证码"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/auth/2fa/disable [post]
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	userID, ok := h.interactiveUser(c)
+	if !ok {
+		return
+	}
+	var req models.DisableTwoFactorRequest
+	if !bindTwoFactorRequest(c, &req) {
+		return
+	}
+
+	if err := h.authService.DisableTwoFactor(userID, &req); err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "两步验证已关闭",
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+// @Summary 重新生成恢复码
+// @Description 旧的恢复码全部作废
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code body models.TwoFactorCodeRequest true "验证码"
+// @Success 200 {object} models.RecoveryCodesResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/auth/2fa/recovery-codes [post]
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, ok := h.interactiveUser(c)
+	if !ok {
+		return
+	}
+	var req models.TwoFactorCodeRequest
+	if !bindTwoFactorRequest(c, &req) {
+		return
+	}
+
+	codes, err := h.authService.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "恢复码已重新生成",
+		"data":    models.RecoveryCodesResponse{RecoveryCodes: codes},
+	})
+}
+
+// GetTwoFactorPolicy 获取两步验证策略（管理员）
+// @Summary 获取两步验证策略
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.TwoFactorPolicy
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/auth/2fa/policy [get]
+func (h *AuthHandler) GetTwoFactorPolicy(c *gin.Context) {
+	policy, err := h.authService.GetTwoFactorPolicy()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取两步验证策略失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    policy,
+	})
+}
+
+// UpdateTwoFactorPolicy 设置两步验证策略（管理员）
+// @Summary 设置两步验证策略
+// @Description 拥有所列角色 (内置角色或 Casbin 角色) 的本地账号登录时必须完成两步验证，未启用的用户需要先绑定
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param policy body models.TwoFactorPolicy true "必须启用两步验证的角色"
+// @Success 200 {object} models.TwoFactorPolicy
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/auth/2fa/policy [put]
+func (h *AuthHandler) UpdateTwoFactorPolicy(c *gin.Context) {
+	var req models.TwoFactorPolicy
+	if !bindTwoFactorRequest(c, &req) {
+		return
+	}
+
+	policy, err := h.authService.SetTwoFactorPolicy(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "更新两步验证策略失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "更新成功",
+		"data":    policy,
+	})
+}
+
+// ResetUserTwoFactor 重置用户的两步验证（管理员）
+// @Summary 重置用户的两步验证
+// @Description 用于用户丢失认证设备和恢复码的情况，用户下次登录时可重新绑定
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/auth/users/{id}/2fa [delete]
+func (h *AuthHandler) ResetUserTwoFactor(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.ResetTwoFactor(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "重置两步验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "两步验证已重置",
+	})
+}
+
+func bindTwoFactorRequest(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// respondTwoFactorError 验证码或挑战错误返回 401，策略禁止返回 403，被锁定返回 429，其余为 400
+func respondTwoFactorError(c *gin.Context, err error) {
+	if respondLoginLocked(c, err) {
+		return
+	}
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, service.ErrInvalidTwoFactorCode), errors.Is(err, service.ErrInvalidChallenge):
+		status = http.StatusUnauthorized
+	case errors.Is(err, service.ErrTwoFactorMandatory):
+		status = http.StatusForbidden
+	}
+	c.JSON(status, gin.H{
+		"code":    status,
+		"message": err.Error(),
+	})
+}
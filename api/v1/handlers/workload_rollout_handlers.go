@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RestartDeployment godoc
+// @Summary Restart a Deployment
+// @Description 与 kubectl rollout restart 相同：在 Pod 模板上设置 kubectl.kubernetes.io/restartedAt 注解触发滚动更新；已暂停的 Deployment 返回 409
+// @Tags Deployments
+// @Produce json
+// @Param namespace path string true "Namespace"
+// @Param name path string true "Deployment Name"
+// @Param dryRun query string false "Set to All to validate without persisting"
+// @Success 200 {object} models.DeploymentResponse
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/namespaces/{namespace}/deployments/{name}/restart [post]
+func (h *DeploymentHandler) RestartDeployment(c *gin.Context) {
+	namespace, name, ok := deploymentParams(c)
+	if !ok {
+		return
+	}
+	deployment, err := h.service(c).Restart(namespace, name)
+	if err != nil {
+		if stderrors.Is(err, service.ErrDeploymentPaused) {
+			respondError(c, http.StatusConflict, err.Error())
+			return
+		}
+		respondResourceError(c, "重启Deployment失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToDeploymentResponse(deployment))
+}
+
+// PauseDeployment godoc
+// @Summary Pause a Deployment rollout
+// @Description 与 kubectl rollout pause 相同：暂停期间对 Pod 模板的修改不会触发新的发布
+// @Tags Deployments
+// @Produce json
+// @Param namespace path string true "Namespace"
+// @Param name path string true "Deployment Name"
+// @Success 200 {object} models.DeploymentResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/namespaces/{namespace}/deployments/{name}/pause [post]
+func (h *DeploymentHandler) PauseDeployment(c *gin.Context) {
+	namespace, name, ok := deploymentParams(c)
+	if !ok {
+		return
+	}
+	deployment, err := h.service(c).Pause(namespace, name)
+	if err != nil {
+		respondResourceError(c, "暂停Deployment失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToDeploymentResponse(deployment))
+}
+
+// ResumeDeployment godoc
+// @Summary Resume a paused Deployment
+// @Description 与 kubectl rollout resume 相同，暂停期间累积的修改随后一次性发布
+// @Tags Deployments
+// @Produce json
+// @Param namespace path string true "Namespace"
+// @Param name path string true "Deployment Name"
+// @Success 200 {object} models.DeploymentResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/namespaces/{namespace}/deployments/{name}/resume [post]
+func (h *DeploymentHandler) ResumeDeployment(c *gin.Context) {
+	namespace, name, ok := deploymentParams(c)
+	if !ok {
+		return
+	}
+	deployment, err := h.service(c).Resume(namespace, name)
+	if err != nil {
+		respondResourceError(c, "恢复Deployment失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToDeploymentResponse(deployment))
+}
+
+// DeploymentRolloutStatus godoc
+// @Summary Follow a Deployment rollout
+// @Description SSE 流，规则与 kubectl rollout status 相同。进度以 status 事件推送，最后以 success 或 failure 事件结束
+// @Tags Deployments
+// @Produce text/event-stream
+// @Param namespace path string true "Namespace"
+// @Param name path string true "Deployment Name"
+// @Param timeout query string false "最长等待时间，例如 5m，默认一直等待"
+// @Success 200 {object} models.RolloutStatus
+// @Router /api/v1/namespaces/{namespace}/deployments/{name}/rollout-status [get]
+func (h *DeploymentHandler) DeploymentRolloutStatus(c *gin.Context) {
+	namespace, name, ok := deploymentParams(c)
+	if !ok {
+		return
+	}
+	streamRolloutStatus(c, "获取Deployment rollout状态失败", func(ctx context.Context, send func(models.RolloutStatus)) error {
+		return h.service(c).FollowRollout(ctx, namespace, name, send)
+	})
+}
+
+// RestartStatefulSet 与 kubectl rollout restart 相同，设置 restartedAt 注解触发滚动更新
+func (h *StatefulSetHandler) RestartStatefulSet(c *gin.Context) {
+	namespace, name, ok := workloadParams(c, "StatefulSet")
+	if !ok {
+		return
+	}
+	statefulSet, err := h.service(c).Restart(namespace, name)
+	if err != nil {
+		respondResourceError(c, "重启StatefulSet失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToStatefulSetResponse(statefulSet))
+}
+
+// StatefulSetRolloutStatus 以 SSE 推送 StatefulSet 的 rollout 进度
+func (h *StatefulSetHandler) StatefulSetRolloutStatus(c *gin.Context) {
+	namespace, name, ok := workloadParams(c, "StatefulSet")
+	if !ok {
+		return
+	}
+	streamRolloutStatus(c, "获取StatefulSet rollout状态失败", func(ctx context.Context, send func(models.RolloutStatus)) error {
+		return h.service(c).FollowRollout(ctx, namespace, name, send)
+	})
+}
+
+// RestartDaemonSet 与 kubectl rollout restart 相同，设置 restartedAt 注解触发滚动更新
+func (h *DaemonSetHandler) RestartDaemonSet(c *gin.Context) {
+	namespace, name, ok := workloadParams(c, "DaemonSet")
+	if !ok {
+		return
+	}
+	daemonSet, err := h.service(c).Restart(namespace, name)
+	if err != nil {
+		respondResourceError(c, "重启DaemonSet失败", err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, models.ToDaemonSetResponse(daemonSet))
+}
+
+// DaemonSetRolloutStatus 以 SSE 推送 DaemonSet 的 rollout 进度
+func (h *DaemonSetHandler) DaemonSetRolloutStatus(c *gin.Context) {
+	namespace, name, ok := workloadParams(c, "DaemonSet")
+	if !ok {
+		return
+	}
+	streamRolloutStatus(c, "获取DaemonSet rollout状态失败", func(ctx context.Context, send func(models.RolloutStatus)) error {
+		return h.service(c).FollowRollout(ctx, namespace, name, send)
+	})
+}
+
+// workloadParams 读取并校验路径中的命名空间和资源名称，校验失败时已写入响应
+func workloadParams(c *gin.Context, kind string) (string, string, bool) {
+	namespace := strings.TrimSpace(c.Param("namespace"))
+	name := strings.TrimSpace(c.Param("name"))
+	if !utils.ValidateNamespace(namespace) || !utils.ValidateResourceName(name) {
+		respondError(c, http.StatusBadRequest, "无效的命名空间或"+kind+"名称格式")
+		return "", "", false
+	}
+	return namespace, name, true
+}
+
+// streamRolloutStatus 把 follow 产生的状态写成 SSE：未结束的状态为 status 事件，
+// 最终状态为 success 或 failure 事件。第一个事件之前的错误 (如资源不存在) 按普通 JSON 错误返回，
+// 之后的错误以 error 事件结束流
+func streamRolloutStatus(c *gin.Context, message string, follow func(context.Context, func(models.RolloutStatus)) error) {
+	ctx := c.Request.Context()
+	if raw := c.Query("timeout"); raw != "" {
+		timeout, err := time.ParseDuration(raw)
+		if err != nil || timeout <= 0 {
+			respondError(c, http.StatusBadRequest, "无效的timeout，应为正的时长，例如 5m")
+			return
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	started := false
+	send := func(status models.RolloutStatus) {
+		if !started {
+			c.Writer.Header().Set("Content-Type", "text/event-stream")
+			c.Writer.Header().Set("Cache-Control", "no-cache")
+			c.Writer.Header().Set("Connection", "keep-alive")
+			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+			started = true
+		}
+		switch {
+		case status.Done:
+			c.SSEvent("success", status)
+		case status.Failed:
+			c.SSEvent("failure", status)
+		default:
+			c.SSEvent("status", status)
+		}
+		c.Writer.Flush()
+	}
+
+	err := follow(ctx, send)
+	switch {
+	case err == nil:
+	case !started:
+		respondResourceError(c, message, err)
+	case stderrors.Is(err, context.DeadlineExceeded):
+		send(models.RolloutStatus{Message: "timed out waiting for the condition", Failed: true})
+	case ctx.Err() != nil:
+		// 客户端断开连接
+	default:
+		c.SSEvent("error", gin.H{"message": message + ": " + err.Error()})
+		c.Writer.Flush()
+	}
+}
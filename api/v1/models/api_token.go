@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// APIToken 个人访问令牌，用于 CI 等自动化调用，数据库中只保存 SHA-256 哈希。
+// Scopes 形如 "<resource>:<verb>"，两部分都可以是 "*"，在用户自身权限之内进一步限制令牌能做的事
+type APIToken struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"index;not null"`
+	Name       string     `json:"name" gorm:"size:100;not null"`
+	Prefix     string     `json:"prefix" gorm:"size:16"` // 令牌开头几位，便于识别
+	TokenHash  string     `json:"-" gorm:"uniqueIndex;size:64;not null"`
+	Scopes     []string   `json:"scopes" gorm:"serializer:json"`
+	ExpiresAt  *time.Time `json:"expires_at"` // 为空表示永不过期
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// TableName 指定表名
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
+
+type CreateAPITokenRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreateAPITokenResponse 明文令牌只在创建时返回一次
+type CreateAPITokenResponse struct {
+	APIToken
+	Token string `json:"token"`
+}
+
+// CreateRobotRequest 创建机器人用户：不能使用密码登录，只能通过 API token 调用接口。
+// Role 为内置角色 (默认 user)，Roles 为在所有集群上额外绑定的 Casbin 角色
+type CreateRobotRequest struct {
+	Username string   `json:"username" binding:"required,min=3,max=50"`
+	Role     string   `json:"role" binding:"omitempty,oneof=admin user"`
+	Roles    []string `json:"roles"`
+}
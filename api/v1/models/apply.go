@@ -0,0 +1,27 @@
+package models
+
+// Apply 结果
+const (
+	ApplyActionCreated    = "created"
+	ApplyActionConfigured = "configured"
+	ApplyActionUnchanged  = "unchanged"
+	ApplyActionError      = "error"
+)
+
+// ApplyResult 清单中单个对象的 apply 结果
+type ApplyResult struct {
+	Index      int    `json:"index"` // 对象在清单中的序号，从 0 开始 (List 会展开为多个对象)
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Action     string `json:"action"` // created / configured / unchanged / error
+	Error      string `json:"error,omitempty"`
+}
+
+// ApplyResponse POST /apply 的响应
+type ApplyResponse struct {
+	DryRun  bool           `json:"dryRun"`
+	Results []ApplyResult  `json:"results"`
+	Summary map[string]int `json:"summary"` // 各 action 的对象数量
+}
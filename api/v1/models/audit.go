@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// AuditEvent 一次经由 CiliKube 对集群执行的写操作，或一次 Pod exec、日志访问
+type AuditEvent struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	UserID     uint   `json:"user_id" gorm:"index"`
+	Username   string `json:"username" gorm:"index;size:100"`
+	APITokenID uint   `json:"api_token_id,omitempty"` // 使用 API token 时为 token 的 ID
+	Cluster    string `json:"cluster" gorm:"index;size:100"`
+	Namespace  string `json:"namespace" gorm:"index;size:253"`
+	Resource   string `json:"resource" gorm:"index;size:100"`
+	Name       string `json:"name" gorm:"size:253"`
+	Verb       string `json:"verb" gorm:"index;size:20"`
+	Method     string `json:"method" gorm:"size:10"`
+	Path       string `json:"path" gorm:"size:1024"`
+	// BodyHash 请求体的 SHA-256，不保存请求体本身 (可能包含 Secret 等敏感数据)
+	BodyHash  string    `json:"body_hash" gorm:"size:64"`
+	Status    int       `json:"status" gorm:"index"`
+	LatencyMs int64     `json:"latency_ms"`
+	IP        string    `json:"ip" gorm:"size:64"`
+	UserAgent string    `json:"user_agent" gorm:"size:255"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName 指定表名
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// AuditQuery 审计记录查询条件，空字段不过滤；Since/Until 为 RFC 3339 时间
+type AuditQuery struct {
+	Username  string    `form:"username"`
+	Cluster   string    `form:"cluster"`
+	Namespace string    `form:"namespace"`
+	Resource  string    `form:"resource"`
+	Verb      string    `form:"verb"`
+	Since     time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until     time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+	Failed    bool      `form:"failed"` // 只返回状态码 >= 400 的记录
+	Page      int       `form:"page"`
+	PageSize  int       `form:"page_size"`
+}
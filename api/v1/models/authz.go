@@ -0,0 +1,50 @@
+package models
+
+// Policy 一条授权规则：角色在某集群、某命名空间，对某种资源可以执行的动作。
+// Cluster/Namespace/Resource 支持 "*" 及后缀通配，例如命名空间 "a-*"
+type Policy struct {
+	Role      string `json:"role"`
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Resource  string `json:"resource"`
+	Verb      string `json:"verb"`
+}
+
+// PolicyRequest 为角色批量添加或删除规则，每个动词生成一条 Policy。
+// Cluster/Namespace/Resource 为空时视为 "*"
+type PolicyRequest struct {
+	Role      string   `json:"role" binding:"required"`
+	Cluster   string   `json:"cluster"`
+	Namespace string   `json:"namespace"`
+	Resource  string   `json:"resource"`
+	Verbs     []string `json:"verbs" binding:"required,min=1"`
+}
+
+// RoleBinding 将用户绑定到角色，仅在 Cluster 匹配的集群上生效
+type RoleBinding struct {
+	User    string `json:"user" binding:"required"`
+	Role    string `json:"role" binding:"required"`
+	Cluster string `json:"cluster"`
+}
+
+// RoleInfo 角色及其规则、绑定
+type RoleInfo struct {
+	Name     string        `json:"name"`
+	BuiltIn  bool          `json:"builtIn"`
+	Policies []Policy      `json:"policies"`
+	Bindings []RoleBinding `json:"bindings"`
+}
+
+// AuthzCheckRequest 检查用户是否可以执行某个操作
+type AuthzCheckRequest struct {
+	User      string `json:"user" binding:"required"`
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Resource  string `json:"resource" binding:"required"`
+	Verb      string `json:"verb" binding:"required"`
+}
+
+// AuthzCheckResponse 检查结果
+type AuthzCheckResponse struct {
+	Allowed bool `json:"allowed"`
+}
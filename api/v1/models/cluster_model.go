@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // ClusterInfo represents metadata about a configured Kubernetes cluster.
 type ClusterInfo struct {
 	Name           string `json:"name"`
@@ -14,3 +16,36 @@ type AddClusterRequest struct {
 	KubeconfigContent string `json:"kubeconfigContent" binding:"required"`
 	Description       string `json:"description"`
 }
+
+// UpdateClusterRequest is the request body for renaming or re-describing a cluster.
+type UpdateClusterRequest struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+}
+
+// ValidateClusterRequest is the request body for checking a kubeconfig before adding it.
+type ValidateClusterRequest struct {
+	KubeconfigContent string `json:"kubeconfigContent" binding:"required"`
+}
+
+// ClusterDetail describes a registered cluster together with its live connection state.
+type ClusterDetail struct {
+	ClusterInfo
+	Source    string `json:"source"`            // "config" (config.yaml) or "registry" (added through the API)
+	Status    string `json:"status"`            // Latest background health probe result: unknown, healthy or unavailable
+	Server    string `json:"server,omitempty"`  // API server address
+	Version   string `json:"version,omitempty"` // Kubernetes version reported by the API server
+	Connected bool   `json:"connected"`         // Whether the API server answered the version probe
+	Error     string `json:"error,omitempty"`   // Connection error, if any
+}
+
+// Cluster is the database record for a cluster added through the registry API.
+// It is only used when the database is enabled; otherwise configs/cluster.json is used.
+type Cluster struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	Name              string    `json:"name" gorm:"uniqueIndex;size:63;not null"`
+	Description       string    `json:"description" gorm:"size:255"`
+	KubeconfigContent string    `json:"-" gorm:"type:longtext;not null"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
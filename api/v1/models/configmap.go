@@ -20,7 +20,7 @@ type ConfigMapResponse struct {
 // ConfigMapListResponse structures the list response for ConfigMaps.
 type ConfigMapListResponse struct {
 	Items []ConfigMapResponse `json:"items"`
-	Total int                 `json:"total"`
+	ListMeta
 }
 
 // ToConfigMapResponse converts a corev1.ConfigMap to our API response model.
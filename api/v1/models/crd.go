@@ -0,0 +1,178 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// 响应结构
+type CRDResponse struct {
+	Name           string               `json:"name"`
+	Group          string               `json:"group"`
+	Kind           string               `json:"kind"`
+	Plural         string               `json:"plural"`
+	Singular       string               `json:"singular,omitempty"`
+	ShortNames     []string             `json:"shortNames,omitempty"`
+	Categories     []string             `json:"categories,omitempty"`
+	Scope          string               `json:"scope"` // Namespaced 或 Cluster
+	Versions       []CRDVersionResponse `json:"versions"`
+	StorageVersion string               `json:"storageVersion,omitempty"`
+	Established    bool                 `json:"established"`
+	CreatedAt      metav1.Time          `json:"createdAt"`
+}
+
+type CRDVersionResponse struct {
+	Name               string             `json:"name"`
+	Served             bool               `json:"served"`
+	Storage            bool               `json:"storage"`
+	Deprecated         bool               `json:"deprecated,omitempty"`
+	DeprecationWarning string             `json:"deprecationWarning,omitempty"`
+	PrinterColumns     []CRDPrinterColumn `json:"printerColumns"`
+	// Schema 仅在详情接口中返回
+	Schema *apiextensionsv1.JSONSchemaProps `json:"schema,omitempty"`
+}
+
+// CRDPrinterColumn 对应 additionalPrinterColumns，priority > 0 的列在宽视图中显示
+type CRDPrinterColumn struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Format      string `json:"format,omitempty"`
+	Description string `json:"description,omitempty"`
+	Priority    int32  `json:"priority"`
+	JSONPath    string `json:"jsonPath"`
+}
+
+type CRDListResponse struct {
+	Items []CRDResponse `json:"items"`
+	ListMeta
+}
+
+// CustomResourceTable 按 CRD 的 additionalPrinterColumns 渲染的自定义资源列表
+type CustomResourceTable struct {
+	Group      string              `json:"group"`
+	Version    string              `json:"version"`
+	Resource   string              `json:"resource"`
+	Kind       string              `json:"kind"`
+	Namespaced bool                `json:"namespaced"`
+	Columns    []CRDPrinterColumn  `json:"columns"`
+	Rows       []CustomResourceRow `json:"rows"`
+	ListMeta
+}
+
+// CustomResourceRow 一行对应一个自定义资源，cells 与 columns 一一对应，取不到值时为 null
+type CustomResourceRow struct {
+	Name      string        `json:"name"`
+	Namespace string        `json:"namespace,omitempty"`
+	CreatedAt metav1.Time   `json:"createdAt"`
+	Cells     []interface{} `json:"cells"`
+}
+
+// defaultPrinterColumns 未声明 additionalPrinterColumns 时与 kubectl 一致，只显示 Age
+var defaultPrinterColumns = []CRDPrinterColumn{
+	{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+}
+
+func ToCRDResponse(crd *apiextensionsv1.CustomResourceDefinition) CRDResponse {
+	resp := CRDResponse{
+		Name:       crd.Name,
+		Group:      crd.Spec.Group,
+		Kind:       crd.Spec.Names.Kind,
+		Plural:     crd.Spec.Names.Plural,
+		Singular:   crd.Spec.Names.Singular,
+		ShortNames: crd.Spec.Names.ShortNames,
+		Categories: crd.Spec.Names.Categories,
+		Scope:      string(crd.Spec.Scope),
+		Versions:   make([]CRDVersionResponse, 0, len(crd.Spec.Versions)),
+		CreatedAt:  crd.CreationTimestamp,
+	}
+	for _, version := range crd.Spec.Versions {
+		v := CRDVersionResponse{
+			Name:           version.Name,
+			Served:         version.Served,
+			Storage:        version.Storage,
+			Deprecated:     version.Deprecated,
+			PrinterColumns: ToCRDPrinterColumns(&version),
+		}
+		if version.DeprecationWarning != nil {
+			v.DeprecationWarning = *version.DeprecationWarning
+		}
+		if version.Storage {
+			resp.StorageVersion = version.Name
+		}
+		resp.Versions = append(resp.Versions, v)
+	}
+	for _, cond := range crd.Status.Conditions {
+		if cond.Type == apiextensionsv1.Established && cond.Status == apiextensionsv1.ConditionTrue {
+			resp.Established = true
+		}
+	}
+	return resp
+}
+
+// ToCRDDetailResponse 在列表信息之外附带每个版本的 OpenAPI schema
+func ToCRDDetailResponse(crd *apiextensionsv1.CustomResourceDefinition) CRDResponse {
+	resp := ToCRDResponse(crd)
+	for i, version := range crd.Spec.Versions {
+		if version.Schema != nil {
+			resp.Versions[i].Schema = version.Schema.OpenAPIV3Schema
+		}
+	}
+	return resp
+}
+
+func ToCRDPrinterColumns(version *apiextensionsv1.CustomResourceDefinitionVersion) []CRDPrinterColumn {
+	if len(version.AdditionalPrinterColumns) == 0 {
+		return append([]CRDPrinterColumn(nil), defaultPrinterColumns...)
+	}
+	columns := make([]CRDPrinterColumn, 0, len(version.AdditionalPrinterColumns))
+	for _, col := range version.AdditionalPrinterColumns {
+		columns = append(columns, CRDPrinterColumn{
+			Name:        col.Name,
+			Type:        col.Type,
+			Format:      col.Format,
+			Description: col.Description,
+			Priority:    col.Priority,
+			JSONPath:    col.JSONPath,
+		})
+	}
+	return columns
+}
+
+// ToCustomResourceRow 按列的 jsonPath 从对象中取值
+func ToCustomResourceRow(obj *unstructured.Unstructured, columns []CRDPrinterColumn) CustomResourceRow {
+	row := CustomResourceRow{
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+		CreatedAt: obj.GetCreationTimestamp(),
+		Cells:     make([]interface{}, 0, len(columns)),
+	}
+	for _, col := range columns {
+		row.Cells = append(row.Cells, printerColumnValue(obj, col.JSONPath))
+	}
+	return row
+}
+
+// printerColumnValue 计算 jsonPath，结果为单个值时原样返回，多个值时与 kubectl 一样以逗号拼接
+func printerColumnValue(obj *unstructured.Unstructured, path string) interface{} {
+	parser := jsonpath.New("column").AllowMissingKeys(true)
+	if err := parser.Parse(fmt.Sprintf("{%s}", path)); err != nil {
+		return nil
+	}
+	results, err := parser.FindResults(obj.Object)
+	if err != nil || len(results) == 0 || len(results[0]) == 0 {
+		return nil
+	}
+	if len(results[0]) == 1 {
+		return results[0][0].Interface()
+	}
+	values := make([]string, 0, len(results[0]))
+	for _, value := range results[0] {
+		values = append(values, fmt.Sprint(value.Interface()))
+	}
+	return strings.Join(values, ",")
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// 请求结构
+type CreateCronJobRequest struct {
+	Name        string              `json:"name" binding:"required"`
+	Namespace   string              `json:"namespace" binding:"required"`
+	Labels      map[string]string   `json:"labels,omitempty"`
+	Annotations map[string]string   `json:"annotations,omitempty"`
+	Spec        batchv1.CronJobSpec `json:"spec" binding:"required"`
+}
+
+type UpdateCronJobRequest struct {
+	Labels      map[string]string   `json:"labels,omitempty"`
+	Annotations map[string]string   `json:"annotations,omitempty"`
+	Spec        batchv1.CronJobSpec `json:"spec" binding:"required"`
+}
+
+// TriggerCronJobRequest 立即运行 CronJob，JobName 为空时自动生成
+type TriggerCronJobRequest struct {
+	JobName string `json:"jobName,omitempty"`
+}
+
+// 响应结构
+type CronJobResponse struct {
+	Name        string                `json:"name"`
+	Namespace   string                `json:"namespace"`
+	Labels      map[string]string     `json:"labels,omitempty"`
+	Annotations map[string]string     `json:"annotations,omitempty"`
+	Schedule    string                `json:"schedule"`
+	Suspended   bool                  `json:"suspended"`
+	Active      int                   `json:"active"`
+	Spec        batchv1.CronJobSpec   `json:"spec"`
+	Status      batchv1.CronJobStatus `json:"status"`
+	CreatedAt   metav1.Time           `json:"createdAt"`
+}
+
+type CronJobListResponse struct {
+	Items []CronJobResponse `json:"items"`
+	ListMeta
+}
+
+// CronJobHistoryResponse CronJob 创建的 Job，按创建时间倒序，以及各阶段的数量
+type CronJobHistoryResponse struct {
+	Jobs      []JobResponse `json:"jobs"`
+	Running   int           `json:"running"` // 尚未结束的 Job，包括暂停的
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+}
+
+func ToCronJobResponse(cronJob *batchv1.CronJob) CronJobResponse {
+	return CronJobResponse{
+		Name:        cronJob.Name,
+		Namespace:   cronJob.Namespace,
+		Labels:      cronJob.Labels,
+		Annotations: cronJob.Annotations,
+		Schedule:    cronJob.Spec.Schedule,
+		Suspended:   cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend,
+		Active:      len(cronJob.Status.Active),
+		Spec:        cronJob.Spec,
+		Status:      cronJob.Status,
+		CreatedAt:   cronJob.CreationTimestamp,
+	}
+}
@@ -36,7 +36,7 @@ type DaemonSetResponse struct {
 
 type DaemonSetListResponse struct {
 	Items []DaemonSetResponse `json:"items"`
-	Total int                 `json:"total"`
+	ListMeta
 }
 
 func ToDaemonSetResponse(daemonset *appsv1.DaemonSet) DaemonSetResponse {
@@ -32,15 +32,18 @@ type DeploymentResponse struct {
 	AvailableReplicas   int32             `json:"availableReplicas"`
 	UnavailableReplicas int32             `json:"unavailableReplicas"`
 	CreatedAt           metav1.Time       `json:"createdAt"`
+	HPA                 *HPAReference     `json:"hpa,omitempty"`          // 以该 Deployment 为目标的 HPA，只在详情和扩缩容接口中填充
+	ScaleWarning        string            `json:"scaleWarning,omitempty"` // 扩缩容时存在 HPA 的提示
+	HPAWarning          string            `json:"hpaWarning,omitempty"`   // 无法查询 HPA 时的原因，此时 HPA 字段为空并不代表没有 HPA
 }
 
 type DeploymentListResponse struct {
 	Items []DeploymentResponse `json:"items"`
-	Total int                  `json:"total"`
+	ListMeta
 }
 
 type ScaleDeploymentRequest struct {
-	Replicas int32 `json:"replicas" binding:"required"`
+	Replicas *int32 `json:"replicas" binding:"required,min=0"` // 指针类型，允许缩容到 0
 }
 
 func ToDeploymentResponse(deployment *appsv1.Deployment) DeploymentResponse {
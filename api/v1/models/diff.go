@@ -0,0 +1,31 @@
+package models
+
+// Diff 预览模式
+const (
+	DiffModeUpdate = "update" // 以请求体整体替换当前对象 (PUT)
+	DiffModeApply  = "apply"  // 以请求体做 server-side apply，可只包含需要修改的字段
+)
+
+// DiffChange 一处字段变化
+type DiffChange struct {
+	Path string      `json:"path"` // JSON Pointer，如 /spec/replicas
+	Op   string      `json:"op"`   // add / remove / replace
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// DiffResponse /diff 接口的响应：当前对象与服务端 dry-run 结果的差异。
+// 比较前两侧都会去掉 managedFields、status、resourceVersion 和 generation
+type DiffResponse struct {
+	APIVersion string                 `json:"apiVersion"`
+	Kind       string                 `json:"kind"`
+	Namespace  string                 `json:"namespace,omitempty"`
+	Name       string                 `json:"name"`
+	Mode       string                 `json:"mode"`
+	Exists     bool                   `json:"exists"` // 对象不存在时为创建预览
+	Changed    bool                   `json:"changed"`
+	Changes    []DiffChange           `json:"changes"`
+	Unified    string                 `json:"unified"` // unified diff 格式的 YAML 差异
+	Live       map[string]interface{} `json:"live,omitempty"`
+	Result     map[string]interface{} `json:"result"`
+}
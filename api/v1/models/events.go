@@ -40,7 +40,7 @@ type ObjectReference struct {
 // EventList 表示事件列表
 type EventList struct {
 	Items []Event `json:"items"`
-	Total int     `json:"total"`
+	ListMeta
 }
 
 // K8sEventToEvent 将Kubernetes Event转换为应用模型
@@ -0,0 +1,180 @@
+package models
+
+import (
+	"fmt"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// 请求结构
+type CreateHPARequest struct {
+	Name        string                                    `json:"name" binding:"required"`
+	Namespace   string                                    `json:"namespace" binding:"required"`
+	Labels      map[string]string                         `json:"labels,omitempty"`
+	Annotations map[string]string                         `json:"annotations,omitempty"`
+	Spec        autoscalingv2.HorizontalPodAutoscalerSpec `json:"spec" binding:"required"`
+}
+
+type UpdateHPARequest struct {
+	Labels      map[string]string                         `json:"labels,omitempty"`
+	Annotations map[string]string                         `json:"annotations,omitempty"`
+	Spec        autoscalingv2.HorizontalPodAutoscalerSpec `json:"spec" binding:"required"`
+}
+
+// 响应结构
+type HPAResponse struct {
+	Name            string                                           `json:"name"`
+	Namespace       string                                           `json:"namespace"`
+	Labels          map[string]string                                `json:"labels,omitempty"`
+	Annotations     map[string]string                                `json:"annotations,omitempty"`
+	ScaleTargetRef  autoscalingv2.CrossVersionObjectReference        `json:"scaleTargetRef"`
+	MinReplicas     int32                                            `json:"minReplicas"`
+	MaxReplicas     int32                                            `json:"maxReplicas"`
+	CurrentReplicas int32                                            `json:"currentReplicas"`
+	DesiredReplicas int32                                            `json:"desiredReplicas"`
+	Metrics         []HPAMetric                                      `json:"metrics"`
+	Conditions      []autoscalingv2.HorizontalPodAutoscalerCondition `json:"conditions,omitempty"`
+	LastScaleTime   *metav1.Time                                     `json:"lastScaleTime,omitempty"`
+	Spec            autoscalingv2.HorizontalPodAutoscalerSpec        `json:"spec"`
+	Status          autoscalingv2.HorizontalPodAutoscalerStatus      `json:"status"`
+	CreatedAt       metav1.Time                                      `json:"createdAt"`
+}
+
+// HPAMetric 一个指标的目标值与当前值，格式与 kubectl describe hpa 相同，例如 80% / 45%
+type HPAMetric struct {
+	Type    autoscalingv2.MetricSourceType `json:"type"`
+	Name    string                         `json:"name"`
+	Target  string                         `json:"target"`
+	Current string                         `json:"current"` // 指标尚未采集到时为 <unknown>
+}
+
+type HPAListResponse struct {
+	Items []HPAResponse `json:"items"`
+	ListMeta
+}
+
+// HPAReference 以某个工作负载为目标的 HPA 概要，嵌入在 Deployment / StatefulSet 详情中
+type HPAReference struct {
+	Name            string `json:"name"`
+	MinReplicas     int32  `json:"minReplicas"`
+	MaxReplicas     int32  `json:"maxReplicas"`
+	CurrentReplicas int32  `json:"currentReplicas"`
+	DesiredReplicas int32  `json:"desiredReplicas"`
+}
+
+func ToHPAResponse(hpa *autoscalingv2.HorizontalPodAutoscaler) HPAResponse {
+	resp := HPAResponse{
+		Name:            hpa.Name,
+		Namespace:       hpa.Namespace,
+		Labels:          hpa.Labels,
+		Annotations:     hpa.Annotations,
+		ScaleTargetRef:  hpa.Spec.ScaleTargetRef,
+		MinReplicas:     hpaMinReplicas(hpa),
+		MaxReplicas:     hpa.Spec.MaxReplicas,
+		CurrentReplicas: hpa.Status.CurrentReplicas,
+		DesiredReplicas: hpa.Status.DesiredReplicas,
+		Metrics:         make([]HPAMetric, 0, len(hpa.Spec.Metrics)),
+		Conditions:      hpa.Status.Conditions,
+		LastScaleTime:   hpa.Status.LastScaleTime,
+		Spec:            hpa.Spec,
+		Status:          hpa.Status,
+		CreatedAt:       hpa.CreationTimestamp,
+	}
+	// 与 kubectl 一样按下标把 status.currentMetrics 对应到 spec.metrics
+	for i, spec := range hpa.Spec.Metrics {
+		var status *autoscalingv2.MetricStatus
+		if i < len(hpa.Status.CurrentMetrics) {
+			status = &hpa.Status.CurrentMetrics[i]
+		}
+		resp.Metrics = append(resp.Metrics, toHPAMetric(spec, status))
+	}
+	return resp
+}
+
+func ToHPAReference(hpa *autoscalingv2.HorizontalPodAutoscaler) *HPAReference {
+	return &HPAReference{
+		Name:            hpa.Name,
+		MinReplicas:     hpaMinReplicas(hpa),
+		MaxReplicas:     hpa.Spec.MaxReplicas,
+		CurrentReplicas: hpa.Status.CurrentReplicas,
+		DesiredReplicas: hpa.Status.DesiredReplicas,
+	}
+}
+
+// hpaMinReplicas spec.minReplicas 未设置时默认为 1
+func hpaMinReplicas(hpa *autoscalingv2.HorizontalPodAutoscaler) int32 {
+	if hpa.Spec.MinReplicas == nil {
+		return 1
+	}
+	return *hpa.Spec.MinReplicas
+}
+
+func toHPAMetric(spec autoscalingv2.MetricSpec, status *autoscalingv2.MetricStatus) HPAMetric {
+	metric := HPAMetric{Type: spec.Type, Current: "<unknown>"}
+	var current *autoscalingv2.MetricValueStatus
+	switch spec.Type {
+	case autoscalingv2.ResourceMetricSourceType:
+		if spec.Resource != nil {
+			metric.Name = string(spec.Resource.Name)
+			metric.Target = formatMetricTarget(spec.Resource.Target)
+		}
+		if status != nil && status.Resource != nil {
+			current = &status.Resource.Current
+		}
+	case autoscalingv2.ContainerResourceMetricSourceType:
+		if spec.ContainerResource != nil {
+			metric.Name = spec.ContainerResource.Container + "/" + string(spec.ContainerResource.Name)
+			metric.Target = formatMetricTarget(spec.ContainerResource.Target)
+		}
+		if status != nil && status.ContainerResource != nil {
+			current = &status.ContainerResource.Current
+		}
+	case autoscalingv2.PodsMetricSourceType:
+		if spec.Pods != nil {
+			metric.Name = spec.Pods.Metric.Name
+			metric.Target = formatMetricTarget(spec.Pods.Target)
+		}
+		if status != nil && status.Pods != nil {
+			current = &status.Pods.Current
+		}
+	case autoscalingv2.ObjectMetricSourceType:
+		if spec.Object != nil {
+			metric.Name = fmt.Sprintf("%s (on %s/%s)", spec.Object.Metric.Name, spec.Object.DescribedObject.Kind, spec.Object.DescribedObject.Name)
+			metric.Target = formatMetricTarget(spec.Object.Target)
+		}
+		if status != nil && status.Object != nil {
+			current = &status.Object.Current
+		}
+	case autoscalingv2.ExternalMetricSourceType:
+		if spec.External != nil {
+			metric.Name = spec.External.Metric.Name
+			metric.Target = formatMetricTarget(spec.External.Target)
+		}
+		if status != nil && status.External != nil {
+			current = &status.External.Current
+		}
+	}
+	if current != nil {
+		metric.Current = formatMetricValue(current.AverageUtilization, current.AverageValue, current.Value)
+	}
+	return metric
+}
+
+func formatMetricTarget(target autoscalingv2.MetricTarget) string {
+	return formatMetricValue(target.AverageUtilization, target.AverageValue, target.Value)
+}
+
+// formatMetricValue 利用率显示为百分比，平均值带 (avg) 后缀
+func formatMetricValue(utilization *int32, averageValue, value *resource.Quantity) string {
+	switch {
+	case utilization != nil:
+		return fmt.Sprintf("%d%%", *utilization)
+	case averageValue != nil:
+		return averageValue.String() + " (avg)"
+	case value != nil:
+		return value.String()
+	}
+	return "<unknown>"
+}
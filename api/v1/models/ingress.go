@@ -33,7 +33,7 @@ type IngressResponse struct {
 
 type IngressListResponse struct {
 	Items []IngressResponse `json:"items"`
-	Total int               `json:"total"`
+	ListMeta
 }
 
 func ToIngressResponse(ingress *networkingv1.Ingress) IngressResponse {
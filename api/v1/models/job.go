@@ -0,0 +1,99 @@
+package models
+
+import (
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Job 的运行阶段，由 status.conditions 和 spec.suspend 推导
+const (
+	JobPhaseRunning   = "Running"
+	JobPhaseComplete  = "Complete"
+	JobPhaseFailed    = "Failed"
+	JobPhaseSuspended = "Suspended"
+)
+
+// 请求结构
+type CreateJobRequest struct {
+	Name        string            `json:"name" binding:"required"`
+	Namespace   string            `json:"namespace" binding:"required"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Spec        batchv1.JobSpec   `json:"spec" binding:"required"`
+}
+
+type UpdateJobRequest struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Spec        batchv1.JobSpec   `json:"spec" binding:"required"`
+}
+
+// CleanupJobsQuery 清理已结束 Job 的条件，运行中的 Job 永远不会被清理
+type CleanupJobsQuery struct {
+	Status        string `form:"status" binding:"omitempty,oneof=succeeded failed"` // 为空时清理成功和失败的 Job
+	OlderThan     string `form:"olderThan"`                                         // 只清理结束时间早于该时长的 Job，例如 24h
+	LabelSelector string `form:"labelSelector"`
+}
+
+// 响应结构
+type JobResponse struct {
+	Name           string            `json:"name"`
+	Namespace      string            `json:"namespace"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	Annotations    map[string]string `json:"annotations,omitempty"`
+	Phase          string            `json:"phase"`
+	CronJob        string            `json:"cronJob,omitempty"` // 由 CronJob 创建时为其名称
+	Spec           batchv1.JobSpec   `json:"spec"`
+	Status         batchv1.JobStatus `json:"status"`
+	StartTime      *metav1.Time      `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time      `json:"completionTime,omitempty"` // 只有成功的 Job 才有
+	CreatedAt      metav1.Time       `json:"createdAt"`
+}
+
+type JobListResponse struct {
+	Items []JobResponse `json:"items"`
+	ListMeta
+}
+
+type CleanupJobsResponse struct {
+	Deleted []string `json:"deleted"`
+}
+
+func ToJobResponse(job *batchv1.Job) JobResponse {
+	resp := JobResponse{
+		Name:           job.Name,
+		Namespace:      job.Namespace,
+		Labels:         job.Labels,
+		Annotations:    job.Annotations,
+		Phase:          JobPhase(job),
+		Spec:           job.Spec,
+		Status:         job.Status,
+		StartTime:      job.Status.StartTime,
+		CompletionTime: job.Status.CompletionTime,
+		CreatedAt:      job.CreationTimestamp,
+	}
+	if owner := metav1.GetControllerOf(job); owner != nil && owner.Kind == "CronJob" {
+		resp.CronJob = owner.Name
+	}
+	return resp
+}
+
+// JobPhase Complete 和 Failed 条件为终态，其次是暂停，否则视为运行中
+func JobPhase(job *batchv1.Job) string {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return JobPhaseComplete
+		case batchv1.JobFailed:
+			return JobPhaseFailed
+		}
+	}
+	if job.Spec.Suspend != nil && *job.Spec.Suspend {
+		return JobPhaseSuspended
+	}
+	return JobPhaseRunning
+}
@@ -0,0 +1,49 @@
+package models
+
+// 列表排序字段
+const (
+	SortByName              = "name"
+	SortByNamespace         = "namespace"
+	SortByCreationTimestamp = "creationTimestamp"
+
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+
+	// DefaultListLimit 请求未指定 limit 时每页的数量，避免一次返回大集群中的全部对象
+	DefaultListLimit = 500
+)
+
+// ListQuery 所有列表接口统一的查询参数，由 handler 解析一次后交给 service
+type ListQuery struct {
+	Limit         int64  `form:"limit" json:"limit,omitempty"`                 // 每页数量，未指定时为 DefaultListLimit，0 表示不分页
+	Continue      string `form:"continue" json:"continue,omitempty"`           // 上一页返回的 continue
+	LabelSelector string `form:"labelSelector" json:"labelSelector,omitempty"` // 标签选择器
+	FieldSelector string `form:"fieldSelector" json:"fieldSelector,omitempty"` // 字段选择器，直接交给 API Server
+	SortBy        string `form:"sortBy" json:"sortBy,omitempty"`               // name / namespace / creationTimestamp
+	Order         string `form:"order" json:"order,omitempty"`                 // asc (默认) / desc
+	Search        string `form:"search" json:"search,omitempty"`               // 名称子串匹配，不区分大小写
+}
+
+// ListMeta 列表响应中的分页信息
+type ListMeta struct {
+	// Total 符合条件的对象总数；由 API Server 分页且未返回剩余数量时，仅为本页数量
+	Total int `json:"total"`
+	// Continue 非空时表示还有下一页，原样放入下一次请求的 continue 参数
+	Continue string `json:"continue,omitempty"`
+	// RemainingItemCount 本页之后剩余的对象数量 (已知时)
+	RemainingItemCount *int64 `json:"remainingItemCount,omitempty"`
+}
+
+// ListResponse 直接返回 Kubernetes 原始对象的列表响应
+type ListResponse[T any] struct {
+	Items []T `json:"items"`
+	ListMeta
+}
+
+// NewListResponse 构造列表响应，保证 items 不为 null
+func NewListResponse[T any](items []T, meta ListMeta) ListResponse[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return ListResponse[T]{Items: items, ListMeta: meta}
+}
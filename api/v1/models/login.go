@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// 登录方式
+const (
+	LoginMethodPassword  = "password"
+	LoginMethodTwoFactor = "2fa"
+	LoginMethodOIDC      = "oidc"
+)
+
+// LoginClient 发起登录请求的客户端，用于锁定与审计
+type LoginClient struct {
+	IP        string
+	UserAgent string
+}
+
+// LoginEvent 登录审计记录，每次登录成功或失败写入一条
+type LoginEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"index"` // 用户不存在时为 0
+	Username  string    `json:"username" gorm:"index;size:100"`
+	Method    string    `json:"method" gorm:"size:20"`
+	Success   bool      `json:"success" gorm:"index"`
+	Reason    string    `json:"reason" gorm:"size:255"` // 失败原因，成功时为空
+	IP        string    `json:"ip" gorm:"index;size:64"`
+	UserAgent string    `json:"user_agent" gorm:"size:255"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName 指定表名
+func (LoginEvent) TableName() string {
+	return "login_events"
+}
+
+// LoginFailure 按用户名或来源 IP 累计的登录失败次数，Key 形如 "user:<name>"、"ip:<addr>"
+type LoginFailure struct {
+	Key           string `gorm:"column:lock_key;primaryKey;size:191"`
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
+
+// TableName 指定表名
+func (LoginFailure) TableName() string {
+	return "login_failures"
+}
+
+// PasswordHistory 用户曾经使用过的密码哈希，用于禁止重复使用
+type PasswordHistory struct {
+	ID           uint   `gorm:"primaryKey"`
+	UserID       uint   `gorm:"index;not null"`
+	PasswordHash string `gorm:"not null"`
+	CreatedAt    time.Time
+}
+
+// TableName 指定表名
+func (PasswordHistory) TableName() string {
+	return "password_histories"
+}
+
+// LoginEventQuery 登录审计查询条件，Success 为空时不过滤
+type LoginEventQuery struct {
+	Username string `form:"username"`
+	IP       string `form:"ip"`
+	Success  *bool  `form:"success"`
+	Page     int    `form:"page"`
+	PageSize int    `form:"page_size"`
+}
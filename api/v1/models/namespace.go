@@ -28,7 +28,7 @@ type NamespaceResponse struct {
 
 type NamespaceListResponse struct {
 	Items []NamespaceResponse `json:"items"`
-	Total int                 `json:"total"`
+	ListMeta
 }
 
 func ToNamespaceResponse(namespace *corev1.Namespace) NamespaceResponse {
@@ -32,7 +32,7 @@ type NetworkPolicyResponse struct {
 
 type NetworkPolicyListResponse struct {
 	Items []NetworkPolicyResponse `json:"items"`
-	Total int                     `json:"total"`
+	ListMeta
 }
 
 func ToNetworkPolicyResponse(networkPolicy *networkingv1.NetworkPolicy) NetworkPolicyResponse {
@@ -31,7 +31,7 @@ type NodeResponse struct {
 
 type NodeListResponse struct {
 	Items []NodeResponse `json:"items"`
-	Total int            `json:"total"`
+	ListMeta
 }
 
 func ToNodeResponse(node *corev1.Node) NodeResponse {
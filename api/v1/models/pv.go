@@ -31,7 +31,7 @@ type PVResponse struct {
 
 type PVListResponse struct {
 	Items []PVResponse `json:"items"`
-	Total int          `json:"total"`
+	ListMeta
 }
 
 func ToPVResponse(pv *corev1.PersistentVolume) PVResponse {
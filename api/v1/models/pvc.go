@@ -74,7 +74,7 @@ type PVCResponse struct {
 // PVCListResponse is the response structure for listing PVCs.
 type PVCListResponse struct {
 	Items []PVCResponse `json:"items"`
-	Total int           `json:"total"`
+	ListMeta
 }
 
 // ToPVCResponse converts a corev1.PersistentVolumeClaim to our PVCResponse model.
@@ -0,0 +1,25 @@
+package models
+
+// CoreGroupAlias 路由中代表核心 API 组 (空组名) 的占位符，例如 /resources/core/v1/pods
+const CoreGroupAlias = "core"
+
+// APIResource 发现接口返回的一种可访问的资源
+type APIResource struct {
+	Group      string   `json:"group"`
+	Version    string   `json:"version"`
+	Resource   string   `json:"resource"` // 复数资源名，用于 /resources/:group/:version/:resource
+	Kind       string   `json:"kind"`
+	Namespaced bool     `json:"namespaced"`
+	Verbs      []string `json:"verbs"`
+	ShortNames []string `json:"shortNames,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+	Preferred  bool     `json:"preferred"` // 是否为该组的首选版本
+}
+
+// APIResourceListResponse 发现接口的响应
+type APIResourceListResponse struct {
+	Items []APIResource `json:"items"`
+	Total int           `json:"total"`
+	// FailedGroups 发现失败的 GroupVersion 及原因 (如聚合 API 不可用)，其余资源仍正常返回
+	FailedGroups map[string]string `json:"failedGroups,omitempty"`
+}
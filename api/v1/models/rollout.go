@@ -0,0 +1,54 @@
+package models
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// ContainerImage 容器及其镜像
+type ContainerImage struct {
+	Container string `json:"container"`
+	Image     string `json:"image"`
+}
+
+// ImageChange 相对上一个版本的镜像变化，新增容器的 From 和删除容器的 To 为空
+type ImageChange struct {
+	Container string `json:"container"`
+	From      string `json:"from,omitempty"`
+	To        string `json:"to,omitempty"`
+}
+
+// DeploymentRevision Deployment 的一个历史版本，对应一个由它创建的 ReplicaSet
+type DeploymentRevision struct {
+	Revision     int64            `json:"revision"`
+	ReplicaSet   string           `json:"replicaSet"`
+	ChangeCause  string           `json:"changeCause,omitempty"` // kubernetes.io/change-cause 注解
+	Images       []ContainerImage `json:"images"`
+	ImageChanges []ImageChange    `json:"imageChanges,omitempty"`
+	Replicas     int32            `json:"replicas"`
+	Current      bool             `json:"current"`
+	CreatedAt    metav1.Time      `json:"createdAt"`
+}
+
+// DeploymentHistoryResponse 按版本号升序排列的历史版本
+type DeploymentHistoryResponse struct {
+	Revisions []DeploymentRevision `json:"revisions"`
+}
+
+// RollbackDeploymentResponse 回滚结果。目标版本与当前模板相同时 Skipped 为 true，Deployment 不变
+type RollbackDeploymentResponse struct {
+	Revision   int64              `json:"revision"`
+	Skipped    bool               `json:"skipped"`
+	Deployment DeploymentResponse `json:"deployment"`
+}
+
+// RolloutStatus 工作负载的 rollout 进度，判断规则与 kubectl rollout status 一致。
+// Done 表示已完成，Failed 表示不会再完成 (例如超过 progressDeadlineSeconds)
+type RolloutStatus struct {
+	Generation         int64  `json:"generation"`
+	ObservedGeneration int64  `json:"observedGeneration"`
+	Replicas           int32  `json:"replicas"` // 期望的副本数，DaemonSet 为需要调度的节点数
+	UpdatedReplicas    int32  `json:"updatedReplicas"`
+	ReadyReplicas      int32  `json:"readyReplicas"`
+	AvailableReplicas  int32  `json:"availableReplicas"`
+	Message            string `json:"message"`
+	Done               bool   `json:"done"`
+	Failed             bool   `json:"failed"`
+}
@@ -23,7 +23,7 @@ type SecretResponse struct {
 // SecretListResponse structures the list response for Secrets.
 type SecretListResponse struct {
 	Items []SecretResponse `json:"items"`
-	Total int              `json:"total"`
+	ListMeta
 }
 
 // ToSecretResponse converts a corev1.Secret to our API list response model.
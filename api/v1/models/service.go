@@ -33,7 +33,7 @@ type ServiceResponse struct {
 
 type ServiceListResponse struct {
 	Items []ServiceResponse `json:"items"`
-	Total int               `json:"total"`
+	ListMeta
 }
 
 func ToServiceResponse(service *corev1.Service) ServiceResponse {
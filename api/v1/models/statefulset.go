@@ -20,20 +20,27 @@ type UpdateStatefulSetRequest struct {
 	Spec        appsv1.StatefulSetSpec `json:"spec" binding:"required"`
 }
 
+type ScaleStatefulSetRequest struct {
+	Replicas *int32 `json:"replicas" binding:"required,min=0"`
+}
+
 // 响应结构
 type StatefulSetResponse struct {
-	Name        string                   `json:"name"`
-	Namespace   string                   `json:"namespace"`
-	Labels      map[string]string        `json:"labels,omitempty"`
-	Annotations map[string]string        `json:"annotations,omitempty"`
-	Spec        appsv1.StatefulSetSpec   `json:"spec"`
-	Status      appsv1.StatefulSetStatus `json:"status"`
-	CreatedAt   metav1.Time              `json:"createdAt"`
+	Name         string                   `json:"name"`
+	Namespace    string                   `json:"namespace"`
+	Labels       map[string]string        `json:"labels,omitempty"`
+	Annotations  map[string]string        `json:"annotations,omitempty"`
+	Spec         appsv1.StatefulSetSpec   `json:"spec"`
+	Status       appsv1.StatefulSetStatus `json:"status"`
+	CreatedAt    metav1.Time              `json:"createdAt"`
+	HPA          *HPAReference            `json:"hpa,omitempty"`
+	ScaleWarning string                   `json:"scaleWarning,omitempty"`
+	HPAWarning   string                   `json:"hpaWarning,omitempty"`
 }
 
 type StatefulSetListResponse struct {
 	Items []StatefulSetResponse `json:"items"`
-	Total int                   `json:"total"`
+	ListMeta
 }
 
 func ToStatefulSetResponse(statefulSet *appsv1.StatefulSet) StatefulSetResponse {
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// RefreshToken 刷新令牌，数据库中只保存其 SHA-256 哈希。
+// 每次刷新都会吊销旧令牌并签发新令牌，同一次登录轮换出的令牌共享 SessionID
+type RefreshToken struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	UserID    uint   `json:"user_id" gorm:"index;not null"`
+	SessionID string `json:"session_id" gorm:"index;size:64;not null"`
+	TokenHash string `json:"-" gorm:"uniqueIndex;size:64;not null"`
+	// AccessTokenID 与该刷新令牌一起签发的 access token 的 jti，吊销会话时一并加入黑名单
+	AccessTokenID   string     `json:"-" gorm:"size:64"`
+	AccessExpiresAt time.Time  `json:"-"`
+	ExpiresAt       time.Time  `json:"expires_at" gorm:"index"`
+	RevokedAt       *time.Time `json:"revoked_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// TableName 指定表名
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// RevokedToken access token 黑名单 (按 jti)，过期后的记录会被清理
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey;size:64"`
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time
+}
+
+// TableName 指定表名
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
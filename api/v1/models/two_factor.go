@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// RecoveryCode 两步验证的一次性恢复码，只保存哈希，使用后即作废
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index;not null"`
+	CodeHash  string `gorm:"size:64;not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// TableName 指定表名
+func (RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
+
+// TwoFactorRequiredRole 拥有该角色 (内置角色或 Casbin 角色) 的本地账号必须启用两步验证
+type TwoFactorRequiredRole struct {
+	Role      string `gorm:"primaryKey;size:100"`
+	CreatedAt time.Time
+}
+
+// TableName 指定表名
+func (TwoFactorRequiredRole) TableName() string {
+	return "two_factor_required_roles"
+}
+
+// TwoFactorChallenge 密码校验通过后返回的登录挑战，凭 ChallengeToken 提交验证码换取 token。
+// EnrollmentRequired 表示角色要求两步验证但用户尚未启用，需要先完成绑定
+type TwoFactorChallenge struct {
+	ChallengeToken     string    `json:"challenge_token"`
+	ExpiresAt          time.Time `json:"expires_at"`
+	EnrollmentRequired bool      `json:"enrollment_required"`
+}
+
+// TwoFactorEnrollment 待确认的 TOTP 密钥，URI 可生成二维码供认证器应用扫描
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// TwoFactorLoginRequest 完成登录的第二步：提交验证码，或使用一个恢复码
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+type TwoFactorEnrollRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password     string `json:"password" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// RecoveryCodesResponse 恢复码明文只在生成时返回一次
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorPolicy 必须启用两步验证的角色
+type TwoFactorPolicy struct {
+	RequiredRoles []string `json:"required_roles"`
+}
@@ -7,18 +7,41 @@ import (
 	"gorm.io/gorm"
 )
 
+// 内置角色，同时作为 Casbin 中的角色名 (g 规则: 用户名 -> 角色)
+const (
+	RoleAdmin = "admin" // 所有接口的全部权限
+	RoleUser  = "user"  // 只读
+)
+
+// 用户来源：本地账号使用密码登录，外部账号由身份提供方首次登录时自动创建，
+// 机器人账号由管理员创建，只能使用 API token
+const (
+	UserSourceLocal = "local"
+	UserSourceOIDC  = "oidc"
+	UserSourceLDAP  = "ldap"
+	UserSourceRobot = "robot"
+)
+
 // User 用户模型
 type User struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Username  string         `json:"username" gorm:"uniqueIndex;not null;size:50"`
-	Email     string         `json:"email" gorm:"uniqueIndex;not null;size:100"`
-	Password  string         `json:"-" gorm:"not null"`
-	Role      string         `json:"role" gorm:"default:user;size:20"`
-	IsActive  bool           `json:"is_active" gorm:"default:true"`
-	LastLogin *time.Time     `json:"last_login"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID           uint   `json:"id" gorm:"primaryKey"`
+	Username     string `json:"username" gorm:"uniqueIndex;not null;size:50"`
+	Email        string `json:"email" gorm:"uniqueIndex;not null;size:100"`
+	Password     string `json:"-" gorm:"not null"`
+	Role         string `json:"role" gorm:"default:user;size:20"`
+	Source       string `json:"source" gorm:"default:local;size:20"`
+	ExternalID   string `json:"-" gorm:"index;size:255"`     // 外部身份提供方中的唯一标识，例如 OIDC 的 issuer 与 sub
+	TokenVersion uint   `json:"-" gorm:"not null;default:0"` // 递增后该用户此前签发的所有 token 失效
+	TOTPSecret   string `json:"-" gorm:"size:64"`            // 两步验证密钥，绑定确认前 TOTPEnabled 为 false
+	TOTPEnabled  bool   `json:"totp_enabled" gorm:"default:false"`
+	TOTPLastStep uint64 `json:"-"` // 最近一次使用的验证码时间窗口，防止重放
+	// MustChangePassword 修改密码前只能访问自己的账号接口，用于初始化创建的账号
+	MustChangePassword bool           `json:"must_change_password" gorm:"default:false"`
+	IsActive           bool           `json:"is_active" gorm:"default:true"`
+	LastLogin          *time.Time     `json:"last_login"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 //// UserRole 用户角色关联表
@@ -43,12 +66,12 @@ type LoginRequest struct {
 type RegisterRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=50"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"` // 强度由 auth.passwordPolicy 校验
 }
 
 type ChangePasswordRequest struct {
 	OldPassword string `json:"old_password" binding:"required"`
-	NewPassword string `json:"new_password" binding:"required,min=6"`
+	NewPassword string `json:"new_password" binding:"required"`
 }
 
 type UpdateProfileRequest struct {
@@ -56,19 +79,28 @@ type UpdateProfileRequest struct {
 }
 
 type UserResponse struct {
-	ID        uint       `json:"id"`
-	Username  string     `json:"username"`
-	Email     string     `json:"email"`
-	Role      string     `json:"role"`
-	IsActive  bool       `json:"is_active"`
-	LastLogin *time.Time `json:"last_login"`
-	CreatedAt time.Time  `json:"created_at"`
+	ID                 uint       `json:"id"`
+	Username           string     `json:"username"`
+	Email              string     `json:"email"`
+	Role               string     `json:"role"`
+	Source             string     `json:"source"`
+	IsActive           bool       `json:"is_active"`
+	TOTPEnabled        bool       `json:"totp_enabled"`
+	MustChangePassword bool       `json:"must_change_password"`
+	LastLogin          *time.Time `json:"last_login"`
+	CreatedAt          time.Time  `json:"created_at"`
 }
 
 type LoginResponse struct {
-	Token     string       `json:"token"`
-	ExpiresAt time.Time    `json:"expires_at"`
-	User      UserResponse `json:"user"`
+	Token            string       `json:"token"`
+	ExpiresAt        time.Time    `json:"expires_at"`
+	RefreshToken     string       `json:"refresh_token"`
+	RefreshExpiresAt time.Time    `json:"refresh_expires_at"`
+	User             UserResponse `json:"user"`
+	// TwoFactor 不为空时需要先完成两步验证，此时不包含 token
+	TwoFactor *TwoFactorChallenge `json:"two_factor,omitempty"`
+	// RecoveryCodes 登录时完成两步验证绑定才会返回
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
 }
 
 // TableName 指定表名
@@ -95,19 +127,27 @@ func (u *User) CheckPassword(password string) bool {
 // ToResponse 转换为响应格式
 func (u *User) ToResponse() UserResponse {
 	return UserResponse{
-		ID:        u.ID,
-		Username:  u.Username,
-		Email:     u.Email,
-		Role:      u.Role,
-		IsActive:  u.IsActive,
-		LastLogin: u.LastLogin,
-		CreatedAt: u.CreatedAt,
+		ID:                 u.ID,
+		Username:           u.Username,
+		Email:              u.Email,
+		Role:               u.Role,
+		Source:             u.Source,
+		IsActive:           u.IsActive,
+		TOTPEnabled:        u.TOTPEnabled,
+		MustChangePassword: u.MustChangePassword,
+		LastLogin:          u.LastLogin,
+		CreatedAt:          u.CreatedAt,
 	}
 }
 
+// IsLocal 是否为使用密码登录的本地账号
+func (u *User) IsLocal() bool {
+	return u.Source == "" || u.Source == UserSourceLocal
+}
+
 // IsAdmin 检查是否为管理员
 func (u *User) IsAdmin() bool {
-	return u.Role == "admin"
+	return u.Role == RoleAdmin
 }
 
 // BeforeCreate GORM钩子：创建前加密密码
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterApplyRoutes 注册清单 apply 相关路由
+func RegisterApplyRoutes(router *gin.RouterGroup, handler *handlers.ApplyHandler) {
+	router.POST("/apply", handler.Apply)
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/api/v1/handlers"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterAuditRoutes 注册审计查询路由，仅管理员可用
+func RegisterAuditRoutes(router *gin.RouterGroup, handler *handlers.AuditHandler) {
+	auditGroup := router.Group("/audit")
+	auditGroup.Use(auth.AdminRequiredMiddleware())
+	{
+		auditGroup.GET("", handler.ListAuditEvents)
+	}
+}
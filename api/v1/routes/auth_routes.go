@@ -6,33 +6,60 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupAuthRoutes(router *gin.Engine) {
-	authHandler := handlers.NewAuthHandler()
+// RegisterAuthRoutes 注册认证路由。
+// public 不需要认证；authenticated 需要有效 token，用于用户管理自己的账号；
+// protected 在认证之外还经过 Casbin 授权，用户管理接口另外要求管理员角色
+func RegisterAuthRoutes(public, authenticated, protected *gin.RouterGroup, authHandler *handlers.AuthHandler) {
+	// 公开路由（不需要认证）
+	publicGroup := public.Group("/auth")
+	{
+		publicGroup.POST("/login", authHandler.Login)
+		publicGroup.POST("/register", authHandler.Register)
+		publicGroup.POST("/refresh", authHandler.Refresh)
+		publicGroup.POST("/login/2fa", authHandler.CompleteTwoFactorLogin)
+		publicGroup.POST("/login/2fa/enroll", authHandler.EnrollTwoFactorLogin)
+	}
 
-	// 认证路由组
-	authGroup := router.Group("/api/v1/auth")
+	// 需要认证的路由
+	authenticatedGroup := authenticated.Group("/auth")
 	{
-		// 公开路由（不需要认证）
-		authGroup.POST("/login", authHandler.Login)
-		authGroup.POST("/register", authHandler.Register)
+		authenticatedGroup.GET("/profile", authHandler.GetProfile)
+		authenticatedGroup.PUT("/profile", authHandler.UpdateProfile)
+		authenticatedGroup.POST("/change-password", authHandler.ChangePassword)
+		authenticatedGroup.POST("/logout", authHandler.Logout)
+		authenticatedGroup.POST("/logout-all", authHandler.LogoutAll)
+		authenticatedGroup.GET("/tokens", authHandler.ListAPITokens)
+		authenticatedGroup.POST("/tokens", authHandler.CreateAPIToken)
+		authenticatedGroup.DELETE("/tokens/:id", authHandler.RevokeAPIToken)
+		authenticatedGroup.POST("/2fa/enroll", authHandler.BeginTwoFactorEnrollment)
+		authenticatedGroup.POST("/2fa/verify", authHandler.ConfirmTwoFactorEnrollment)
+		authenticatedGroup.POST("/2fa/disable", authHandler.DisableTwoFactor)
+		authenticatedGroup.POST("/2fa/recovery-codes", authHandler.RegenerateRecoveryCodes)
+	}
 
-		// 需要认证的路由
-		authenticated := authGroup.Group("")
-		authenticated.Use(auth.JWTAuthMiddleware())
-		{
-			authenticated.GET("/profile", authHandler.GetProfile)
-			authenticated.PUT("/profile", authHandler.UpdateProfile)
-			authenticated.POST("/change-password", authHandler.ChangePassword)
-			authenticated.POST("/logout", authHandler.Logout)
-		}
+	// 管理员专用路由
+	admin := protected.Group("/auth")
+	admin.Use(auth.AdminRequiredMiddleware())
+	{
+		admin.GET("/users", authHandler.GetUserList)
+		admin.PUT("/users/:id/status", authHandler.UpdateUserStatus)
+		admin.DELETE("/users/:id", authHandler.DeleteUser)
+		admin.POST("/robots", authHandler.CreateRobot)
+		admin.GET("/users/:id/tokens", authHandler.ListUserAPITokens)
+		admin.POST("/users/:id/tokens", authHandler.CreateUserAPIToken)
+		admin.DELETE("/users/:id/tokens/:tokenId", authHandler.RevokeUserAPIToken)
+		admin.DELETE("/users/:id/2fa", authHandler.ResetUserTwoFactor)
+		admin.GET("/2fa/policy", authHandler.GetTwoFactorPolicy)
+		admin.PUT("/2fa/policy", authHandler.UpdateTwoFactorPolicy)
+		admin.GET("/login-events", authHandler.ListLoginEvents)
+	}
+}
 
-		// 管理员专用路由
-		admin := authGroup.Group("")
-		admin.Use(auth.JWTAuthMiddleware(), auth.AdminRequiredMiddleware())
-		{
-			admin.GET("/users", authHandler.GetUserList)
-			admin.PUT("/users/:id/status", authHandler.UpdateUserStatus)
-			admin.DELETE("/users/:id", authHandler.DeleteUser)
-		}
+// RegisterOIDCRoutes 注册 OIDC 单点登录路由，均不需要认证
+func RegisterOIDCRoutes(public *gin.RouterGroup, oidcHandler *handlers.OIDCHandler) {
+	oidcGroup := public.Group("/auth/oidc")
+	{
+		oidcGroup.GET("/login", oidcHandler.Login)
+		oidcGroup.GET("/callback", oidcHandler.Callback)
 	}
 }
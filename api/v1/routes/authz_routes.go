@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/api/v1/handlers"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterAuthzRoutes 注册授权管理路由，仅管理员可用
+func RegisterAuthzRoutes(router *gin.RouterGroup, handler *handlers.AuthzHandler) {
+	authzGroup := router.Group("/authz")
+	authzGroup.Use(auth.AdminRequiredMiddleware())
+	{
+		authzGroup.GET("/roles", handler.ListRoles)
+		authzGroup.GET("/roles/:role", handler.GetRole)
+		authzGroup.DELETE("/roles/:role", handler.DeleteRole)
+
+		authzGroup.GET("/policies", handler.ListPolicies)
+		authzGroup.POST("/policies", handler.AddPolicies)
+		authzGroup.DELETE("/policies", handler.RemovePolicies)
+
+		authzGroup.GET("/bindings", handler.ListRoleBindings)
+		authzGroup.POST("/bindings", handler.AddRoleBinding)
+		authzGroup.DELETE("/bindings", handler.RemoveRoleBinding)
+
+		authzGroup.POST("/check", handler.Check)
+	}
+}
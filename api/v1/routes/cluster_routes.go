@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/api/v1/handlers"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterClusterRoutes 注册集群登记表相关路由
+func RegisterClusterRoutes(router *gin.RouterGroup, handler *handlers.ClusterHandler) {
+	clusterGroup := router.Group("/clusters")
+	{
+		clusterGroup.GET("", handler.ListClusters)
+		clusterGroup.POST("", handler.AddCluster)
+		clusterGroup.POST("/validate", handler.ValidateCluster)
+		clusterGroup.GET("/:"+k8s.ClusterParam, handler.GetCluster)
+		clusterGroup.PUT("/:"+k8s.ClusterParam, handler.UpdateCluster)
+		clusterGroup.DELETE("/:"+k8s.ClusterParam, handler.DeleteCluster)
+		clusterGroup.POST("/:"+k8s.ClusterParam+"/activate", handler.ActivateCluster)
+		clusterGroup.GET("/:"+k8s.ClusterParam+"/health", handler.GetClusterHealth)
+	}
+}
@@ -14,7 +14,9 @@ func RegisterConfigMapRoutes(router *gin.RouterGroup, handler *handlers.ConfigMa
 		configMapGroup.POST("", handler.CreateConfigMap)
 		configMapGroup.GET("/:name", handler.GetConfigMap)
 		configMapGroup.PUT("/:name", handler.UpdateConfigMap)
+		configMapGroup.PATCH("/:name", handler.PatchConfigMap)
 		configMapGroup.DELETE("/:name", handler.DeleteConfigMap)
+		configMapGroup.POST("/:name/diff", handler.DiffConfigMap)
 	}
 
 	// // Watch端点
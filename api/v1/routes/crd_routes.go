@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterCRDRoutes 注册CRD浏览相关路由；单个自定义资源的读写使用通用资源接口 /resources/...
+func RegisterCRDRoutes(router *gin.RouterGroup, handler *handlers.CRDHandler) {
+	crdGroup := router.Group("/crds")
+	{
+		crdGroup.GET("", handler.ListCRDs)
+		crdGroup.GET("/:name", handler.GetCRD)
+		crdGroup.GET("/:name/resources", handler.ListCustomResources)
+		crdGroup.GET("/:name/namespaces/:namespace/resources", handler.ListCustomResources)
+	}
+}
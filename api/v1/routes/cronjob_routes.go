@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterCronJobRoutes 注册CronJob相关路由
+func RegisterCronJobRoutes(router *gin.RouterGroup, handler *handlers.CronJobHandler) {
+	// 基础资源操作
+	cronJobGroup := router.Group("/namespaces/:namespace/cronjobs")
+	{
+		cronJobGroup.GET("", handler.ListCronJobs)
+		cronJobGroup.POST("", handler.CreateCronJob)
+		cronJobGroup.GET("/:name", handler.GetCronJob)
+		cronJobGroup.PUT("/:name", handler.UpdateCronJob)
+		cronJobGroup.PATCH("/:name", handler.PatchCronJob)
+		cronJobGroup.DELETE("/:name", handler.DeleteCronJob)
+		cronJobGroup.POST("/:name/diff", handler.DiffCronJob)
+		cronJobGroup.POST("/:name/trigger", handler.TriggerCronJob)
+		cronJobGroup.POST("/:name/suspend", handler.SuspendCronJob)
+		cronJobGroup.POST("/:name/resume", handler.ResumeCronJob)
+		cronJobGroup.GET("/:name/history", handler.GetCronJobHistory)
+	}
+
+	// Watch端点
+	watchGroup := router.Group("/watch/namespaces/:namespace/cronjobs")
+	{
+		watchGroup.GET("", handler.WatchCronJobs)
+	}
+}
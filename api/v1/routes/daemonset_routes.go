@@ -14,7 +14,11 @@ func RegisterDaemonSetRoutes(router *gin.RouterGroup, handler *handlers.DaemonSe
 		daemonSetGroup.POST("", handler.CreateDaemonSet)
 		daemonSetGroup.GET("/:name", handler.GetDaemonSet)
 		daemonSetGroup.PUT("/:name", handler.UpdateDaemonSet)
+		daemonSetGroup.PATCH("/:name", handler.PatchDaemonSet)
 		daemonSetGroup.DELETE("/:name", handler.DeleteDaemonSet)
+		daemonSetGroup.POST("/:name/diff", handler.DiffDaemonSet)
+		daemonSetGroup.POST("/:name/restart", handler.RestartDaemonSet)
+		daemonSetGroup.GET("/:name/rollout-status", handler.DaemonSetRolloutStatus)
 	}
 
 	// Watch端点
@@ -14,9 +14,17 @@ func RegisterDeploymentRoutes(router *gin.RouterGroup, handler *handlers.Deploym
 		deploymentGroup.POST("", handler.CreateDeployment)
 		deploymentGroup.GET("/:name", handler.GetDeployment)
 		deploymentGroup.PUT("/:name", handler.UpdateDeployment)
+		deploymentGroup.PATCH("/:name", handler.PatchDeployment)
 		deploymentGroup.DELETE("/:name", handler.DeleteDeployment)
+		deploymentGroup.POST("/:name/diff", handler.DiffDeployment)
 		deploymentGroup.PUT("/:name/scale", handler.ScaleDeployment)
 		deploymentGroup.GET("/:name/pods", handler.GetDeploymentPods)
+		deploymentGroup.GET("/:name/history", handler.GetDeploymentHistory)
+		deploymentGroup.POST("/:name/rollback", handler.RollbackDeployment)
+		deploymentGroup.POST("/:name/restart", handler.RestartDeployment)
+		deploymentGroup.POST("/:name/pause", handler.PauseDeployment)
+		deploymentGroup.POST("/:name/resume", handler.ResumeDeployment)
+		deploymentGroup.GET("/:name/rollout-status", handler.DeploymentRolloutStatus)
 	}
 
 	// Watch端点
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterHPARoutes 注册HorizontalPodAutoscaler相关路由
+func RegisterHPARoutes(router *gin.RouterGroup, handler *handlers.HPAHandler) {
+	// 基础资源操作
+	hpaGroup := router.Group("/namespaces/:namespace/horizontalpodautoscalers")
+	{
+		hpaGroup.GET("", handler.ListHPAs)
+		hpaGroup.POST("", handler.CreateHPA)
+		hpaGroup.GET("/:name", handler.GetHPA)
+		hpaGroup.PUT("/:name", handler.UpdateHPA)
+		hpaGroup.PATCH("/:name", handler.PatchHPA)
+		hpaGroup.DELETE("/:name", handler.DeleteHPA)
+		hpaGroup.POST("/:name/diff", handler.DiffHPA)
+	}
+
+	// Watch端点
+	watchGroup := router.Group("/watch/namespaces/:namespace/horizontalpodautoscalers")
+	{
+		watchGroup.GET("", handler.WatchHPAs)
+	}
+}
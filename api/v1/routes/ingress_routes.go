@@ -14,7 +14,9 @@ func RegisterIngressRoutes(router *gin.RouterGroup, handler *handlers.IngressHan
 		ingressGroup.POST("", handler.CreateIngress)
 		ingressGroup.GET("/:name", handler.GetIngress)
 		ingressGroup.PUT("/:name", handler.UpdateIngress)
+		ingressGroup.PATCH("/:name", handler.PatchIngress)
 		ingressGroup.DELETE("/:name", handler.DeleteIngress)
+		ingressGroup.POST("/:name/diff", handler.DiffIngress)
 	}
 
 	// Watch端点
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterJobRoutes 注册Job相关路由
+func RegisterJobRoutes(router *gin.RouterGroup, handler *handlers.JobHandler) {
+	// 基础资源操作
+	jobGroup := router.Group("/namespaces/:namespace/jobs")
+	{
+		jobGroup.GET("", handler.ListJobs)
+		jobGroup.POST("", handler.CreateJob)
+		jobGroup.DELETE("", handler.CleanupJobs)
+		jobGroup.GET("/:name", handler.GetJob)
+		jobGroup.PUT("/:name", handler.UpdateJob)
+		jobGroup.PATCH("/:name", handler.PatchJob)
+		jobGroup.DELETE("/:name", handler.DeleteJob)
+		jobGroup.POST("/:name/diff", handler.DiffJob)
+		jobGroup.GET("/:name/pods", handler.GetJobPods)
+		jobGroup.GET("/:name/logs", handler.GetJobLogs)
+		jobGroup.POST("/:name/suspend", handler.SuspendJob)
+		jobGroup.POST("/:name/resume", handler.ResumeJob)
+	}
+
+	// Watch端点
+	watchGroup := router.Group("/watch/namespaces/:namespace/jobs")
+	{
+		watchGroup.GET("", handler.WatchJobs)
+	}
+}
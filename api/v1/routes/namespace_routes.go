@@ -14,7 +14,9 @@ func RegisterNamespaceRoutes(router *gin.RouterGroup, handler *handlers.Namespac
 		namespaceGroup.POST("", handler.CreateNamespace)
 		namespaceGroup.GET("/:name", handler.GetNamespace)
 		namespaceGroup.PUT("/:name", handler.UpdateNamespace)
+		namespaceGroup.PATCH("/:name", handler.PatchNamespace)
 		namespaceGroup.DELETE("/:name", handler.DeleteNamespace)
+		namespaceGroup.POST("/:name/diff", handler.DiffNamespace)
 	}
 
 	// Watch端点
@@ -14,7 +14,9 @@ func RegisterNetworkPolicyRoutes(router *gin.RouterGroup, handler *handlers.Netw
 		networkPolicyGroup.POST("", handler.CreateNetworkPolicy)
 		networkPolicyGroup.GET("/:name", handler.GetNetworkPolicy)
 		networkPolicyGroup.PUT("/:name", handler.UpdateNetworkPolicy)
+		networkPolicyGroup.PATCH("/:name", handler.PatchNetworkPolicy)
 		networkPolicyGroup.DELETE("/:name", handler.DeleteNetworkPolicy)
+		networkPolicyGroup.POST("/:name/diff", handler.DiffNetworkPolicy)
 	}
 
 	// Watch端点
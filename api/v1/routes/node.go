@@ -14,7 +14,9 @@ func RegisterNodeRoutes(router *gin.RouterGroup, handler *handlers.NodeHandler)
 		nodeGroup.POST("", handler.CreateNode)
 		nodeGroup.GET("/:name", handler.GetNode)
 		nodeGroup.PUT("/:name", handler.UpdateNode)
+		nodeGroup.PATCH("/:name", handler.PatchNode)
 		nodeGroup.DELETE("/:name", handler.DeleteNode)
+		nodeGroup.POST("/:name/diff", handler.DiffNode)
 	}
 
 	// Watch端点
@@ -21,9 +21,11 @@ func RegisterPodRoutes(router *gin.RouterGroup, handler *handlers.PodHandler) {
 			// Pod specific operations
 			podNameGroup := podGroup.Group("/:name")
 			{
-				podNameGroup.GET("", handler.GetPod)       // Get Pod details
-				podNameGroup.PUT("", handler.UpdatePod)    // Update Pod (JSON or YAML) - Prefer YAML or PATCH
-				podNameGroup.DELETE("", handler.DeletePod) // Delete Pod
+				podNameGroup.GET("", handler.GetPod)        // Get Pod details
+				podNameGroup.PUT("", handler.UpdatePod)     // Update Pod (JSON or YAML) - Prefer YAML or PATCH
+				podNameGroup.PATCH("", handler.PatchPod)    // Patch Pod (type selected by Content-Type)
+				podNameGroup.DELETE("", handler.DeletePod)  // Delete Pod
+				podNameGroup.POST("/diff", handler.DiffPod) // Preview changes (server-side dry-run)
 
 				// --- New Endpoints ---
 				podNameGroup.GET("/logs", handler.GetPodLogs)    // Get Pod Logs
@@ -14,7 +14,9 @@ func RegisterPVRoutes(router *gin.RouterGroup, handler *handlers.PVHandler) {
 		pvGroup.POST("", handler.CreatePV)
 		pvGroup.GET("/:name", handler.GetPV)
 		pvGroup.PUT("/:name", handler.UpdatePV)
+		pvGroup.PATCH("/:name", handler.PatchPV)
 		pvGroup.DELETE("/:name", handler.DeletePV)
+		pvGroup.POST("/:name/diff", handler.DiffPV)
 	}
 
 	// Watch端点
@@ -14,7 +14,9 @@ func RegisterPVCRoutes(router *gin.RouterGroup, handler *handlers.PVCHandler) {
 		pvcGroup.POST("", handler.CreatePVC)
 		pvcGroup.GET("/:name", handler.GetPVC)
 		pvcGroup.PUT("/:name", handler.UpdatePVC)
+		pvcGroup.PATCH("/:name", handler.PatchPVC)
 		pvcGroup.DELETE("/:name", handler.DeletePVC)
+		pvcGroup.POST("/:name/diff", handler.DiffPVC)
 	}
 
 	// Watch端点
@@ -0,0 +1,47 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterResourceRoutes 注册通用资源 (dynamic client) 相关路由，核心组使用 "core"，例如:
+//
+//	/resources/core/v1/pods/namespaces/default
+//	/resources/cert-manager.io/v1/certificates/namespaces/default/my-cert
+//	/resources/apiextensions.k8s.io/v1/customresourcedefinitions
+func RegisterResourceRoutes(router *gin.RouterGroup, handler *handlers.ResourceHandler) {
+	// 发现接口：列出所有 API 资源及支持的操作
+	router.GET("/resources", handler.DiscoverResources)
+
+	// 集群级资源，或跨所有命名空间列出命名空间级资源
+	resourceGroup := router.Group("/resources/:group/:version/:resource")
+	{
+		resourceGroup.GET("", handler.ListResources)
+		resourceGroup.POST("", handler.CreateResource)
+		resourceGroup.GET("/:name", handler.GetResource)
+		resourceGroup.PUT("/:name", handler.UpdateResource)
+		resourceGroup.PATCH("/:name", handler.PatchResource)
+		resourceGroup.DELETE("/:name", handler.DeleteResource)
+		resourceGroup.POST("/:name/diff", handler.DiffResource)
+	}
+
+	// 命名空间级资源
+	namespacedGroup := router.Group("/resources/:group/:version/:resource/namespaces/:namespace")
+	{
+		namespacedGroup.GET("", handler.ListResources)
+		namespacedGroup.POST("", handler.CreateResource)
+		namespacedGroup.GET("/:name", handler.GetResource)
+		namespacedGroup.PUT("/:name", handler.UpdateResource)
+		namespacedGroup.PATCH("/:name", handler.PatchResource)
+		namespacedGroup.DELETE("/:name", handler.DeleteResource)
+		namespacedGroup.POST("/:name/diff", handler.DiffResource)
+	}
+
+	// Watch端点
+	watchGroup := router.Group("/watch/resources/:group/:version/:resource")
+	{
+		watchGroup.GET("", handler.WatchResources)
+		watchGroup.GET("/namespaces/:namespace", handler.WatchResources)
+	}
+}
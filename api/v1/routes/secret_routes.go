@@ -14,7 +14,9 @@ func RegisterSecretRoutes(router *gin.RouterGroup, handler *handlers.SecretHandl
 		secretGroup.POST("", handler.CreateSecret)
 		secretGroup.GET("/:name", handler.GetSecret)
 		secretGroup.PUT("/:name", handler.UpdateSecret)
+		secretGroup.PATCH("/:name", handler.PatchSecret)
 		secretGroup.DELETE("/:name", handler.DeleteSecret)
+		secretGroup.POST("/:name/diff", handler.DiffSecret)
 	}
 
 	// // Watch端点
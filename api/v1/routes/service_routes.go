@@ -14,7 +14,9 @@ func RegisterServiceRoutes(router *gin.RouterGroup, handler *handlers.ServiceHan
 		serviceGroup.POST("", handler.CreateService)
 		serviceGroup.GET("/:name", handler.GetService)
 		serviceGroup.PUT("/:name", handler.UpdateService)
+		serviceGroup.PATCH("/:name", handler.PatchService)
 		serviceGroup.DELETE("/:name", handler.DeleteService)
+		serviceGroup.POST("/:name/diff", handler.DiffService)
 	}
 
 	// Watch端点
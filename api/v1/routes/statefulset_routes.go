@@ -14,7 +14,12 @@ func RegisterStatefulSetRoutes(router *gin.RouterGroup, handler *handlers.Statef
 		statefulSetGroup.POST("", handler.CreateStatefulSet)
 		statefulSetGroup.GET("/:name", handler.GetStatefulSet)
 		statefulSetGroup.PUT("/:name", handler.UpdateStatefulSet)
+		statefulSetGroup.PATCH("/:name", handler.PatchStatefulSet)
 		statefulSetGroup.DELETE("/:name", handler.DeleteStatefulSet)
+		statefulSetGroup.POST("/:name/diff", handler.DiffStatefulSet)
+		statefulSetGroup.PUT("/:name/scale", handler.ScaleStatefulSet)
+		statefulSetGroup.POST("/:name/restart", handler.RestartStatefulSet)
+		statefulSetGroup.GET("/:name/rollout-status", handler.StatefulSetRolloutStatus)
 	}
 
 	// Watch端点
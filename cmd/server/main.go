@@ -1,18 +1,17 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	// time is still needed for healthz in main
-	"github.com/casbin/casbin/v2"
 	"github.com/ciliverse/cilikube/configs"
 	"github.com/ciliverse/cilikube/internal/initialization" // Import the new package
-	"github.com/ciliverse/cilikube/pkg/auth"
-	"github.com/ciliverse/cilikube/pkg/database"
-	"github.com/ciliverse/cilikube/pkg/k8s" // Your custom k8s client package
+	"github.com/ciliverse/cilikube/pkg/k8s"                 // Your custom k8s client package
 )
 
 func main() {
@@ -36,38 +35,38 @@ func main() {
 	// log.Println("数据库自动迁移成功。")
 
 	// --- Kubernetes Client Initialization ---
-	// initializeK8sClient remains in main as it's the connection point for k8s
-	k8sClient, k8sAvailable := initializeK8sClient(cfg)
+	// initializeClientManager remains in main as it's the connection point for k8s
+	clientManager := initializeClientManager(cfg)
 
 	// --- Application Initialization (Services & Handlers) ---
 	// Call functions from the new initialization package
 	// repositories := initialization.InitializeRepositories(DB)
-	services := initialization.InitializeServices(k8sClient, k8sAvailable, cfg)
+	services := initialization.InitializeServices(cfg, clientManager)
 	appHandlers := initialization.InitializeHandlers(services)
 
-	// <--- ADDED: Casbin Initialization ---
-	var e *casbin.Enforcer // 声明 enforcer 变量
-	if cfg.Database.Enabled {
-		// 确保数据库已成功初始化
-		if database.DB != nil {
-			e, err = auth.InitCasbin(database.DB) // 调用 InitCasbin
-			if err != nil {
-				log.Fatalf("初始化 Casbin 失败: %v", err)
-			}
-			log.Println("Casbin 初始化成功。")
-			// (可选) 在这里调用初始化默认用户和 Casbin 角色绑定
-			// initialization.InitializeDefaultUser(e, database.DB)
-		} else {
-			log.Println("警告: 数据库已启用但未成功初始化，跳过 Casbin 初始化。")
-		}
-	} else {
-		log.Println("警告: 数据库未启用，跳过 Casbin 初始化。")
+	// --- Cluster Health Checks ---
+	// Probes every registered cluster (including registry clusters loaded above)
+	// once now, then keeps probing in the background.
+	clientManager.StartHealthChecks(context.Background(),
+		time.Duration(cfg.Kubernetes.HealthCheckInterval)*time.Second,
+		time.Duration(cfg.Kubernetes.HealthCheckTimeout)*time.Second)
+	if !clientManager.HasHealthyCluster() {
+		log.Println("警告: 当前没有可连接的集群，Kubernetes 相关接口将返回 503，直到集群恢复或通过 /api/v1/clusters 添加集群。")
+	}
+
+	// --- LDAP Group Sync ---
+	if services.LDAPAuthenticator != nil && cfg.Auth.LDAP.SyncInterval > 0 {
+		services.LDAPAuthenticator.StartGroupSync(context.Background(), cfg.Auth.LDAP.SyncInterval)
+	}
+
+	// --- Audit Retention ---
+	if services.AuditService != nil {
+		services.AuditService.StartRetention(context.Background(), time.Hour)
 	}
-	// <--- ADDED: Casbin Initialization End ---
 
 	// --- Gin Router Setup ---
 	// Call function from the new initialization package
-	router := initialization.SetupRouter(cfg, appHandlers, k8sAvailable, e)
+	router := initialization.SetupRouter(cfg, appHandlers, clientManager, services.Enforcer)
 
 	// --- Start Server ---
 	// startServer remains in main as it's the server lifecycle management
@@ -116,40 +115,73 @@ func loadConfig() (*configs.Config, error) {
 	return cfg, nil
 }
 
-// initializeK8sClient initializes the Kubernetes client and checks connectivity.
-// Kept in main as it's a core dependency check for the application startup.
-func initializeK8sClient(cfg *configs.Config) (*k8s.Client, bool) {
+// initializeClientManager registers every configured cluster into a k8s.ClientManager.
+// Clusters come from cfg.Clusters; when that list is empty the legacy
+// cfg.Kubernetes.Kubeconfig is registered under cfg.Server.ActiveCluster (or "default").
+// Reachability is tracked afterwards by the background health checks.
+func initializeClientManager(cfg *configs.Config) *k8s.ClientManager {
+	clientManager := k8s.NewClientManager()
+	clientManager.SetCacheEnabled(!cfg.Kubernetes.DisableCache)
+
+	clusters := cfg.Clusters
+	if len(clusters) == 0 {
+		name := cfg.Server.ActiveCluster
+		if name == "" {
+			name = "default"
+		}
+		clusters = []configs.ClusterInfo{{Name: name, ConfigPath: cfg.Kubernetes.Kubeconfig, IsActive: true}}
+	}
+
+	activeCluster := cfg.Server.ActiveCluster
+	for _, cluster := range clusters {
+		if cluster.Name == "" {
+			log.Println("警告: 跳过未命名的集群配置。")
+			continue
+		}
+		k8sClient := initializeK8sClient(cluster.Name, cluster.ConfigPath)
+		if k8sClient == nil {
+			continue
+		}
+		clientManager.AddClient(cluster.Name, k8sClient)
+		if cluster.IsActive && activeCluster == "" {
+			activeCluster = cluster.Name
+		}
+	}
+
+	if activeCluster != "" {
+		if err := clientManager.SetActiveClient(activeCluster); err != nil {
+			log.Printf("警告: 无法激活集群 '%s': %v", activeCluster, err)
+		}
+	}
+	log.Printf("已注册集群: %v，当前激活集群: %s", clientManager.ListClusterNames(), clientManager.GetActiveClusterName())
+	return clientManager
+}
+
+// initializeK8sClient creates the Kubernetes client for one cluster.
+// Connectivity is not checked here: an unreachable cluster is still registered
+// and becomes available as soon as a health probe succeeds.
+func initializeK8sClient(clusterName, kubeconfigPath string) *k8s.Client {
 	// Determine kubeconfig path
-	kubeconfigPath := cfg.Kubernetes.Kubeconfig
 	if kubeconfigPath == "in-cluster" {
 		kubeconfigPath = "" // NewClient treats "" as in-cluster attempt
-		log.Println("配置指定使用 in-cluster Kubernetes 配置。")
+		log.Printf("集群 '%s' 使用 in-cluster Kubernetes 配置。", clusterName)
 	} else if kubeconfigPath == "" {
-		log.Println("配置文件中未指定 kubeconfig 路径，将尝试 in-cluster 配置。")
+		log.Printf("集群 '%s' 未指定 kubeconfig 路径，将尝试 in-cluster 配置。", clusterName)
 	} else {
-		log.Printf("从配置文件获取 kubeconfig 路径: %s\n", kubeconfigPath)
+		log.Printf("集群 '%s' 的 kubeconfig 路径: %s\n", clusterName, kubeconfigPath)
 	}
 
 	// Initialize your custom Kubernetes client
 	k8sClient, err := k8s.NewClient(kubeconfigPath)
 	if err != nil {
-		log.Printf("警告: 创建 Kubernetes 客户端失败: %v。Kubernetes 相关功能将不可用。", err)
-		return nil, false
-	}
-
-	log.Println("Kubernetes 客户端创建成功。检查集群连接...")
-	// Perform a connection check
-	if err := k8sClient.CheckConnection(); err != nil {
-		log.Printf("警告: 无法连接 Kubernetes 集群: %v。Kubernetes 相关功能将不可用。", err)
-		// Return client even if check fails, some limited info might be possible,
-		// but k8sAvailable flag controls feature availability.
-		return k8sClient, false
+		log.Printf("警告: 创建集群 '%s' 的 Kubernetes 客户端失败: %v。该集群将不可用。", clusterName, err)
+		return nil
 	}
 
-	log.Println("成功连接到 Kubernetes 集群。")
+	log.Printf("集群 '%s' 的 Kubernetes 客户端创建成功。", clusterName)
 	// Log API Server URL for confirmation (optional)
 	if k8sClient.Config != nil {
-		log.Printf("连接到 API Server: %s", k8sClient.Config.Host)
+		log.Printf("API Server: %s", k8sClient.Config.Host)
 	}
-	return k8sClient, true
+	return k8sClient
 }
@@ -15,6 +15,8 @@ type Config struct {
 	Installer  InstallerConfig  `yaml:"installer" json:"installer"`
 	Database   DatabaseConfig   `yaml:"database" json:"database"`
 	JWT        JWTConfig        `yaml:"jwt" json:"jwt"`
+	Auth       AuthConfig       `yaml:"auth" json:"auth"`
+	Audit      AuditConfig      `yaml:"audit" json:"audit"`
 	Clusters   []ClusterInfo    `yaml:"clusters" json:"clusters"`
 }
 
@@ -27,7 +29,14 @@ type ServerConfig struct {
 }
 
 type KubernetesConfig struct {
-	Kubeconfig string `yaml:"kubeconfig" json:"kubeconfig"`
+	Kubeconfig    string `yaml:"kubeconfig" json:"kubeconfig"`
+	RegistryFile  string `yaml:"registryFile" json:"registryFile"`   // 通过 API 添加的集群登记文件（未启用数据库时使用）
+	KubeconfigDir string `yaml:"kubeconfigDir" json:"kubeconfigDir"` // 通过 API 上传的 kubeconfig 存放目录
+
+	HealthCheckInterval int `yaml:"healthCheckInterval" json:"healthCheckInterval"` // 集群健康探测间隔（秒）
+	HealthCheckTimeout  int `yaml:"healthCheckTimeout" json:"healthCheckTimeout"`   // 单次探测超时（秒）
+
+	DisableCache bool `yaml:"disableCache" json:"disableCache"` // 关闭 informer 缓存，所有读请求直接访问 API Server
 }
 
 type InstallerConfig struct {
@@ -47,9 +56,115 @@ type DatabaseConfig struct {
 }
 
 type JWTConfig struct {
-	SecretKey      string        `yaml:"secret_key" json:"secret_key"`
+	SecretKey string `yaml:"secret_key" json:"secret_key"`
+	// ExpireDuration access token 有效期，应保持较短，过期后使用刷新令牌换取新 token
 	ExpireDuration time.Duration `yaml:"expire_duration" json:"expire_duration"`
-	Issuer         string        `yaml:"issuer" json:"issuer"`
+	// RefreshExpireDuration 刷新令牌有效期，每次刷新都会轮换为新的刷新令牌
+	RefreshExpireDuration time.Duration `yaml:"refresh_expire_duration" json:"refresh_expire_duration"`
+	Issuer                string        `yaml:"issuer" json:"issuer"`
+}
+
+// AuditConfig 审计日志：记录经由 CiliKube 对集群执行的每个写操作，以及 Pod exec 和日志访问。
+// 数据库启用时写入 audit_events 表，否则以 JSON Lines 写入 File
+type AuditConfig struct {
+	Disabled bool `yaml:"disabled" json:"disabled"`
+	// Retention 数据库中审计记录的保留时长，默认 90 天，负数表示永久保留
+	Retention time.Duration `yaml:"retention" json:"retention"`
+	// File 数据库未启用时的输出文件，为空时输出到标准输出
+	File string `yaml:"file" json:"file"`
+}
+
+// AuthConfig 认证与授权配置。默认开启：/api/v1 下除登录、注册外的接口都需要有效的 JWT，
+// 并由 Casbin 按当前用户授权；开启时必须启用数据库
+type AuthConfig struct {
+	// Disabled 开发模式：跳过 JWT 与 Casbin，所有请求以管理员身份执行，切勿用于生产环境
+	Disabled bool `yaml:"disabled" json:"disabled"`
+	// Impersonation 以 CiliKube 用户身份模拟请求 Kubernetes API，由集群 RBAC 决定最终权限
+	Impersonation ImpersonationConfig `yaml:"impersonation" json:"impersonation"`
+	// OIDC 通过外部身份提供方 (Keycloak、Dex 等) 单点登录，与本地密码登录并存
+	OIDC OIDCConfig `yaml:"oidc" json:"oidc"`
+	// LDAP 使用 LDAP / Active Directory 账号密码登录，本地账号优先
+	LDAP LDAPConfig `yaml:"ldap" json:"ldap"`
+	// PasswordPolicy 本地账号设置密码 (注册、修改密码) 时的要求
+	PasswordPolicy PasswordPolicyConfig `yaml:"passwordPolicy" json:"passwordPolicy"`
+	// Lockout 登录失败次数过多时按用户名和来源 IP 锁定
+	Lockout LockoutConfig `yaml:"lockout" json:"lockout"`
+}
+
+// PasswordPolicyConfig 密码策略，字段为 0 时使用默认值
+type PasswordPolicyConfig struct {
+	MinLength int `yaml:"minLength" json:"minLength"` // 默认 8
+	// MinClasses 至少包含几类字符 (大写字母、小写字母、数字、符号)，默认 2
+	MinClasses int `yaml:"minClasses" json:"minClasses"`
+	// History 新密码不能与最近几次使用过的密码相同 (含当前密码)，默认 3
+	History int `yaml:"history" json:"history"`
+}
+
+// LockoutConfig 登录锁定，字段为 0 时使用默认值。Window 内连续失败达到阈值后锁定，
+// 之后每次失败锁定时间翻倍，直到 MaxDuration；登录成功后清除该用户名的失败记录
+type LockoutConfig struct {
+	MaxAttempts   int           `yaml:"maxAttempts" json:"maxAttempts"`     // 每个用户名，默认 5
+	IPMaxAttempts int           `yaml:"ipMaxAttempts" json:"ipMaxAttempts"` // 每个来源 IP，默认 20
+	Window        time.Duration `yaml:"window" json:"window"`               // 失败计数的时间窗口，默认 15m
+	Duration      time.Duration `yaml:"duration" json:"duration"`           // 首次锁定时长，默认 1m
+	MaxDuration   time.Duration `yaml:"maxDuration" json:"maxDuration"`     // 默认 1h
+}
+
+// ImpersonationConfig 用户模拟配置。开启后每个请求以 UsernamePrefix+用户名 及映射出的组访问集群，
+// 服务凭据 (kubeconfig 中的用户) 需要具备 impersonate users/groups 的权限
+type ImpersonationConfig struct {
+	Enabled        bool                `yaml:"enabled" json:"enabled"`
+	UsernamePrefix string              `yaml:"usernamePrefix" json:"usernamePrefix"` // 例如 "cilikube:"，避免与集群中已有用户重名
+	Groups         []string            `yaml:"groups" json:"groups"`                 // 所有用户都附加的组
+	RoleGroups     map[string][]string `yaml:"roleGroups" json:"roleGroups"`         // CiliKube 角色 -> 附加的组
+}
+
+// OIDCConfig OIDC 授权码登录配置。用户首次登录时自动创建，之后每次登录按 groups 声明同步角色
+type OIDCConfig struct {
+	Enabled      bool     `yaml:"enabled" json:"enabled"`
+	IssuerURL    string   `yaml:"issuerURL" json:"issuerURL"`
+	ClientID     string   `yaml:"clientID" json:"clientID"`
+	ClientSecret string   `yaml:"clientSecret" json:"-"`
+	RedirectURL  string   `yaml:"redirectURL" json:"redirectURL"` // 身份提供方回调地址，即 <外部地址>/api/v1/auth/oidc/callback
+	Scopes       []string `yaml:"scopes" json:"scopes"`           // 默认 openid profile email groups
+
+	UsernameClaim string `yaml:"usernameClaim" json:"usernameClaim"` // 默认 preferred_username
+	GroupsClaim   string `yaml:"groupsClaim" json:"groupsClaim"`     // 默认 groups
+
+	// GroupRoles 身份提供方中的组 -> 角色。映射到 admin/user 时决定用户的内置角色，
+	// 映射到其他 Casbin 角色时在所有集群上绑定该角色
+	GroupRoles  map[string]string `yaml:"groupRoles" json:"groupRoles"`
+	DefaultRole string            `yaml:"defaultRole" json:"defaultRole"` // 没有组映射到内置角色时使用，默认 user
+
+	// PostLoginRedirect 登录成功后跳转的前端地址，token 放在 URL fragment 中；为空时回调直接返回 JSON
+	PostLoginRedirect string `yaml:"postLoginRedirect" json:"postLoginRedirect"`
+}
+
+// LDAPConfig LDAP 认证配置：先以服务账号搜索用户，再以用户 DN 和密码绑定校验，
+// 然后搜索用户所属的组并映射为角色。用户首次登录时自动创建
+type LDAPConfig struct {
+	Enabled            bool   `yaml:"enabled" json:"enabled"`
+	URL                string `yaml:"url" json:"url"` // ldap://host:389 或 ldaps://host:636
+	StartTLS           bool   `yaml:"startTLS" json:"startTLS"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify" json:"insecureSkipVerify"`
+	BindDN             string `yaml:"bindDN" json:"bindDN"` // 用于搜索的服务账号，为空时匿名搜索
+	BindPassword       string `yaml:"bindPassword" json:"-"`
+
+	BaseDN         string `yaml:"baseDN" json:"baseDN"`                 // 用户搜索起点
+	UserFilter     string `yaml:"userFilter" json:"userFilter"`         // %s 替换为转义后的用户名，默认 (uid=%s)，AD 可用 (sAMAccountName=%s)
+	EmailAttribute string `yaml:"emailAttribute" json:"emailAttribute"` // 默认 mail
+
+	GroupBaseDN        string `yaml:"groupBaseDN" json:"groupBaseDN"`               // 组搜索起点，默认同 BaseDN
+	GroupFilter        string `yaml:"groupFilter" json:"groupFilter"`               // %s 替换为转义后的用户 DN，默认 (member=%s)
+	GroupNameAttribute string `yaml:"groupNameAttribute" json:"groupNameAttribute"` // 默认 cn
+
+	// GroupRoles 组名 -> 角色，规则与 OIDC 的 groupRoles 相同
+	GroupRoles  map[string]string `yaml:"groupRoles" json:"groupRoles"`
+	DefaultRole string            `yaml:"defaultRole" json:"defaultRole"` // 默认 user
+
+	// SyncInterval 定期重新读取所有 LDAP 用户的组并同步角色绑定，0 表示只在登录时同步
+	SyncInterval time.Duration `yaml:"syncInterval" json:"syncInterval"`
+	Timeout      time.Duration `yaml:"timeout" json:"timeout"` // 连接超时，默认 10s
 }
 
 type ClusterInfo struct {
@@ -84,6 +199,13 @@ func Load(path string) (*Config, error) {
 
 	GlobalConfig = cfg
 	setDefaults()
+	// 集群登记文件与 kubeconfig 目录默认与配置文件放在同一目录
+	if cfg.Kubernetes.RegistryFile == "" {
+		cfg.Kubernetes.RegistryFile = filepath.Join(filepath.Dir(path), "cluster.json")
+	}
+	if cfg.Kubernetes.KubeconfigDir == "" {
+		cfg.Kubernetes.KubeconfigDir = filepath.Join(filepath.Dir(path), "kubeconfigs")
+	}
 
 	return cfg, nil
 }
@@ -138,11 +260,17 @@ func setDefaults() {
 		}
 	}
 	if GlobalConfig.JWT.ExpireDuration == 0 {
-		GlobalConfig.JWT.ExpireDuration = 24 * time.Hour
+		GlobalConfig.JWT.ExpireDuration = 15 * time.Minute
+	}
+	if GlobalConfig.JWT.RefreshExpireDuration == 0 {
+		GlobalConfig.JWT.RefreshExpireDuration = 7 * 24 * time.Hour
 	}
 	if GlobalConfig.JWT.Issuer == "" {
 		GlobalConfig.JWT.Issuer = "cilikube"
 	}
+	if GlobalConfig.Audit.Retention == 0 {
+		GlobalConfig.Audit.Retention = 90 * 24 * time.Hour
+	}
 	if GlobalConfig.Installer.MinikubeDriver == "" {
 		GlobalConfig.Installer.MinikubeDriver = "docker"
 	}
@@ -156,6 +284,12 @@ func setDefaults() {
 			GlobalConfig.Kubernetes.Kubeconfig = filepath.Join(os.Getenv("HOME"), ".kube", "config")
 		}
 	}
+	if GlobalConfig.Kubernetes.HealthCheckInterval == 0 {
+		GlobalConfig.Kubernetes.HealthCheckInterval = 30 // 默认 30 秒
+	}
+	if GlobalConfig.Kubernetes.HealthCheckTimeout == 0 {
+		GlobalConfig.Kubernetes.HealthCheckTimeout = 5 // 默认 5 秒
+	}
 	// 数据库默认值
 	if GlobalConfig.Database.Enabled {
 		if GlobalConfig.Database.Host == "" {
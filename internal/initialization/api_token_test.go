@@ -0,0 +1,120 @@
+package initialization
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createAPIToken(t *testing.T, router *gin.Engine, path, token string, req models.CreateAPITokenRequest) models.CreateAPITokenResponse {
+	t.Helper()
+	w := doRequest(router, http.MethodPost, path, token, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Data models.CreateAPITokenResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotEmpty(t, resp.Data.Token)
+	return resp.Data
+}
+
+func TestAPITokens_Scopes(t *testing.T) {
+	router := newTestRouter(t, false)
+	w := doRequest(router, http.MethodPost, "/api/v1/auth/register", "", models.RegisterRequest{Username: "alice", Email: "alice@example.com", Password: "alice-password"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	alice := login(t, router, "alice", "alice-password")
+	admin := login(t, router, "admin", "admin123")
+
+	created := createAPIToken(t, router, "/api/v1/auth/tokens", alice, models.CreateAPITokenRequest{Name: "ci", Scopes: []string{"namespaces:list"}})
+	pat := created.Token
+	assert.Contains(t, pat, created.Prefix)
+
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, "/api/v1/namespace", pat, nil).Code)
+	// 超出 scope (以及用户本身的权限) 的请求被拒绝
+	assert.Equal(t, http.StatusForbidden, doRequest(router, http.MethodGet, "/api/v1/namespace/default", pat, nil).Code)
+	// API token 不能用来管理 API token
+	assert.Equal(t, http.StatusForbidden, doRequest(router, http.MethodGet, "/api/v1/auth/tokens", pat, nil).Code)
+
+	w = doRequest(router, http.MethodGet, "/api/v1/auth/tokens", alice, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list struct {
+		Data []models.APIToken `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, "ci", list.Data[0].Name)
+	assert.Equal(t, []string{"namespaces:list"}, list.Data[0].Scopes)
+	assert.NotNil(t, list.Data[0].LastUsedAt)
+	assert.NotContains(t, w.Body.String(), pat)
+
+	// 管理员的令牌同样受 scope 限制
+	adminPAT := createAPIToken(t, router, "/api/v1/auth/tokens", admin, models.CreateAPITokenRequest{Name: "read-only", Scopes: []string{"*:get", "*:list"}}).Token
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, "/api/v1/namespace", adminPAT, nil).Code)
+	assert.Equal(t, http.StatusForbidden, doRequest(router, http.MethodPost, "/api/v1/namespace", adminPAT, models.CreateNamespaceRequest{Name: "team-a"}).Code)
+
+	invalid := []models.CreateAPITokenRequest{
+		{Name: "ci", Scopes: []string{"namespaces:list"}},
+		{Name: "bad-scope", Scopes: []string{"namespaces"}},
+		{Name: "bad-verb", Scopes: []string{"namespaces:read"}},
+		{Name: "expired", Scopes: []string{"*:*"}, ExpiresAt: ptrTime(time.Now().Add(-time.Hour))},
+	}
+	for _, req := range invalid {
+		assert.Equal(t, http.StatusBadRequest, doRequest(router, http.MethodPost, "/api/v1/auth/tokens", alice, req).Code, req.Name)
+	}
+
+	w = doRequest(router, http.MethodDelete, fmt.Sprintf("/api/v1/auth/tokens/%d", created.ID), alice, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, doRequest(router, http.MethodGet, "/api/v1/namespace", pat, nil).Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(router, http.MethodDelete, fmt.Sprintf("/api/v1/auth/tokens/%d", created.ID), alice, nil).Code)
+}
+
+func TestAPITokens_RobotUsers(t *testing.T) {
+	router := newTestRouter(t, false)
+	admin := login(t, router, "admin", "admin123")
+
+	w := doRequest(router, http.MethodPost, "/api/v1/authz/policies", admin, models.PolicyRequest{Role: "deployer", Resource: "namespaces", Verbs: []string{"create"}})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = doRequest(router, http.MethodPost, "/api/v1/auth/robots", admin, models.CreateRobotRequest{Username: "ci-bot", Roles: []string{"deployer"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var robot struct {
+		Data models.UserResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &robot))
+	assert.Equal(t, models.UserSourceRobot, robot.Data.Source)
+	assert.Equal(t, models.RoleUser, robot.Data.Role)
+
+	tokensPath := fmt.Sprintf("/api/v1/auth/users/%d/tokens", robot.Data.ID)
+	pat := createAPIToken(t, router, tokensPath, admin, models.CreateAPITokenRequest{Name: "pipeline", Scopes: []string{"*:*"}}).Token
+
+	// 权限来自绑定的 deployer 角色
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodPost, "/api/v1/namespace", pat, models.CreateNamespaceRequest{Name: "from-ci"}).Code)
+	assert.Equal(t, http.StatusForbidden, doRequest(router, http.MethodDelete, "/api/v1/namespace/from-ci", pat, nil).Code)
+
+	// 只能为机器人创建令牌，机器人不能使用密码登录
+	var adminUser models.UserResponse
+	w = doRequest(router, http.MethodGet, "/api/v1/auth/profile", admin, nil)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &struct {
+		Data *models.UserResponse `json:"data"`
+	}{&adminUser}))
+	w = doRequest(router, http.MethodPost, fmt.Sprintf("/api/v1/auth/users/%d/tokens", adminUser.ID), admin, models.CreateAPITokenRequest{Name: "x", Scopes: []string{"*:*"}})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = doRequest(router, http.MethodPost, "/api/v1/auth/login", "", models.LoginRequest{Username: "ci-bot", Password: "whatever"})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// 禁用机器人后令牌失效
+	w = doRequest(router, http.MethodPut, fmt.Sprintf("/api/v1/auth/users/%d/status", robot.Data.ID), admin, map[string]bool{"is_active": false})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, doRequest(router, http.MethodGet, "/api/v1/namespace", pat, nil).Code)
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
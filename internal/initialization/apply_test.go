@@ -0,0 +1,376 @@
+package initialization
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var (
+	configMapsGVR          = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	clusterRoleBindingsGVR = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings"}
+	cronTabsGVR            = schema.GroupVersionResource{Group: "stable.example.com", Version: "v1", Resource: "crontabs"}
+	crdsGVR                = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+)
+
+// testDiscovery fake 集群通过 discovery 暴露的资源
+var testDiscovery = []*metav1.APIResourceList{
+	{GroupVersion: "v1", APIResources: []metav1.APIResource{
+		{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: metav1.Verbs{"get", "list", "watch", "create", "update", "patch", "delete"}},
+		{Name: "namespaces", Kind: "Namespace", Verbs: metav1.Verbs{"get", "list", "watch", "create", "update", "patch", "delete"}},
+	}},
+	{GroupVersion: "rbac.authorization.k8s.io/v1", APIResources: []metav1.APIResource{
+		{Name: "clusterrolebindings", Kind: "ClusterRoleBinding", Verbs: metav1.Verbs{"get", "list", "watch", "create", "update", "patch", "delete"}},
+	}},
+	{GroupVersion: "stable.example.com/v1", APIResources: []metav1.APIResource{
+		{Name: "crontabs", Kind: "CronTab", Namespaced: true, ShortNames: []string{"ct"}, Verbs: metav1.Verbs{"get", "list", "watch", "create", "update", "patch", "delete"}},
+	}},
+}
+
+// withDynamicCluster 把测试集群 "test" 替换为带 discovery 和 dynamic client 的 fake 集群。
+// fake dynamic client 的 apply 只能修改已存在的对象，这里补上不存在时创建的行为；
+// 带 dryRun 的写操作只返回结果，不修改 tracker
+func withDynamicCluster(dynamicClient **dynamicfake.FakeDynamicClient, objects ...runtime.Object) func(*configs.Config, *k8s.ClientManager) {
+	return func(_ *configs.Config, cm *k8s.ClientManager) {
+		clientset := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+		clientset.Resources = testDiscovery
+		// CRD 及自定义资源不在 scheme 中，需要显式告诉 fake dynamic client 它们的列表类型
+		listKinds := map[schema.GroupVersionResource]string{cronTabsGVR: "CronTabList", crdsGVR: "CustomResourceDefinitionList"}
+		dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme.Scheme, listKinds, objects...)
+		dyn.PrependReactor("*", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			switch action := action.(type) {
+			case k8stesting.CreateActionImpl:
+				if len(action.CreateOptions.DryRun) == 0 {
+					return false, nil, nil
+				}
+				obj := action.GetObject().(*unstructured.Unstructured)
+				if _, err := dyn.Tracker().Get(action.GetResource(), action.GetNamespace(), obj.GetName()); err == nil {
+					return true, nil, apierrors.NewAlreadyExists(action.GetResource().GroupResource(), obj.GetName())
+				}
+				return true, obj, nil
+			case k8stesting.UpdateActionImpl:
+				if len(action.UpdateOptions.DryRun) == 0 {
+					return false, nil, nil
+				}
+				obj := action.GetObject().(*unstructured.Unstructured)
+				if _, err := dyn.Tracker().Get(action.GetResource(), action.GetNamespace(), obj.GetName()); err != nil {
+					return true, nil, err
+				}
+				return true, obj, nil
+			case k8stesting.PatchActionImpl:
+				return dryRunOrCreateOnApply(dyn, action)
+			}
+			return false, nil, nil
+		})
+		*dynamicClient = dyn
+		cm.AddClient("test", &k8s.Client{Clientset: clientset, Dynamic: optionsDynamicClient{dyn}})
+	}
+}
+
+// dryRunOrCreateOnApply apply 的对象不存在时创建；apply 和 merge patch 带 dryRun 时在副本上合并后返回
+func dryRunOrCreateOnApply(dyn *dynamicfake.FakeDynamicClient, patch k8stesting.PatchActionImpl) (bool, runtime.Object, error) {
+	dryRun := len(patch.PatchOptions.DryRun) > 0
+	patchType := patch.GetPatchType()
+	if patchType != types.ApplyPatchType && !(dryRun && patchType == types.MergePatchType) {
+		return false, nil, nil
+	}
+	content := map[string]interface{}{}
+	if err := json.Unmarshal(patch.GetPatch(), &content); err != nil {
+		return true, nil, err
+	}
+
+	existing, err := dyn.Tracker().Get(patch.GetResource(), patch.GetNamespace(), patch.GetName())
+	switch {
+	case apierrors.IsNotFound(err) && patchType == types.ApplyPatchType:
+		obj := &unstructured.Unstructured{Object: content}
+		if dryRun {
+			return true, obj, nil
+		}
+		return true, obj, dyn.Tracker().Create(patch.GetResource(), obj, patch.GetNamespace())
+	case err != nil:
+		return true, nil, err
+	case !dryRun:
+		return false, nil, nil
+	}
+	merged := existing.DeepCopyObject().(*unstructured.Unstructured)
+	mergePatch(merged.Object, content)
+	return true, merged, nil
+}
+
+// mergePatch 按 JSON merge patch 的语义把 patch 合并进 target，null 表示删除字段
+func mergePatch(target, patch map[string]interface{}) {
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		nested, ok := value.(map[string]interface{})
+		current, isMap := target[key].(map[string]interface{})
+		if ok && isMap {
+			mergePatch(current, nested)
+			continue
+		}
+		target[key] = value
+	}
+}
+
+// optionsDynamicClient fake dynamic client 的 Create/Update/Patch/Apply 不会把选项放进 action，
+// 包装后 reactor 能按 dryRun 处理，测试也能检查字段管理器等选项
+type optionsDynamicClient struct {
+	*dynamicfake.FakeDynamicClient
+}
+
+func (c optionsDynamicClient) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return optionsNamespaceableClient{optionsResourceClient{ResourceInterface: c.FakeDynamicClient.Resource(gvr), fake: c.FakeDynamicClient, gvr: gvr}}
+}
+
+type optionsNamespaceableClient struct {
+	optionsResourceClient
+}
+
+func (c optionsNamespaceableClient) Namespace(namespace string) dynamic.ResourceInterface {
+	return optionsResourceClient{ResourceInterface: c.fake.Resource(c.gvr).Namespace(namespace), fake: c.fake, gvr: c.gvr, namespace: namespace}
+}
+
+type optionsResourceClient struct {
+	dynamic.ResourceInterface
+	fake      *dynamicfake.FakeDynamicClient
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+func (c optionsResourceClient) invoke(action k8stesting.Action) (*unstructured.Unstructured, error) {
+	obj, err := c.fake.Invokes(action, nil)
+	if err != nil {
+		return nil, err
+	}
+	// tracker 中 scheme 已注册的类型以结构体保存，与 fake dynamic client 一样转换为 unstructured
+	result := &unstructured.Unstructured{}
+	if err := scheme.Scheme.Convert(obj, result, nil); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c optionsResourceClient) Create(ctx context.Context, obj *unstructured.Unstructured, opts metav1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	if len(subresources) > 0 {
+		return c.ResourceInterface.Create(ctx, obj, opts, subresources...)
+	}
+	return c.invoke(k8stesting.NewCreateActionWithOptions(c.gvr, c.namespace, obj, opts))
+}
+
+func (c optionsResourceClient) Update(ctx context.Context, obj *unstructured.Unstructured, opts metav1.UpdateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	if len(subresources) > 0 {
+		return c.ResourceInterface.Update(ctx, obj, opts, subresources...)
+	}
+	return c.invoke(k8stesting.NewUpdateActionWithOptions(c.gvr, c.namespace, obj, opts))
+}
+
+func (c optionsResourceClient) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	if len(subresources) > 0 {
+		return c.ResourceInterface.Patch(ctx, name, pt, data, opts, subresources...)
+	}
+	return c.invoke(k8stesting.NewPatchActionWithOptions(c.gvr, c.namespace, name, pt, data, opts))
+}
+
+func (c optionsResourceClient) Apply(ctx context.Context, name string, obj *unstructured.Unstructured, opts metav1.ApplyOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return c.Patch(ctx, name, types.ApplyPatchType, data, opts.ToPatchOptions(), subresources...)
+}
+
+func postManifest(router *gin.Engine, path, token, manifest string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(manifest))
+	req.Header.Set("Content-Type", "application/yaml")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func configMapManifest(namespace, name string) string {
+	return "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\n  namespace: " + namespace + "\ndata:\n  key: value\n"
+}
+
+const clusterRoleBindingManifest = `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: escalate
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: cluster-admin
+subjects:
+- kind: User
+  name: alice
+`
+
+func TestApply_AuthorizesEveryObject(t *testing.T) {
+	var dyn *dynamicfake.FakeDynamicClient
+	router := newTestRouter(t, false, withDynamicCluster(&dyn))
+	admin := login(t, router, "admin", "admin123")
+	register(t, router, "alice", "alice-password")
+
+	// team-a 可以使用 apply，但只能写 team-a 命名空间中的 ConfigMap
+	for _, policy := range []models.PolicyRequest{
+		{Role: "team-a", Cluster: "test", Resource: "apply", Verbs: []string{"create"}},
+		{Role: "team-a", Cluster: "test", Namespace: "team-a", Resource: "configmaps", Verbs: []string{"create", "update"}},
+	} {
+		w := doRequest(router, http.MethodPost, "/api/v1/authz/policies", admin, policy)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	}
+	w := doRequest(router, http.MethodPost, "/api/v1/authz/bindings", admin, models.RoleBinding{User: "alice", Role: "team-a", Cluster: "test"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	alice := login(t, router, "alice", "alice-password")
+
+	denied := map[string]string{
+		"cluster-scoped object":       configMapManifest("team-a", "first") + "---\n" + clusterRoleBindingManifest,
+		"other namespace":             configMapManifest("team-a", "first") + "---\n" + configMapManifest("team-b", "second"),
+		"default namespace by option": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: first\n",
+	}
+	for name, manifest := range denied {
+		t.Run(name, func(t *testing.T) {
+			w := postManifest(router, "/api/v1/apply", alice, manifest)
+			assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+		})
+	}
+	// 被拒绝的清单中任何对象都没有写入
+	_, err := dyn.Resource(configMapsGVR).Namespace("team-a").Get(t.Context(), "first", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+	_, err = dyn.Resource(clusterRoleBindingsGVR).Get(t.Context(), "escalate", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+
+	w = postManifest(router, "/api/v1/apply?namespace=team-a", alice, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: first\n")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp models.ApplyResponse
+	decodeData(t, w.Body.Bytes(), &resp)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, models.ApplyActionCreated, resp.Results[0].Action)
+	assert.Equal(t, "team-a", resp.Results[0].Namespace)
+
+	// 管理员不受限制
+	w = postManifest(router, "/api/v1/apply", admin, clusterRoleBindingManifest)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	_, err = dyn.Resource(clusterRoleBindingsGVR).Get(t.Context(), "escalate", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+// applyActions 返回 fake 集群收到的 apply 请求
+func applyActions(dyn *dynamicfake.FakeDynamicClient) []k8stesting.PatchActionImpl {
+	var actions []k8stesting.PatchActionImpl
+	for _, action := range dyn.Actions() {
+		if patch, ok := action.(k8stesting.PatchActionImpl); ok && patch.GetPatchType() == types.ApplyPatchType {
+			actions = append(actions, patch)
+		}
+	}
+	return actions
+}
+
+func TestApply_MultiDocumentManifest(t *testing.T) {
+	var dyn *dynamicfake.FakeDynamicClient
+	router := newTestRouter(t, false, withDynamicCluster(&dyn,
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default"}, Data: map[string]string{"key": "old"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "static", Namespace: "default"}, Data: map[string]string{"key": "value"}},
+	))
+	admin := login(t, router, "admin", "admin123")
+
+	// 空文档被跳过，kind: List 展开为其中的对象，单个对象失败不影响其余对象
+	manifest := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\ndata:\n  key: new\n" +
+		"---\n" + configMapManifest("default", "static") +
+		"---\n" +
+		"---\napiVersion: v1\nkind: List\nitems:\n- apiVersion: v1\n  kind: ConfigMap\n  metadata:\n    name: fresh\n  data:\n    key: value\n" +
+		"---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  generateName: unnamed-\n"
+	w := postManifest(router, "/api/v1/apply", admin, manifest)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp models.ApplyResponse
+	decodeData(t, w.Body.Bytes(), &resp)
+	assert.False(t, resp.DryRun)
+	require.Len(t, resp.Results, 4)
+	var actions []string
+	for i, result := range resp.Results {
+		assert.Equal(t, i, result.Index)
+		actions = append(actions, result.Action)
+	}
+	assert.Equal(t, []string{models.ApplyActionConfigured, models.ApplyActionUnchanged, models.ApplyActionCreated, models.ApplyActionError}, actions)
+	assert.Equal(t, "default", resp.Results[2].Namespace, "未指定命名空间的对象使用默认命名空间")
+	assert.NotEmpty(t, resp.Results[3].Error)
+	assert.Equal(t, map[string]int{
+		models.ApplyActionConfigured: 1, models.ApplyActionUnchanged: 1, models.ApplyActionCreated: 1, models.ApplyActionError: 1,
+	}, resp.Summary)
+
+	settings, err := dyn.Resource(configMapsGVR).Namespace("default").Get(t.Context(), "settings", metav1.GetOptions{})
+	require.NoError(t, err)
+	value, _, _ := unstructured.NestedString(settings.Object, "data", "key")
+	assert.Equal(t, "new", value)
+	_, err = dyn.Resource(configMapsGVR).Namespace("default").Get(t.Context(), "fresh", metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	// 每个对象都以 cilikube 字段管理器提交，默认不强制获取字段所有权
+	applied := applyActions(dyn)
+	require.Len(t, applied, 3)
+	for _, action := range applied {
+		assert.Equal(t, "cilikube", action.PatchOptions.FieldManager)
+		assert.False(t, action.PatchOptions.Force != nil && *action.PatchOptions.Force)
+		assert.Empty(t, action.PatchOptions.DryRun)
+	}
+}
+
+func TestApply_ForceAndDryRun(t *testing.T) {
+	var dyn *dynamicfake.FakeDynamicClient
+	router := newTestRouter(t, false, withDynamicCluster(&dyn))
+	admin := login(t, router, "admin", "admin123")
+
+	w := postManifest(router, "/api/v1/apply?namespace=team-a&force=true&dryRun=All", admin, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: preview\n")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp models.ApplyResponse
+	decodeData(t, w.Body.Bytes(), &resp)
+	assert.True(t, resp.DryRun)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, models.ApplyActionCreated, resp.Results[0].Action)
+	assert.Equal(t, "team-a", resp.Results[0].Namespace)
+
+	applied := applyActions(dyn)
+	require.Len(t, applied, 1)
+	require.NotNil(t, applied[0].PatchOptions.Force)
+	assert.True(t, *applied[0].PatchOptions.Force)
+	assert.Equal(t, []string{metav1.DryRunAll}, applied[0].PatchOptions.DryRun)
+	_, err := dyn.Resource(configMapsGVR).Namespace("team-a").Get(t.Context(), "preview", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err), "dry-run 不持久化")
+
+	invalid := map[string]string{
+		"unparseable manifest": "apiVersion: v1\nkind: [ConfigMap\n",
+		"empty manifest":       "---\n---\n",
+	}
+	for name, manifest := range invalid {
+		t.Run(name, func(t *testing.T) {
+			w := postManifest(router, "/api/v1/apply", admin, manifest)
+			assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		})
+	}
+	w = postManifest(router, "/api/v1/apply?namespace=Team_A", admin, configMapManifest("default", "preview"))
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+}
@@ -0,0 +1,158 @@
+package initialization
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/audit"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type auditPage struct {
+	Events []models.AuditEvent `json:"events"`
+	Total  int64               `json:"total"`
+}
+
+func listAudit(t *testing.T, router *gin.Engine, token, query string) auditPage {
+	t.Helper()
+	w := doRequest(router, http.MethodGet, "/api/v1/audit"+query, token, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var page auditPage
+	decodeData(t, w.Body.Bytes(), &page)
+	return page
+}
+
+func TestAudit_RecordsKubernetesOperations(t *testing.T) {
+	router := newTestRouter(t, false)
+	admin := login(t, router, "admin", "admin123")
+	register(t, router, "alice", "alice-password")
+	alice := login(t, router, "alice", "alice-password")
+
+	configMap := corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "settings"},
+		Data:       map[string]string{"key": "value"},
+	}
+	w := doRequest(router, http.MethodPost, "/api/v1/clusters/test/namespaces/default/configmaps", admin, configMap)
+	require.Less(t, w.Code, 300, w.Body.String())
+	// 只读请求不记录，被拒绝的写操作和日志访问要记录
+	require.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, "/api/v1/namespaces/default/configmaps", admin, nil).Code)
+	require.Equal(t, http.StatusForbidden, doRequest(router, http.MethodDelete, "/api/v1/namespaces/default/configmaps/settings", alice, nil).Code)
+	require.Equal(t, http.StatusNotFound, doRequest(router, http.MethodGet, "/api/v1/namespaces/default/pods/web/logs?container=app", alice, nil).Code)
+
+	page := listAudit(t, router, admin, "?resource=configmaps")
+	require.EqualValues(t, 2, page.Total)
+	denied, created := page.Events[0], page.Events[1]
+
+	body, err := json.Marshal(configMap)
+	require.NoError(t, err)
+	sum := sha256.Sum256(body)
+	assert.Equal(t, "admin", created.Username)
+	assert.Equal(t, "test", created.Cluster)
+	assert.Equal(t, "default", created.Namespace)
+	assert.Equal(t, "create", created.Verb)
+	assert.Equal(t, hex.EncodeToString(sum[:]), created.BodyHash)
+	assert.Less(t, created.Status, 300)
+
+	assert.Equal(t, "alice", denied.Username)
+	assert.Equal(t, "delete", denied.Verb)
+	assert.Equal(t, "settings", denied.Name)
+	assert.Equal(t, http.StatusForbidden, denied.Status)
+
+	page = listAudit(t, router, admin, "?username=alice&failed=true")
+	require.EqualValues(t, 2, page.Total)
+	assert.Equal(t, "pods/logs", page.Events[0].Resource)
+	assert.Equal(t, "web", page.Events[0].Name)
+
+	assert.EqualValues(t, 0, listAudit(t, router, admin, "?since=2999-01-01T00:00:00Z").Total)
+	assert.Equal(t, http.StatusBadRequest, doRequest(router, http.MethodGet, "/api/v1/audit?since=yesterday", admin, nil).Code)
+	assert.Equal(t, http.StatusForbidden, doRequest(router, http.MethodGet, "/api/v1/audit", alice, nil).Code)
+}
+
+func TestAudit_FileSinkWithoutDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	auditService, err := service.NewAuditService(configs.AuditConfig{File: path}, nil)
+	require.NoError(t, err)
+
+	auditService.Record(&models.AuditEvent{Username: "dev", Resource: "pods/exec", Verb: "create", Status: http.StatusOK})
+	auditService.Record(&models.AuditEvent{Username: "dev", Resource: "deployments", Verb: "delete", Status: http.StatusOK})
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+	require.Len(t, lines, 2)
+	var event models.AuditEvent
+	require.NoError(t, json.Unmarshal(lines[1], &event))
+	assert.Equal(t, "deployments", event.Resource)
+	assert.False(t, event.CreatedAt.IsZero())
+
+	_, _, err = auditService.ListAuditEvents(&models.AuditQuery{Page: 1, PageSize: 10})
+	assert.ErrorIs(t, err, service.ErrAuditQueryUnavailable)
+}
+
+// countingReader 记录被读取的字节数
+type countingReader struct {
+	io.Reader
+	read int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.read += int64(n)
+	return n, err
+}
+
+func TestAudit_StreamsBodyWithinLimit(t *testing.T) {
+	router := newTestRouter(t, false)
+	admin := login(t, router, "admin", "admin123")
+	send := func(path string, body io.Reader, contentLength int64) int {
+		req := httptest.NewRequest(http.MethodPost, path, body)
+		req.ContentLength = contentLength
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+admin)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// handler 没有读到的尾部空白同样计入哈希
+	body := `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"}}` + "\n\n"
+	require.Less(t, send("/api/v1/namespaces/default/configmaps", strings.NewReader(body), int64(len(body))), 300)
+	sum := sha256.Sum256([]byte(body))
+	page := listAudit(t, router, admin, "?resource=configmaps")
+	require.EqualValues(t, 1, page.Total)
+	assert.Equal(t, hex.EncodeToString(sum[:]), page.Events[0].BodyHash)
+
+	// 声明的长度超限时不读取请求体
+	oversized := &countingReader{Reader: strings.NewReader(strings.Repeat(" ", audit.MaxBodySize+1))}
+	assert.Equal(t, http.StatusRequestEntityTooLarge, send("/api/v1/apply", oversized, audit.MaxBodySize+1))
+	assert.Zero(t, oversized.read)
+
+	// 未声明长度 (chunked) 时最多读取 MaxBodySize+1 字节
+	chunked := &countingReader{Reader: strings.NewReader(strings.Repeat(" ", audit.MaxBodySize+1<<10))}
+	assert.Equal(t, http.StatusBadRequest, send("/api/v1/apply", chunked, -1))
+	assert.LessOrEqual(t, chunked.read, int64(audit.MaxBodySize+1))
+
+	page = listAudit(t, router, admin, "?resource=apply")
+	require.EqualValues(t, 2, page.Total)
+	for _, event := range page.Events {
+		assert.Empty(t, event.BodyHash)
+	}
+	assert.Equal(t, http.StatusRequestEntityTooLarge, page.Events[1].Status)
+}
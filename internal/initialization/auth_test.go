@@ -0,0 +1,337 @@
+package initialization
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/database"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newTestRouter 使用内存 SQLite 与 fake 集群组装完整的路由，覆盖认证 -> 授权 -> handler 整条链路。
+// configure 在组装路由前调整配置或注册其他集群
+func newTestRouter(t *testing.T, authDisabled bool, configure ...func(*configs.Config, *k8s.ClientManager)) *gin.Engine {
+	router, _ := newTestServer(t, authDisabled, configure...)
+	return router
+}
+
+// newTestServer 同 newTestRouter，同时返回组装路由所用的服务
+func newTestServer(t *testing.T, authDisabled bool, configure ...func(*configs.Config, *k8s.ClientManager)) (*gin.Engine, *AppServices) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := &configs.Config{
+		Database: configs.DatabaseConfig{Enabled: true},
+		JWT:      configs.JWTConfig{SecretKey: "test-secret", ExpireDuration: time.Hour, RefreshExpireDuration: 24 * time.Hour, Issuer: "cilikube-test"},
+		Auth:     configs.AuthConfig{Disabled: authDisabled},
+	}
+	configs.GlobalConfig = cfg
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	database.DB = db
+	t.Cleanup(func() {
+		_ = database.CloseDatabase()
+		database.DB = nil
+	})
+	require.NoError(t, database.AutoMigrate())
+
+	enforcer, err := auth.InitCasbin(db)
+	require.NoError(t, err)
+	enforcer.EnableLog(false)
+
+	clientManager := k8s.NewClientManager()
+	clientManager.SetCacheEnabled(false)
+	clientManager.AddClient("test", &k8s.Client{Clientset: fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}})})
+
+	auditService, err := service.NewAuditService(cfg.Audit, db)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	services := &AppServices{
+		AuthService:    service.NewAuthService(enforcer),
+		AuthzService:   service.NewAuthzService(enforcer),
+		ClusterService: service.NewClusterService(clientManager, filepath.Join(dir, "cluster.json"), filepath.Join(dir, "kubeconfigs")),
+		AuditService:   auditService,
+		Enforcer:       enforcer,
+	}
+	services.ClusterService.SetEnforcer(enforcer)
+	require.NoError(t, database.CreateDefaultAdmin())
+	// 大部分用例直接以默认管理员身份操作，强制改密码的流程在 login_security_test.go 中单独覆盖
+	require.NoError(t, database.DB.Model(&models.User{}).Where("username = ?", "admin").Update("must_change_password", false).Error)
+	require.NoError(t, services.AuthService.SyncRoleBindings())
+
+	for _, fn := range configure {
+		fn(cfg, clientManager)
+	}
+	if cfg.Auth.OIDC.Enabled {
+		services.OIDCService = service.NewOIDCService(cfg.Auth.OIDC, services.AuthService)
+	}
+	if cfg.Auth.LDAP.Enabled {
+		services.LDAPAuthenticator = service.NewLDAPAuthenticator(cfg.Auth.LDAP, services.AuthService)
+		services.AuthService.AddAuthenticator(services.LDAPAuthenticator)
+	}
+	return SetupRouter(cfg, InitializeHandlers(services), clientManager, enforcer), services
+}
+
+func doRequest(router *gin.Engine, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func login(t *testing.T, router *gin.Engine, username, password string) string {
+	t.Helper()
+	w := doRequest(router, http.MethodPost, "/api/v1/auth/login", "", models.LoginRequest{Username: username, Password: password})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp struct {
+		Data models.LoginResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotEmpty(t, resp.Data.Token)
+	return resp.Data.Token
+}
+
+func TestAuthPipeline_LoginAndAuthorize(t *testing.T) {
+	router := newTestRouter(t, false)
+
+	w := doRequest(router, http.MethodPost, "/api/v1/auth/register", "", models.RegisterRequest{Username: "alice", Email: "alice@example.com", Password: "alice-password"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	adminToken := login(t, router, "admin", "admin123")
+	userToken := login(t, router, "alice", "alice-password")
+	newNamespace := models.CreateNamespaceRequest{Name: "team-a"}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		body   interface{}
+		want   int
+	}{
+		{"no token", http.MethodGet, "/api/v1/namespace", "", nil, http.StatusUnauthorized},
+		{"invalid token", http.MethodGet, "/api/v1/namespace", "not-a-jwt", nil, http.StatusUnauthorized},
+		{"wrong password", http.MethodPost, "/api/v1/auth/login", "", models.LoginRequest{Username: "alice", Password: "wrong-password"}, http.StatusUnauthorized},
+		{"user can read", http.MethodGet, "/api/v1/namespace", userToken, nil, http.StatusOK},
+		{"user can read via cluster path", http.MethodGet, "/api/v1/clusters/test/namespace", userToken, nil, http.StatusOK},
+		{"user cannot write", http.MethodPost, "/api/v1/namespace", userToken, newNamespace, http.StatusForbidden},
+		{"user cannot exec", http.MethodGet, "/api/v1/namespaces/default/pods/web/exec", userToken, nil, http.StatusForbidden},
+		{"user cannot exec via cluster path", http.MethodGet, "/api/v1/clusters/test/namespaces/default/pods/web/exec", userToken, nil, http.StatusForbidden},
+		{"user cannot list users", http.MethodGet, "/api/v1/auth/users", userToken, nil, http.StatusForbidden},
+		{"user reads own profile", http.MethodGet, "/api/v1/auth/profile", userToken, nil, http.StatusOK},
+		{"user changes own password", http.MethodPost, "/api/v1/auth/change-password", userToken, models.ChangePasswordRequest{OldPassword: "alice-password", NewPassword: "alice-password-2"}, http.StatusOK},
+		{"admin can write", http.MethodPost, "/api/v1/namespace", adminToken, newNamespace, http.StatusOK},
+		{"admin can list users", http.MethodGet, "/api/v1/auth/users", adminToken, nil, http.StatusOK},
+		{"healthz is public", http.MethodGet, "/healthz", "", nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doRequest(router, tt.method, tt.path, tt.token, tt.body)
+			assert.Equal(t, tt.want, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestAuthPipeline_DomainPolicies(t *testing.T) {
+	router := newTestRouter(t, false)
+	adminToken := login(t, router, "admin", "admin123")
+	for _, name := range []string{"alice", "bob"} {
+		w := doRequest(router, http.MethodPost, "/api/v1/auth/register", "", models.RegisterRequest{Username: name, Email: name + "@example.com", Password: name + "-password"})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// team-a 可以在集群 test 上修改 a-* 命名空间中的 Deployment
+	w := doRequest(router, http.MethodPost, "/api/v1/authz/policies", adminToken, models.PolicyRequest{
+		Role: "team-a", Cluster: "test", Namespace: "a-*", Resource: "deployments", Verbs: []string{"create", "update", "patch", "delete"},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = doRequest(router, http.MethodPost, "/api/v1/authz/bindings", adminToken, models.RoleBinding{User: "alice", Role: "team-a", Cluster: "test"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = doRequest(router, http.MethodPost, "/api/v1/authz/bindings", adminToken, models.RoleBinding{User: "bob", Role: "team-a", Cluster: "prod"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	aliceToken := login(t, router, "alice", "alice-password")
+	bobToken := login(t, router, "bob", "bob-password")
+	patch := map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]string{"team": "a"}}}
+
+	tests := []struct {
+		name  string
+		path  string
+		token string
+		want  int
+	}{
+		// 通过授权后由 handler 处理，fake 集群中不存在该 Deployment
+		{"matching namespace", "/api/v1/namespaces/a-1/deployments/web", aliceToken, http.StatusNotFound},
+		{"matching namespace via cluster path", "/api/v1/clusters/test/namespaces/a-2/deployments/web", aliceToken, http.StatusNotFound},
+		{"other namespace", "/api/v1/namespaces/b-1/deployments/web", aliceToken, http.StatusForbidden},
+		{"other resource", "/api/v1/namespaces/a-1/statefulsets/web", aliceToken, http.StatusForbidden},
+		{"binding on other cluster", "/api/v1/namespaces/a-1/deployments/web", bobToken, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doRequest(router, http.MethodPatch, tt.path, tt.token, patch)
+			assert.Equal(t, tt.want, w.Code, w.Body.String())
+		})
+	}
+
+	w = doRequest(router, http.MethodPost, "/api/v1/authz/check", adminToken, models.AuthzCheckRequest{User: "alice", Cluster: "test", Namespace: "a-9", Resource: "deployments", Verb: "delete"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"allowed":true`)
+
+	w = doRequest(router, http.MethodGet, "/api/v1/authz/roles", aliceToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	w = doRequest(router, http.MethodDelete, "/api/v1/authz/roles/admin", adminToken, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	// 删除角色后授权立即失效
+	w = doRequest(router, http.MethodDelete, "/api/v1/authz/roles/team-a", adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doRequest(router, http.MethodPatch, "/api/v1/namespaces/a-1/deployments/web", aliceToken, patch)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+}
+
+func TestAuthPipeline_RoleNamesAreNotUsers(t *testing.T) {
+	router, services := newTestServer(t, false)
+	adminToken := login(t, router, "admin", "admin123")
+	w := doRequest(router, http.MethodPost, "/api/v1/authz/policies", adminToken, models.PolicyRequest{Role: "ops", Resource: "namespaces", Verbs: []string{"create"}})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// 与角色同名的用户不继承该角色的规则
+	register(t, router, "ops", "ops-password")
+	opsToken := login(t, router, "ops", "ops-password")
+	w = doRequest(router, http.MethodPost, "/api/v1/namespace", opsToken, models.CreateNamespaceRequest{Name: "team-a"})
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	w = doRequest(router, http.MethodPost, "/api/v1/auth/register", "", models.RegisterRequest{Username: "role:ops", Email: "ops2@example.com", Password: "ops-password"})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	// 绑定后获得角色的规则，接口中的角色名不带前缀
+	w = doRequest(router, http.MethodPost, "/api/v1/authz/bindings", adminToken, models.RoleBinding{User: "ops", Role: "ops"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = doRequest(router, http.MethodPost, "/api/v1/namespace", opsToken, models.CreateNamespaceRequest{Name: "team-a"})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doRequest(router, http.MethodGet, "/api/v1/authz/roles/ops", adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var role models.RoleInfo
+	decodeData(t, w.Body.Bytes(), &role)
+	assert.Equal(t, []models.RoleBinding{{User: "ops", Role: "ops", Cluster: auth.Wildcard}}, role.Bindings)
+	require.Len(t, role.Policies, 1)
+	assert.Equal(t, "ops", role.Policies[0].Role)
+
+	// 旧版本写入的不带前缀的规则和绑定在启动时迁移
+	_, err := services.Enforcer.AddPolicy("legacy", auth.Wildcard, auth.Wildcard, "secrets", auth.VerbDelete)
+	require.NoError(t, err)
+	_, err = services.Enforcer.AddGroupingPolicy("ops", "legacy", auth.Wildcard)
+	require.NoError(t, err)
+	enforcer, err := auth.InitCasbin(database.DB)
+	require.NoError(t, err)
+	enforcer.EnableLog(false)
+	has, err := enforcer.HasPolicy(auth.RoleSubject("legacy"), auth.Wildcard, auth.Wildcard, "secrets", auth.VerbDelete)
+	require.NoError(t, err)
+	assert.True(t, has)
+	allowed, err := enforcer.Enforce("ops", "test", "default", "secrets", auth.VerbDelete)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, err = enforcer.Enforce("legacy", "test", "default", "secrets", auth.VerbDelete)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func TestAuthPipeline_Impersonation(t *testing.T) {
+	type seen struct {
+		user   string
+		groups []string
+	}
+	requests := make(chan seen, 10)
+	apiserver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- seen{user: r.Header.Get("Impersonate-User"), groups: r.Header.Values("Impersonate-Group")}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"NamespaceList","apiVersion":"v1","metadata":{},"items":[]}`))
+	}))
+	defer apiserver.Close()
+
+	kubeconfig := fmt.Sprintf("apiVersion: v1\nkind: Config\nclusters:\n- name: real\n  cluster:\n    server: %s\ncontexts:\n- name: real\n  context:\n    cluster: real\n    user: svc\ncurrent-context: real\nusers:\n- name: svc\n  user:\n    token: service-token\n", apiserver.URL)
+	router := newTestRouter(t, false, func(cfg *configs.Config, cm *k8s.ClientManager) {
+		cfg.Auth.Impersonation = configs.ImpersonationConfig{
+			Enabled:        true,
+			UsernamePrefix: "cilikube:",
+			Groups:         []string{"cilikube:authenticated"},
+			RoleGroups:     map[string][]string{models.RoleAdmin: {"cilikube:admins"}},
+		}
+		client, err := k8s.NewClientFromKubeconfig([]byte(kubeconfig))
+		require.NoError(t, err)
+		cm.AddClient("real", client)
+	})
+	token := login(t, router, "admin", "admin123")
+
+	for i := 0; i < 2; i++ {
+		w := doRequest(router, http.MethodGet, "/api/v1/clusters/real/namespace", token, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		got := <-requests
+		assert.Equal(t, "cilikube:admin", got.user)
+		assert.ElementsMatch(t, []string{"cilikube:authenticated", "cilikube:admins"}, got.groups)
+	}
+}
+
+func TestAuthPipeline_AccessTokenQueryParam(t *testing.T) {
+	var accessLog bytes.Buffer
+	defaultWriter := gin.DefaultWriter
+	gin.DefaultWriter = &accessLog
+	t.Cleanup(func() { gin.DefaultWriter = defaultWriter })
+	router := newTestRouter(t, false)
+	token := login(t, router, "admin", "admin123")
+
+	// 只有浏览器无法设置请求头的流式路由接受查询参数中的 token
+	w := doRequest(router, http.MethodGet, "/api/v1/namespace?access_token="+token, "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	w = doRequest(router, http.MethodGet, "/api/v1/namespaces/default/pods/web/logs?container=app&access_token="+token, "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+
+	// 访问日志中不出现 token，其余查询参数保留
+	assert.Contains(t, accessLog.String(), "/pods/web/logs?container=app")
+	assert.NotContains(t, accessLog.String(), token)
+	assert.NotContains(t, accessLog.String(), "access_token")
+}
+
+func TestAuthPipeline_Disabled(t *testing.T) {
+	router := newTestRouter(t, true)
+
+	w := doRequest(router, http.MethodGet, "/api/v1/namespace", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doRequest(router, http.MethodPost, "/api/v1/namespace", "", models.CreateNamespaceRequest{Name: "team-a"})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
@@ -0,0 +1,181 @@
+package initialization
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// withCache 为测试集群 "test" 开启 informer 缓存，须放在 withClusterObjects 之后
+func withCache(t *testing.T) func(*configs.Config, *k8s.ClientManager) {
+	return func(_ *configs.Config, cm *k8s.ClientManager) {
+		cm.SetCacheEnabled(true)
+		client, err := cm.GetClientByName("test")
+		require.NoError(t, err)
+		t.Cleanup(client.StopCache)
+	}
+}
+
+// countActions 统计 fake 集群收到的某类请求
+func countActions(clientset *fake.Clientset, verb, resource string) int {
+	count := 0
+	for _, action := range clientset.Actions() {
+		if action.GetVerb() == verb && action.GetResource().Resource == resource {
+			count++
+		}
+	}
+	return count
+}
+
+func labeledConfigMap(name, app string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": app}},
+		Data:       map[string]string{"key": "value"},
+	}
+}
+
+func TestCache_ListAndGetFromInformer(t *testing.T) {
+	var clientset *fake.Clientset
+	router := newTestRouter(t, false,
+		withClusterObjects(&clientset, labeledConfigMap("web-config", "web"), labeledConfigMap("api-config", "api")),
+		withCache(t))
+	admin := login(t, router, "admin", "admin123")
+	list := func(query string) []string {
+		t.Helper()
+		w := doRequest(router, http.MethodGet, "/api/v1/namespaces/default/configmaps"+query, admin, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp models.ConfigMapListResponse
+		decodeData(t, w.Body.Bytes(), &resp)
+		names := make([]string, 0, len(resp.Items))
+		for _, item := range resp.Items {
+			names = append(names, item.Name)
+		}
+		return names
+	}
+
+	// 第一次读取启动 informer，此后的列表、选择器过滤和分页都由缓存完成
+	assert.Equal(t, []string{"api-config", "web-config"}, list(""))
+	assert.Equal(t, []string{"web-config"}, list("?labelSelector=app%3Dweb"))
+	assert.Equal(t, []string{"api-config"}, list("?limit=1"))
+	w := doRequest(router, http.MethodGet, "/api/v1/namespaces/default/configmaps/web-config", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, 1, countActions(clientset, "list", "configmaps"), "只有 informer 自身的 list")
+	assert.Zero(t, countActions(clientset, "get", "configmaps"))
+
+	// informer 通过 watch 收到集群中的变化
+	_, err := clientset.CoreV1().ConfigMaps("default").Create(t.Context(), labeledConfigMap("new-config", "web"), metav1.CreateOptions{})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return len(list("?labelSelector=app%3Dweb")) == 2
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// ?consistent=true 跳过缓存直接读取 API Server
+	assert.Equal(t, []string{"api-config", "new-config", "web-config"}, list("?consistent=true"))
+	assert.Equal(t, 2, countActions(clientset, "list", "configmaps"))
+}
+
+// watchedEvent SSE watch 流中的一个事件
+type watchedEvent struct {
+	Type   string `json:"type"`
+	Object struct {
+		Name string `json:"name"`
+	} `json:"object"`
+}
+
+// streamWatch 打开 SSE watch 流，返回解析后的事件；测试结束时关闭流
+func streamWatch(t *testing.T, url, token string) <-chan watchedEvent {
+	t.Helper()
+	ctx, cancel := context.WithCancel(t.Context())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	events := make(chan watchedEvent, 16)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data:")
+			if !ok {
+				continue
+			}
+			var event watchedEvent
+			if json.Unmarshal([]byte(data), &event) == nil {
+				events <- event
+			}
+		}
+	}()
+	return events
+}
+
+// nextEvent 等待下一个事件
+func nextEvent(t *testing.T, events <-chan watchedEvent) watchedEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "watch 流提前结束")
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("等待 watch 事件超时")
+		return watchedEvent{}
+	}
+}
+
+func TestCache_WatchSharesInformer(t *testing.T) {
+	web := rolledOutDeployment("web")
+	web.Labels = map[string]string{"app": "web"}
+	var clientset *fake.Clientset
+	router := newTestRouter(t, false, withClusterObjects(&clientset, web), withCache(t))
+	admin := login(t, router, "admin", "admin123")
+	// 先注册 server.Close，使 streamWatch 注册的取消先执行，Close 不会等待仍在推送的流
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	url := server.URL + "/api/v1/watch/namespaces/default/deployments?labelSelector=app%3Dweb"
+	first := streamWatch(t, url, admin)
+	second := streamWatch(t, url, admin)
+
+	// 与直接 watch 一样，先为已有对象发送 Added
+	for _, events := range []<-chan watchedEvent{first, second} {
+		event := nextEvent(t, events)
+		assert.Equal(t, "ADDED", event.Type)
+		assert.Equal(t, "web", event.Object.Name)
+	}
+
+	// 不匹配选择器的对象不推送；标签变化后不再匹配的对象以 DELETED 推送
+	other := rolledOutDeployment("other")
+	other.Labels = map[string]string{"app": "api"}
+	_, err := clientset.AppsV1().Deployments("default").Create(t.Context(), other, metav1.CreateOptions{})
+	require.NoError(t, err)
+	relabeled := web.DeepCopy()
+	relabeled.Labels = map[string]string{"app": "api"}
+	_, err = clientset.AppsV1().Deployments("default").Update(t.Context(), relabeled, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	for _, events := range []<-chan watchedEvent{first, second} {
+		event := nextEvent(t, events)
+		assert.Equal(t, "DELETED", event.Type)
+		assert.Equal(t, "web", event.Object.Name)
+	}
+
+	// 两个订阅共用 informer 的一条 watch 连接
+	assert.Equal(t, 1, countActions(clientset, "watch", "deployments"))
+}
@@ -0,0 +1,177 @@
+package initialization
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newVersionServer 模拟只响应 /version 的 API Server，足以通过添加集群时的连通性检查
+func newVersionServer(t *testing.T) string {
+	t.Helper()
+	kubeconfig, _ := newFlakyVersionServer(t)
+	return kubeconfig
+}
+
+// newFlakyVersionServer 同 newVersionServer，failing 为 true 时所有请求返回 503
+func newFlakyVersionServer(t *testing.T) (string, *atomic.Bool) {
+	t.Helper()
+	failing := &atomic.Bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			http.Error(w, "etcd unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path != "/version" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"major":"1","minor":"33","gitVersion":"v1.33.0"}`)
+	}))
+	t.Cleanup(server.Close)
+	return fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: fake
+  cluster:
+    server: %s
+contexts:
+- name: fake
+  context:
+    cluster: fake
+    user: fake
+current-context: fake
+users:
+- name: fake
+  user:
+    token: fake-token
+`, server.URL), failing
+}
+
+func TestClusterRename_RewritesCasbinRules(t *testing.T) {
+	router, services := newTestServer(t, false)
+	admin := login(t, router, "admin", "admin123")
+	register(t, router, "alice", "alice-password")
+
+	w := doRequest(router, http.MethodPost, "/api/v1/clusters", admin, models.AddClusterRequest{Name: "staging", KubeconfigContent: newVersionServer(t)})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// alice 只在 staging 上拥有 team-a；team-a 在 staging 上可以修改 Deployment，在所有集群上可以读取 Pod
+	w = doRequest(router, http.MethodPost, "/api/v1/authz/policies", admin, models.PolicyRequest{
+		Role: "team-a", Cluster: "staging", Resource: "deployments", Verbs: []string{"update"},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = doRequest(router, http.MethodPost, "/api/v1/authz/policies", admin, models.PolicyRequest{
+		Role: "team-a", Cluster: "*", Resource: "pods", Verbs: []string{"get"},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = doRequest(router, http.MethodPost, "/api/v1/authz/bindings", admin, models.RoleBinding{User: "alice", Role: "team-a", Cluster: "staging"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = doRequest(router, http.MethodPut, "/api/v1/clusters/staging", admin, models.UpdateClusterRequest{Name: "qa"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	check := func(cluster, resource, verb string) bool {
+		t.Helper()
+		w := doRequest(router, http.MethodPost, "/api/v1/authz/check", admin, models.AuthzCheckRequest{User: "alice", Cluster: cluster, Namespace: "default", Resource: resource, Verb: verb})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp struct {
+			Allowed bool `json:"allowed"`
+		}
+		decodeData(t, w.Body.Bytes(), &resp)
+		return resp.Allowed
+	}
+	assert.True(t, check("qa", "deployments", "update"), "规则应跟随集群改名")
+	assert.True(t, check("qa", "pods", "get"), "绑定应跟随集群改名")
+	assert.False(t, check("staging", "deployments", "update"), "旧集群名不应保留授权")
+
+	// 不再有以旧名称为键的规则，通配规则保持不变
+	policies, err := services.Enforcer.GetFilteredPolicy(1, "staging")
+	require.NoError(t, err)
+	assert.Empty(t, policies)
+	bindings, err := services.Enforcer.GetFilteredGroupingPolicy(2, "staging")
+	require.NoError(t, err)
+	assert.Empty(t, bindings)
+	wildcard, err := services.Enforcer.GetFilteredPolicy(1, "*", "", "pods")
+	require.NoError(t, err)
+	assert.Len(t, wildcard, 1)
+
+	// 以新名称定位到的集群仍然可用，旧名称已注销
+	w = doRequest(router, http.MethodGet, "/api/v1/clusters/qa", admin, nil)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doRequest(router, http.MethodGet, "/api/v1/clusters/staging", admin, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
+
+func TestClusterHealth_FailsAndRecovers(t *testing.T) {
+	router := newTestRouter(t, false)
+	admin := login(t, router, "admin", "admin123")
+	kubeconfig, failing := newFlakyVersionServer(t)
+	w := doRequest(router, http.MethodPost, "/api/v1/clusters", admin, models.AddClusterRequest{Name: "staging", KubeconfigContent: kubeconfig})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	health := func(query string) k8s.ClusterHealth {
+		t.Helper()
+		w := doRequest(router, http.MethodGet, "/api/v1/clusters/staging/health"+query, admin, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp k8s.ClusterHealth
+		decodeData(t, w.Body.Bytes(), &resp)
+		return resp
+	}
+	healthz := func() map[string]k8s.ClusterStatus {
+		t.Helper()
+		w := doRequest(router, http.MethodGet, "/healthz", "", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp struct {
+			Clusters []k8s.ClusterHealth `json:"clusters"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		statuses := map[string]k8s.ClusterStatus{}
+		for _, cluster := range resp.Clusters {
+			statuses[cluster.Cluster] = cluster.Status
+		}
+		return statuses
+	}
+
+	// 添加集群时已探测过一次
+	assert.Equal(t, k8s.ClusterStatusHealthy, health("").Status)
+	assert.Equal(t, k8s.ClusterStatusHealthy, healthz()["staging"])
+
+	// 不刷新时返回最近一次探测结果，刷新时立即探测
+	failing.Store(true)
+	assert.Equal(t, k8s.ClusterStatusHealthy, health("").Status)
+	first := health("?refresh=true")
+	assert.Equal(t, k8s.ClusterStatusHealthy, first.Status)
+	assert.Equal(t, 1, first.ConsecutiveFailures)
+	assert.Equal(t, k8s.ClusterStatusUnavailable, health("?refresh=true").Status)
+	assert.Equal(t, k8s.ClusterStatusUnavailable, healthz()["staging"])
+
+	// 不可用的集群直接返回 503，其他集群不受影响
+	w = doRequest(router, http.MethodGet, "/api/v1/clusters/staging/namespaces", admin, nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, w.Body.String())
+	w = doRequest(router, http.MethodGet, "/api/v1/clusters/test/namespaces", admin, nil)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	failing.Store(false)
+	recovered := health("?refresh=true")
+	assert.Equal(t, k8s.ClusterStatusHealthy, recovered.Status)
+	assert.Equal(t, "v1.33.0", recovered.Version)
+	assert.Equal(t, k8s.ClusterStatusHealthy, healthz()["staging"])
+	w = doRequest(router, http.MethodGet, "/api/v1/clusters/staging/namespaces", admin, nil)
+	assert.NotEqual(t, http.StatusServiceUnavailable, w.Code, w.Body.String())
+
+	// 只有不存在的集群返回 404
+	for _, query := range []string{"", "?refresh=true"} {
+		w = doRequest(router, http.MethodGet, "/api/v1/clusters/missing/health"+query, admin, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,152 @@
+package initialization
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+// cronTabCRD stable.example.com 的 CronTab CRD：v1 为存储版本并声明打印列，v1beta1 已停止服务
+func cronTabCRD(t *testing.T) *unstructured.Unstructured {
+	t.Helper()
+	schema := &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"spec": {Type: "object", Properties: map[string]apiextensionsv1.JSONSchemaProps{
+				"cronSpec": {Type: "string"},
+				"replicas": {Type: "integer"},
+			}},
+		},
+	}}
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition"},
+		ObjectMeta: metav1.ObjectMeta{Name: "crontabs.stable.example.com"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: "stable.example.com",
+			Names: apiextensionsv1.CustomResourceDefinitionNames{Plural: "crontabs", Singular: "crontab", Kind: "CronTab", ShortNames: []string{"ct"}},
+			Scope: apiextensionsv1.NamespaceScoped,
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1beta1", Schema: schema},
+				{Name: "v1", Served: true, Storage: true, Schema: schema, AdditionalPrinterColumns: []apiextensionsv1.CustomResourceColumnDefinition{
+					{Name: "Spec", Type: "string", JSONPath: ".spec.cronSpec"},
+					{Name: "Replicas", Type: "integer", JSONPath: ".spec.replicas", Priority: 1},
+					{Name: "Containers", Type: "string", JSONPath: ".spec.containers[*].name"},
+				}},
+			},
+		},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{Conditions: []apiextensionsv1.CustomResourceDefinitionCondition{
+			{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionTrue},
+		}},
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(crd)
+	require.NoError(t, err)
+	return &unstructured.Unstructured{Object: content}
+}
+
+func cronTabObject(namespace, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetAPIVersion("stable.example.com/v1")
+	obj.SetKind("CronTab")
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func TestCRDs_ListAndDetail(t *testing.T) {
+	var dyn *dynamicfake.FakeDynamicClient
+	router := newTestRouter(t, false, withDynamicCluster(&dyn, cronTabCRD(t)))
+	admin := login(t, router, "admin", "admin123")
+
+	w := doRequest(router, http.MethodGet, "/api/v1/crds", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list models.CRDListResponse
+	decodeData(t, w.Body.Bytes(), &list)
+	require.Len(t, list.Items, 1)
+	crd := list.Items[0]
+	assert.Equal(t, "crontabs.stable.example.com", crd.Name)
+	assert.Equal(t, "CronTab", crd.Kind)
+	assert.Equal(t, "Namespaced", crd.Scope)
+	assert.Equal(t, "v1", crd.StorageVersion)
+	assert.True(t, crd.Established)
+	require.Len(t, crd.Versions, 2)
+	assert.False(t, crd.Versions[0].Served)
+	assert.Nil(t, crd.Versions[1].Schema, "列表中不返回 schema")
+	// 未声明打印列的版本与 kubectl 一样只显示 Age
+	assert.Equal(t, []string{"Age"}, columnNames(crd.Versions[0].PrinterColumns))
+	assert.Equal(t, []string{"Spec", "Replicas", "Containers"}, columnNames(crd.Versions[1].PrinterColumns))
+
+	w = doRequest(router, http.MethodGet, "/api/v1/crds/crontabs.stable.example.com", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var detail models.CRDResponse
+	decodeData(t, w.Body.Bytes(), &detail)
+	require.Len(t, detail.Versions, 2)
+	require.NotNil(t, detail.Versions[1].Schema)
+	assert.Equal(t, "string", detail.Versions[1].Schema.Properties["spec"].Properties["cronSpec"].Type)
+
+	w = doRequest(router, http.MethodGet, "/api/v1/crds/widgets.example.org", admin, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
+
+func columnNames(columns []models.CRDPrinterColumn) []string {
+	names := make([]string, 0, len(columns))
+	for _, col := range columns {
+		names = append(names, col.Name)
+	}
+	return names
+}
+
+func TestCRDs_CustomResourceTable(t *testing.T) {
+	var dyn *dynamicfake.FakeDynamicClient
+	router := newTestRouter(t, false, withDynamicCluster(&dyn,
+		cronTabCRD(t),
+		cronTabObject("default", "nightly", map[string]interface{}{
+			"cronSpec":   "0 2 * * *",
+			"replicas":   int64(2),
+			"containers": []interface{}{map[string]interface{}{"name": "backup"}, map[string]interface{}{"name": "notify"}},
+		}),
+		cronTabObject("team-a", "hourly", map[string]interface{}{"cronSpec": "0 * * * *"}),
+	))
+	admin := login(t, router, "admin", "admin123")
+
+	table := func(path string) models.CustomResourceTable {
+		t.Helper()
+		w := doRequest(router, http.MethodGet, path, admin, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp models.CustomResourceTable
+		decodeData(t, w.Body.Bytes(), &resp)
+		return resp
+	}
+
+	// 未指定版本时使用存储版本，单元格与打印列一一对应
+	resp := table("/api/v1/crds/crontabs.stable.example.com/namespaces/default/resources")
+	assert.Equal(t, "v1", resp.Version)
+	assert.Equal(t, "CronTab", resp.Kind)
+	assert.True(t, resp.Namespaced)
+	assert.Equal(t, []string{"Spec", "Replicas", "Containers"}, columnNames(resp.Columns))
+	require.Len(t, resp.Rows, 1)
+	assert.Equal(t, "nightly", resp.Rows[0].Name)
+	// 多个值与 kubectl 一样以逗号拼接
+	assert.Equal(t, []interface{}{"0 2 * * *", float64(2), "backup,notify"}, resp.Rows[0].Cells)
+
+	// 不指定命名空间时列出所有命名空间，取不到的值为 null
+	resp = table("/api/v1/crds/crontabs.stable.example.com/resources?sortBy=name")
+	require.Len(t, resp.Rows, 2)
+	assert.Equal(t, "hourly", resp.Rows[0].Name)
+	assert.Equal(t, "team-a", resp.Rows[0].Namespace)
+	assert.Equal(t, []interface{}{"0 * * * *", nil, nil}, resp.Rows[0].Cells)
+
+	for name, version := range map[string]string{"version not served": "v1beta1", "unknown version": "v2"} {
+		t.Run(name, func(t *testing.T) {
+			w := doRequest(router, http.MethodGet, "/api/v1/crds/crontabs.stable.example.com/resources?version="+version, admin, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		})
+	}
+}
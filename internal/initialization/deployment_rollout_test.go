@@ -0,0 +1,148 @@
+package initialization
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// withClusterObjects 把测试集群 "test" 替换为包含 objects 的 fake 集群，返回其客户端以便检查结果
+func withClusterObjects(clientset **fake.Clientset, objects ...runtime.Object) func(*configs.Config, *k8s.ClientManager) {
+	return func(_ *configs.Config, cm *k8s.ClientManager) {
+		objects = append(objects, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+		*clientset = fake.NewSimpleClientset(objects...)
+		cm.AddClient("test", &k8s.Client{Clientset: *clientset})
+	}
+}
+
+func podTemplate(image, hash string) corev1.PodTemplateSpec {
+	labels := map[string]string{"app": "web"}
+	if hash != "" {
+		labels[appsv1.DefaultDeploymentUniqueLabelKey] = hash
+	}
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: labels},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: image}}},
+	}
+}
+
+// rolloutObjects 一个已经发布过三次的 Deployment：当前为版本 3 (web:v3)
+func rolloutObjects() []runtime.Object {
+	replicas := int32(2)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "web", Namespace: "default", UID: types.UID("web-uid"),
+			Annotations: map[string]string{"deployment.kubernetes.io/revision": "3", "kubernetes.io/change-cause": "upgrade to v3"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: podTemplate("web:v3", ""),
+		},
+	}
+	objects := []runtime.Object{deployment}
+	for i, cause := range []string{"initial", "upgrade to v2", "upgrade to v3"} {
+		revision := i + 1
+		rsReplicas := int32(0)
+		if revision == 3 {
+			rsReplicas = replicas
+		}
+		objects = append(objects, &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("web-%d", revision), Namespace: "default",
+				Labels:          map[string]string{"app": "web"},
+				Annotations:     map[string]string{"deployment.kubernetes.io/revision": fmt.Sprint(revision), "kubernetes.io/change-cause": cause},
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+			},
+			Spec: appsv1.ReplicaSetSpec{
+				Replicas: &rsReplicas,
+				Selector: deployment.Spec.Selector,
+				Template: podTemplate(fmt.Sprintf("web:v%d", revision), fmt.Sprintf("hash%d", revision)),
+			},
+		})
+	}
+	// 选择器相同但不属于该 Deployment 的 ReplicaSet 不应出现在历史中
+	objects = append(objects, &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "orphan", Namespace: "default", Labels: map[string]string{"app": "web"},
+			Annotations: map[string]string{"deployment.kubernetes.io/revision": "9"}},
+		Spec: appsv1.ReplicaSetSpec{Selector: deployment.Spec.Selector, Template: podTemplate("other:v1", "")},
+	})
+	return objects
+}
+
+func TestDeploymentRollout_History(t *testing.T) {
+	var clientset *fake.Clientset
+	router := newTestRouter(t, false, withClusterObjects(&clientset, rolloutObjects()...))
+	admin := login(t, router, "admin", "admin123")
+
+	w := doRequest(router, http.MethodGet, "/api/v1/namespaces/default/deployments/web/history", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var history models.DeploymentHistoryResponse
+	decodeData(t, w.Body.Bytes(), &history)
+
+	require.Len(t, history.Revisions, 3)
+	first, latest := history.Revisions[0], history.Revisions[2]
+	assert.EqualValues(t, 1, first.Revision)
+	assert.Empty(t, first.ImageChanges)
+	assert.Equal(t, "upgrade to v3", latest.ChangeCause)
+	assert.True(t, latest.Current)
+	assert.False(t, first.Current)
+	assert.Equal(t, []models.ImageChange{{Container: "app", From: "web:v2", To: "web:v3"}}, latest.ImageChanges)
+}
+
+func TestDeploymentRollout_Rollback(t *testing.T) {
+	var clientset *fake.Clientset
+	router := newTestRouter(t, false, withClusterObjects(&clientset, rolloutObjects()...))
+	admin := login(t, router, "admin", "admin123")
+	rollback := func(query string) (int, models.RollbackDeploymentResponse) {
+		w := doRequest(router, http.MethodPost, "/api/v1/namespaces/default/deployments/web/rollback"+query, admin, nil)
+		var resp models.RollbackDeploymentResponse
+		if w.Code == http.StatusOK {
+			decodeData(t, w.Body.Bytes(), &resp)
+		}
+		return w.Code, resp
+	}
+	live := func() *appsv1.Deployment {
+		deployment, err := clientset.AppsV1().Deployments("default").Get(t.Context(), "web", metav1.GetOptions{})
+		require.NoError(t, err)
+		return deployment
+	}
+
+	code, _ := rollback("?toRevision=7")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = rollback("?toRevision=-1")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// 不指定版本时回滚到上一个版本，模板中不带 pod-template-hash，注解随版本恢复
+	code, resp := rollback("")
+	require.Equal(t, http.StatusOK, code)
+	assert.EqualValues(t, 2, resp.Revision)
+	assert.False(t, resp.Skipped)
+	deployment := live()
+	assert.Equal(t, "web:v2", deployment.Spec.Template.Spec.Containers[0].Image)
+	assert.NotContains(t, deployment.Spec.Template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	assert.Equal(t, "upgrade to v2", deployment.Annotations["kubernetes.io/change-cause"])
+	assert.Equal(t, "3", deployment.Annotations["deployment.kubernetes.io/revision"])
+
+	code, resp = rollback("?toRevision=2")
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, resp.Skipped)
+
+	deployment.Spec.Paused = true
+	_, err := clientset.AppsV1().Deployments("default").Update(t.Context(), deployment, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	code, _ = rollback("?toRevision=1")
+	assert.Equal(t, http.StatusConflict, code)
+}
@@ -0,0 +1,206 @@
+package initialization
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// liveConfigMap 以 unstructured 形式预置的 ConfigMap，与 API Server 返回的字段一致
+func liveConfigMap(name string, data map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"data": data}}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace("default")
+	obj.SetName(name)
+	obj.SetResourceVersion("7")
+	return obj
+}
+
+func postDiff(t *testing.T, router *gin.Engine, path, token, body string) models.DiffResponse {
+	t.Helper()
+	w := postManifest(router, path, token, body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp models.DiffResponse
+	decodeData(t, w.Body.Bytes(), &resp)
+	return resp
+}
+
+func TestDiff_UpdatePreview(t *testing.T) {
+	var dyn *dynamicfake.FakeDynamicClient
+	router := newTestRouter(t, false, withDynamicCluster(&dyn, liveConfigMap("settings", map[string]interface{}{"key": "old", "stale": "x"})))
+	admin := login(t, router, "admin", "admin123")
+
+	// 请求体缺少 apiVersion/kind 时按路径补全
+	resp := postDiff(t, router, "/api/v1/namespaces/default/configmaps/settings/diff", admin,
+		"metadata:\n  name: settings\ndata:\n  key: new\n  extra: \"1\"\n")
+	assert.Equal(t, "v1", resp.APIVersion)
+	assert.Equal(t, "ConfigMap", resp.Kind)
+	assert.Equal(t, models.DiffModeUpdate, resp.Mode)
+	assert.True(t, resp.Exists)
+	assert.True(t, resp.Changed)
+	assert.Equal(t, []models.DiffChange{
+		{Path: "/data/extra", Op: "add", New: "1"},
+		{Path: "/data/key", Op: "replace", Old: "old", New: "new"},
+		{Path: "/data/stale", Op: "remove", Old: "x"},
+	}, resp.Changes)
+	assert.Contains(t, resp.Unified, "-  key: old\n")
+	assert.Contains(t, resp.Unified, "+  key: new\n")
+	// resourceVersion 每次写入都会变化，不参与比较
+	assert.NotContains(t, resp.Unified, "resourceVersion")
+
+	// 提交 dry-run，不修改集群中的对象
+	live, err := dyn.Resource(configMapsGVR).Namespace("default").Get(t.Context(), "settings", metav1.GetOptions{})
+	require.NoError(t, err)
+	value, _, _ := unstructured.NestedString(live.Object, "data", "key")
+	assert.Equal(t, "old", value)
+
+	// 内容不变时没有差异
+	resp = postDiff(t, router, "/api/v1/namespaces/default/configmaps/settings/diff", admin,
+		"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\ndata:\n  key: old\n  stale: x\n")
+	assert.False(t, resp.Changed)
+	assert.Empty(t, resp.Changes)
+	assert.Empty(t, resp.Unified)
+}
+
+func TestDiff_CreateAndApplyModes(t *testing.T) {
+	var dyn *dynamicfake.FakeDynamicClient
+	router := newTestRouter(t, false, withDynamicCluster(&dyn, liveConfigMap("settings", map[string]interface{}{"key": "old"})))
+	admin := login(t, router, "admin", "admin123")
+
+	// 对象不存在时预览创建，整体视为新增
+	resp := postDiff(t, router, "/api/v1/resources/core/v1/configmaps/namespaces/default/fresh/diff", admin, configMapManifest("default", "fresh"))
+	assert.False(t, resp.Exists)
+	assert.True(t, resp.Changed)
+	require.Len(t, resp.Changes, 1)
+	assert.Equal(t, "", resp.Changes[0].Path)
+	assert.Equal(t, "add", resp.Changes[0].Op)
+	assert.Nil(t, resp.Live)
+	assert.Contains(t, resp.Unified, "+  key: value\n")
+	_, err := dyn.Resource(configMapsGVR).Namespace("default").Get(t.Context(), "fresh", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err), "diff 不创建对象")
+
+	// apply 模式下请求体只包含需要修改的字段，其余字段保持不变
+	resp = postDiff(t, router, "/api/v1/namespaces/default/configmaps/settings/diff?mode=apply", admin, "data:\n  extra: \"1\"\n")
+	assert.Equal(t, models.DiffModeApply, resp.Mode)
+	assert.Equal(t, []models.DiffChange{{Path: "/data/extra", Op: "add", New: "1"}}, resp.Changes)
+	applied := applyActions(dyn)
+	require.Len(t, applied, 1)
+	assert.Equal(t, []string{metav1.DryRunAll}, applied[0].PatchOptions.DryRun)
+	assert.Equal(t, "cilikube", applied[0].PatchOptions.FieldManager)
+
+	invalid := map[string]struct{ path, body string }{
+		"unknown mode":                {"/api/v1/namespaces/default/configmaps/settings/diff?mode=replace", "data:\n  key: new\n"},
+		"name differs from path":      {"/api/v1/namespaces/default/configmaps/settings/diff", configMapManifest("default", "other")},
+		"namespace differs from path": {"/api/v1/namespaces/default/configmaps/settings/diff", configMapManifest("team-a", "settings")},
+		"unparseable body":            {"/api/v1/namespaces/default/configmaps/settings/diff", "data: [\n"},
+		"empty body":                  {"/api/v1/namespaces/default/configmaps/settings/diff", ""},
+	}
+	for name, tt := range invalid {
+		t.Run(name, func(t *testing.T) {
+			w := postManifest(router, tt.path, admin, tt.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		})
+	}
+}
+
+// writeActionDryRun 返回 fake 集群收到的某类写请求携带的 dryRun 和字段管理器
+func writeActionDryRun(t *testing.T, clientset *fake.Clientset, verb string) ([]string, string) {
+	t.Helper()
+	actions := clientset.Actions()
+	for i := len(actions) - 1; i >= 0; i-- {
+		if actions[i].GetVerb() != verb || actions[i].GetResource().Resource != "configmaps" {
+			continue
+		}
+		switch action := actions[i].(type) {
+		case k8stesting.CreateActionImpl:
+			return action.CreateOptions.DryRun, action.CreateOptions.FieldManager
+		case k8stesting.UpdateActionImpl:
+			return action.UpdateOptions.DryRun, action.UpdateOptions.FieldManager
+		case k8stesting.PatchActionImpl:
+			return action.PatchOptions.DryRun, action.PatchOptions.FieldManager
+		case k8stesting.DeleteActionImpl:
+			return action.DeleteOptions.DryRun, ""
+		}
+	}
+	t.Fatalf("没有收到 %s configmaps 请求", verb)
+	return nil, ""
+}
+
+func TestDryRun_MutatingEndpoints(t *testing.T) {
+	var clientset *fake.Clientset
+	router := newTestRouter(t, false, withClusterObjects(&clientset,
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default"}, Data: map[string]string{"key": "old"}}))
+	admin := login(t, router, "admin", "admin123")
+
+	settings := func(name string) corev1.ConfigMap {
+		return corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Data:       map[string]string{"key": "new"},
+		}
+	}
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		verb   string
+		dryRun []string
+	}{
+		{"create", http.MethodPost, "/api/v1/namespaces/default/configmaps?dryRun=All", settings("fresh"), "create", []string{metav1.DryRunAll}},
+		{"update with dryRun=true", http.MethodPut, "/api/v1/namespaces/default/configmaps/settings?dryRun=true", settings("settings"), "update", []string{metav1.DryRunAll}},
+		{"patch", http.MethodPatch, "/api/v1/namespaces/default/configmaps/settings?dryRun=All", map[string]interface{}{"data": map[string]string{"key": "patched"}}, "patch", []string{metav1.DryRunAll}},
+		{"dryRun=false writes", http.MethodPut, "/api/v1/namespaces/default/configmaps/settings?dryRun=false", settings("settings"), "update", nil},
+		{"delete", http.MethodDelete, "/api/v1/namespaces/default/configmaps/settings?dryRun=All", nil, "delete", []string{metav1.DryRunAll}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doRequest(router, tt.method, tt.path, admin, tt.body)
+			require.Less(t, w.Code, 300, w.Body.String())
+			dryRun, fieldManager := writeActionDryRun(t, clientset, tt.verb)
+			assert.Equal(t, tt.dryRun, dryRun)
+			if tt.verb != "delete" {
+				assert.Equal(t, "cilikube", fieldManager)
+			}
+		})
+	}
+
+	// 其它 dryRun 值原样交给 API Server 校验
+	doRequest(router, http.MethodDelete, "/api/v1/namespaces/default/configmaps/settings?dryRun=Partial", admin, nil)
+	dryRun, _ := writeActionDryRun(t, clientset, "delete")
+	assert.Equal(t, []string{"Partial"}, dryRun)
+}
+
+func TestDryRun_DynamicResourceNotPersisted(t *testing.T) {
+	var dyn *dynamicfake.FakeDynamicClient
+	router := newTestRouter(t, false, withDynamicCluster(&dyn, liveConfigMap("settings", map[string]interface{}{"key": "old"})))
+	admin := login(t, router, "admin", "admin123")
+	const collection = "/api/v1/resources/core/v1/configmaps/namespaces/default"
+
+	w := postManifest(router, collection+"?dryRun=All", admin, configMapManifest("default", "fresh"))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created unstructured.Unstructured
+	decodeData(t, w.Body.Bytes(), &created.Object)
+	assert.Equal(t, "fresh", created.GetName())
+	_, err := dyn.Resource(configMapsGVR).Namespace("default").Get(t.Context(), "fresh", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+
+	w = doRequest(router, http.MethodPut, collection+"/settings?dryRun=All", admin, liveConfigMap("settings", map[string]interface{}{"key": "new"}).Object)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	live, err := dyn.Resource(configMapsGVR).Namespace("default").Get(t.Context(), "settings", metav1.GetOptions{})
+	require.NoError(t, err)
+	value, _, _ := unstructured.NestedString(live.Object, "data", "key")
+	assert.Equal(t, "old", value)
+}
@@ -0,0 +1,186 @@
+package initialization
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func cpuHPASpec(kind, apiVersion, target string) autoscalingv2.HorizontalPodAutoscalerSpec {
+	minReplicas, utilization := int32(2), int32(80)
+	return autoscalingv2.HorizontalPodAutoscalerSpec{
+		ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: kind, APIVersion: apiVersion, Name: target},
+		MinReplicas:    &minReplicas,
+		MaxReplicas:    10,
+		Metrics: []autoscalingv2.MetricSpec{{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name:   corev1.ResourceCPU,
+				Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: &utilization},
+			},
+		}, {
+			Type: autoscalingv2.PodsMetricSourceType,
+			Pods: &autoscalingv2.PodsMetricSource{
+				Metric: autoscalingv2.MetricIdentifier{Name: "requests_per_second"},
+				Target: autoscalingv2.MetricTarget{Type: autoscalingv2.AverageValueMetricType, AverageValue: resource.NewQuantity(100, resource.DecimalSI)},
+			},
+		}},
+	}
+}
+
+func TestHPA(t *testing.T) {
+	replicas := int32(1)
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas, Template: podTemplate("db:v1", "")},
+	}
+	// 同名但属于其他 API 组的目标不应匹配 StatefulSet
+	foreign := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "foreign", Namespace: "default"},
+		Spec:       cpuHPASpec("StatefulSet", "example.com/v1", "db"),
+	}
+	var clientset *fake.Clientset
+	router := newTestRouter(t, false, withClusterObjects(&clientset, rolledOutDeployment("web"), statefulSet, foreign))
+	admin := login(t, router, "admin", "admin123")
+	withScaleSubresource(clientset, "deployments")
+	base := "/api/v1/namespaces/default/horizontalpodautoscalers"
+
+	w := doRequest(router, http.MethodPost, base, admin, models.CreateHPARequest{Name: "web", Namespace: "default", Spec: cpuHPASpec("Deployment", "apps/v1", "web")})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// 模拟控制器更新状态：只采集到了 CPU 指标
+	hpa, err := clientset.AutoscalingV2().HorizontalPodAutoscalers("default").Get(t.Context(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	current := int32(45)
+	hpa.Status = autoscalingv2.HorizontalPodAutoscalerStatus{
+		CurrentReplicas: 2,
+		DesiredReplicas: 3,
+		CurrentMetrics: []autoscalingv2.MetricStatus{{
+			Type:     autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricStatus{Name: corev1.ResourceCPU, Current: autoscalingv2.MetricValueStatus{AverageUtilization: &current}},
+		}},
+		Conditions: []autoscalingv2.HorizontalPodAutoscalerCondition{{Type: autoscalingv2.AbleToScale, Status: corev1.ConditionTrue, Reason: "SucceededRescale"}},
+	}
+	_, err = clientset.AutoscalingV2().HorizontalPodAutoscalers("default").UpdateStatus(t.Context(), hpa, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	w = doRequest(router, http.MethodGet, base+"/web", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp models.HPAResponse
+	decodeData(t, w.Body.Bytes(), &resp)
+	assert.EqualValues(t, 2, resp.MinReplicas)
+	assert.EqualValues(t, 2, resp.CurrentReplicas)
+	assert.EqualValues(t, 3, resp.DesiredReplicas)
+	assert.Equal(t, []models.HPAMetric{
+		{Type: autoscalingv2.ResourceMetricSourceType, Name: "cpu", Target: "80%", Current: "45%"},
+		{Type: autoscalingv2.PodsMetricSourceType, Name: "requests_per_second", Target: "100 (avg)", Current: "<unknown>"},
+	}, resp.Metrics)
+	require.Len(t, resp.Conditions, 1)
+	assert.Equal(t, "SucceededRescale", resp.Conditions[0].Reason)
+
+	w = doRequest(router, http.MethodGet, base, admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list models.HPAListResponse
+	decodeData(t, w.Body.Bytes(), &list)
+	assert.Len(t, list.Items, 2)
+
+	// 工作负载详情附带 HPA
+	w = doRequest(router, http.MethodGet, "/api/v1/namespaces/default/deployments/web", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var deployment models.DeploymentResponse
+	decodeData(t, w.Body.Bytes(), &deployment)
+	require.NotNil(t, deployment.HPA)
+	assert.Equal(t, models.HPAReference{Name: "web", MinReplicas: 2, MaxReplicas: 10, CurrentReplicas: 2, DesiredReplicas: 3}, *deployment.HPA)
+
+	w = doRequest(router, http.MethodGet, "/api/v1/namespaces/default/statefulsets/db", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var db models.StatefulSetResponse
+	decodeData(t, w.Body.Bytes(), &db)
+	assert.Nil(t, db.HPA)
+
+	// 手动扩缩受 HPA 控制的 Deployment 仍然执行，但带有提示
+	w = doRequest(router, http.MethodPut, "/api/v1/namespaces/default/deployments/web/scale", admin, gin.H{"replicas": 5})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	decodeData(t, w.Body.Bytes(), &deployment)
+	assert.EqualValues(t, 5, deployment.Replicas)
+	assert.Contains(t, deployment.ScaleWarning, "HPA web")
+
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodDelete, base+"/web", admin, nil).Code)
+	w = doRequest(router, http.MethodPut, "/api/v1/namespaces/default/deployments/web/scale", admin, gin.H{"replicas": 4})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	deployment = models.DeploymentResponse{}
+	decodeData(t, w.Body.Bytes(), &deployment)
+	assert.Empty(t, deployment.ScaleWarning)
+	assert.Nil(t, deployment.HPA)
+}
+
+func TestHPA_LookupFailureIsReported(t *testing.T) {
+	var clientset *fake.Clientset
+	router := newTestRouter(t, false, withClusterObjects(&clientset, rolledOutDeployment("web")))
+	admin := login(t, router, "admin", "admin123")
+	withScaleSubresource(clientset, "deployments")
+	clientset.PrependReactor("list", "horizontalpodautoscalers", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(autoscalingv2.Resource("horizontalpodautoscalers"), "", errors.New("no access"))
+	})
+
+	// 工作负载接口本身不受影响，但响应中说明 HPA 状态未知
+	for _, req := range []struct {
+		method string
+		body   interface{}
+	}{{http.MethodGet, nil}, {http.MethodPut, gin.H{"replicas": 3}}} {
+		path := "/api/v1/namespaces/default/deployments/web"
+		if req.method == http.MethodPut {
+			path += "/scale"
+		}
+		w := doRequest(router, req.method, path, admin, req.body)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var deployment models.DeploymentResponse
+		decodeData(t, w.Body.Bytes(), &deployment)
+		assert.Nil(t, deployment.HPA)
+		assert.Empty(t, deployment.ScaleWarning)
+		assert.Contains(t, deployment.HPAWarning, "no access")
+	}
+}
+
+func TestHPA_TargetLookup(t *testing.T) {
+	// scaleTargetRef 未写 apiVersion 时按 apps 组匹配
+	ungrouped := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       cpuHPASpec("Deployment", "", "web"),
+	}
+	var clientset *fake.Clientset
+	router := newTestRouter(t, false, withClusterObjects(&clientset, rolledOutDeployment("web"), ungrouped), withCache(t))
+	admin := login(t, router, "admin", "admin123")
+	withScaleSubresource(clientset, "deployments")
+
+	for i := 0; i < 3; i++ {
+		w := doRequest(router, http.MethodGet, "/api/v1/namespaces/default/deployments/web", admin, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var deployment models.DeploymentResponse
+		decodeData(t, w.Body.Bytes(), &deployment)
+		require.NotNil(t, deployment.HPA)
+		assert.Equal(t, "web", deployment.HPA.Name)
+	}
+	w := doRequest(router, http.MethodPut, "/api/v1/namespaces/default/deployments/web/scale", admin, gin.H{"replicas": 5})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var deployment models.DeploymentResponse
+	decodeData(t, w.Body.Bytes(), &deployment)
+	assert.Contains(t, deployment.ScaleWarning, "HPA web")
+
+	// 查找 HPA 走 informer 缓存，不再每次列出命名空间中的所有 HPA
+	assert.Equal(t, 1, countActions(clientset, "list", "horizontalpodautoscalers"))
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/ciliverse/cilikube/api/v1/routes"
 	"github.com/ciliverse/cilikube/configs"
 	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/audit"
 	"github.com/ciliverse/cilikube/pkg/auth"
 	"github.com/ciliverse/cilikube/pkg/database"
 	"github.com/ciliverse/cilikube/pkg/k8s"
@@ -27,25 +28,17 @@ import (
 
 // AppServices holds all initialized services
 // Moved from main.go
+// Kubernetes services are no longer held here: handlers build them per request
+// from the cluster client resolved by k8s.ClientManager.ClusterMiddleware.
 type AppServices struct {
-	PodService           *service.PodService
-	DeploymentService    *service.DeploymentService
-	DaemonSetService     *service.DaemonSetService
-	ServiceService       *service.ServiceService
-	IngressService       *service.IngressService
-	NetworkPolicyService *service.NetworkPolicyService
-	ConfigMapService     *service.ConfigMapService
-	SecretService        *service.SecretService
-	PVCService           *service.PVCService
-	PVService            *service.PVService
-	StatefulSetService   *service.StatefulSetService
-	NodeService          *service.NodeService
-	NamespaceService     *service.NamespaceService
-	SummaryService       *service.SummaryService
-	EventsService        *service.EventsService
-	RbacService          *service.RbacService
-	InstallerService     service.InstallerService // Non-k8s service
-	AuthService          *service.AuthService     // auth service
+	InstallerService  service.InstallerService   // Non-k8s service
+	AuthService       *service.AuthService       // auth service
+	AuthzService      *service.AuthzService      // 角色、规则与绑定管理
+	OIDCService       *service.OIDCService       // OIDC 单点登录，未启用时为 nil
+	LDAPAuthenticator *service.LDAPAuthenticator // LDAP 认证后端，未启用时为 nil
+	ClusterService    *service.ClusterService    // cluster registry
+	AuditService      *service.AuditService      // 审计日志，audit.disabled 时为 nil
+	Enforcer          *casbin.Enforcer           // Casbin 授权，数据库未启用时为 nil
 }
 
 // AppHandlers holds all initialized handlers
@@ -62,13 +55,29 @@ type AppHandlers struct {
 	PVCHandler           *handlers.PVCHandler
 	PVHandler            *handlers.PVHandler
 	StatefulSetHandler   *handlers.StatefulSetHandler
+	JobHandler           *handlers.JobHandler
+	CronJobHandler       *handlers.CronJobHandler
+	HPAHandler           *handlers.HPAHandler
 	NodeHandler          *handlers.NodeHandler
 	NamespaceHandler     *handlers.NamespaceHandler
 	SummaryHandler       *handlers.SummaryHandler
 	EventsHandler        *handlers.EventsHandler
 	RbacHandler          *handlers.RbacHandler
+	ResourceHandler      *handlers.ResourceHandler
+	CRDHandler           *handlers.CRDHandler
+	ApplyHandler         *handlers.ApplyHandler
 	InstallerHandler     *handlers.InstallerHandler // Non-k8s handlers
 	AuthHandler          *handlers.AuthHandler      // auth handler
+	AuthzHandler         *handlers.AuthzHandler     // authorization admin handler
+	OIDCHandler          *handlers.OIDCHandler      // OIDC single sign-on handler
+	ClusterHandler       *handlers.ClusterHandler   // cluster registry handler
+	AuditHandler         *handlers.AuditHandler     // audit query handler
+
+	// TokenValidator 拒绝已吊销的 token，APITokenResolver 校验 API token；数据库未启用时均为 nil
+	TokenValidator   auth.TokenValidator
+	APITokenResolver auth.APITokenResolver
+	// AuditRecorder 记录 Kubernetes 写操作，关闭审计时为 nil
+	AuditRecorder audit.Recorder
 }
 
 // InitializeRepository initializes the database repository.
@@ -82,9 +91,10 @@ type AppHandlers struct {
 // }
 
 // InitializeServices initializes all application services.
-// K8s-dependent services are only initialized if k8sAvailable is true.
+// Kubernetes services are resolved per request against the target cluster,
+// so only non-k8s services are created here.
 // Moved from main.go
-func InitializeServices(k8sClient *k8s.Client, k8sAvailable bool, cfg *configs.Config) *AppServices {
+func InitializeServices(cfg *configs.Config, clientManager *k8s.ClientManager) *AppServices {
 	log.Println("初始化服务层...")
 	services := &AppServices{}
 
@@ -111,42 +121,62 @@ func InitializeServices(k8sClient *k8s.Client, k8sAvailable bool, cfg *configs.C
 	} else {
 		log.Println("警告: 数据库未启用，相关服务将无法使用。")
 	}
-	// Initialize AuthService
+	// Initialize ClusterService: clusters added through the API live in the
+	// database when it is available, otherwise in the registry file.
+	if database.DB != nil {
+		services.ClusterService = service.NewClusterServiceWithDB(clientManager, database.DB)
+	} else {
+		services.ClusterService = service.NewClusterService(clientManager, cfg.Kubernetes.RegistryFile, cfg.Kubernetes.KubeconfigDir)
+	}
+	if err := services.ClusterService.LoadRegistry(); err != nil {
+		log.Printf("警告: 加载集群登记表失败: %v", err)
+	}
+	log.Println("Cluster 服务初始化完成。")
 
-	// --- Auth Initialization ---
-	// - 这里初始化 AuthService
-	// - 需要确保 database.DB 已经成功连接
-
-	// Initialize K8s-dependent services (conditionally)
-	if k8sAvailable && k8sClient != nil && k8sClient.Clientset != nil {
-		log.Println("Kubernetes 可用，初始化 Kubernetes 相关服务...")
-		// Services requiring rest.Config need special handling
-		if k8sClient.Config == nil {
-			log.Printf("警告: k8sClient.Config 为 nil。需要 rest.Config 的 Kubernetes 服务将无法完全初始化！")
-			// Proceed, as many services only need Clientset
+	// 审计记录优先写入数据库，数据库未启用时写入文件或标准输出
+	if !cfg.Audit.Disabled {
+		auditService, err := service.NewAuditService(cfg.Audit, database.DB)
+		if err != nil {
+			log.Fatalf("初始化审计日志失败: %v", err)
 		}
-
-		// Pass k8sClient.Clientset and potentially k8sClient.Config where needed
-		services.PodService = service.NewPodService(k8sClient.Clientset, k8sClient.Config) // Assuming PodService needs Config
-		services.DeploymentService = service.NewDeploymentService(k8sClient.Clientset)
-		services.DaemonSetService = service.NewDaemonSetService(k8sClient.Clientset)
-		services.ServiceService = service.NewServiceService(k8sClient.Clientset)
-		services.IngressService = service.NewIngressService(k8sClient.Clientset)
-		services.NetworkPolicyService = service.NewNetworkPolicyService(k8sClient.Clientset)
-		services.ConfigMapService = service.NewConfigMapService(k8sClient.Clientset)
-		services.SecretService = service.NewSecretService(k8sClient.Clientset)
-		services.PVCService = service.NewPVCService(k8sClient.Clientset)
-		services.PVService = service.NewPVService(k8sClient.Clientset)
-		services.StatefulSetService = service.NewStatefulSetService(k8sClient.Clientset)
-		services.NodeService = service.NewNodeService(k8sClient.Clientset)
-		services.NamespaceService = service.NewNamespaceService(k8sClient.Clientset)
-		services.SummaryService = service.NewSummaryService(k8sClient.Clientset)
-		services.EventsService = service.NewEventsService(k8sClient.Clientset)
-		services.RbacService = service.NewRbacService(k8sClient.Clientset)
-		log.Println("Kubernetes 相关服务初始化完成。")
+		services.AuditService = auditService
+		log.Println("Audit 服务初始化完成。")
 	} else {
-		log.Println("Kubernetes 不可用，跳过相关服务初始化。")
-		// K8s service pointers in 'services' struct remain nil
+		log.Println("警告: 审计日志已关闭 (audit.disabled)。")
+	}
+
+	// --- Auth Initialization ---
+	// 认证依赖数据库中的用户与 Casbin 策略；未启用数据库时只能显式关闭认证
+	if database.DB != nil {
+		enforcer, err := auth.InitCasbin(database.DB)
+		if err != nil {
+			log.Fatalf("初始化 Casbin 失败: %v", err)
+		}
+		services.Enforcer = enforcer
+		services.ClusterService.SetEnforcer(enforcer)
+		services.AuthService = service.NewAuthService(enforcer)
+		services.AuthzService = service.NewAuthzService(enforcer)
+		if cfg.Auth.OIDC.Enabled {
+			services.OIDCService = service.NewOIDCService(cfg.Auth.OIDC, services.AuthService)
+			log.Printf("OIDC 单点登录已启用，身份提供方: %s", cfg.Auth.OIDC.IssuerURL)
+		}
+		if cfg.Auth.LDAP.Enabled {
+			services.LDAPAuthenticator = service.NewLDAPAuthenticator(cfg.Auth.LDAP, services.AuthService)
+			services.AuthService.AddAuthenticator(services.LDAPAuthenticator)
+			log.Printf("LDAP 认证已启用: %s", cfg.Auth.LDAP.URL)
+		}
+		if err := database.CreateDefaultAdmin(); err != nil {
+			log.Fatalf("初始化失败: %v", err)
+		}
+		if err := services.AuthService.SyncRoleBindings(); err != nil {
+			log.Fatalf("同步用户角色绑定失败: %v", err)
+		}
+		log.Println("Auth 服务初始化完成。")
+	} else if !cfg.Auth.Disabled {
+		log.Fatalf("初始化失败: 认证已启用但数据库不可用。请启用 database，或在开发环境设置 auth.disabled: true")
+	}
+	if cfg.Auth.Disabled {
+		log.Println("警告: 认证已关闭 (auth.disabled)，所有请求将以管理员身份执行，请勿用于生产环境！")
 	}
 
 	log.Println("服务初始化尝试完成。")
@@ -154,7 +184,8 @@ func InitializeServices(k8sClient *k8s.Client, k8sAvailable bool, cfg *configs.C
 }
 
 // InitializeHandlers initializes all application handlers.
-// Handlers are only initialized if their corresponding service is available (non-nil).
+// Kubernetes handlers resolve their services from the request's target cluster,
+// so they are always created; non-k8s handlers depend on their service.
 // Moved from main.go
 func InitializeHandlers(services *AppServices) *AppHandlers {
 	log.Println("初始化处理器层...")
@@ -168,73 +199,65 @@ func InitializeHandlers(services *AppServices) *AppHandlers {
 		log.Println("警告: Installer 服务未初始化，跳过 Installer 处理器初始化。")
 	}
 
-	// Initialize K8s-dependent handlers (conditionally based on service)
-	// Check if the specific service pointer is non-nil
-	if services.PodService != nil {
-		appHandlers.PodHandler = handlers.NewPodHandler(services.PodService)
-	}
-	if services.DeploymentService != nil {
-		appHandlers.DeploymentHandler = handlers.NewDeploymentHandler(services.DeploymentService)
-	}
-	if services.DaemonSetService != nil {
-		appHandlers.DaemonSetHandler = handlers.NewDaemonSetHandler(services.DaemonSetService)
-	}
-	if services.ServiceService != nil {
-		appHandlers.ServiceHandler = handlers.NewServiceHandler(services.ServiceService)
-	}
-	if services.IngressService != nil {
-		appHandlers.IngressHandler = handlers.NewIngressHandler(services.IngressService)
-	}
-	if services.NetworkPolicyService != nil {
-		appHandlers.NetworkPolicyHandler = handlers.NewNetworkPolicyHandler(services.NetworkPolicyService)
+	appHandlers.ClusterHandler = handlers.NewClusterHandler(services.ClusterService)
+	if services.AuthService != nil {
+		appHandlers.AuthHandler = handlers.NewAuthHandler(services.AuthService)
+		appHandlers.TokenValidator = services.AuthService.ValidateToken
+		appHandlers.APITokenResolver = services.AuthService.ResolveAPIToken
 	}
-	if services.ConfigMapService != nil {
-		appHandlers.ConfigMapHandler = handlers.NewConfigMapHandler(services.ConfigMapService)
+	if services.AuthzService != nil {
+		appHandlers.AuthzHandler = handlers.NewAuthzHandler(services.AuthzService)
 	}
-	if services.SecretService != nil {
-		appHandlers.SecretHandler = handlers.NewSecretHandler(services.SecretService)
+	if services.OIDCService != nil {
+		appHandlers.OIDCHandler = handlers.NewOIDCHandler(services.OIDCService)
 	}
-	if services.PVCService != nil {
-		appHandlers.PVCHandler = handlers.NewPVCHandler(services.PVCService)
-	}
-	if services.PVService != nil {
-		appHandlers.PVHandler = handlers.NewPVHandler(services.PVService)
-	}
-	if services.StatefulSetService != nil {
-		appHandlers.StatefulSetHandler = handlers.NewStatefulSetHandler(services.StatefulSetService)
-	}
-	if services.NodeService != nil {
-		appHandlers.NodeHandler = handlers.NewNodeHandler(services.NodeService)
-	}
-	if services.NamespaceService != nil {
-		appHandlers.NamespaceHandler = handlers.NewNamespaceHandler(services.NamespaceService)
-	}
-	if services.SummaryService != nil {
-		appHandlers.SummaryHandler = handlers.NewSummaryHandler(services.SummaryService)
-	}
-	if services.EventsService != nil {
-		appHandlers.EventsHandler = handlers.NewEventsHandler(services.EventsService)
-	}
-	if services.RbacService != nil {
-		appHandlers.RbacHandler = handlers.NewRbacHandler(services.RbacService)
+	if services.AuditService != nil {
+		appHandlers.AuditHandler = handlers.NewAuditHandler(services.AuditService)
+		appHandlers.AuditRecorder = services.AuditService
 	}
 
-	log.Println("处理器初始化尝试完成 (部分可能因服务未初始化而跳过)。")
+	// Initialize K8s-dependent handlers
+	appHandlers.PodHandler = handlers.NewPodHandler()
+	appHandlers.DeploymentHandler = handlers.NewDeploymentHandler()
+	appHandlers.DaemonSetHandler = handlers.NewDaemonSetHandler()
+	appHandlers.ServiceHandler = handlers.NewServiceHandler()
+	appHandlers.IngressHandler = handlers.NewIngressHandler()
+	appHandlers.NetworkPolicyHandler = handlers.NewNetworkPolicyHandler()
+	appHandlers.ConfigMapHandler = handlers.NewConfigMapHandler()
+	appHandlers.SecretHandler = handlers.NewSecretHandler()
+	appHandlers.PVCHandler = handlers.NewPVCHandler()
+	appHandlers.PVHandler = handlers.NewPVHandler()
+	appHandlers.StatefulSetHandler = handlers.NewStatefulSetHandler()
+	appHandlers.JobHandler = handlers.NewJobHandler()
+	appHandlers.CronJobHandler = handlers.NewCronJobHandler()
+	appHandlers.HPAHandler = handlers.NewHPAHandler()
+	appHandlers.NodeHandler = handlers.NewNodeHandler()
+	appHandlers.NamespaceHandler = handlers.NewNamespaceHandler()
+	appHandlers.SummaryHandler = handlers.NewSummaryHandler()
+	appHandlers.EventsHandler = handlers.NewEventsHandler()
+	appHandlers.RbacHandler = handlers.NewRbacHandler()
+	appHandlers.ResourceHandler = handlers.NewResourceHandler()
+	appHandlers.CRDHandler = handlers.NewCRDHandler()
+	appHandlers.ApplyHandler = handlers.NewApplyHandler()
+
+	log.Println("处理器初始化尝试完成。")
 	return appHandlers
 }
 
 // SetupRouter configures the Gin router with middleware and routes.
 // Moved from main.go
-func SetupRouter(cfg *configs.Config, handlers *AppHandlers, k8sAvailable bool, e *casbin.Enforcer) *gin.Engine {
+func SetupRouter(cfg *configs.Config, handlers *AppHandlers, clientManager *k8s.ClientManager, e *casbin.Enforcer) *gin.Engine {
 	log.Println("设置 Gin 路由器...")
 	// gin.SetMode(gin.ReleaseMode) // Uncomment for production
-	router := gin.Default()
+	router := gin.New()
+	// access_token 查询参数在写访问日志之前移除
+	router.Use(auth.QueryTokenMiddleware(), gin.Logger(), gin.Recovery())
 
 	// 从配置或环境变量加载允许的源
 	router.Use(cors.New(cors.Config{
 		AllowAllOrigins:  true,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", k8s.ClusterHeader},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -255,11 +278,13 @@ func SetupRouter(cfg *configs.Config, handlers *AppHandlers, k8sAvailable bool,
 	// Health Check Endpoint
 	router.GET("/healthz", func(c *gin.Context) {
 		healthStatus := gin.H{"status": "ok", "timestamp": time.Now().UTC()}
-		if k8sAvailable {
+		if clientManager.HasHealthyCluster() {
 			healthStatus["kubernetes"] = "connected"
 		} else {
-			healthStatus["kubernetes"] = "disconnected (features disabled)"
+			healthStatus["kubernetes"] = "disconnected (no healthy cluster)"
 		}
+		healthStatus["clusters"] = clientManager.ListClusterHealth()
+		healthStatus["activeCluster"] = clientManager.GetActiveClusterName()
 		c.JSON(http.StatusOK, healthStatus)
 	})
 
@@ -273,145 +298,76 @@ func SetupRouter(cfg *configs.Config, handlers *AppHandlers, k8sAvailable bool,
 	log.Println("注册 API v1 路由...")
 	v1 := router.Group("/api/v1")
 	{
-		// Register non-k8s routes first (if handlers are initialized)
-		// --- Auth Routes ---
-		// 首先先从环境变量中获取 secret key
-		// secretKey := os.Getenv("CILIKUBE_JWT_SECRET")
-
-		// --- Protected Routes ---
-		// 将与 k8s 相关操作放进 v1 中，将 login
-		// 或者其他不需要受保护的路由 Ignore (这里需要注意的事一定要用v1，否则不会生效)
-		log.Println("初始化 JWT 中间件...")
-		// 添加后会用 JWT 中间件来保护 API 路由
-		// v1.Use(auth.JWTAuthMiddleware())
-
-		// --- RBAC Middleware ---
-		// 注册 RBAC 中间件，这个中间件会在每个受保护的路由上应用
-		// log.Println("初始化 RBAC 中间件...")
-		if e != nil {
-			log.Println("应用 RBAC 中间件...")
-			v1.Use(auth.NewCasbinBuilder().
-				IgnorePath("/api/v1/auth/login"). // 忽略登录路径
-				CasbinMiddleware(e))              // 应用 Casbin
-		} else {
-			log.Println("Casbin 未初始化，跳过 RBAC 中间件。")
+		// --- Authentication & Authorization ---
+		// 认证 (JWT) 与授权 (Casbin) 组成一条流水线：
+		//   public        -> 登录、注册、刷新 token，不需要 token
+		//   authenticated -> 需要有效 token，例如修改自己的资料
+		//   protected     -> 需要有效 token，且 Casbin 以当前用户为 subject 放行
+		// Kubernetes 路由的授权在 ClusterMiddleware 之后执行，以便按解析出的目标集群授权
+		authenticate := auth.JWTAuthMiddleware(handlers.TokenValidator, handlers.APITokenResolver)
+		authorize := auth.NewCasbinBuilder().CasbinMiddleware(e)
+		authorizeCluster := auth.NewCasbinBuilder().ClusterResolver(k8s.ClusterNameFromContext).CasbinMiddleware(e)
+		if cfg.Auth.Disabled {
+			log.Println("认证已关闭，跳过 JWT 与 RBAC 中间件。")
+			noop := func(c *gin.Context) { c.Next() }
+			authenticate, authorize, authorizeCluster = auth.DevAuthMiddleware(), noop, noop
+		} else if e == nil {
+			log.Fatalf("认证已启用但 Casbin 未初始化，无法注册受保护的路由：请启用 database，本地开发可设置 auth.disabled: true")
 		}
-
-		// --- Auth Routes ---
-
-		// Register K8s related routes only if handlers were initialized
-		// We check if the specific handlers pointer is non-nil
-		if k8sAvailable { // Optional log: k8sAvailable check here gives context
-			log.Println("注册 Kubernetes API 路由...")
-			if handlers.PodHandler != nil {
-				routes.RegisterPodRoutes(v1, handlers.PodHandler)
-			} else {
-				log.Println("跳过 Pod 路由注册: Handler 未初始化。")
-			} // Optional detailed logs
-			if handlers.DeploymentHandler != nil {
-				routes.RegisterDeploymentRoutes(v1, handlers.DeploymentHandler)
-			} else {
-				log.Println("跳过 Deployment 路由注册: Handler 未初始化。")
-			}
-			if handlers.DaemonSetHandler != nil {
-				routes.RegisterDaemonSetRoutes(v1, handlers.DaemonSetHandler)
-			} else {
-				log.Println("跳过 DaemonSet 路由注册: Handler 未初始化。")
-			}
-			if handlers.ServiceHandler != nil {
-				routes.RegisterServiceRoutes(v1, handlers.ServiceHandler)
-			} else {
-				log.Println("跳过 Service 路由注册: Handler 未初始化。")
-			}
-			if handlers.IngressHandler != nil {
-				routes.RegisterIngressRoutes(v1, handlers.IngressHandler)
-			} else {
-				log.Println("跳过 Ingress 路由注册: Handler 未初始化。")
-			}
-			if handlers.NetworkPolicyHandler != nil {
-				routes.RegisterNetworkPolicyRoutes(v1, handlers.NetworkPolicyHandler)
-			} else {
-				log.Println("跳过 NetworkPolicy 路由注册: Handler 未初始化。")
-			}
-			if handlers.ConfigMapHandler != nil {
-				routes.RegisterConfigMapRoutes(v1, handlers.ConfigMapHandler)
-			} else {
-				log.Println("跳过 ConfigMap 路由注册: Handler 未初始化。")
-			}
-			if handlers.SecretHandler != nil {
-				routes.RegisterSecretRoutes(v1, handlers.SecretHandler)
-			} else {
-				log.Println("跳过 Secret 路由注册: Handler 未初始化。")
-			}
-			if handlers.PVCHandler != nil {
-				routes.RegisterPVCRoutes(v1, handlers.PVCHandler)
-			} else {
-				log.Println("跳过 PVC 路由注册: Handler 未初始化。")
-			}
-			if handlers.PVHandler != nil {
-				routes.RegisterPVRoutes(v1, handlers.PVHandler)
-			} else {
-				log.Println("跳过 PV 路由注册: Handler 未初始化。")
-			}
-			if handlers.StatefulSetHandler != nil {
-				routes.RegisterStatefulSetRoutes(v1, handlers.StatefulSetHandler)
-			} else {
-				log.Println("跳过 StatefulSet 路由注册: Handler 未初始化。")
-			}
-			if handlers.NodeHandler != nil {
-				routes.RegisterNodeRoutes(v1, handlers.NodeHandler)
-			} else {
-				log.Println("跳过 Node 路由注册: Handler 未初始化。")
-			}
-			if handlers.NamespaceHandler != nil {
-				routes.RegisterNamespaceRoutes(v1, handlers.NamespaceHandler)
-			} else {
-				log.Println("跳过 Namespace 路由注册: Handler 未初始化。")
-			}
-			if handlers.SummaryHandler != nil {
-				routes.RegisterSummaryRoutes(v1, handlers.SummaryHandler)
+		if cfg.Auth.Impersonation.Enabled {
+			if cfg.Auth.Disabled {
+				log.Println("警告: 认证已关闭，用户模拟 (auth.impersonation) 不生效。")
 			} else {
-				log.Println("跳过 Summary 路由注册: Handler 未初始化。")
-			}
-			if handlers.EventsHandler != nil {
-				routes.RegisterEventsRoutes(v1, handlers.EventsHandler)
-			} else {
-				log.Println("跳过 Events 路由注册: Handler 未初始化。")
-			}
-			if handlers.RbacHandler != nil {
-				routes.RegisterRbacRoutes(v1, handlers.RbacHandler)
-			} else {
-				log.Println("跳过 Rbac 路由注册: Handler 未初始化。")
-			}
-
-			// Optional check if any K8s routes were registered
-			// This check is still a bit manual, could be more abstract, but works.
-			if handlers.PodHandler == nil && handlers.DeploymentHandler == nil && // ... check all k8s handlers ...
-				handlers.DaemonSetHandler == nil && handlers.ServiceHandler == nil && handlers.IngressHandler == nil &&
-				handlers.NetworkPolicyHandler == nil && handlers.ConfigMapHandler == nil && handlers.SecretHandler == nil &&
-				handlers.PVCHandler == nil && handlers.PVHandler == nil && handlers.StatefulSetHandler == nil &&
-				handlers.NodeHandler == nil && handlers.NamespaceHandler == nil && handlers.SummaryHandler == nil &&
-				handlers.EventsHandler == nil && handlers.RbacHandler == nil {
-				log.Println("警告: Kubernetes 似乎可用，但没有注册任何 Kubernetes API 路由。")
-			} else {
-				log.Println("Kubernetes API 路由注册完成。")
+				log.Println("已开启用户模拟: Kubernetes 请求将以当前用户身份发送，由集群 RBAC 授权。")
+				clientManager.SetIdentityResolver(auth.ImpersonationIdentityResolver(cfg.Auth.Impersonation))
 			}
+		}
+		authenticated := v1.Group("", authenticate)
+		protected := authenticated.Group("", authorize)
 
+		// --- Auth Routes ---
+		if handlers.AuthHandler != nil {
+			routes.RegisterAuthRoutes(v1, authenticated, protected, handlers.AuthHandler)
 		} else {
-			log.Println("Kubernetes 不可用，跳过相关 API 路由注册。")
-			// Register a status endpoint if K8s is unavailable
-			v1.GET("/kubernetes-status", func(c *gin.Context) {
-				c.JSON(http.StatusServiceUnavailable, gin.H{"status": "Kubernetes service unavailable", "details": "Kubernetes client initialization or connection failed"})
-			})
+			log.Println("警告: Auth handlers 未初始化 (数据库未启用)，跳过认证路由注册。")
+		}
+		if handlers.AuthzHandler != nil {
+			routes.RegisterAuthzRoutes(protected, handlers.AuthzHandler)
+		}
+		if handlers.OIDCHandler != nil {
+			routes.RegisterOIDCRoutes(v1, handlers.OIDCHandler)
+		}
+
+		if handlers.AuditHandler != nil {
+			routes.RegisterAuditRoutes(protected, handlers.AuditHandler)
 		}
 
+		// --- Cluster Registry Routes ---
+		routes.RegisterClusterRoutes(protected, handlers.ClusterHandler)
+
+		// Register K8s related routes on two entry points:
+		//   /api/v1/...                  -> X-Cilikube-Cluster 请求头或当前激活集群
+		//   /api/v1/clusters/:cluster/... -> 路径中显式指定的集群
+		// Routes are always registered because clusters can be added or recover at
+		// runtime; ClusterMiddleware answers 503 for missing or unhealthy clusters.
+		log.Println("注册 Kubernetes API 路由...")
+		// 审计在授权之前执行，被拒绝的操作同样会留下记录
+		kubernetesMiddlewares := []gin.HandlerFunc{clientManager.ClusterMiddleware()}
+		if handlers.AuditRecorder != nil {
+			kubernetesMiddlewares = append(kubernetesMiddlewares, audit.Middleware(handlers.AuditRecorder, k8s.ClusterNameFromContext))
+		}
+		kubernetesMiddlewares = append(kubernetesMiddlewares, authorizeCluster)
+		registerKubernetesRoutes(authenticated.Group("", kubernetesMiddlewares...), handlers)
+		registerKubernetesRoutes(authenticated.Group("/clusters/:"+k8s.ClusterParam, kubernetesMiddlewares...), handlers)
+		log.Println("Kubernetes API 路由注册完成。")
+
 		// Always register non-k8s routes if handlers exists
 		log.Println("注册非 Kubernetes API 路由...")
 		if handlers.InstallerHandler != nil {
-			routes.RegisterInstallerRoutes(v1, handlers.InstallerHandler)
+			routes.RegisterInstallerRoutes(protected, handlers.InstallerHandler)
 		} else {
 			log.Println("警告: Installer handlers 未初始化，无法注册相关路由。")
-			v1.GET("/installer-status", func(c *gin.Context) {
+			protected.GET("/installer-status", func(c *gin.Context) {
 				c.JSON(http.StatusInternalServerError, gin.H{"status": "Installer service unavailable", "details": "Installer handlers not initialized"})
 			})
 		}
@@ -420,6 +376,32 @@ func SetupRouter(cfg *configs.Config, handlers *AppHandlers, k8sAvailable bool,
 	return router
 }
 
+// registerKubernetesRoutes 在给定路由组上注册所有 Kubernetes 资源路由
+func registerKubernetesRoutes(group *gin.RouterGroup, handlers *AppHandlers) {
+	routes.RegisterPodRoutes(group, handlers.PodHandler)
+	routes.RegisterDeploymentRoutes(group, handlers.DeploymentHandler)
+	routes.RegisterDaemonSetRoutes(group, handlers.DaemonSetHandler)
+	routes.RegisterServiceRoutes(group, handlers.ServiceHandler)
+	routes.RegisterIngressRoutes(group, handlers.IngressHandler)
+	routes.RegisterNetworkPolicyRoutes(group, handlers.NetworkPolicyHandler)
+	routes.RegisterConfigMapRoutes(group, handlers.ConfigMapHandler)
+	routes.RegisterSecretRoutes(group, handlers.SecretHandler)
+	routes.RegisterPVCRoutes(group, handlers.PVCHandler)
+	routes.RegisterPVRoutes(group, handlers.PVHandler)
+	routes.RegisterStatefulSetRoutes(group, handlers.StatefulSetHandler)
+	routes.RegisterJobRoutes(group, handlers.JobHandler)
+	routes.RegisterCronJobRoutes(group, handlers.CronJobHandler)
+	routes.RegisterHPARoutes(group, handlers.HPAHandler)
+	routes.RegisterNodeRoutes(group, handlers.NodeHandler)
+	routes.RegisterNamespaceRoutes(group, handlers.NamespaceHandler)
+	routes.RegisterSummaryRoutes(group, handlers.SummaryHandler)
+	routes.RegisterEventsRoutes(group, handlers.EventsHandler)
+	routes.RegisterRbacRoutes(group, handlers.RbacHandler)
+	routes.RegisterResourceRoutes(group, handlers.ResourceHandler)
+	routes.RegisterCRDRoutes(group, handlers.CRDHandler)
+	routes.RegisterApplyRoutes(group, handlers.ApplyHandler)
+}
+
 // InitializeDefaultConfig 初始化默认配置
 
 // InitializeDefaultUser 创建超级管理员账户和游客账户
@@ -0,0 +1,271 @@
+package initialization
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func nightlyCronJob() *batchv1.CronJob {
+	return &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default", UID: types.UID("nightly-uid")},
+		Spec: batchv1.CronJobSpec{
+			Schedule: "0 2 * * *",
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "data"}},
+				Spec:       batchv1.JobSpec{Template: podTemplate("etl:v1", "")},
+			},
+		},
+	}
+}
+
+// finishedJob 在 age 之前结束的 Job；phase 为空表示仍在运行
+func finishedJob(name, phase string, age time.Duration, owner *batchv1.CronJob) *batchv1.Job {
+	created := metav1.NewTime(time.Now().Add(-age - time.Minute))
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: created, Labels: map[string]string{"team": "data"}},
+		Spec:       batchv1.JobSpec{Template: podTemplate("etl:v1", "")},
+	}
+	if owner != nil {
+		job.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(owner, batchv1.SchemeGroupVersion.WithKind("CronJob"))}
+	}
+	conditionType := map[string]batchv1.JobConditionType{models.JobPhaseComplete: batchv1.JobComplete, models.JobPhaseFailed: batchv1.JobFailed}[phase]
+	if conditionType != "" {
+		job.Status.Conditions = []batchv1.JobCondition{{Type: conditionType, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(time.Now().Add(-age))}}
+	}
+	return job
+}
+
+func TestCronJobTriggerSuspendAndHistory(t *testing.T) {
+	cronJob := nightlyCronJob()
+	var clientset *fake.Clientset
+	router := newTestRouter(t, false, withClusterObjects(&clientset, cronJob,
+		finishedJob("nightly-1", models.JobPhaseFailed, 3*time.Hour, cronJob),
+		finishedJob("nightly-2", models.JobPhaseComplete, 2*time.Hour, cronJob),
+		finishedJob("nightly-3", "", time.Hour, cronJob),
+		finishedJob("adhoc", models.JobPhaseComplete, time.Hour, nil),
+	))
+	admin := login(t, router, "admin", "admin123")
+	base := "/api/v1/namespaces/default/cronjobs/nightly"
+
+	w := doRequest(router, http.MethodGet, base+"/history", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var history models.CronJobHistoryResponse
+	decodeData(t, w.Body.Bytes(), &history)
+	require.Len(t, history.Jobs, 3)
+	assert.Equal(t, []string{"nightly-3", "nightly-2", "nightly-1"}, []string{history.Jobs[0].Name, history.Jobs[1].Name, history.Jobs[2].Name})
+	assert.Equal(t, "nightly", history.Jobs[0].CronJob)
+	assert.Equal(t, 1, history.Running)
+	assert.Equal(t, 1, history.Succeeded)
+	assert.Equal(t, 1, history.Failed)
+
+	// 立即运行：Job 来自 jobTemplate，归属于 CronJob
+	w = doRequest(router, http.MethodPost, base+"/trigger", admin, nil)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var triggered models.JobResponse
+	decodeData(t, w.Body.Bytes(), &triggered)
+	assert.Regexp(t, `^nightly-manual-[a-z0-9]{5}$`, triggered.Name)
+	job, err := clientset.BatchV1().Jobs("default").Get(t.Context(), triggered.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "manual", job.Annotations[service.ManualInstantiateAnnotation])
+	assert.Equal(t, "data", job.Labels["team"])
+	assert.True(t, metav1.IsControlledBy(job, cronJob))
+	assert.Equal(t, "etl:v1", job.Spec.Template.Spec.Containers[0].Image)
+
+	w = doRequest(router, http.MethodPost, base+"/trigger", admin, models.TriggerCronJobRequest{JobName: "nightly-rerun"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, http.StatusConflict, doRequest(router, http.MethodPost, base+"/trigger", admin, models.TriggerCronJobRequest{JobName: "nightly-rerun"}).Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(router, http.MethodPost, "/api/v1/namespaces/default/cronjobs/missing/trigger", admin, nil).Code)
+
+	w = doRequest(router, http.MethodPost, base+"/suspend", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp models.CronJobResponse
+	decodeData(t, w.Body.Bytes(), &resp)
+	assert.True(t, resp.Suspended)
+	w = doRequest(router, http.MethodPost, base+"/resume", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	decodeData(t, w.Body.Bytes(), &resp)
+	assert.False(t, resp.Suspended)
+}
+
+func TestJobCleanup(t *testing.T) {
+	var clientset *fake.Clientset
+	router := newTestRouter(t, false, withClusterObjects(&clientset,
+		finishedJob("old-failed", models.JobPhaseFailed, 48*time.Hour, nil),
+		finishedJob("old-complete", models.JobPhaseComplete, 48*time.Hour, nil),
+		finishedJob("new-complete", models.JobPhaseComplete, time.Minute, nil),
+		finishedJob("running", "", 72*time.Hour, nil),
+	))
+	admin := login(t, router, "admin", "admin123")
+	cleanup := func(query string) []string {
+		w := doRequest(router, http.MethodDelete, "/api/v1/namespaces/default/jobs"+query, admin, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp models.CleanupJobsResponse
+		decodeData(t, w.Body.Bytes(), &resp)
+		return resp.Deleted
+	}
+
+	assert.Equal(t, http.StatusBadRequest, doRequest(router, http.MethodDelete, "/api/v1/namespaces/default/jobs?status=running", admin, nil).Code)
+	assert.Equal(t, http.StatusBadRequest, doRequest(router, http.MethodDelete, "/api/v1/namespaces/default/jobs?olderThan=yesterday", admin, nil).Code)
+
+	assert.Equal(t, []string{"old-failed"}, cleanup("?status=failed"))
+	assert.Equal(t, []string{"old-complete"}, cleanup("?olderThan=24h"))
+	assert.Equal(t, []string{"new-complete"}, cleanup(""))
+
+	remaining, err := clientset.BatchV1().Jobs("default").List(t.Context(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, remaining.Items, 1)
+	assert.Equal(t, "running", remaining.Items[0].Name)
+
+	// Job 的删除会级联删除 Pod
+	var policies []metav1.DeletionPropagation
+	for _, action := range clientset.Actions() {
+		if deleteAction, ok := action.(k8stesting.DeleteAction); ok && action.GetResource().Resource == "jobs" {
+			require.NotNil(t, deleteAction.GetDeleteOptions().PropagationPolicy)
+			policies = append(policies, *deleteAction.GetDeleteOptions().PropagationPolicy)
+		}
+	}
+	assert.Equal(t, []metav1.DeletionPropagation{metav1.DeletePropagationBackground, metav1.DeletePropagationBackground, metav1.DeletePropagationBackground}, policies)
+
+	// 普通用户没有 jobs 的 delete 权限
+	register(t, router, "erin", "erin-password")
+	erin := login(t, router, "erin", "erin-password")
+	assert.Equal(t, http.StatusForbidden, doRequest(router, http.MethodDelete, "/api/v1/namespaces/default/jobs", erin, nil).Code)
+}
+
+func TestJobCRUDPodsAndLogs(t *testing.T) {
+	pod := func(name string, age time.Duration) runtime.Object {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"job-name": "etl"},
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age))},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "etl:v1"}}},
+		}
+	}
+	var clientset *fake.Clientset
+	router := newTestRouter(t, false, withClusterObjects(&clientset, pod("etl-old", time.Hour), pod("etl-new", time.Minute)))
+	admin := login(t, router, "admin", "admin123")
+
+	w := doRequest(router, http.MethodPost, "/api/v1/namespaces/default/jobs", admin, models.CreateJobRequest{
+		Name: "etl", Namespace: "default", Spec: batchv1.JobSpec{Template: podTemplate("etl:v1", "")},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var created models.JobResponse
+	decodeData(t, w.Body.Bytes(), &created)
+	assert.Equal(t, models.JobPhaseRunning, created.Phase)
+
+	w = doRequest(router, http.MethodGet, "/api/v1/namespaces/default/jobs/etl/pods", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var pods models.PodListResponse
+	decodeData(t, w.Body.Bytes(), &pods)
+	require.Len(t, pods.Items, 2)
+	assert.Equal(t, "etl-new", pods.Items[0].Name)
+
+	w = doRequest(router, http.MethodGet, "/api/v1/namespaces/default/jobs/etl/logs", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "data: fake logs")
+	assert.Equal(t, http.StatusNotFound, doRequest(router, http.MethodGet, "/api/v1/namespaces/default/jobs/etl/logs?pod=other", admin, nil).Code)
+
+	w = doRequest(router, http.MethodPost, "/api/v1/namespaces/default/jobs/etl/suspend", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	decodeData(t, w.Body.Bytes(), &created)
+	assert.Equal(t, models.JobPhaseSuspended, created.Phase)
+
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodDelete, "/api/v1/namespaces/default/jobs/etl", admin, nil).Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(router, http.MethodGet, "/api/v1/namespaces/default/jobs/etl", admin, nil).Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(router, http.MethodGet, "/api/v1/namespaces/default/cronjobs/missing", admin, nil).Code)
+}
+
+func TestCronJobHistory_SelectorAndCache(t *testing.T) {
+	cronJob := nightlyCronJob()
+	other := finishedJob("other-team", models.JobPhaseComplete, time.Hour, cronJob)
+	other.Labels = map[string]string{"team": "web"}
+	var clientset *fake.Clientset
+	var manager *k8s.ClientManager
+	router := newTestRouter(t, false, withClusterObjects(&clientset, cronJob,
+		finishedJob("nightly-1", models.JobPhaseComplete, 2*time.Hour, cronJob),
+		finishedJob("nightly-2", models.JobPhaseComplete, time.Hour, cronJob),
+		finishedJob("adhoc", models.JobPhaseComplete, time.Hour, nil),
+		other,
+	), func(_ *configs.Config, cm *k8s.ClientManager) { manager = cm })
+	admin := login(t, router, "admin", "admin123")
+
+	// selectorLists 记录按 jobTemplate 标签过滤的 Job list 请求
+	var selectorLists []string
+	clientset.PrependReactor("list", "jobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if selector := action.(k8stesting.ListAction).GetListRestrictions().Labels.String(); selector != "" {
+			selectorLists = append(selectorLists, selector)
+		}
+		return false, nil, nil
+	})
+	history := func() []string {
+		t.Helper()
+		w := doRequest(router, http.MethodGet, "/api/v1/namespaces/default/cronjobs/nightly/history", admin, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp models.CronJobHistoryResponse
+		decodeData(t, w.Body.Bytes(), &resp)
+		names := make([]string, 0, len(resp.Jobs))
+		for _, job := range resp.Jobs {
+			names = append(names, job.Name)
+		}
+		return names
+	}
+
+	// 不使用缓存时只按 jobTemplate 的标签向 API Server 列出 Job
+	assert.Equal(t, []string{"nightly-2", "nightly-1"}, history())
+	assert.Equal(t, []string{"team=data"}, selectorLists)
+
+	// 启用缓存后从 informer 读取，不再发出带选择器的 list 请求
+	manager.SetCacheEnabled(true)
+	client, err := manager.GetClientByName("test")
+	require.NoError(t, err)
+	t.Cleanup(client.StopCache)
+	selectorLists = nil
+	assert.Equal(t, []string{"nightly-2", "nightly-1"}, history())
+	assert.Empty(t, selectorLists)
+}
+
+func TestJobCleanup_PartialFailure(t *testing.T) {
+	var clientset *fake.Clientset
+	router := newTestRouter(t, false, withClusterObjects(&clientset,
+		finishedJob("a-done", models.JobPhaseComplete, time.Hour, nil),
+		finishedJob("b-done", models.JobPhaseComplete, time.Hour, nil),
+		finishedJob("c-done", models.JobPhaseComplete, time.Hour, nil),
+	))
+	admin := login(t, router, "admin", "admin123")
+	clientset.PrependReactor("delete", "jobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.DeleteAction).GetName() == "b-done" {
+			return true, nil, apierrors.NewInternalError(errors.New("etcd unavailable"))
+		}
+		return false, nil, nil
+	})
+
+	// 中途失败时错误响应仍然带上已经删除的 Job
+	w := doRequest(router, http.MethodDelete, "/api/v1/namespaces/default/jobs", admin, nil)
+	require.Equal(t, http.StatusInternalServerError, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "etcd unavailable")
+	var resp models.CleanupJobsResponse
+	decodeData(t, w.Body.Bytes(), &resp)
+	assert.Equal(t, []string{"a-done"}, resp.Deleted)
+
+	remaining, err := clientset.BatchV1().Jobs("default").List(t.Context(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, remaining.Items, 2)
+	assert.Equal(t, "b-done", remaining.Items[0].Name)
+	assert.Equal(t, "c-done", remaining.Items[1].Name)
+}
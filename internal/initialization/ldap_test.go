@@ -0,0 +1,130 @@
+package initialization
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/database"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/gin-gonic/gin"
+	"github.com/jimlambrt/gldap"
+	"github.com/jimlambrt/gldap/testdirectory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startTestDirectory 启动内存 LDAP 服务器：dave 属于 cilikube-admins 与 team-a，erin 只属于 team-a，密码均为 "password"
+func startTestDirectory(t *testing.T) *testdirectory.Directory {
+	t.Helper()
+	return testdirectory.Start(t,
+		testdirectory.WithNoTLS(t),
+		testdirectory.WithDefaults(t, &testdirectory.Defaults{
+			AllowAnonymousBind: true,
+			Users:              testdirectory.NewUsers(t, []string{"dave", "erin"}),
+			Groups: []*gldap.Entry{
+				testdirectory.NewGroup(t, "cilikube-admins", []string{"dave"}),
+				testdirectory.NewGroup(t, "team-a", []string{"dave", "erin"}),
+			},
+		}),
+	)
+}
+
+func ldapTestConfig(url string) func(*configs.Config, *k8s.ClientManager) {
+	return func(cfg *configs.Config, _ *k8s.ClientManager) {
+		cfg.Auth.LDAP = configs.LDAPConfig{
+			Enabled:        true,
+			URL:            url,
+			BaseDN:         testdirectory.DefaultUserDN,
+			UserFilter:     "(cn=%s)",
+			EmailAttribute: "email",
+			GroupBaseDN:    testdirectory.DefaultGroupDN,
+			GroupRoles:     map[string]string{"cilikube-admins": models.RoleAdmin, "team-a": "team-a"},
+			Timeout:        5 * time.Second,
+		}
+	}
+}
+
+func ldapLogin(t *testing.T, router *gin.Engine, username, password string) (int, models.LoginResponse) {
+	t.Helper()
+	w := doRequest(router, http.MethodPost, "/api/v1/auth/login", "", models.LoginRequest{Username: username, Password: password})
+	var resp struct {
+		Data models.LoginResponse `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w.Code, resp.Data
+}
+
+func hasBinding(t *testing.T, services *AppServices, username, role string) bool {
+	t.Helper()
+	has, err := services.Enforcer.HasGroupingPolicy(username, auth.RoleSubject(role), auth.Wildcard)
+	require.NoError(t, err)
+	return has
+}
+
+func TestLDAPLogin_ProvisionsUserAndMapsGroups(t *testing.T) {
+	directory := startTestDirectory(t)
+	router, services := newTestServer(t, false, ldapTestConfig(fmt.Sprintf("ldap://%s:%d", directory.Host(), directory.Port())))
+
+	code, resp := ldapLogin(t, router, "dave", "password")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "dave", resp.User.Username)
+	assert.Equal(t, "dave@example.com", resp.User.Email)
+	assert.Equal(t, models.UserSourceLDAP, resp.User.Source)
+	assert.Equal(t, models.RoleAdmin, resp.User.Role)
+	assert.True(t, hasBinding(t, services, "dave", "team-a"))
+
+	code, resp = ldapLogin(t, router, "erin", "password")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, models.RoleUser, resp.User.Role)
+
+	code, _ = ldapLogin(t, router, "dave", "wrong-password")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = ldapLogin(t, router, "nobody", "password")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	// 本地账号仍然优先
+	code, _ = ldapLogin(t, router, "admin", "admin123")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestLDAPGroupSync(t *testing.T) {
+	directory := startTestDirectory(t)
+	router, services := newTestServer(t, false, ldapTestConfig(fmt.Sprintf("ldap://%s:%d", directory.Host(), directory.Port())))
+
+	code, _ := ldapLogin(t, router, "dave", "password")
+	require.Equal(t, http.StatusOK, code)
+
+	// dave 被移出所有组：同步后降为普通用户并解除 team-a 绑定
+	directory.SetGroups(testdirectory.NewGroup(t, "team-a", []string{"erin"}))
+	require.NoError(t, services.LDAPAuthenticator.SyncGroups())
+
+	var dave models.User
+	require.NoError(t, database.DB.Where("username = ?", "dave").First(&dave).Error)
+	assert.Equal(t, models.RoleUser, dave.Role)
+	assert.False(t, hasBinding(t, services, "dave", "team-a"))
+	assert.False(t, hasBinding(t, services, "dave", models.RoleAdmin))
+	assert.True(t, hasBinding(t, services, "dave", models.RoleUser))
+
+	// dave 从目录中删除：同步后被禁用
+	directory.SetUsers(testdirectory.NewUsers(t, []string{"erin"})...)
+	require.NoError(t, services.LDAPAuthenticator.SyncGroups())
+	require.NoError(t, database.DB.Where("username = ?", "dave").First(&dave).Error)
+	assert.False(t, dave.IsActive)
+}
+
+func TestLDAPLogin_LocalAccountsWorkWhenLDAPIsDown(t *testing.T) {
+	router := newTestRouter(t, false, ldapTestConfig(fmt.Sprintf("ldap://127.0.0.1:%d", testdirectory.FreePort(t))))
+
+	code, _ := ldapLogin(t, router, "admin", "admin123")
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = ldapLogin(t, router, "dave", "password")
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
@@ -0,0 +1,105 @@
+package initialization
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/database"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func register(t *testing.T, router *gin.Engine, username, password string) {
+	t.Helper()
+	w := doRequest(router, http.MethodPost, "/api/v1/auth/register", "", models.RegisterRequest{Username: username, Email: username + "@example.com", Password: password})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	router := newTestRouter(t, false)
+	for _, password := range []string{"short-1", "onlylowercase", "12345678901"} {
+		w := doRequest(router, http.MethodPost, "/api/v1/auth/register", "", models.RegisterRequest{Username: "alice", Email: "alice@example.com", Password: password})
+		assert.Equal(t, http.StatusBadRequest, w.Code, password)
+	}
+	register(t, router, "alice", "alice-password")
+	alice := login(t, router, "alice", "alice-password")
+
+	changePassword := func(oldPassword, newPassword string) int {
+		return doRequest(router, http.MethodPost, "/api/v1/auth/change-password", alice, models.ChangePasswordRequest{OldPassword: oldPassword, NewPassword: newPassword}).Code
+	}
+	assert.Equal(t, http.StatusBadRequest, changePassword("alice-password", "alice-password"))
+	require.Equal(t, http.StatusOK, changePassword("alice-password", "alice-password-2"))
+	alice = login(t, router, "alice", "alice-password-2")
+	require.Equal(t, http.StatusOK, changePassword("alice-password-2", "alice-password-3"))
+	alice = login(t, router, "alice", "alice-password-3")
+
+	// 默认保留最近 3 次 (含当前) 的密码
+	assert.Equal(t, http.StatusBadRequest, changePassword("alice-password-3", "alice-password"))
+	assert.Equal(t, http.StatusBadRequest, changePassword("alice-password-3", "alice-password-2"))
+	require.Equal(t, http.StatusOK, changePassword("alice-password-3", "alice-password-4"))
+	alice = login(t, router, "alice", "alice-password-4")
+	assert.Equal(t, http.StatusOK, changePassword("alice-password-4", "alice-password"))
+}
+
+func TestLoginLockoutAndAudit(t *testing.T) {
+	router := newTestRouter(t, false)
+	register(t, router, "bob", "bob-password")
+
+	for i := 0; i < 5; i++ {
+		w := doRequest(router, http.MethodPost, "/api/v1/auth/login", "", models.LoginRequest{Username: "bob", Password: fmt.Sprintf("wrong-%d", i)})
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+	// 锁定期间密码正确也拒绝
+	w := doRequest(router, http.MethodPost, "/api/v1/auth/login", "", models.LoginRequest{Username: "bob", Password: "bob-password"})
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// 同一 IP 上的其他用户不受影响
+	admin := login(t, router, "admin", "admin123")
+
+	var page struct {
+		Events []models.LoginEvent `json:"events"`
+		Total  int64               `json:"total"`
+	}
+	w = doRequest(router, http.MethodGet, "/api/v1/auth/login-events?username=bob&success=false", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	decodeData(t, w.Body.Bytes(), &page)
+	require.EqualValues(t, 6, page.Total)
+	assert.Equal(t, "locked", page.Events[0].Reason)
+	assert.Equal(t, "invalid_credentials", page.Events[1].Reason)
+	assert.NotEmpty(t, page.Events[0].IP)
+
+	w = doRequest(router, http.MethodGet, "/api/v1/auth/login-events?username=admin&success=true", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	decodeData(t, w.Body.Bytes(), &page)
+	assert.NotZero(t, page.Total)
+	assert.Equal(t, models.LoginMethodPassword, page.Events[0].Method)
+
+	// 审计只对管理员开放
+	register(t, router, "carol", "carol-password")
+	carol := login(t, router, "carol", "carol-password")
+	assert.Equal(t, http.StatusForbidden, doRequest(router, http.MethodGet, "/api/v1/auth/login-events", carol, nil).Code)
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, "/api/v1/auth/login-events", admin, nil).Code)
+}
+
+func TestDefaultAdminMustChangePassword(t *testing.T) {
+	router := newTestRouter(t, false)
+	// 恢复 CreateDefaultAdmin 创建时的状态
+	require.NoError(t, database.DB.Model(&models.User{}).Where("username = ?", "admin").Update("must_change_password", true).Error)
+
+	resp := passwordLogin(t, router, "admin", "admin123")
+	assert.True(t, resp.User.MustChangePassword)
+	admin := resp.Token
+	assert.Equal(t, http.StatusForbidden, doRequest(router, http.MethodGet, "/api/v1/auth/users", admin, nil).Code)
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, "/api/v1/auth/profile", admin, nil).Code)
+
+	w := doRequest(router, http.MethodPost, "/api/v1/auth/change-password", admin, models.ChangePasswordRequest{OldPassword: "admin123", NewPassword: "Admin-Passw0rd"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	resp = passwordLogin(t, router, "admin", "Admin-Passw0rd")
+	assert.False(t, resp.User.MustChangePassword)
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, "/api/v1/auth/users", resp.Token, nil).Code)
+}
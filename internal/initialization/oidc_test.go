@@ -0,0 +1,231 @@
+package initialization
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/pkg/database"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/gin-gonic/gin"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testOIDCClientID    = "cilikube"
+	testOIDCRedirectURL = "http://cilikube.test/api/v1/auth/oidc/callback"
+)
+
+// fakeIdP 进程内的 OIDC 身份提供方：discovery、JWKS、授权 (直接以 user 登录) 与 token 接口
+type fakeIdP struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	user  map[string]interface{} // 下一次登录的 ID Token 声明
+	codes map[string]fakeAuthCode
+}
+
+type fakeAuthCode struct {
+	nonce     string
+	challenge string
+	claims    map[string]interface{}
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	idp := &fakeIdP{key: key, codes: map[string]fakeAuthCode{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"issuer":                                idp.URL,
+			"authorization_endpoint":                idp.URL + "/authorize",
+			"token_endpoint":                        idp.URL + "/token",
+			"jwks_uri":                              idp.URL + "/keys",
+			"response_types_supported":              []string{"code"},
+			"subject_types_supported":               []string{"public"},
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &key.PublicKey, KeyID: "test", Algorithm: "RS256", Use: "sig"}}})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		code := "code-" + q.Get("state")
+		idp.mu.Lock()
+		idp.codes[code] = fakeAuthCode{nonce: q.Get("nonce"), challenge: q.Get("code_challenge"), claims: idp.user}
+		idp.mu.Unlock()
+		redirect, _ := url.Parse(q.Get("redirect_uri"))
+		redirect.RawQuery = url.Values{"code": {code}, "state": {q.Get("state")}}.Encode()
+		http.Redirect(w, r, redirect.String(), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		idp.mu.Lock()
+		authCode, ok := idp.codes[r.PostForm.Get("code")]
+		delete(idp.codes, r.PostForm.Get("code"))
+		idp.mu.Unlock()
+		verifierHash := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if !ok || base64.RawURLEncoding.EncodeToString(verifierHash[:]) != authCode.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			writeJSON(w, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idp.sign(t, authCode.nonce, authCode.claims),
+		})
+	})
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+func (idp *fakeIdP) sign(t *testing.T, nonce string, user map[string]interface{}) string {
+	claims := map[string]interface{}{
+		"iss":   idp.URL,
+		"aud":   testOIDCClientID,
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": nonce,
+	}
+	for k, v := range user {
+		claims[k] = v
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: jose.JSONWebKey{Key: idp.key, KeyID: "test"}}, (&jose.SignerOptions{}).WithType("JWT"))
+	require.NoError(t, err)
+	payload, _ := json.Marshal(claims)
+	jws, err := signer.Sign(payload)
+	require.NoError(t, err)
+	token, err := jws.CompactSerialize()
+	require.NoError(t, err)
+	return token
+}
+
+// login 以 user 的身份走一遍授权码流程，返回回调的响应
+func (idp *fakeIdP) login(t *testing.T, router *gin.Engine, user map[string]interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	idp.mu.Lock()
+	idp.user = user
+	idp.mu.Unlock()
+
+	start := doRequest(router, http.MethodGet, "/api/v1/auth/oidc/login", "", nil)
+	require.Equal(t, http.StatusFound, start.Code, start.Body.String())
+
+	noFollow := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := noFollow.Get(start.Header().Get("Location"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, "/api/v1/auth/oidc/callback", callback.Path)
+
+	req := httptest.NewRequest(http.MethodGet, callback.RequestURI(), nil)
+	for _, cookie := range start.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newOIDCTestRouter(t *testing.T, idp *fakeIdP) *gin.Engine {
+	return newTestRouter(t, false, func(cfg *configs.Config, _ *k8s.ClientManager) {
+		cfg.Auth.OIDC = configs.OIDCConfig{
+			Enabled:     true,
+			IssuerURL:   idp.URL,
+			ClientID:    testOIDCClientID,
+			RedirectURL: testOIDCRedirectURL,
+			GroupRoles:  map[string]string{"platform-admins": models.RoleAdmin, "team-a": "team-a"},
+		}
+	})
+}
+
+func TestOIDCLogin_ProvisionsUserAndMapsGroups(t *testing.T) {
+	idp := newFakeIdP(t)
+	router := newOIDCTestRouter(t, idp)
+	carol := map[string]interface{}{"sub": "1234", "preferred_username": "carol", "email": "carol@example.com", "groups": []string{"platform-admins", "team-a"}}
+
+	w := idp.login(t, router, carol)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Data models.LoginResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "carol", resp.Data.User.Username)
+	assert.Equal(t, models.RoleAdmin, resp.Data.User.Role)
+	assert.Equal(t, models.UserSourceOIDC, resp.Data.User.Source)
+
+	// 签发的是普通的 CiliKube token
+	w = doRequest(router, http.MethodGet, "/api/v1/auth/users", resp.Data.Token, nil)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doRequest(router, http.MethodGet, "/api/v1/authz/bindings?user=carol&role=team-a", resp.Data.Token, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"role":"team-a"`)
+
+	// 再次登录时按新的组同步角色，用户不重复创建
+	carol["groups"] = []string{}
+	w = idp.login(t, router, carol)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, models.RoleUser, resp.Data.User.Role)
+	var count int64
+	database.DB.Model(&models.User{}).Where("username = ?", "carol").Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	adminToken := login(t, router, "admin", "admin123")
+	w = doRequest(router, http.MethodGet, "/api/v1/authz/bindings?user=carol&role=team-a", adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), `"role":"team-a"`)
+
+	// 外部账号不能使用密码登录
+	w = doRequest(router, http.MethodPost, "/api/v1/auth/login", "", models.LoginRequest{Username: "carol", Password: "anything"})
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+}
+
+func TestOIDCLogin_DoesNotTakeOverLocalAccount(t *testing.T) {
+	idp := newFakeIdP(t)
+	router := newOIDCTestRouter(t, idp)
+
+	w := idp.login(t, router, map[string]interface{}{"sub": "evil", "preferred_username": "admin"})
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+}
+
+func TestOIDCLogin_RejectsUnknownState(t *testing.T) {
+	idp := newFakeIdP(t)
+	router := newOIDCTestRouter(t, idp)
+
+	start := doRequest(router, http.MethodGet, "/api/v1/auth/oidc/login", "", nil)
+	require.Equal(t, http.StatusFound, start.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/callback?code=code-forged&state=forged", nil)
+	for _, cookie := range start.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+}
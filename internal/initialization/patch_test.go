@@ -0,0 +1,120 @@
+package initialization
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func patchRequest(router *gin.Engine, path, token, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// lastPatch 返回 fake 集群收到的最后一个 patch 请求
+func lastPatch(t *testing.T, actions []k8stesting.Action) k8stesting.PatchActionImpl {
+	t.Helper()
+	for i := len(actions) - 1; i >= 0; i-- {
+		if patch, ok := actions[i].(k8stesting.PatchActionImpl); ok {
+			return patch
+		}
+	}
+	t.Fatal("没有收到 patch 请求")
+	return k8stesting.PatchActionImpl{}
+}
+
+func TestPatch_ContentTypeSelectsPatchType(t *testing.T) {
+	var clientset *fake.Clientset
+	router := newTestRouter(t, false, withClusterObjects(&clientset,
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default"}, Data: map[string]string{"key": "old", "keep": "yes"}}))
+	admin := login(t, router, "admin", "admin123")
+	const path = "/api/v1/namespaces/default/configmaps/settings"
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		patchType   types.PatchType
+		data        map[string]string
+	}{
+		{"json defaults to strategic merge", "application/json", `{"data":{"key":"smp"}}`, types.StrategicMergePatchType, map[string]string{"key": "smp", "keep": "yes"}},
+		{"strategic merge", "application/strategic-merge-patch+json", `{"data":{"key":"smp2"}}`, types.StrategicMergePatchType, map[string]string{"key": "smp2", "keep": "yes"}},
+		{"merge patch", "application/merge-patch+json", `{"data":{"key":"merge","keep":null}}`, types.MergePatchType, map[string]string{"key": "merge"}},
+		{"json patch", "application/json-patch+json", `[{"op":"add","path":"/data/added","value":"1"}]`, types.JSONPatchType, map[string]string{"key": "merge", "added": "1"}},
+		{"apply patch", "application/apply-patch+yaml", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\ndata:\n  key: applied\n", types.ApplyPatchType, map[string]string{"key": "applied", "added": "1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := patchRequest(router, path, admin, tt.contentType, tt.body)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			stored, err := clientset.CoreV1().ConfigMaps("default").Get(t.Context(), "settings", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.data, stored.Data)
+
+			patch := lastPatch(t, clientset.Actions())
+			assert.Equal(t, tt.patchType, patch.GetPatchType())
+			assert.Equal(t, "cilikube", patch.PatchOptions.FieldManager)
+		})
+	}
+
+	// force 只随 apply patch 发送
+	w := patchRequest(router, path+"?force=true", admin, "application/merge-patch+json", `{"data":{"key":"forced"}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Nil(t, lastPatch(t, clientset.Actions()).PatchOptions.Force)
+	w = patchRequest(router, path+"?force=true", admin, "application/apply-patch+yaml", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\ndata:\n  key: forced\n")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	force := lastPatch(t, clientset.Actions()).PatchOptions.Force
+	require.NotNil(t, force)
+	assert.True(t, *force)
+
+	// 不支持的类型在发送到集群之前被拒绝
+	patches := countActions(clientset, "patch", "configmaps")
+	w = patchRequest(router, path, admin, "text/plain", `{"data":{"key":"text"}}`)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code, w.Body.String())
+	w = patchRequest(router, path, admin, "application/merge-patch+json", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	assert.Equal(t, patches, countActions(clientset, "patch", "configmaps"))
+}
+
+func TestPatch_DynamicResourceDefaultsToMergePatch(t *testing.T) {
+	var dyn *dynamicfake.FakeDynamicClient
+	router := newTestRouter(t, false, withDynamicCluster(&dyn, liveConfigMap("settings", map[string]interface{}{"key": "old", "keep": "yes"})))
+	admin := login(t, router, "admin", "admin123")
+	const path = "/api/v1/resources/core/v1/configmaps/namespaces/default/settings"
+
+	// 自定义资源不支持 strategic merge patch，application/json 按 merge patch 处理
+	for _, contentType := range []string{"", "application/json"} {
+		w := patchRequest(router, path, admin, contentType, `{"data":{"key":"merged"}}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, types.MergePatchType, lastPatch(t, dyn.Actions()).GetPatchType())
+	}
+
+	w := patchRequest(router, path, admin, "application/json-patch+json", `[{"op":"remove","path":"/data/keep"}]`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, types.JSONPatchType, lastPatch(t, dyn.Actions()).GetPatchType())
+	live, err := dyn.Resource(configMapsGVR).Namespace("default").Get(t.Context(), "settings", metav1.GetOptions{})
+	require.NoError(t, err)
+	data, _, _ := unstructured.NestedStringMap(live.Object, "data")
+	assert.Equal(t, map[string]string{"key": "merged"}, data)
+
+	w = patchRequest(router, path, admin, "application/xml", "<data/>")
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code, w.Body.String())
+}
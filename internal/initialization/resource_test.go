@@ -0,0 +1,157 @@
+package initialization
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func cronTab(namespace, name, spec string) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "stable.example.com/v1",
+		"kind":       "CronTab",
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+		"spec":       map[string]interface{}{"cronSpec": spec},
+	}
+}
+
+func TestResources_Discovery(t *testing.T) {
+	var dyn *dynamicfake.FakeDynamicClient
+	router := newTestRouter(t, false, withDynamicCluster(&dyn))
+	admin := login(t, router, "admin", "admin123")
+
+	w := doRequest(router, http.MethodGet, "/api/v1/resources", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp models.APIResourceListResponse
+	decodeData(t, w.Body.Bytes(), &resp)
+	require.Equal(t, len(resp.Items), resp.Total)
+
+	// 按 group/resource 排序，核心组在前，CRD 与内置资源一样列出
+	var names []string
+	byName := map[string]models.APIResource{}
+	for _, r := range resp.Items {
+		names = append(names, r.Group+"/"+r.Resource)
+		byName[r.Resource] = r
+	}
+	assert.Equal(t, []string{"/configmaps", "/namespaces", "rbac.authorization.k8s.io/clusterrolebindings", "stable.example.com/crontabs"}, names)
+	crontabs := byName["crontabs"]
+	assert.Equal(t, "CronTab", crontabs.Kind)
+	assert.True(t, crontabs.Namespaced)
+	assert.Equal(t, []string{"ct"}, crontabs.ShortNames)
+	assert.Contains(t, crontabs.Verbs, "list")
+	assert.False(t, byName["namespaces"].Namespaced)
+}
+
+func TestResources_CustomResourceCRUD(t *testing.T) {
+	var dyn *dynamicfake.FakeDynamicClient
+	router := newTestRouter(t, false, withDynamicCluster(&dyn))
+	admin := login(t, router, "admin", "admin123")
+	const base = "/api/v1/resources/stable.example.com/v1/crontabs"
+	const collection = base + "/namespaces/default"
+
+	w := doRequest(router, http.MethodPost, collection, admin, cronTab("", "nightly", "0 2 * * *"))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	// YAML 请求体与 JSON 等价，命名空间由路径补全
+	w = postManifest(router, collection, admin, "apiVersion: stable.example.com/v1\nkind: CronTab\nmetadata:\n  name: hourly\nspec:\n  cronSpec: '0 * * * *'\n")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	stored, err := dyn.Resource(cronTabsGVR).Namespace("default").Get(t.Context(), "hourly", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "default", stored.GetNamespace())
+
+	list := func(path string) ([]string, models.ListMeta) {
+		t.Helper()
+		w := doRequest(router, http.MethodGet, path, admin, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp models.ListResponse[unstructured.Unstructured]
+		decodeData(t, w.Body.Bytes(), &resp)
+		var names []string
+		for _, item := range resp.Items {
+			names = append(names, item.GetName())
+		}
+		return names, resp.ListMeta
+	}
+	names, _ := list(collection)
+	assert.Equal(t, []string{"hourly", "nightly"}, names)
+	// 命名空间级资源不指定命名空间时列出所有命名空间
+	names, _ = list(base)
+	assert.Equal(t, []string{"hourly", "nightly"}, names)
+	// 排序后在内存中分页
+	names, meta := list(collection + "?sortBy=name&order=desc&limit=1")
+	assert.Equal(t, []string{"nightly"}, names)
+	assert.Equal(t, 2, meta.Total)
+	assert.NotEmpty(t, meta.Continue)
+
+	w = doRequest(router, http.MethodGet, collection+"/nightly", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var got unstructured.Unstructured
+	decodeData(t, w.Body.Bytes(), &got.Object)
+	spec, _, _ := unstructured.NestedString(got.Object, "spec", "cronSpec")
+	assert.Equal(t, "0 2 * * *", spec)
+
+	w = doRequest(router, http.MethodPut, collection+"/nightly", admin, cronTab("default", "nightly", "30 3 * * *"))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	stored, err = dyn.Resource(cronTabsGVR).Namespace("default").Get(t.Context(), "nightly", metav1.GetOptions{})
+	require.NoError(t, err)
+	spec, _, _ = unstructured.NestedString(stored.Object, "spec", "cronSpec")
+	assert.Equal(t, "30 3 * * *", spec)
+
+	w = doRequest(router, http.MethodDelete, collection+"/nightly?propagationPolicy=Sometimes", admin, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	w = doRequest(router, http.MethodDelete, collection+"/nightly?propagationPolicy=Foreground", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	_, err = dyn.Resource(cronTabsGVR).Namespace("default").Get(t.Context(), "nightly", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+	w = doRequest(router, http.MethodGet, collection+"/nightly", admin, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
+
+func TestResources_RejectsMismatchedRequests(t *testing.T) {
+	var dyn *dynamicfake.FakeDynamicClient
+	router := newTestRouter(t, false, withDynamicCluster(&dyn))
+	admin := login(t, router, "admin", "admin123")
+	const collection = "/api/v1/resources/stable.example.com/v1/crontabs/namespaces/default"
+
+	wrongKind := cronTab("", "nightly", "0 2 * * *")
+	wrongKind["kind"] = "CronJob"
+	wrongVersion := cronTab("", "nightly", "0 2 * * *")
+	wrongVersion["apiVersion"] = "stable.example.com/v2"
+	unnamed := cronTab("", "", "0 2 * * *")
+	kindless := cronTab("", "nightly", "0 2 * * *")
+	delete(kindless, "kind")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		status int
+	}{
+		{"unknown resource", http.MethodGet, "/api/v1/resources/stable.example.com/v1/widgets/namespaces/default", nil, http.StatusNotFound},
+		{"unknown group version", http.MethodGet, "/api/v1/resources/example.org/v1alpha1/widgets", nil, http.StatusNotFound},
+		{"cluster-scoped resource with namespace", http.MethodGet, "/api/v1/resources/core/v1/namespaces/namespaces/default", nil, http.StatusBadRequest},
+		{"namespaced resource without namespace", http.MethodPost, "/api/v1/resources/stable.example.com/v1/crontabs", cronTab("", "nightly", "0 2 * * *"), http.StatusBadRequest},
+		{"kind differs from path", http.MethodPost, collection, wrongKind, http.StatusBadRequest},
+		{"missing kind", http.MethodPost, collection, kindless, http.StatusBadRequest},
+		{"apiVersion differs from path", http.MethodPost, collection, wrongVersion, http.StatusBadRequest},
+		{"namespace differs from path", http.MethodPost, collection, cronTab("kube-system", "nightly", "0 2 * * *"), http.StatusBadRequest},
+		{"missing name", http.MethodPost, collection, unnamed, http.StatusBadRequest},
+		{"name differs from path", http.MethodPut, collection + "/hourly", cronTab("default", "nightly", "0 2 * * *"), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doRequest(router, tt.method, tt.path, admin, tt.body)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+		})
+	}
+	// 被拒绝的请求都没有写入
+	list, err := dyn.Resource(cronTabsGVR).Namespace("").List(t.Context(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Items)
+}
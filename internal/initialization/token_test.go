@@ -0,0 +1,114 @@
+package initialization
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loginSession(t *testing.T, router *gin.Engine, username, password string) models.LoginResponse {
+	t.Helper()
+	w := doRequest(router, http.MethodPost, "/api/v1/auth/login", "", models.LoginRequest{Username: username, Password: password})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	return decodeLogin(t, w.Body.Bytes())
+}
+
+func refresh(router *gin.Engine, refreshToken string) (int, []byte) {
+	w := doRequest(router, http.MethodPost, "/api/v1/auth/refresh", "", models.RefreshTokenRequest{RefreshToken: refreshToken})
+	return w.Code, w.Body.Bytes()
+}
+
+func decodeLogin(t *testing.T, body []byte) models.LoginResponse {
+	t.Helper()
+	var resp struct {
+		Data models.LoginResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	require.NotEmpty(t, resp.Data.Token)
+	require.NotEmpty(t, resp.Data.RefreshToken)
+	return resp.Data
+}
+
+func profileStatus(router *gin.Engine, token string) int {
+	return doRequest(router, http.MethodGet, "/api/v1/auth/profile", token, nil).Code
+}
+
+func TestTokens_RefreshRotation(t *testing.T) {
+	router := newTestRouter(t, false)
+	session := loginSession(t, router, "admin", "admin123")
+
+	code, body := refresh(router, session.RefreshToken)
+	require.Equal(t, http.StatusOK, code, string(body))
+	rotated := decodeLogin(t, body)
+	assert.NotEqual(t, session.RefreshToken, rotated.RefreshToken)
+	assert.Equal(t, http.StatusOK, profileStatus(router, rotated.Token))
+
+	code, _ = refresh(router, "unknown-refresh-token")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	// 重复使用已轮换的刷新令牌：整个会话被吊销
+	code, _ = refresh(router, session.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = refresh(router, rotated.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Equal(t, http.StatusUnauthorized, profileStatus(router, session.Token))
+	assert.Equal(t, http.StatusUnauthorized, profileStatus(router, rotated.Token))
+}
+
+func TestTokens_Logout(t *testing.T) {
+	router := newTestRouter(t, false)
+	first := loginSession(t, router, "admin", "admin123")
+	second := loginSession(t, router, "admin", "admin123")
+
+	w := doRequest(router, http.MethodPost, "/api/v1/auth/logout", first.Token, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, profileStatus(router, first.Token))
+	code, _ := refresh(router, first.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	// 其他会话不受影响
+	assert.Equal(t, http.StatusOK, profileStatus(router, second.Token))
+
+	w = doRequest(router, http.MethodPost, "/api/v1/auth/logout-all", second.Token, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, profileStatus(router, second.Token))
+	code, _ = refresh(router, second.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	// 重新登录后可以正常使用
+	assert.Equal(t, http.StatusOK, profileStatus(router, loginSession(t, router, "admin", "admin123").Token))
+}
+
+func TestTokens_RevokedOnPasswordChangeAndDeactivation(t *testing.T) {
+	router := newTestRouter(t, false)
+	w := doRequest(router, http.MethodPost, "/api/v1/auth/register", "", models.RegisterRequest{Username: "alice", Email: "alice@example.com", Password: "alice-password"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	alice := loginSession(t, router, "alice", "alice-password")
+	w = doRequest(router, http.MethodPost, "/api/v1/auth/change-password", alice.Token, models.ChangePasswordRequest{OldPassword: "alice-password", NewPassword: "alice-new-password"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, profileStatus(router, alice.Token))
+	code, _ := refresh(router, alice.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	alice = loginSession(t, router, "alice", "alice-new-password")
+	admin := loginSession(t, router, "admin", "admin123")
+	path := fmt.Sprintf("/api/v1/auth/users/%d/status", alice.User.ID)
+	w = doRequest(router, http.MethodPut, path, admin.Token, map[string]bool{"is_active": false})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, profileStatus(router, alice.Token))
+	code, _ = refresh(router, alice.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	// 重新启用后旧 token 仍然无效
+	w = doRequest(router, http.MethodPut, path, admin.Token, map[string]bool{"is_active": true})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, profileStatus(router, alice.Token))
+	assert.Equal(t, http.StatusOK, profileStatus(router, loginSession(t, router, "alice", "alice-new-password").Token))
+}
@@ -0,0 +1,134 @@
+package initialization
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+	code, err := auth.GenerateTOTPCode(secret, at)
+	require.NoError(t, err)
+	return code
+}
+
+func decodeData(t *testing.T, body []byte, out interface{}) {
+	t.Helper()
+	require.NoError(t, json.Unmarshal(body, &struct {
+		Data interface{} `json:"data"`
+	}{out}))
+}
+
+// passwordLogin 返回登录响应，需要两步验证时其中只有挑战
+func passwordLogin(t *testing.T, router *gin.Engine, username, password string) models.LoginResponse {
+	t.Helper()
+	w := doRequest(router, http.MethodPost, "/api/v1/auth/login", "", models.LoginRequest{Username: username, Password: password})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp models.LoginResponse
+	decodeData(t, w.Body.Bytes(), &resp)
+	return resp
+}
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	// RFC 6238 附录 B 的 SHA1 测试向量 (取后 6 位)
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	assert.Equal(t, "287082", totpCode(t, secret, time.Unix(59, 0)))
+	assert.Equal(t, "081804", totpCode(t, secret, time.Unix(1111111109, 0)))
+	assert.Equal(t, "005924", totpCode(t, secret, time.Unix(1234567890, 0)))
+}
+
+func TestTwoFactor_EnrollAndLogin(t *testing.T) {
+	router := newTestRouter(t, false)
+	w := doRequest(router, http.MethodPost, "/api/v1/auth/register", "", models.RegisterRequest{Username: "alice", Email: "alice@example.com", Password: "alice-password"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	alice := login(t, router, "alice", "alice-password")
+
+	w = doRequest(router, http.MethodPost, "/api/v1/auth/2fa/enroll", alice, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var enrollment models.TwoFactorEnrollment
+	decodeData(t, w.Body.Bytes(), &enrollment)
+	assert.Contains(t, enrollment.URI, "otpauth://totp/")
+	assert.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+
+	w = doRequest(router, http.MethodPost, "/api/v1/auth/2fa/verify", alice, models.TwoFactorCodeRequest{Code: "000000"})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	now := time.Now()
+	w = doRequest(router, http.MethodPost, "/api/v1/auth/2fa/verify", alice, models.TwoFactorCodeRequest{Code: totpCode(t, enrollment.Secret, now)})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var recovery models.RecoveryCodesResponse
+	decodeData(t, w.Body.Bytes(), &recovery)
+	require.Len(t, recovery.RecoveryCodes, 10)
+
+	// 密码正确也只返回挑战，挑战不能当作 token 使用
+	resp := passwordLogin(t, router, "alice", "alice-password")
+	require.NotNil(t, resp.TwoFactor)
+	assert.Empty(t, resp.Token)
+	assert.False(t, resp.TwoFactor.EnrollmentRequired)
+	challenge := resp.TwoFactor.ChallengeToken
+	assert.Equal(t, http.StatusUnauthorized, doRequest(router, http.MethodGet, "/api/v1/auth/profile", challenge, nil).Code)
+
+	// 已使用过的验证码不能重放
+	w = doRequest(router, http.MethodPost, "/api/v1/auth/login/2fa", "", models.TwoFactorLoginRequest{ChallengeToken: challenge, Code: totpCode(t, enrollment.Secret, now)})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = doRequest(router, http.MethodPost, "/api/v1/auth/login/2fa", "", models.TwoFactorLoginRequest{ChallengeToken: "bogus", Code: totpCode(t, enrollment.Secret, now)})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = doRequest(router, http.MethodPost, "/api/v1/auth/login/2fa", "", models.TwoFactorLoginRequest{ChallengeToken: challenge, Code: totpCode(t, enrollment.Secret, now.Add(30*time.Second))})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	session := decodeLogin(t, w.Body.Bytes())
+	assert.True(t, session.User.TOTPEnabled)
+
+	// 恢复码只能使用一次
+	recoveryLogin := models.TwoFactorLoginRequest{ChallengeToken: challenge, RecoveryCode: recovery.RecoveryCodes[0]}
+	w = doRequest(router, http.MethodPost, "/api/v1/auth/login/2fa", "", recoveryLogin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doRequest(router, http.MethodPost, "/api/v1/auth/login/2fa", "", recoveryLogin)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = doRequest(router, http.MethodPost, "/api/v1/auth/2fa/disable", session.Token, models.DisableTwoFactorRequest{Password: "alice-password", RecoveryCode: recovery.RecoveryCodes[1]})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Nil(t, passwordLogin(t, router, "alice", "alice-password").TwoFactor)
+}
+
+func TestTwoFactor_MandatoryForRole(t *testing.T) {
+	router := newTestRouter(t, false)
+	admin := login(t, router, "admin", "admin123")
+	w := doRequest(router, http.MethodPost, "/api/v1/auth/register", "", models.RegisterRequest{Username: "bob", Email: "bob@example.com", Password: "bob-password"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doRequest(router, http.MethodPost, "/api/v1/authz/bindings", admin, models.RoleBinding{User: "bob", Role: "super_admin"})
+	require.Less(t, w.Code, 300, w.Body.String())
+	w = doRequest(router, http.MethodPut, "/api/v1/auth/2fa/policy", admin, models.TwoFactorPolicy{RequiredRoles: []string{"super_admin"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// 未绑定的用户需要在登录过程中完成绑定
+	resp := passwordLogin(t, router, "bob", "bob-password")
+	require.NotNil(t, resp.TwoFactor)
+	assert.True(t, resp.TwoFactor.EnrollmentRequired)
+	challenge := resp.TwoFactor.ChallengeToken
+
+	w = doRequest(router, http.MethodPost, "/api/v1/auth/login/2fa/enroll", "", models.TwoFactorEnrollRequest{ChallengeToken: challenge})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var enrollment models.TwoFactorEnrollment
+	decodeData(t, w.Body.Bytes(), &enrollment)
+
+	w = doRequest(router, http.MethodPost, "/api/v1/auth/login/2fa", "", models.TwoFactorLoginRequest{ChallengeToken: challenge, Code: totpCode(t, enrollment.Secret, time.Now())})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	session := decodeLogin(t, w.Body.Bytes())
+	assert.Len(t, session.RecoveryCodes, 10)
+
+	// 策略要求时不能关闭
+	w = doRequest(router, http.MethodPost, "/api/v1/auth/2fa/disable", session.Token, models.DisableTwoFactorRequest{Password: "bob-password", RecoveryCode: session.RecoveryCodes[0]})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// 不在策略中的角色不受影响
+	assert.Nil(t, passwordLogin(t, router, "admin", "admin123").TwoFactor)
+}
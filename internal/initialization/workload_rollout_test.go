@@ -0,0 +1,210 @@
+package initialization
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+type sseEvent struct {
+	Name   string
+	Status models.RolloutStatus
+}
+
+// parseSSE 解析 rollout-status 返回的 SSE 流
+func parseSSE(t *testing.T, body string) []sseEvent {
+	t.Helper()
+	var events []sseEvent
+	for _, block := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		var event sseEvent
+		for _, line := range strings.Split(block, "\n") {
+			switch {
+			case strings.HasPrefix(line, "event:"):
+				event.Name = strings.TrimPrefix(line, "event:")
+			case strings.HasPrefix(line, "data:"):
+				require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), &event.Status))
+			}
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func rolledOutDeployment(name string) *appsv1.Deployment {
+	replicas := int32(2)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Generation: 2},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: podTemplate("web:v1", ""),
+		},
+		Status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2, AvailableReplicas: 2},
+	}
+}
+
+func TestWorkloadRestart(t *testing.T) {
+	replicas := int32(1)
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas, Template: podTemplate("db:v1", "")},
+	}
+	paused := rolledOutDeployment("paused")
+	paused.Spec.Paused = true
+	var clientset *fake.Clientset
+	router := newTestRouter(t, false, withClusterObjects(&clientset, rolledOutDeployment("web"), paused, statefulSet))
+	admin := login(t, router, "admin", "admin123")
+
+	w := doRequest(router, http.MethodPost, "/api/v1/namespaces/default/deployments/web/restart", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	deployment, err := clientset.AppsV1().Deployments("default").Get(t.Context(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	restartedAt, err := time.Parse(time.RFC3339, deployment.Spec.Template.Annotations[service.RestartedAtAnnotation])
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), restartedAt, time.Minute)
+
+	assert.Equal(t, http.StatusConflict, doRequest(router, http.MethodPost, "/api/v1/namespaces/default/deployments/paused/restart", admin, nil).Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(router, http.MethodPost, "/api/v1/namespaces/default/daemonsets/missing/restart", admin, nil).Code)
+
+	w = doRequest(router, http.MethodPost, "/api/v1/namespaces/default/statefulsets/db/restart", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	statefulSet, err = clientset.AppsV1().StatefulSets("default").Get(t.Context(), "db", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, statefulSet.Spec.Template.Annotations, service.RestartedAtAnnotation)
+	assert.Equal(t, "db:v1", statefulSet.Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestDeploymentRolloutStatus(t *testing.T) {
+	stalled := rolledOutDeployment("stalled")
+	stalled.Status.UpdatedReplicas = 1
+	stalled.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentProgressing, Reason: "ProgressDeadlineExceeded"}}
+	progressing := rolledOutDeployment("progressing")
+	progressing.Status.UpdatedReplicas, progressing.Status.AvailableReplicas = 1, 1
+
+	var clientset *fake.Clientset
+	router := newTestRouter(t, false, withClusterObjects(&clientset, rolledOutDeployment("web"), stalled, progressing))
+	admin := login(t, router, "admin", "admin123")
+	follow := func(name, query string) []sseEvent {
+		w := doRequest(router, http.MethodGet, "/api/v1/namespaces/default/deployments/"+name+"/rollout-status"+query, admin, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream"))
+		return parseSSE(t, w.Body.String())
+	}
+
+	events := follow("web", "")
+	require.Len(t, events, 1)
+	assert.Equal(t, "success", events[0].Name)
+	assert.True(t, events[0].Status.Done)
+
+	events = follow("stalled", "")
+	require.Len(t, events, 1)
+	assert.Equal(t, "failure", events[0].Name)
+	assert.Contains(t, events[0].Status.Message, "exceeded its progress deadline")
+
+	assert.Equal(t, http.StatusNotFound, doRequest(router, http.MethodGet, "/api/v1/namespaces/default/deployments/missing/rollout-status", admin, nil).Code)
+
+	// 控制器推进状态后流以 success 结束
+	finished := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-finished:
+				return
+			case <-time.After(20 * time.Millisecond):
+			}
+			deployment, err := clientset.AppsV1().Deployments("default").Get(t.Context(), "progressing", metav1.GetOptions{})
+			if err != nil {
+				continue
+			}
+			deployment.Status.UpdatedReplicas, deployment.Status.AvailableReplicas = 2, 2
+			_, _ = clientset.AppsV1().Deployments("default").UpdateStatus(t.Context(), deployment, metav1.UpdateOptions{})
+		}
+	}()
+	events = follow("progressing", "?timeout=10s")
+	close(finished)
+	require.Len(t, events, 2)
+	assert.Equal(t, "status", events[0].Name)
+	assert.Contains(t, events[0].Status.Message, "1 out of 2 new replicas have been updated")
+	assert.Equal(t, "success", events[1].Name)
+}
+
+func TestDaemonSetRolloutStatus_Timeout(t *testing.T) {
+	daemonSet := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default", Generation: 1},
+		Status:     appsv1.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 2},
+	}
+	var clientset *fake.Clientset
+	router := newTestRouter(t, false, withClusterObjects(&clientset, daemonSet))
+	admin := login(t, router, "admin", "admin123")
+
+	w := doRequest(router, http.MethodGet, "/api/v1/namespaces/default/daemonsets/agent/rollout-status?timeout=100ms", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	events := parseSSE(t, w.Body.String())
+	require.Len(t, events, 2)
+	assert.Contains(t, events[0].Status.Message, "2 of 3 updated pods are available")
+	assert.Equal(t, "failure", events[1].Name)
+	assert.Equal(t, "timed out waiting for the condition", events[1].Status.Message)
+}
+
+func TestDeploymentRolloutStatus_WatchErrors(t *testing.T) {
+	progressing := rolledOutDeployment("web")
+	progressing.Status.UpdatedReplicas, progressing.Status.AvailableReplicas = 1, 1
+
+	var clientset *fake.Clientset
+	router := newTestRouter(t, false, withClusterObjects(&clientset, progressing))
+	admin := login(t, router, "admin", "admin123")
+
+	// watchWith 让下一次 watch 立即收到 status 错误事件；afterWatch 在返回 watcher 前修改集群状态
+	watches := 0
+	watchWith := func(status metav1.Status, afterWatch func()) {
+		clientset.PrependWatchReactor("deployments", func(action k8stesting.Action) (bool, watch.Interface, error) {
+			watches++
+			if watches > 1 {
+				return false, nil, nil
+			}
+			if afterWatch != nil {
+				afterWatch()
+			}
+			w := watch.NewFakeWithChanSize(1, false)
+			w.Error(&status)
+			return true, w, nil
+		})
+	}
+	follow := func() []sseEvent {
+		w := doRequest(router, http.MethodGet, "/api/v1/namespaces/default/deployments/web/rollout-status?timeout=10s", admin, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		return parseSSE(t, w.Body.String())
+	}
+
+	// 非过期错误不再重新 watch，以 error 事件结束
+	watchWith(metav1.Status{Status: metav1.StatusFailure, Code: http.StatusInternalServerError, Reason: metav1.StatusReasonInternalError, Message: "etcd unavailable"}, nil)
+	events := follow()
+	require.Len(t, events, 2)
+	assert.Equal(t, "status", events[0].Name)
+	assert.Equal(t, "error", events[1].Name)
+	assert.Contains(t, events[1].Status.Message, "etcd unavailable")
+	assert.Equal(t, 1, watches)
+
+	// resourceVersion 过期时重新读取并继续
+	watches = 0
+	watchWith(metav1.Status{Status: metav1.StatusFailure, Code: http.StatusGone, Reason: metav1.StatusReasonExpired, Message: "too old resource version"}, func() {
+		deployment := rolledOutDeployment("web")
+		require.NoError(t, clientset.Tracker().Update(appsv1.SchemeGroupVersion.WithResource("deployments"), deployment, "default"))
+	})
+	events = follow()
+	require.Len(t, events, 2)
+	assert.Equal(t, "status", events[0].Name)
+	assert.Equal(t, "success", events[1].Name)
+}
@@ -0,0 +1,150 @@
+package initialization
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// withScaleSubresource fake 客户端不模拟 scale 子资源，这里把它映射到 spec.replicas；
+// 第一次 UpdateScale 返回冲突以验证重试，dry-run 时不写入。返回 UpdateScale 的调用次数
+func withScaleSubresource(clientset *fake.Clientset, resource string) *int {
+	gvr := appsv1.SchemeGroupVersion.WithResource(resource)
+	updates := 0
+	replicasOf := func(obj runtime.Object) *int32 {
+		switch workload := obj.(type) {
+		case *appsv1.Deployment:
+			return workload.Spec.Replicas
+		case *appsv1.StatefulSet:
+			return workload.Spec.Replicas
+		}
+		return nil
+	}
+	clientset.PrependReactor("get", resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		get := action.(k8stesting.GetAction)
+		if get.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		obj, err := clientset.Tracker().Get(gvr, get.GetNamespace(), get.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		return true, &autoscalingv1.Scale{
+			ObjectMeta: metav1.ObjectMeta{Name: get.GetName(), Namespace: get.GetNamespace()},
+			Spec:       autoscalingv1.ScaleSpec{Replicas: *replicasOf(obj)},
+		}, nil
+	})
+	clientset.PrependReactor("update", resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		update := action.(k8stesting.UpdateAction)
+		if update.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		updates++
+		scale := update.GetObject().(*autoscalingv1.Scale)
+		if updates == 1 {
+			return true, nil, apierrors.NewConflict(schema.GroupResource{Group: "apps", Resource: resource}, scale.Name, nil)
+		}
+		obj, err := clientset.Tracker().Get(gvr, update.GetNamespace(), scale.Name)
+		if err != nil || len(update.(k8stesting.UpdateActionImpl).UpdateOptions.DryRun) > 0 {
+			return true, scale, err
+		}
+		*replicasOf(obj) = scale.Spec.Replicas
+		return true, scale, clientset.Tracker().Update(gvr, obj, update.GetNamespace())
+	})
+	return &updates
+}
+
+func TestDeploymentScaleAndPause(t *testing.T) {
+	var clientset *fake.Clientset
+	router := newTestRouter(t, false, withClusterObjects(&clientset, rolledOutDeployment("web")))
+	admin := login(t, router, "admin", "admin123")
+	updates := withScaleSubresource(clientset, "deployments")
+	scale := func(body interface{}) *models.DeploymentResponse {
+		w := doRequest(router, http.MethodPut, "/api/v1/namespaces/default/deployments/web/scale", admin, body)
+		if w.Code != http.StatusOK {
+			return nil
+		}
+		var resp models.DeploymentResponse
+		decodeData(t, w.Body.Bytes(), &resp)
+		return &resp
+	}
+
+	resp := scale(gin.H{"replicas": 5})
+	require.NotNil(t, resp)
+	assert.EqualValues(t, 5, resp.Replicas)
+	assert.Equal(t, 2, *updates, "冲突后应重试")
+
+	// 可以缩容到 0，缺少 replicas 或为负数时拒绝
+	resp = scale(gin.H{"replicas": 0})
+	require.NotNil(t, resp)
+	assert.EqualValues(t, 0, resp.Replicas)
+	assert.Nil(t, scale(gin.H{}))
+	assert.Nil(t, scale(gin.H{"replicas": -1}))
+
+	// dry-run 返回预览的副本数，集群中保持不变
+	w := doRequest(router, http.MethodPut, "/api/v1/namespaces/default/deployments/web/scale?dryRun=All", admin, gin.H{"replicas": 7})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	decodeData(t, w.Body.Bytes(), resp)
+	assert.EqualValues(t, 7, resp.Replicas)
+	deployment, err := clientset.AppsV1().Deployments("default").Get(t.Context(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, *deployment.Spec.Replicas)
+
+	w = doRequest(router, http.MethodPost, "/api/v1/namespaces/default/deployments/web/pause", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	deployment, err = clientset.AppsV1().Deployments("default").Get(t.Context(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, deployment.Spec.Paused)
+	assert.Equal(t, http.StatusConflict, doRequest(router, http.MethodPost, "/api/v1/namespaces/default/deployments/web/restart", admin, nil).Code)
+
+	w = doRequest(router, http.MethodPost, "/api/v1/namespaces/default/deployments/web/resume", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	deployment, err = clientset.AppsV1().Deployments("default").Get(t.Context(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.False(t, deployment.Spec.Paused)
+	assert.Equal(t, http.StatusNotFound, doRequest(router, http.MethodPost, "/api/v1/namespaces/default/deployments/missing/pause", admin, nil).Code)
+}
+
+func TestStatefulSetScale(t *testing.T) {
+	replicas := int32(1)
+	var clientset *fake.Clientset
+	router := newTestRouter(t, false, withClusterObjects(&clientset, &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas, Template: podTemplate("db:v1", "")},
+	}))
+	admin := login(t, router, "admin", "admin123")
+	withScaleSubresource(clientset, "statefulsets")
+
+	w := doRequest(router, http.MethodPut, "/api/v1/namespaces/default/statefulsets/db/scale", admin, gin.H{"replicas": 3})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp models.StatefulSetResponse
+	decodeData(t, w.Body.Bytes(), &resp)
+	assert.EqualValues(t, 3, *resp.Spec.Replicas)
+
+	// dry-run 返回预览的副本数，集群中保持不变
+	w = doRequest(router, http.MethodPut, "/api/v1/namespaces/default/statefulsets/db/scale?dryRun=All", admin, gin.H{"replicas": 5})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	decodeData(t, w.Body.Bytes(), &resp)
+	assert.EqualValues(t, 5, *resp.Spec.Replicas)
+	stored, err := clientset.AppsV1().StatefulSets("default").Get(t.Context(), "db", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, *stored.Spec.Replicas)
+	assert.Equal(t, http.StatusNotFound, doRequest(router, http.MethodPut, "/api/v1/namespaces/default/statefulsets/missing/scale", admin, gin.H{"replicas": 3}).Code)
+
+	// 普通用户没有 statefulsets/scale 的 update 权限
+	register(t, router, "dave", "dave-password")
+	dave := login(t, router, "dave", "dave-password")
+	assert.Equal(t, http.StatusForbidden, doRequest(router, http.MethodPut, "/api/v1/namespaces/default/statefulsets/db/scale", dave, gin.H{"replicas": 3}).Code)
+}
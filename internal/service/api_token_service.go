@@ -0,0 +1,168 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/database"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAPITokenNotFound = errors.New("API token 不存在")
+	ErrInvalidAPIToken  = errors.New("API token 无效或已过期")
+	ErrNotRobot         = errors.New("只能为机器人用户创建 API token")
+)
+
+// apiTokenTouchInterval 最近使用时间的更新间隔，避免每个请求都写数据库
+const apiTokenTouchInterval = time.Minute
+
+// CreateAPIToken 为用户创建 API token，明文只在返回值中出现一次
+func (s *AuthService) CreateAPIToken(userID uint, req *models.CreateAPITokenRequest) (*models.CreateAPITokenResponse, error) {
+	for _, scope := range req.Scopes {
+		if _, _, err := auth.ParseScope(scope); err != nil {
+			return nil, err
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("过期时间必须晚于当前时间")
+	}
+	var count int64
+	database.DB.Model(&models.APIToken{}).Where("user_id = ? AND name = ?", userID, req.Name).Count(&count)
+	if count > 0 {
+		return nil, fmt.Errorf("API token %q 已存在", req.Name)
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	plain := auth.APITokenPrefix + secret
+	token := models.APIToken{
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    plain[:len(auth.APITokenPrefix)+6],
+		TokenHash: hashToken(plain),
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := database.DB.Create(&token).Error; err != nil {
+		return nil, fmt.Errorf("创建 API token 失败: %w", err)
+	}
+	return &models.CreateAPITokenResponse{APIToken: token, Token: plain}, nil
+}
+
+// CreateRobotAPIToken 管理员为机器人用户创建 API token
+func (s *AuthService) CreateRobotAPIToken(userID uint, req *models.CreateAPITokenRequest) (*models.CreateAPITokenResponse, error) {
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("用户不存在")
+		}
+		return nil, err
+	}
+	if user.Source != models.UserSourceRobot {
+		return nil, ErrNotRobot
+	}
+	return s.CreateAPIToken(userID, req)
+}
+
+// ListAPITokens 列出用户的 API token (不含明文)
+func (s *AuthService) ListAPITokens(userID uint) ([]models.APIToken, error) {
+	tokens := []models.APIToken{}
+	err := database.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+// RevokeAPIToken 删除用户的 API token，立即失效
+func (s *AuthService) RevokeAPIToken(userID, tokenID uint) error {
+	result := database.DB.Where("id = ? AND user_id = ?", tokenID, userID).Delete(&models.APIToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAPITokenNotFound
+	}
+	return nil
+}
+
+// ResolveAPIToken 供 JWTAuthMiddleware 使用：校验 API token 并以其所属用户的身份认证，
+// 用户被禁用或删除后令牌随之失效
+func (s *AuthService) ResolveAPIToken(plain string) (*auth.JWTClaims, error) {
+	var token models.APIToken
+	if err := database.DB.Where("token_hash = ?", hashToken(plain)).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIToken
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if token.ExpiresAt != nil && now.After(*token.ExpiresAt) {
+		return nil, ErrInvalidAPIToken
+	}
+
+	var user models.User
+	if err := database.DB.Where("id = ? AND is_active = ?", token.UserID, true).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIToken
+		}
+		return nil, err
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > apiTokenTouchInterval {
+		database.DB.Model(&token).Update("last_used_at", now)
+	}
+	return &auth.JWTClaims{
+		UserID:     user.ID,
+		Username:   user.Username,
+		Role:       user.Role,
+		APITokenID: token.ID,
+		Scopes:     token.Scopes,
+
+		PasswordChangeRequired: user.MustChangePassword,
+	}, nil
+}
+
+// CreateRobot 创建机器人用户。机器人没有可用的密码，只能通过管理员为其创建的 API token 访问
+func (s *AuthService) CreateRobot(req *models.CreateRobotRequest) (*models.UserResponse, error) {
+	if err := validateUsername(req.Username); err != nil {
+		return nil, err
+	}
+	var count int64
+	database.DB.Unscoped().Model(&models.User{}).Where("username = ?", req.Username).Count(&count)
+	if count > 0 {
+		return nil, errors.New("用户名已存在")
+	}
+	role := req.Role
+	if role == "" {
+		role = models.RoleUser
+	}
+	password, err := randomPassword()
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Username: req.Username,
+		Email:    req.Username + "@" + models.UserSourceRobot + ".invalid",
+		Password: password,
+		Role:     role,
+		Source:   models.UserSourceRobot,
+		IsActive: true,
+	}
+	if err := database.DB.Create(user).Error; err != nil {
+		return nil, err
+	}
+	if err := s.bindRole(user.Username, user.Role); err != nil {
+		return nil, err
+	}
+	if err := s.syncExternalRoles(user.Username, req.Roles, req.Roles); err != nil {
+		return nil, err
+	}
+
+	response := user.ToResponse()
+	return &response, nil
+}
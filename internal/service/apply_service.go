@@ -0,0 +1,239 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+)
+
+// ApplyOptions apply 选项
+type ApplyOptions struct {
+	Namespace string // 未指定命名空间的命名空间级对象使用该命名空间，默认 default
+	Force     bool   // 字段冲突时强制获取所有权
+	DryRun    bool   // 仅由 API Server 校验，不持久化
+
+	// Authorize 在写入之前对每个对象检查权限，verb 为 create (对象不存在) 或 update，
+	// resource 为小写复数资源名，集群级对象的 namespace 为空。返回错误时整个清单都不会被应用
+	Authorize func(namespace, resource, verb string) error
+}
+
+// ApplyService 使用 server-side apply 应用多文档 YAML/JSON 清单
+type ApplyService struct {
+	dynamic dynamic.Interface
+	mapper  meta.ResettableRESTMapper
+}
+
+func NewApplyService(dynamicClient dynamic.Interface, mapper meta.ResettableRESTMapper) *ApplyService {
+	return &ApplyService{dynamic: dynamicClient, mapper: mapper}
+}
+
+// applyTarget 清单中一个对象解析后的写入目标
+type applyTarget struct {
+	client   dynamic.ResourceInterface
+	resource string
+	existing *unstructured.Unstructured
+	// unmapped 资源类型尚未注册 (例如同一清单中的 CRD 还没创建)，resource 是按 kind 推测的
+	unmapped bool
+	err      error
+}
+
+func (t *applyTarget) verb() string {
+	if t.existing != nil {
+		return "update"
+	}
+	return "create"
+}
+
+// Apply 先解析并授权全部对象，再按清单顺序逐个 apply；单个对象 apply 失败不影响其余对象。
+// 清单无法解析或任何一个对象未通过授权时返回错误，不写入任何对象
+func (s *ApplyService) Apply(manifest []byte, opts ApplyOptions) (*models.ApplyResponse, error) {
+	objects, err := DecodeManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, NewValidationError("清单中没有任何对象")
+	}
+	if opts.Namespace == "" {
+		opts.Namespace = metav1.NamespaceDefault
+	}
+
+	targets := make([]*applyTarget, len(objects))
+	for i, obj := range objects {
+		targets[i] = s.resolveTarget(obj, opts.Namespace)
+		if targets[i].err == nil && opts.Authorize != nil {
+			if err := opts.Authorize(obj.GetNamespace(), targets[i].resource, targets[i].verb()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	response := &models.ApplyResponse{
+		DryRun:  opts.DryRun,
+		Results: make([]models.ApplyResult, 0, len(objects)),
+		Summary: map[string]int{},
+	}
+	for i, obj := range objects {
+		result := models.ApplyResult{
+			Index:      i,
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Name:       obj.GetName(),
+		}
+		action, err := s.applyObject(obj, targets[i], opts)
+		result.Namespace = obj.GetNamespace()
+		result.Action = action
+		if err != nil {
+			result.Action = models.ApplyActionError
+			result.Error = err.Error()
+		}
+		response.Summary[result.Action]++
+		response.Results = append(response.Results, result)
+	}
+	return response, nil
+}
+
+// resolveTarget 校验对象并解析其资源类型和是否已存在
+func (s *ApplyService) resolveTarget(obj *unstructured.Unstructured, defaultNamespace string) *applyTarget {
+	if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+		return &applyTarget{err: errors.New("apiVersion 和 kind 不能为空")}
+	}
+	if obj.GetName() == "" {
+		return &applyTarget{err: errors.New("metadata.name 不能为空 (apply 不支持 generateName)")}
+	}
+
+	gvk := obj.GroupVersionKind()
+	mapping, err := s.restMapping(obj)
+	if meta.IsNoMatchError(err) {
+		// 类型可能由清单中前面的 CRD 定义，apply 时再解析；授权按 kubectl 的规则推测资源名
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(defaultNamespace)
+		}
+		plural, _ := meta.UnsafeGuessKindToResource(gvk)
+		return &applyTarget{resource: plural.Resource, unmapped: true}
+	}
+	if err != nil {
+		return &applyTarget{err: fmt.Errorf("无法识别资源类型 %s: %w", gvk.String(), err)}
+	}
+
+	target := &applyTarget{resource: mapping.Resource.Resource}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		obj.SetNamespace("")
+		target.client = s.dynamic.Resource(mapping.Resource)
+	} else {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(defaultNamespace)
+		}
+		target.client = s.dynamic.Resource(mapping.Resource).Namespace(obj.GetNamespace())
+	}
+
+	existing, err := target.client.Get(context.TODO(), obj.GetName(), metav1.GetOptions{})
+	switch {
+	case err == nil:
+		target.existing = existing
+	case !apierrors.IsNotFound(err):
+		target.err = err
+	}
+	return target
+}
+
+// applyObject 对单个对象执行 server-side apply，返回 created / configured / unchanged
+func (s *ApplyService) applyObject(obj *unstructured.Unstructured, target *applyTarget, opts ApplyOptions) (string, error) {
+	if target.unmapped {
+		// 前面的对象已经注册了该类型；解析结果与授权时推测的不同则重新授权
+		authorized, namespace := *target, obj.GetNamespace()
+		target = s.resolveTarget(obj, opts.Namespace)
+		if target.unmapped {
+			return "", fmt.Errorf("无法识别资源类型 %s", obj.GroupVersionKind().String())
+		}
+		changed := target.resource != authorized.resource || target.verb() != authorized.verb() || obj.GetNamespace() != namespace
+		if target.err == nil && opts.Authorize != nil && changed {
+			target.err = opts.Authorize(obj.GetNamespace(), target.resource, target.verb())
+		}
+	}
+	if target.err != nil {
+		return "", target.err
+	}
+
+	applyOpts := metav1.ApplyOptions{FieldManager: FieldManager, Force: opts.Force}
+	if opts.DryRun {
+		applyOpts.DryRun = []string{metav1.DryRunAll}
+	}
+	applied, err := target.client.Apply(context.TODO(), obj.GetName(), obj, applyOpts)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case target.existing == nil:
+		return models.ApplyActionCreated, nil
+	case equality.Semantic.DeepEqual(comparableContent(target.existing), comparableContent(applied)):
+		return models.ApplyActionUnchanged, nil
+	default:
+		return models.ApplyActionConfigured, nil
+	}
+}
+
+// restMapping 通过 RESTMapper 解析对象的 GVK；找不到时重置映射再试一次，
+// 以便同一清单中先创建的 CRD 可以被后面的自定义资源使用
+func (s *ApplyService) restMapping(obj *unstructured.Unstructured) (*meta.RESTMapping, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := s.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		s.mapper.Reset()
+		mapping, err = s.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	return mapping, err
+}
+
+// DecodeManifest 解码以 --- 分隔的多文档 YAML 或 JSON，跳过空文档，并展开 kind: List
+func DecodeManifest(manifest []byte) ([]*unstructured.Unstructured, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifest), 4096)
+	var objects []*unstructured.Unstructured
+	for doc := 0; ; doc++ {
+		content := map[string]interface{}{}
+		if err := decoder.Decode(&content); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, NewValidationError(fmt.Sprintf("解析第 %d 个文档失败: %v", doc+1, err))
+		}
+		if len(content) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: content}
+		if !obj.IsList() {
+			objects = append(objects, obj)
+			continue
+		}
+		list, err := obj.ToList()
+		if err != nil {
+			return nil, NewValidationError(fmt.Sprintf("解析第 %d 个文档中的列表失败: %v", doc+1, err))
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	}
+	return objects, nil
+}
+
+// comparableContent 去掉每次写入都会变化的元数据，用于判断对象是否被修改
+func comparableContent(obj *unstructured.Unstructured) map[string]interface{} {
+	content := obj.DeepCopy().Object
+	unstructured.RemoveNestedField(content, "metadata", "managedFields")
+	unstructured.RemoveNestedField(content, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(content, "metadata", "generation")
+	return content
+}
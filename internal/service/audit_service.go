@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/configs"
+	"gorm.io/gorm"
+)
+
+// ErrAuditQueryUnavailable 审计记录没有写入数据库，无法查询
+var ErrAuditQueryUnavailable = errors.New("数据库未启用，审计记录写入了文件，无法通过接口查询")
+
+// AuditService 保存与查询审计记录。db 为 nil 时写入 JSON Lines 文件或标准输出
+type AuditService struct {
+	cfg configs.AuditConfig
+	db  *gorm.DB
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewAuditService 创建审计服务；db 为 nil 时打开 cfg.File (为空则使用标准输出)
+func NewAuditService(cfg configs.AuditConfig, db *gorm.DB) (*AuditService, error) {
+	s := &AuditService{cfg: cfg, db: db}
+	if db != nil {
+		return s, nil
+	}
+	if cfg.File == "" {
+		s.out = os.Stdout
+		return s, nil
+	}
+	file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	s.out = file
+	return s, nil
+}
+
+// Record 实现 audit.Recorder，写入失败只记录日志
+func (s *AuditService) Record(event *models.AuditEvent) {
+	event.Username = truncate(event.Username, 100)
+	event.Path = truncate(event.Path, 1024)
+	event.UserAgent = truncate(event.UserAgent, 255)
+
+	if s.db != nil {
+		if err := s.db.Create(event).Error; err != nil {
+			log.Printf("写入审计记录失败: %v", err)
+		}
+		return
+	}
+
+	event.CreatedAt = time.Now()
+	line, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("序列化审计记录失败: %v", err)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.out.Write(append(line, '\n')); err != nil {
+		log.Printf("写入审计记录失败: %v", err)
+	}
+}
+
+// ListAuditEvents 按条件分页查询审计记录，按时间倒序
+func (s *AuditService) ListAuditEvents(query *models.AuditQuery) ([]models.AuditEvent, int64, error) {
+	if s.db == nil {
+		return nil, 0, ErrAuditQueryUnavailable
+	}
+	db := s.db.Model(&models.AuditEvent{})
+	for column, value := range map[string]string{
+		"username":  query.Username,
+		"cluster":   query.Cluster,
+		"namespace": query.Namespace,
+		"resource":  query.Resource,
+		"verb":      query.Verb,
+	} {
+		if value != "" {
+			db = db.Where(column+" = ?", value)
+		}
+	}
+	if !query.Since.IsZero() {
+		db = db.Where("created_at >= ?", query.Since)
+	}
+	if !query.Until.IsZero() {
+		db = db.Where("created_at < ?", query.Until)
+	}
+	if query.Failed {
+		db = db.Where("status >= ?", 400)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	events := []models.AuditEvent{}
+	err := db.Order("id DESC").Offset((query.Page - 1) * query.PageSize).Limit(query.PageSize).Find(&events).Error
+	return events, total, err
+}
+
+// PruneAuditEvents 删除超过保留时长的审计记录，返回删除的条数；保留时长不是正数时不清理
+func (s *AuditService) PruneAuditEvents() (int64, error) {
+	if s.db == nil || s.cfg.Retention <= 0 {
+		return 0, nil
+	}
+	result := s.db.Where("created_at < ?", time.Now().Add(-s.cfg.Retention)).Delete(&models.AuditEvent{})
+	return result.RowsAffected, result.Error
+}
+
+// StartRetention 立即清理一次过期记录，之后在后台按 interval 周期清理，直到 ctx 结束
+func (s *AuditService) StartRetention(ctx context.Context, interval time.Duration) {
+	prune := func() {
+		if n, err := s.PruneAuditEvents(); err != nil {
+			log.Printf("清理过期审计记录失败: %v", err)
+		} else if n > 0 {
+			log.Printf("已清理 %d 条过期审计记录", n)
+		}
+	}
+	prune()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				prune()
+			}
+		}
+	}()
+}
@@ -2,54 +2,114 @@ package service
 
 import (
 	"errors"
-	"time"
+	"fmt"
+	"strings"
 
+	"github.com/casbin/casbin/v2"
 	"github.com/ciliverse/cilikube/api/v1/models"
 	"github.com/ciliverse/cilikube/pkg/auth"
 	"github.com/ciliverse/cilikube/pkg/database"
 	"gorm.io/gorm"
 )
 
-type AuthService struct{}
+// AuthService 用户管理与登录。enforcer 不为 nil 时同步维护 Casbin 中 用户名 -> 角色 的 g 规则；
+// 密码登录依次尝试 authenticators，默认只有本地账号
+type AuthService struct {
+	enforcer       *casbin.Enforcer
+	authenticators []Authenticator
+}
 
-// Login 用户登录
-func (s *AuthService) Login(req *models.LoginRequest) (*models.LoginResponse, error) {
-	var user models.User
+func NewAuthService(enforcer *casbin.Enforcer) *AuthService {
+	return &AuthService{enforcer: enforcer, authenticators: []Authenticator{localAuthenticator{}}}
+}
 
-	// 根据用户名查找用户
-	err := database.DB.Where("username = ? AND is_active = ?", req.Username, true).First(&user).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("用户名或密码错误")
+// AddAuthenticator 在本地账号之后追加认证后端
+func (s *AuthService) AddAuthenticator(authenticator Authenticator) {
+	s.authenticators = append(s.authenticators, authenticator)
+}
+
+// SyncRoleBindings 为所有用户补齐 Casbin 角色绑定，用于启动时迁移已有用户
+func (s *AuthService) SyncRoleBindings() error {
+	if s.enforcer == nil {
+		return nil
+	}
+	var users []models.User
+	if err := database.DB.Find(&users).Error; err != nil {
+		return err
+	}
+	for i := range users {
+		if err := s.bindRole(users[i].Username, users[i].Role); err != nil {
+			return err
 		}
-		return nil, err
 	}
+	return nil
+}
 
-	// 验证密码
-	if !user.CheckPassword(req.Password) {
-		return nil, errors.New("用户名或密码错误")
+// validateUsername 用户名不能以角色前缀开头，否则会在 Casbin 中被当作角色
+func validateUsername(username string) error {
+	if strings.HasPrefix(username, auth.RolePrefix) {
+		return NewValidationError(fmt.Sprintf("用户名不能以 %q 开头", auth.RolePrefix))
 	}
+	return nil
+}
 
-	// 更新最后登录时间
-	now := time.Now()
-	user.LastLogin = &now
-	database.DB.Save(&user)
+// bindRole 将用户在所有集群上绑定到其内置角色 (admin/user)，替换另一个内置角色的绑定。
+// 通过授权管理接口添加的自定义角色绑定不受影响
+func (s *AuthService) bindRole(username, role string) error {
+	if s.enforcer == nil {
+		return nil
+	}
+	for _, builtin := range []string{models.RoleAdmin, models.RoleUser} {
+		if builtin == role {
+			continue
+		}
+		if _, err := s.enforcer.RemoveGroupingPolicy(username, auth.RoleSubject(builtin), auth.Wildcard); err != nil {
+			return fmt.Errorf("清除用户 %s 的角色绑定失败: %w", username, err)
+		}
+	}
+	has, err := s.enforcer.HasGroupingPolicy(username, auth.RoleSubject(role), auth.Wildcard)
+	if err != nil {
+		return err
+	}
+	if has {
+		return nil
+	}
+	if _, err := s.enforcer.AddGroupingPolicy(username, auth.RoleSubject(role), auth.Wildcard); err != nil {
+		return fmt.Errorf("绑定用户 %s 的角色 %s 失败: %w", username, role, err)
+	}
+	return nil
+}
 
-	// 生成JWT token
-	token, expiresAt, err := auth.GenerateToken(&user)
+// Login 用户登录。需要两步验证时返回挑战 (LoginResponse.TwoFactor)，由 CompleteTwoFactorLogin 签发 token；
+// 用户名或来源 IP 被锁定时返回 *LoginLockedError
+func (s *AuthService) Login(req *models.LoginRequest, client models.LoginClient) (*models.LoginResponse, error) {
+	if err := s.checkLockout(client, models.LoginMethodPassword, req.Username); err != nil {
+		return nil, err
+	}
+	user, err := s.authenticate(req.Username, req.Password)
 	if err != nil {
+		s.loginFailed(client, models.LoginMethodPassword, req.Username, 0, loginReasonInvalidCredentials)
 		return nil, err
 	}
-
-	return &models.LoginResponse{
-		Token:     token,
-		ExpiresAt: expiresAt,
-		User:      user.ToResponse(),
-	}, nil
+	// 发出挑战不算登录成功，以第二步的结果为准
+	challenge, err := s.twoFactorChallenge(user)
+	if err != nil || challenge != nil {
+		return challenge, err
+	}
+	s.loginSucceeded(client, models.LoginMethodPassword, user)
+	return s.issueToken(user)
 }
 
 // Register 用户注册
 func (s *AuthService) Register(req *models.RegisterRequest) (*models.UserResponse, error) {
+	if err := s.ValidatePassword(req.Password); err != nil {
+		return nil, err
+	}
+
+	if err := validateUsername(req.Username); err != nil {
+		return nil, err
+	}
+
 	// 检查用户名是否已存在
 	var count int64
 	database.DB.Model(&models.User{}).Where("username = ?", req.Username).Count(&count)
@@ -68,13 +128,16 @@ func (s *AuthService) Register(req *models.RegisterRequest) (*models.UserRespons
 		Username: req.Username,
 		Email:    req.Email,
 		Password: req.Password, // 密码会在BeforeCreate钩子中加密
-		Role:     "user",
+		Role:     models.RoleUser,
 		IsActive: true,
 	}
 
 	if err := database.DB.Create(user).Error; err != nil {
 		return nil, err
 	}
+	if err := s.bindRole(user.Username, user.Role); err != nil {
+		return nil, err
+	}
 
 	response := user.ToResponse()
 	return &response, nil
@@ -137,18 +200,37 @@ func (s *AuthService) ChangePassword(userID uint, req *models.ChangePasswordRequ
 		return err
 	}
 
+	if !user.IsLocal() {
+		return errors.New("外部账号请在身份提供方修改密码")
+	}
+
 	// 验证旧密码
 	if !user.CheckPassword(req.OldPassword) {
 		return errors.New("旧密码错误")
 	}
 
+	if err := s.ValidatePassword(req.NewPassword); err != nil {
+		return err
+	}
+	if err := s.checkPasswordHistory(&user, req.NewPassword); err != nil {
+		return err
+	}
+	if err := s.rememberPassword(user.ID, user.Password); err != nil {
+		return err
+	}
+
 	// 更新密码
 	user.Password = req.NewPassword
+	user.MustChangePassword = false
 	if err := user.HashPassword(); err != nil {
 		return err
 	}
 
-	return database.DB.Save(&user).Error
+	if err := database.DB.Save(&user).Error; err != nil {
+		return err
+	}
+	// 密码修改后所有已登录的会话都需要重新登录
+	return s.revokeUserTokens(user.ID)
 }
 
 // GetUserList 获取用户列表（管理员功能）
@@ -177,10 +259,38 @@ func (s *AuthService) GetUserList(page, pageSize int) ([]models.UserResponse, in
 
 // UpdateUserStatus 更新用户状态（管理员功能）
 func (s *AuthService) UpdateUserStatus(userID uint, isActive bool) error {
-	return database.DB.Model(&models.User{}).Where("id = ?", userID).Update("is_active", isActive).Error
+	if err := database.DB.Model(&models.User{}).Where("id = ?", userID).Update("is_active", isActive).Error; err != nil {
+		return err
+	}
+	if isActive {
+		return nil
+	}
+	// 禁用后立即使已签发的 token 失效，重新启用也不会恢复
+	return s.revokeUserTokens(userID)
 }
 
 // DeleteUser 删除用户（管理员功能）
 func (s *AuthService) DeleteUser(userID uint) error {
-	return database.DB.Delete(&models.User{}, userID).Error
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("用户不存在")
+		}
+		return err
+	}
+	if err := database.DB.Delete(&user).Error; err != nil {
+		return err
+	}
+	if err := s.revokeUserTokens(user.ID); err != nil {
+		return err
+	}
+	if err := database.DB.Where("user_id = ?", user.ID).Delete(&models.APIToken{}).Error; err != nil {
+		return fmt.Errorf("删除用户 %s 的 API token 失败: %w", user.Username, err)
+	}
+	if s.enforcer != nil {
+		if _, err := s.enforcer.RemoveFilteredGroupingPolicy(0, user.Username); err != nil {
+			return fmt.Errorf("清除用户 %s 的角色绑定失败: %w", user.Username, err)
+		}
+	}
+	return nil
 }
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"errors"
+	"log"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/database"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCredentials 认证后端不认识该用户或密码错误，AuthService.Login 会继续尝试下一个后端
+var ErrInvalidCredentials = errors.New("用户名或密码错误")
+
+// Authenticator 用户名密码认证后端。AuthService.Login 按注册顺序依次尝试，
+// 第一个认证通过的后端返回的用户用于签发 token
+type Authenticator interface {
+	// Name 后端名称，用于日志
+	Name() string
+	// Authenticate 校验用户名和密码，成功时返回数据库中的用户 (外部后端负责创建或同步该用户)
+	Authenticate(username, password string) (*models.User, error)
+}
+
+// localAuthenticator 校验 users 表中本地账号的 bcrypt 密码
+type localAuthenticator struct{}
+
+func (localAuthenticator) Name() string { return models.UserSourceLocal }
+
+func (localAuthenticator) Authenticate(username, password string) (*models.User, error) {
+	var user models.User
+	err := database.DB.Where("username = ? AND is_active = ?", username, true).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	// 外部账号没有可用的本地密码
+	if !user.IsLocal() || !user.CheckPassword(password) {
+		return nil, ErrInvalidCredentials
+	}
+	return &user, nil
+}
+
+// authenticate 依次尝试各认证后端。某个后端出错 (例如 LDAP 不可达) 时记录日志并继续，
+// 避免外部服务故障影响本地账号登录
+func (s *AuthService) authenticate(username, password string) (*models.User, error) {
+	for _, authenticator := range s.authenticators {
+		user, err := authenticator.Authenticate(username, password)
+		if err == nil {
+			return user, nil
+		}
+		if !errors.Is(err, ErrInvalidCredentials) {
+			log.Printf("认证后端 %s 校验用户 %s 失败: %v", authenticator.Name(), username, err)
+		}
+	}
+	return nil, ErrInvalidCredentials
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/auth"
+)
+
+var (
+	ErrRoleNotFound    = errors.New("角色不存在")
+	ErrPolicyNotFound  = errors.New("规则不存在")
+	ErrBindingNotFound = errors.New("角色绑定不存在")
+)
+
+// AuthzService 管理 Casbin 中的角色、规则 (p) 与角色绑定 (g)。
+// 变更通过 gorm adapter 自动保存，并立即对后续请求生效
+type AuthzService struct {
+	enforcer *casbin.Enforcer
+}
+
+func NewAuthzService(enforcer *casbin.Enforcer) *AuthzService {
+	return &AuthzService{enforcer: enforcer}
+}
+
+// isBuiltInRole 内置角色由用户记录中的 role 字段决定全局绑定，不能删除
+func isBuiltInRole(role string) bool {
+	return role == models.RoleAdmin || role == models.RoleUser
+}
+
+// ListRoles 列出所有出现在规则或绑定中的角色
+func (s *AuthzService) ListRoles() ([]models.RoleInfo, error) {
+	names := map[string]bool{models.RoleAdmin: true, models.RoleUser: true}
+	subjects, err := s.enforcer.GetAllSubjects()
+	if err != nil {
+		return nil, err
+	}
+	roles, err := s.enforcer.GetAllRoles()
+	if err != nil {
+		return nil, err
+	}
+	for _, subject := range append(subjects, roles...) {
+		if name, ok := auth.RoleName(subject); ok {
+			names[name] = true
+		}
+	}
+
+	result := make([]models.RoleInfo, 0, len(names))
+	for name := range names {
+		info, err := s.GetRole(name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *info)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// GetRole 获取角色的规则与绑定
+func (s *AuthzService) GetRole(role string) (*models.RoleInfo, error) {
+	policies, err := s.ListPolicies(role)
+	if err != nil {
+		return nil, err
+	}
+	bindings, err := s.ListRoleBindings("", role)
+	if err != nil {
+		return nil, err
+	}
+	if len(policies) == 0 && len(bindings) == 0 && !isBuiltInRole(role) {
+		return nil, fmt.Errorf("%w: %s", ErrRoleNotFound, role)
+	}
+	return &models.RoleInfo{Name: role, BuiltIn: isBuiltInRole(role), Policies: policies, Bindings: bindings}, nil
+}
+
+// DeleteRole 删除角色的所有规则和绑定
+func (s *AuthzService) DeleteRole(role string) error {
+	if isBuiltInRole(role) {
+		return NewValidationError("不能删除内置角色: " + role)
+	}
+	if _, err := s.GetRole(role); err != nil {
+		return err
+	}
+	if _, err := s.enforcer.RemoveFilteredPolicy(0, auth.RoleSubject(role)); err != nil {
+		return fmt.Errorf("删除角色 %s 的规则失败: %w", role, err)
+	}
+	if _, err := s.enforcer.RemoveFilteredGroupingPolicy(1, auth.RoleSubject(role)); err != nil {
+		return fmt.Errorf("删除角色 %s 的绑定失败: %w", role, err)
+	}
+	return nil
+}
+
+// ListPolicies 列出规则，role 为空时列出全部
+func (s *AuthzService) ListPolicies(role string) ([]models.Policy, error) {
+	var rules [][]string
+	var err error
+	if role == "" {
+		rules, err = s.enforcer.GetPolicy()
+	} else {
+		rules, err = s.enforcer.GetFilteredPolicy(0, auth.RoleSubject(role))
+	}
+	if err != nil {
+		return nil, err
+	}
+	policies := make([]models.Policy, 0, len(rules))
+	for _, rule := range rules {
+		role, ok := auth.RoleName(rule[0])
+		if len(rule) < 5 || !ok {
+			continue
+		}
+		policies = append(policies, models.Policy{Role: role, Cluster: rule[1], Namespace: rule[2], Resource: rule[3], Verb: rule[4]})
+	}
+	return policies, nil
+}
+
+// AddPolicies 为角色添加规则，已存在的规则被忽略
+func (s *AuthzService) AddPolicies(req *models.PolicyRequest) ([]models.Policy, error) {
+	rules, err := policyRules(req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.enforcer.AddPoliciesEx(rules); err != nil {
+		return nil, fmt.Errorf("添加规则失败: %w", err)
+	}
+	return s.ListPolicies(req.Role)
+}
+
+// RemovePolicies 删除角色的规则，一条都不存在时返回 ErrPolicyNotFound
+func (s *AuthzService) RemovePolicies(req *models.PolicyRequest) error {
+	rules, err := policyRules(req)
+	if err != nil {
+		return err
+	}
+	removed := false
+	for _, rule := range rules {
+		ok, err := s.enforcer.RemovePolicy(toParams(rule)...)
+		if err != nil {
+			return fmt.Errorf("删除规则失败: %w", err)
+		}
+		removed = removed || ok
+	}
+	if !removed {
+		return ErrPolicyNotFound
+	}
+	return nil
+}
+
+// ListRoleBindings 列出角色绑定，user/role 为空时不过滤
+func (s *AuthzService) ListRoleBindings(user, role string) ([]models.RoleBinding, error) {
+	if role != "" {
+		role = auth.RoleSubject(role)
+	}
+	rules, err := s.enforcer.GetFilteredGroupingPolicy(0, user, role)
+	if err != nil {
+		return nil, err
+	}
+	bindings := make([]models.RoleBinding, 0, len(rules))
+	for _, rule := range rules {
+		if len(rule) < 3 {
+			continue
+		}
+		name, _ := auth.RoleName(rule[1])
+		bindings = append(bindings, models.RoleBinding{User: rule[0], Role: name, Cluster: rule[2]})
+	}
+	return bindings, nil
+}
+
+// AddRoleBinding 将用户绑定到角色
+func (s *AuthzService) AddRoleBinding(binding *models.RoleBinding) error {
+	if err := normalizeRoleBinding(binding); err != nil {
+		return err
+	}
+	if _, err := s.enforcer.AddGroupingPolicy(binding.User, auth.RoleSubject(binding.Role), binding.Cluster); err != nil {
+		return fmt.Errorf("添加角色绑定失败: %w", err)
+	}
+	return nil
+}
+
+// RemoveRoleBinding 解除角色绑定
+func (s *AuthzService) RemoveRoleBinding(binding *models.RoleBinding) error {
+	if err := normalizeRoleBinding(binding); err != nil {
+		return err
+	}
+	removed, err := s.enforcer.RemoveGroupingPolicy(binding.User, auth.RoleSubject(binding.Role), binding.Cluster)
+	if err != nil {
+		return fmt.Errorf("删除角色绑定失败: %w", err)
+	}
+	if !removed {
+		return ErrBindingNotFound
+	}
+	return nil
+}
+
+// Check 按当前规则判断用户能否执行操作，用于调试授权配置
+func (s *AuthzService) Check(req *models.AuthzCheckRequest) (bool, error) {
+	return s.enforcer.Enforce(req.User, req.Cluster, req.Namespace, req.Resource, req.Verb)
+}
+
+// policyRules 校验请求并展开为 Casbin 规则，每个动词一条
+func policyRules(req *models.PolicyRequest) ([][]string, error) {
+	role := strings.TrimSpace(req.Role)
+	if role == "" || role == auth.Wildcard {
+		return nil, NewValidationError("无效的角色名称: " + req.Role)
+	}
+	cluster, namespace, resource := orWildcard(req.Cluster), orWildcard(req.Namespace), orWildcard(strings.ToLower(req.Resource))
+
+	rules := make([][]string, 0, len(req.Verbs))
+	for _, verb := range req.Verbs {
+		verb = strings.ToLower(strings.TrimSpace(verb))
+		if !isValidVerb(verb) {
+			return nil, NewValidationError(fmt.Sprintf("无效的动作 %q，可选值: %s 或 %s", verb, strings.Join(auth.Verbs, ", "), auth.Wildcard))
+		}
+		rules = append(rules, []string{auth.RoleSubject(role), cluster, namespace, resource, verb})
+	}
+	return rules, nil
+}
+
+// normalizeRoleBinding 校验绑定，集群为空时视为所有集群
+func normalizeRoleBinding(binding *models.RoleBinding) error {
+	binding.User = strings.TrimSpace(binding.User)
+	binding.Role = strings.TrimSpace(binding.Role)
+	binding.Cluster = orWildcard(binding.Cluster)
+	if binding.User == "" || binding.Role == "" || binding.Role == auth.Wildcard {
+		return NewValidationError("用户和角色不能为空")
+	}
+	if isBuiltInRole(binding.Role) && binding.Cluster == auth.Wildcard {
+		return NewValidationError("内置角色在所有集群上的绑定由用户的角色字段决定，请通过用户管理修改")
+	}
+	return nil
+}
+
+func isValidVerb(verb string) bool {
+	if verb == auth.Wildcard {
+		return true
+	}
+	for _, v := range auth.Verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
+
+func orWildcard(value string) string {
+	if value = strings.TrimSpace(value); value == "" {
+		return auth.Wildcard
+	}
+	return value
+}
+
+func toParams(rule []string) []interface{} {
+	params := make([]interface{}, len(rule))
+	for i, v := range rule {
+		params[i] = v
+	}
+	return params
+}
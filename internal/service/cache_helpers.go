@@ -0,0 +1,49 @@
+package service
+
+import (
+	"log"
+	"sort"
+
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// 缓存读取约定:
+//   - service 的 cache 为 nil 时 (缓存关闭或请求带 ?consistent=true) 直接访问 API Server
+//   - 缓存未同步时回退到 API Server，不影响请求结果
+//   - 带 limit 的分页请求、以及缓存中找不到的对象 (可能刚创建) 同样回退到 API Server
+//   - 从缓存返回的对象都是深拷贝，调用方可以安全修改
+
+// parseSelector 解析标签选择器
+func parseSelector(selector string) (labels.Selector, error) {
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return nil, NewValidationError("无效的标签选择器: " + err.Error())
+	}
+	return parsed, nil
+}
+
+// cachedItems 将缓存中的对象按 namespace/name 排序 (与 API Server 返回顺序一致) 并深拷贝为列表项
+func cachedItems[T any, PT interface {
+	*T
+	metav1.Object
+	DeepCopy() *T
+}](objs []PT) []T {
+	sort.Slice(objs, func(i, j int) bool {
+		if objs[i].GetNamespace() != objs[j].GetNamespace() {
+			return objs[i].GetNamespace() < objs[j].GetNamespace()
+		}
+		return objs[i].GetName() < objs[j].GetName()
+	})
+	items := make([]T, 0, len(objs))
+	for _, obj := range objs {
+		items = append(items, *obj.DeepCopy())
+	}
+	return items
+}
+
+// cacheUnavailable 记录缓存回退，便于排查缓存未生效的原因
+func cacheUnavailable(resource k8s.CachedResource, err error) {
+	log.Printf("缓存不可用，直接读取 API Server (%s): %v", resource, err)
+}
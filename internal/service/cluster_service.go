@@ -0,0 +1,538 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	// ClusterSourceConfig 集群来自 config.yaml，只能通过修改配置文件变更
+	ClusterSourceConfig = "config"
+	// ClusterSourceRegistry 集群通过 /api/v1/clusters 接口添加
+	ClusterSourceRegistry = "registry"
+)
+
+// defaultProbeTimeout 按需探测集群时的超时时间
+const defaultProbeTimeout = 5 * time.Second
+
+// reservedClusterNames 与 /api/v1/clusters 下的静态路由冲突的名称
+var reservedClusterNames = map[string]bool{"validate": true}
+
+var (
+	ErrClusterNotFound = errors.New("集群不存在")
+	ErrClusterExists   = errors.New("集群已存在")
+)
+
+// storedCluster 是持久化层中的一条集群记录
+type storedCluster struct {
+	Info    models.ClusterInfo
+	Content []byte
+}
+
+// clusterStore 持久化通过 API 添加的集群 (文件或数据库)
+type clusterStore interface {
+	Load() ([]storedCluster, error)
+	Save(info models.ClusterInfo, content []byte) (models.ClusterInfo, error)
+	Update(oldName string, info models.ClusterInfo) (models.ClusterInfo, error)
+	Delete(name string) error
+}
+
+// ClusterService 管理集群登记表，并将集群热注册到 ClientManager
+type ClusterService struct {
+	mu       sync.Mutex
+	manager  *k8s.ClientManager
+	store    clusterStore
+	registry map[string]models.ClusterInfo // 通过 API 添加的集群
+	enforcer *casbin.Enforcer              // 重命名集群时改写 Casbin 规则，认证关闭时为 nil
+
+	probeTimeout time.Duration
+}
+
+// NewClusterService 创建基于文件登记表 (registryFile + kubeconfigDir) 的集群服务
+func NewClusterService(manager *k8s.ClientManager, registryFile, kubeconfigDir string) *ClusterService {
+	return newClusterService(manager, &fileClusterStore{registryFile: registryFile, kubeconfigDir: kubeconfigDir})
+}
+
+// NewClusterServiceWithDB 创建基于数据库的集群服务
+func NewClusterServiceWithDB(manager *k8s.ClientManager, db *gorm.DB) *ClusterService {
+	return newClusterService(manager, &dbClusterStore{db: db})
+}
+
+func newClusterService(manager *k8s.ClientManager, store clusterStore) *ClusterService {
+	return &ClusterService{
+		manager:  manager,
+		store:    store,
+		registry: make(map[string]models.ClusterInfo),
+
+		probeTimeout: defaultProbeTimeout,
+	}
+}
+
+// SetEnforcer 设置 Casbin enforcer，之后重命名集群会同步改写以集群名为键的 p/g 规则
+func (s *ClusterService) SetEnforcer(enforcer *casbin.Enforcer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.enforcer = enforcer
+}
+
+// LoadRegistry 读取持久化的集群并注册到 ClientManager。
+// 单个集群加载失败只记录日志，不影响其余集群。
+func (s *ClusterService) LoadRegistry() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clusters, err := s.store.Load()
+	if err != nil {
+		return err
+	}
+	for _, cluster := range clusters {
+		name := cluster.Info.Name
+		if s.manager.HasClient(name) {
+			log.Printf("警告: 登记表中的集群 '%s' 与配置文件中的集群重名，已忽略。", name)
+			continue
+		}
+		client, err := k8s.NewClientFromKubeconfig(cluster.Content)
+		if err != nil {
+			log.Printf("警告: 加载集群 '%s' 失败: %v", name, err)
+			continue
+		}
+		s.manager.AddClient(name, client)
+		s.registry[name] = cluster.Info
+		log.Printf("已从登记表加载集群 '%s'。", name)
+	}
+	return nil
+}
+
+// List 列出所有已注册集群 (包括配置文件中的集群)
+func (s *ClusterService) List() []models.ClusterDetail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := s.manager.ListClusterNames()
+	clusters := make([]models.ClusterDetail, 0, len(names))
+	for _, name := range names {
+		clusters = append(clusters, s.detailLocked(name))
+	}
+	return clusters
+}
+
+// Get 返回单个集群的信息及实时连接状态
+func (s *ClusterService) Get(name string) (*models.ClusterDetail, error) {
+	health, err := s.Health(name, true)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.manager.GetClientByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrClusterNotFound, name)
+	}
+
+	s.mu.Lock()
+	detail := s.detailLocked(name)
+	s.mu.Unlock()
+
+	if client.Config != nil {
+		detail.Server = client.Config.Host
+	}
+	detail.Status = string(health.Status)
+	detail.Connected = health.Status == k8s.ClusterStatusHealthy
+	detail.Version = health.Version
+	detail.Error = health.Error
+	return &detail, nil
+}
+
+// Health 返回集群健康状态；refresh 为 true 时立即重新探测而不是使用后台探测的结果
+func (s *ClusterService) Health(name string, refresh bool) (*k8s.ClusterHealth, error) {
+	if !refresh {
+		health, ok := s.manager.GetClusterHealth(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrClusterNotFound, name)
+		}
+		return &health, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.probeTimeout)
+	defer cancel()
+	health, err := s.manager.ProbeCluster(ctx, name)
+	if errors.Is(err, k8s.ErrClientNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrClusterNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// Validate 校验 kubeconfig 内容能否连通集群，不做任何持久化
+func (s *ClusterService) Validate(kubeconfigContent string) (*models.ClusterDetail, error) {
+	client, err := k8s.NewClientFromKubeconfig([]byte(kubeconfigContent))
+	if err != nil {
+		return nil, NewValidationError(err.Error())
+	}
+	detail := &models.ClusterDetail{Server: client.Config.Host}
+	ctx, cancel := context.WithTimeout(context.Background(), s.probeTimeout)
+	defer cancel()
+	version, err := client.ServerVersion(ctx)
+	if err != nil {
+		detail.Error = err.Error()
+		return detail, nil
+	}
+	detail.Connected = true
+	detail.Version = version.GitVersion
+	return detail, nil
+}
+
+// Add 校验 kubeconfig 连通性后持久化并热注册集群
+func (s *ClusterService) Add(req *models.AddClusterRequest) (*models.ClusterDetail, error) {
+	name := strings.TrimSpace(req.Name)
+	if !utils.ValidateResourceName(name) || reservedClusterNames[name] {
+		return nil, NewValidationError("无效的集群名称: " + name)
+	}
+
+	client, err := k8s.NewClientFromKubeconfig([]byte(req.KubeconfigContent))
+	if err != nil {
+		return nil, NewValidationError(err.Error())
+	}
+	probeCtx, cancelProbe := context.WithTimeout(context.Background(), s.probeTimeout)
+	defer cancelProbe()
+	if _, err := client.ServerVersion(probeCtx); err != nil {
+		return nil, NewValidationError("无法连接集群: " + err.Error())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.manager.HasClient(name) {
+		return nil, fmt.Errorf("%w: %s", ErrClusterExists, name)
+	}
+	info, err := s.store.Save(models.ClusterInfo{Name: name, Description: req.Description}, []byte(req.KubeconfigContent))
+	if err != nil {
+		return nil, fmt.Errorf("保存集群失败: %w", err)
+	}
+	s.manager.AddClient(name, client)
+	s.registry[name] = info
+
+	// 立即记录一次探测结果，新集群无需等待下一轮后台探测
+	ctx, cancel := context.WithTimeout(context.Background(), s.probeTimeout)
+	defer cancel()
+	s.manager.ProbeCluster(ctx, name)
+
+	detail := s.detailLocked(name)
+	detail.Server = client.Config.Host
+	detail.Connected = true
+	return &detail, nil
+}
+
+// Update 重命名集群或修改描述，只允许修改通过 API 添加的集群
+func (s *ClusterService) Update(name string, req *models.UpdateClusterRequest) (*models.ClusterDetail, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := s.registeredLocked(name)
+	if err != nil {
+		return nil, err
+	}
+
+	newName := strings.TrimSpace(req.Name)
+	if newName == "" {
+		newName = name
+	}
+	if newName != name {
+		if !utils.ValidateResourceName(newName) || reservedClusterNames[newName] {
+			return nil, NewValidationError("无效的集群名称: " + newName)
+		}
+		if s.manager.HasClient(newName) {
+			return nil, fmt.Errorf("%w: %s", ErrClusterExists, newName)
+		}
+	}
+
+	// 先改写授权规则：规则改写失败时拒绝重命名，避免新名称下丢失授权或旧名称残留授权
+	renamedRules := false
+	if newName != name && s.enforcer != nil {
+		if _, err := auth.RenameClusterRules(s.enforcer, name, newName); err != nil {
+			return nil, err
+		}
+		renamedRules = true
+	}
+
+	info.Name = newName
+	if req.Description != nil {
+		info.Description = *req.Description
+	}
+	info, err = s.store.Update(name, info)
+	if err != nil {
+		if renamedRules {
+			if _, rerr := auth.RenameClusterRules(s.enforcer, newName, name); rerr != nil {
+				log.Printf("警告: 回滚集群 '%s' 的 Casbin 规则失败: %v", name, rerr)
+			}
+		}
+		return nil, fmt.Errorf("保存集群失败: %w", err)
+	}
+	if newName != name {
+		if err := s.manager.RenameClient(name, newName); err != nil {
+			return nil, err
+		}
+		delete(s.registry, name)
+	}
+	s.registry[newName] = info
+
+	detail := s.detailLocked(newName)
+	return &detail, nil
+}
+
+// Delete 注销并删除通过 API 添加的集群
+func (s *ClusterService) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.registeredLocked(name); err != nil {
+		return err
+	}
+	if err := s.store.Delete(name); err != nil {
+		return fmt.Errorf("删除集群失败: %w", err)
+	}
+	delete(s.registry, name)
+	s.manager.RemoveClient(name)
+	return nil
+}
+
+// Activate 将集群设为未显式指定集群的请求所使用的默认集群
+func (s *ClusterService) Activate(name string) (*models.ClusterDetail, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.manager.HasClient(name) {
+		return nil, fmt.Errorf("%w: %s", ErrClusterNotFound, name)
+	}
+	if err := s.manager.SetActiveClient(name); err != nil {
+		return nil, err
+	}
+	detail := s.detailLocked(name)
+	return &detail, nil
+}
+
+// registeredLocked 返回通过 API 添加的集群；配置文件中的集群不可修改
+func (s *ClusterService) registeredLocked(name string) (models.ClusterInfo, error) {
+	if info, ok := s.registry[name]; ok {
+		return info, nil
+	}
+	if s.manager.HasClient(name) {
+		return models.ClusterInfo{}, NewValidationError("集群 '" + name + "' 定义在配置文件中，请修改配置文件")
+	}
+	return models.ClusterInfo{}, fmt.Errorf("%w: %s", ErrClusterNotFound, name)
+}
+
+// detailLocked 组装集群的静态信息 (不探测连接)
+func (s *ClusterService) detailLocked(name string) models.ClusterDetail {
+	detail := models.ClusterDetail{Source: ClusterSourceConfig}
+	if info, ok := s.registry[name]; ok {
+		detail.ClusterInfo = info
+		detail.Source = ClusterSourceRegistry
+	}
+	detail.Name = name
+	detail.IsActive = name == s.manager.GetActiveClusterName()
+	if health, ok := s.manager.GetClusterHealth(name); ok {
+		detail.Status = string(health.Status)
+	}
+	return detail
+}
+
+// --- 文件存储 ---
+
+// fileClusterStore 将登记表保存为 JSON (集群名 -> ClusterInfo)，
+// kubeconfig 内容保存在 kubeconfigDir/<name>.yaml。
+// 相对路径的 kubeconfigPath 以登记文件所在目录为基准。
+type fileClusterStore struct {
+	registryFile  string
+	kubeconfigDir string
+}
+
+func (f *fileClusterStore) Load() ([]storedCluster, error) {
+	entries, err := f.readRegistry()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	clusters := make([]storedCluster, 0, len(entries))
+	for _, name := range names {
+		info := entries[name]
+		info.Name = name
+		info.IsActive = false
+		content, err := os.ReadFile(f.resolvePath(info.KubeconfigPath))
+		if err != nil {
+			log.Printf("警告: 读取集群 '%s' 的 kubeconfig 失败: %v", name, err)
+			continue
+		}
+		clusters = append(clusters, storedCluster{Info: info, Content: content})
+	}
+	return clusters, nil
+}
+
+func (f *fileClusterStore) Save(info models.ClusterInfo, content []byte) (models.ClusterInfo, error) {
+	entries, err := f.readRegistry()
+	if err != nil {
+		return info, err
+	}
+	if err := os.MkdirAll(f.kubeconfigDir, 0o700); err != nil {
+		return info, err
+	}
+	path := filepath.Join(f.kubeconfigDir, info.Name+".yaml")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		return info, err
+	}
+	info.KubeconfigPath = f.relativePath(path)
+	entries[info.Name] = info
+	if err := f.writeRegistry(entries); err != nil {
+		os.Remove(path)
+		return info, err
+	}
+	return info, nil
+}
+
+func (f *fileClusterStore) Update(oldName string, info models.ClusterInfo) (models.ClusterInfo, error) {
+	entries, err := f.readRegistry()
+	if err != nil {
+		return info, err
+	}
+	if info.Name != oldName {
+		oldPath := f.resolvePath(info.KubeconfigPath)
+		newPath := filepath.Join(f.kubeconfigDir, info.Name+".yaml")
+		if err := os.Rename(oldPath, newPath); err != nil {
+			return info, err
+		}
+		info.KubeconfigPath = f.relativePath(newPath)
+		delete(entries, oldName)
+	}
+	entries[info.Name] = info
+	return info, f.writeRegistry(entries)
+}
+
+func (f *fileClusterStore) Delete(name string) error {
+	entries, err := f.readRegistry()
+	if err != nil {
+		return err
+	}
+	info, ok := entries[name]
+	if !ok {
+		return nil
+	}
+	delete(entries, name)
+	if err := f.writeRegistry(entries); err != nil {
+		return err
+	}
+	if err := os.Remove(f.resolvePath(info.KubeconfigPath)); err != nil && !os.IsNotExist(err) {
+		log.Printf("警告: 删除集群 '%s' 的 kubeconfig 文件失败: %v", name, err)
+	}
+	return nil
+}
+
+func (f *fileClusterStore) readRegistry() (map[string]models.ClusterInfo, error) {
+	entries := make(map[string]models.ClusterInfo)
+	data, err := os.ReadFile(f.registryFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, fmt.Errorf("读取集群登记文件失败: %w", err)
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return entries, nil
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("解析集群登记文件失败: %w", err)
+	}
+	return entries, nil
+}
+
+func (f *fileClusterStore) writeRegistry(entries map[string]models.ClusterInfo) error {
+	for name, info := range entries {
+		info.IsActive = false // 运行时字段，不持久化
+		entries[name] = info
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.registryFile), 0o755); err != nil {
+		return err
+	}
+	// 先写临时文件再重命名，避免写入中断导致登记文件损坏
+	tmp := f.registryFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.registryFile)
+}
+
+func (f *fileClusterStore) resolvePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(f.registryFile), path)
+}
+
+func (f *fileClusterStore) relativePath(path string) string {
+	rel, err := filepath.Rel(filepath.Dir(f.registryFile), path)
+	if err != nil {
+		return path
+	}
+	return "./" + filepath.ToSlash(rel)
+}
+
+// --- 数据库存储 ---
+
+// dbClusterStore 将集群及 kubeconfig 内容保存在 clusters 表中
+type dbClusterStore struct {
+	db *gorm.DB
+}
+
+func (d *dbClusterStore) Load() ([]storedCluster, error) {
+	var records []models.Cluster
+	if err := d.db.Order("name").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	clusters := make([]storedCluster, 0, len(records))
+	for _, record := range records {
+		clusters = append(clusters, storedCluster{
+			Info:    models.ClusterInfo{Name: record.Name, Description: record.Description},
+			Content: []byte(record.KubeconfigContent),
+		})
+	}
+	return clusters, nil
+}
+
+func (d *dbClusterStore) Save(info models.ClusterInfo, content []byte) (models.ClusterInfo, error) {
+	record := models.Cluster{Name: info.Name, Description: info.Description, KubeconfigContent: string(content)}
+	return info, d.db.Create(&record).Error
+}
+
+func (d *dbClusterStore) Update(oldName string, info models.ClusterInfo) (models.ClusterInfo, error) {
+	err := d.db.Model(&models.Cluster{}).Where("name = ?", oldName).
+		Updates(map[string]interface{}{"name": info.Name, "description": info.Description}).Error
+	return info, err
+}
+
+func (d *dbClusterStore) Delete(name string) error {
+	return d.db.Where("name = ?", name).Delete(&models.Cluster{}).Error
+}
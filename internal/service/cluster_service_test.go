@@ -0,0 +1,203 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// kubeconfigFor 返回指向 handler 所模拟 API Server 的 kubeconfig
+func kubeconfigFor(t *testing.T, handler http.HandlerFunc) string {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: fake
+  cluster:
+    server: %s
+contexts:
+- name: fake
+  context:
+    cluster: fake
+    user: fake
+current-context: fake
+users:
+- name: fake
+  user:
+    token: fake-token
+`, server.URL)
+}
+
+// versionServer 只响应 /version 的 API Server
+func versionServer(t *testing.T) string {
+	return kubeconfigFor(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"major":"1","minor":"33","gitVersion":"v1.33.0"}`)
+	})
+}
+
+// newFileClusterService 在临时目录中使用文件登记表，manager 中已有配置文件集群 "main"
+func newFileClusterService(t *testing.T, dir string) (*ClusterService, *k8s.ClientManager) {
+	t.Helper()
+	manager := k8s.NewClientManager()
+	manager.AddClient("main", &k8s.Client{Clientset: fake.NewSimpleClientset()})
+	return NewClusterService(manager, filepath.Join(dir, "cluster.json"), filepath.Join(dir, "kubeconfigs")), manager
+}
+
+// clusterSources 集群名 -> 来源
+func clusterSources(clusters []models.ClusterDetail) map[string]string {
+	sources := map[string]string{}
+	for _, cluster := range clusters {
+		sources[cluster.Name] = cluster.Source
+	}
+	return sources
+}
+
+func TestClusterService_Validate(t *testing.T) {
+	svc, _ := newFileClusterService(t, t.TempDir())
+	svc.probeTimeout = 200 * time.Millisecond
+
+	detail, err := svc.Validate(versionServer(t))
+	require.NoError(t, err)
+	assert.True(t, detail.Connected)
+	assert.Equal(t, "v1.33.0", detail.Version)
+	assert.NotEmpty(t, detail.Server)
+
+	// 连接失败不是请求错误，结果中带上原因
+	detail, err = svc.Validate(kubeconfigFor(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	require.NoError(t, err)
+	assert.False(t, detail.Connected)
+	assert.NotEmpty(t, detail.Error)
+
+	// 无响应的 API Server 在探测超时后返回
+	start := time.Now()
+	detail, err = svc.Validate(kubeconfigFor(t, func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	require.NoError(t, err)
+	assert.False(t, detail.Connected)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	var validationErr *ValidationError
+	_, err = svc.Validate("not a kubeconfig")
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func TestClusterService_Add(t *testing.T) {
+	dir := t.TempDir()
+	svc, manager := newFileClusterService(t, dir)
+
+	detail, err := svc.Add(&models.AddClusterRequest{Name: " staging ", KubeconfigContent: versionServer(t), Description: "预发环境"})
+	require.NoError(t, err)
+	assert.Equal(t, "staging", detail.Name)
+	assert.Equal(t, ClusterSourceRegistry, detail.Source)
+	assert.Equal(t, string(k8s.ClusterStatusHealthy), detail.Status, "添加后立即探测一次")
+	assert.True(t, detail.Connected)
+	assert.False(t, detail.IsActive, "已有激活集群时不切换")
+	assert.True(t, manager.HasClient("staging"))
+	assert.FileExists(t, filepath.Join(dir, "kubeconfigs", "staging.yaml"))
+
+	var validationErr *ValidationError
+	tests := []struct {
+		name string
+		req  models.AddClusterRequest
+	}{
+		{"invalid name", models.AddClusterRequest{Name: "Bad_Name", KubeconfigContent: versionServer(t)}},
+		{"reserved name", models.AddClusterRequest{Name: "validate", KubeconfigContent: versionServer(t)}},
+		{"invalid kubeconfig", models.AddClusterRequest{Name: "broken", KubeconfigContent: "not a kubeconfig"}},
+		{"unreachable cluster", models.AddClusterRequest{Name: "down", KubeconfigContent: kubeconfigFor(t, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		})}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Add(&tt.req)
+			assert.ErrorAs(t, err, &validationErr)
+			assert.False(t, manager.HasClient(tt.req.Name))
+		})
+	}
+
+	// 与登记表或配置文件中的集群重名
+	for _, name := range []string{"staging", "main"} {
+		_, err = svc.Add(&models.AddClusterRequest{Name: name, KubeconfigContent: versionServer(t)})
+		assert.ErrorIs(t, err, ErrClusterExists)
+	}
+	assert.Equal(t, map[string]string{"main": ClusterSourceConfig, "staging": ClusterSourceRegistry}, clusterSources(svc.List()))
+}
+
+func TestClusterService_ActivateAndDelete(t *testing.T) {
+	dir := t.TempDir()
+	svc, manager := newFileClusterService(t, dir)
+	_, err := svc.Add(&models.AddClusterRequest{Name: "staging", KubeconfigContent: versionServer(t)})
+	require.NoError(t, err)
+
+	detail, err := svc.Activate("staging")
+	require.NoError(t, err)
+	assert.True(t, detail.IsActive)
+	assert.Equal(t, "staging", manager.GetActiveClusterName())
+	_, err = svc.Activate("missing")
+	assert.ErrorIs(t, err, ErrClusterNotFound)
+	assert.Equal(t, "staging", manager.GetActiveClusterName())
+
+	// 配置文件中的集群不能通过接口删除
+	var validationErr *ValidationError
+	assert.ErrorAs(t, svc.Delete("main"), &validationErr)
+	assert.True(t, manager.HasClient("main"))
+	assert.ErrorIs(t, svc.Delete("missing"), ErrClusterNotFound)
+
+	require.NoError(t, svc.Delete("staging"))
+	assert.False(t, manager.HasClient("staging"))
+	assert.Empty(t, manager.GetActiveClusterName(), "删除激活集群后没有激活集群")
+	assert.NoFileExists(t, filepath.Join(dir, "kubeconfigs", "staging.yaml"))
+	assert.Equal(t, map[string]string{"main": ClusterSourceConfig}, clusterSources(svc.List()))
+	assert.ErrorIs(t, svc.Delete("staging"), ErrClusterNotFound)
+}
+
+func TestClusterService_LoadRegistryAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	svc, _ := newFileClusterService(t, dir)
+	for _, name := range []string{"staging", "qa", "gone"} {
+		_, err := svc.Add(&models.AddClusterRequest{Name: name, KubeconfigContent: versionServer(t), Description: name + " 环境"})
+		require.NoError(t, err)
+	}
+	description := "新的描述"
+	_, err := svc.Update("qa", &models.UpdateClusterRequest{Name: "test", Description: &description})
+	require.NoError(t, err)
+	// kubeconfig 丢失的集群跳过，不影响其余集群
+	require.NoError(t, os.Remove(filepath.Join(dir, "kubeconfigs", "gone.yaml")))
+
+	// 重启：新的 manager 中配置文件也定义了 test，登记表中的同名集群被忽略
+	restarted, manager := newFileClusterService(t, dir)
+	manager.AddClient("test", &k8s.Client{Clientset: fake.NewSimpleClientset()})
+	require.NoError(t, restarted.LoadRegistry())
+
+	assert.Equal(t, map[string]string{"main": ClusterSourceConfig, "staging": ClusterSourceRegistry, "test": ClusterSourceConfig}, clusterSources(restarted.List()))
+	detail, err := restarted.Get("staging")
+	require.NoError(t, err)
+	assert.Equal(t, "staging 环境", detail.Description)
+	assert.True(t, detail.Connected)
+
+	// 没有冲突时重命名后的集群以新名称加载
+	again, _ := newFileClusterService(t, dir)
+	require.NoError(t, again.LoadRegistry())
+	assert.Equal(t, map[string]string{"main": ClusterSourceConfig, "staging": ClusterSourceRegistry, "test": ClusterSourceRegistry}, clusterSources(again.List()))
+	for _, cluster := range again.List() {
+		if cluster.Name == "test" {
+			assert.Equal(t, description, cluster.Description)
+		}
+	}
+}
@@ -3,34 +3,58 @@ package service
 import (
 	"context"
 
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 )
 
 type ConfigMapService struct {
+	writeOptions
 	client kubernetes.Interface
+	cache  *k8s.ResourceCache // nil 时直接读取 API Server
 }
 
-func NewConfigMapService(client kubernetes.Interface) *ConfigMapService {
-	return &ConfigMapService{client: client}
+func NewConfigMapService(client kubernetes.Interface, cache *k8s.ResourceCache) *ConfigMapService {
+	return &ConfigMapService{client: client, cache: cache}
 }
 
 // Get retrieves a single ConfigMap by namespace and name.
 func (s *ConfigMapService) Get(namespace, name string) (*corev1.ConfigMap, error) {
+	if s.cache != nil {
+		if lister, err := s.cache.ConfigMaps(); err == nil {
+			if obj, err := lister.ConfigMaps(namespace).Get(name); err == nil {
+				return obj.DeepCopy(), nil
+			}
+		}
+	}
 	return s.client.CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metav1.GetOptions{})
 }
 
-// List retrieves ConfigMaps within a specific namespace.
-func (s *ConfigMapService) List(namespace, labelSelector string, limit int64) (*corev1.ConfigMapList, error) {
-	listOptions := metav1.ListOptions{}
-	if labelSelector != "" {
-		listOptions.LabelSelector = labelSelector
-	}
-	if limit > 0 {
-		listOptions.Limit = limit
+// List 按统一查询参数 (分页、选择器、排序、名称搜索) 列出 ConfigMap
+func (s *ConfigMapService) List(namespace string, query models.ListQuery) (*corev1.ConfigMapList, models.ListMeta, error) {
+	fromCache := cacheList(s.cache, k8s.CachedConfigMaps, func(selector labels.Selector) ([]*corev1.ConfigMap, error) {
+		lister, err := s.cache.ConfigMaps()
+		if err != nil {
+			return nil, err
+		}
+		return lister.ConfigMaps(namespace).List(selector)
+	})
+	items, meta, err := listPage(query, fromCache, func(opts metav1.ListOptions) ([]corev1.ConfigMap, metav1.ListMeta, error) {
+		list, err := s.client.CoreV1().ConfigMaps(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
+	if err != nil {
+		return nil, models.ListMeta{}, err
 	}
-	return s.client.CoreV1().ConfigMaps(namespace).List(context.TODO(), listOptions)
+	return &corev1.ConfigMapList{ListMeta: k8sListMeta(meta), Items: items}, meta, nil
 }
 
 // Create creates a new ConfigMap in the specified namespace.
@@ -45,7 +69,7 @@ func (s *ConfigMapService) Create(namespace string, cm *corev1.ConfigMap) (*core
 		return nil, NewValidationError("ConfigMap name cannot be empty")
 	}
 
-	return s.client.CoreV1().ConfigMaps(namespace).Create(context.TODO(), cm, metav1.CreateOptions{})
+	return s.client.CoreV1().ConfigMaps(namespace).Create(context.TODO(), cm, s.createOptions())
 }
 
 // Update updates an existing ConfigMap.
@@ -65,12 +89,17 @@ func (s *ConfigMapService) Update(namespace string, cm *corev1.ConfigMap) (*core
 	// if err != nil { return nil, err }
 	// cm.ResourceVersion = existingCM.ResourceVersion
 
-	return s.client.CoreV1().ConfigMaps(namespace).Update(context.TODO(), cm, metav1.UpdateOptions{})
+	return s.client.CoreV1().ConfigMaps(namespace).Update(context.TODO(), cm, s.updateOptions())
+}
+
+// Patch 按 patchType 修改ConfigMap
+func (s *ConfigMapService) Patch(namespace, name string, patchType types.PatchType, data []byte) (*corev1.ConfigMap, error) {
+	return s.client.CoreV1().ConfigMaps(namespace).Patch(context.TODO(), name, patchType, data, s.patchOptions(patchType))
 }
 
 // Delete deletes a ConfigMap by namespace and name.
 func (s *ConfigMapService) Delete(namespace, name string) error {
-	return s.client.CoreV1().ConfigMaps(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	return s.client.CoreV1().ConfigMaps(namespace).Delete(context.TODO(), name, s.deleteOptions())
 }
 
 // --- Re-use or define ValidationError ---
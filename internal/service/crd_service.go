@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// crdGVR CustomResourceDefinition 本身的资源定义
+var crdGVR = apiextensionsv1.SchemeGroupVersion.WithResource("customresourcedefinitions")
+
+// CRDService 浏览已安装的 CRD，并按其 schema 渲染自定义资源列表。
+// 通过 dynamic client 读取，无需额外的 apiextensions clientset
+type CRDService struct {
+	dynamic dynamic.Interface
+}
+
+func NewCRDService(dynamicClient dynamic.Interface) *CRDService {
+	return &CRDService{dynamic: dynamicClient}
+}
+
+// List 列出 CRD，支持统一查询参数
+func (s *CRDService) List(query models.ListQuery) ([]apiextensionsv1.CustomResourceDefinition, models.ListMeta, error) {
+	items, meta, err := listPage(query, nil, func(opts metav1.ListOptions) ([]unstructured.Unstructured, metav1.ListMeta, error) {
+		list, err := s.dynamic.Resource(crdGVR).List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, metav1.ListMeta{Continue: list.GetContinue(), RemainingItemCount: list.GetRemainingItemCount()}, nil
+	})
+	if err != nil {
+		return nil, models.ListMeta{}, err
+	}
+
+	crds := make([]apiextensionsv1.CustomResourceDefinition, 0, len(items))
+	for i := range items {
+		crd, err := toCRD(&items[i])
+		if err != nil {
+			return nil, models.ListMeta{}, err
+		}
+		crds = append(crds, *crd)
+	}
+	return crds, meta, nil
+}
+
+// Get 获取 CRD，name 为 <plural>.<group>
+func (s *CRDService) Get(name string) (*apiextensionsv1.CustomResourceDefinition, error) {
+	obj, err := s.dynamic.Resource(crdGVR).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return toCRD(obj)
+}
+
+// ListCustomResources 列出 CRD 定义的自定义资源，并按 additionalPrinterColumns 渲染为表格。
+// version 为空时使用存储版本；namespace 为空时列出所有命名空间
+func (s *CRDService) ListCustomResources(crdName, version, namespace string, query models.ListQuery) (*models.CustomResourceTable, error) {
+	crd, err := s.Get(crdName)
+	if err != nil {
+		return nil, err
+	}
+	crdVersion, err := selectCRDVersion(crd, version)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaced := crd.Spec.Scope == apiextensionsv1.NamespaceScoped
+	if !namespaced && namespace != "" {
+		return nil, NewValidationError(fmt.Sprintf("%s 是集群级资源，不能指定命名空间", crd.Name))
+	}
+
+	gvr := schema.GroupVersionResource{Group: crd.Spec.Group, Version: crdVersion.Name, Resource: crd.Spec.Names.Plural}
+	var client dynamic.ResourceInterface = s.dynamic.Resource(gvr)
+	if namespaced {
+		client = s.dynamic.Resource(gvr).Namespace(namespace)
+	}
+
+	items, meta, err := listPage(query, nil, func(opts metav1.ListOptions) ([]unstructured.Unstructured, metav1.ListMeta, error) {
+		list, err := client.List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, metav1.ListMeta{Continue: list.GetContinue(), RemainingItemCount: list.GetRemainingItemCount()}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	table := &models.CustomResourceTable{
+		Group:      gvr.Group,
+		Version:    gvr.Version,
+		Resource:   gvr.Resource,
+		Kind:       crd.Spec.Names.Kind,
+		Namespaced: namespaced,
+		Columns:    models.ToCRDPrinterColumns(crdVersion),
+		Rows:       make([]models.CustomResourceRow, 0, len(items)),
+		ListMeta:   meta,
+	}
+	for i := range items {
+		table.Rows = append(table.Rows, models.ToCustomResourceRow(&items[i], table.Columns))
+	}
+	return table, nil
+}
+
+// selectCRDVersion 返回指定版本，未指定时返回存储版本 (若未提供服务则取第一个提供服务的版本)
+func selectCRDVersion(crd *apiextensionsv1.CustomResourceDefinition, version string) (*apiextensionsv1.CustomResourceDefinitionVersion, error) {
+	var served *apiextensionsv1.CustomResourceDefinitionVersion
+	for i := range crd.Spec.Versions {
+		v := &crd.Spec.Versions[i]
+		if version != "" {
+			if v.Name != version {
+				continue
+			}
+			if !v.Served {
+				return nil, NewValidationError(fmt.Sprintf("%s 的版本 %s 未提供服务", crd.Name, version))
+			}
+			return v, nil
+		}
+		if v.Served && v.Storage {
+			return v, nil
+		}
+		if v.Served && served == nil {
+			served = v
+		}
+	}
+	if version != "" {
+		return nil, NewValidationError(fmt.Sprintf("%s 没有版本 %s", crd.Name, version))
+	}
+	if served == nil {
+		return nil, NewValidationError(fmt.Sprintf("%s 没有提供服务的版本", crd.Name))
+	}
+	return served, nil
+}
+
+func toCRD(obj *unstructured.Unstructured) (*apiextensionsv1.CustomResourceDefinition, error) {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), crd); err != nil {
+		return nil, fmt.Errorf("解析 CRD '%s' 失败: %w", obj.GetName(), err)
+	}
+	return crd, nil
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ManualInstantiateAnnotation kubectl create job --from=cronjob 在手动触发的 Job 上设置的注解
+const ManualInstantiateAnnotation = "cronjob.kubernetes.io/instantiate"
+
+type CronJobService struct {
+	writeOptions
+	client kubernetes.Interface
+	cache  *k8s.ResourceCache // nil 时直接读取 API Server
+}
+
+func NewCronJobService(client kubernetes.Interface, cache *k8s.ResourceCache) *CronJobService {
+	return &CronJobService{client: client, cache: cache}
+}
+
+// 获取单个CronJob
+func (s *CronJobService) Get(namespace, name string) (*batchv1.CronJob, error) {
+	return s.client.BatchV1().CronJobs(namespace).Get(
+		context.TODO(),
+		name,
+		metav1.GetOptions{},
+	)
+}
+
+// 创建CronJob
+func (s *CronJobService) Create(namespace string, cronJob *batchv1.CronJob) (*batchv1.CronJob, error) {
+
+	if cronJob.Namespace != "" && cronJob.Namespace != namespace {
+		return nil, NewValidationError("cronJob namespace conflicts with path parameter")
+	}
+
+	return s.client.BatchV1().CronJobs(namespace).Create(
+		context.TODO(),
+		cronJob,
+		s.createOptions(),
+	)
+}
+
+// 更新CronJob
+func (s *CronJobService) Update(namespace string, cronJob *batchv1.CronJob) (*batchv1.CronJob, error) {
+	return s.client.BatchV1().CronJobs(namespace).Update(
+		context.TODO(),
+		cronJob,
+		s.updateOptions(),
+	)
+}
+
+// Patch 按 patchType 修改CronJob
+func (s *CronJobService) Patch(namespace, name string, patchType types.PatchType, data []byte) (*batchv1.CronJob, error) {
+	return s.client.BatchV1().CronJobs(namespace).Patch(context.TODO(), name, patchType, data, s.patchOptions(patchType))
+}
+
+// 删除CronJob，连同它创建的 Job 和 Pod
+func (s *CronJobService) Delete(namespace, name string) error {
+	return s.client.BatchV1().CronJobs(namespace).Delete(
+		context.TODO(),
+		name,
+		backgroundDeleteOptions(s.deleteOptions()),
+	)
+}
+
+// List 按统一查询参数 (分页、选择器、排序、名称搜索) 列出 CronJob
+func (s *CronJobService) List(namespace string, query models.ListQuery) (*batchv1.CronJobList, models.ListMeta, error) {
+	items, meta, err := listPage(query, nil, func(opts metav1.ListOptions) ([]batchv1.CronJob, metav1.ListMeta, error) {
+		list, err := s.client.BatchV1().CronJobs(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
+	if err != nil {
+		return nil, models.ListMeta{}, err
+	}
+	return &batchv1.CronJobList{ListMeta: k8sListMeta(meta), Items: items}, meta, nil
+}
+
+// Watch机制实现
+func (s *CronJobService) Watch(namespace, selector string) (watch.Interface, error) {
+	return s.client.BatchV1().CronJobs(namespace).Watch(
+		context.TODO(),
+		metav1.ListOptions{
+			LabelSelector:  selector,
+			Watch:          true,
+			TimeoutSeconds: int64ptr(1800),
+		},
+	)
+}
+
+// SetSuspend 暂停或恢复调度，已经在运行的 Job 不受影响
+func (s *CronJobService) SetSuspend(namespace, name string, suspend bool) (*batchv1.CronJob, error) {
+	return s.Patch(namespace, name, types.StrategicMergePatchType, suspendPatch(suspend))
+}
+
+// Trigger 按 jobTemplate 立即创建一个 Job，与 kubectl create job --from=cronjob/<name> 相同：
+// Job 归属于该 CronJob 并带有 instantiate=manual 注解。jobName 为空时生成 <cronjob>-manual-xxxxx
+func (s *CronJobService) Trigger(namespace, name, jobName string) (*batchv1.Job, error) {
+	cronJob, err := s.Get(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if jobName == "" {
+		const suffix = len("-manual-") + 5
+		base := cronJob.Name
+		if len(base) > 63-suffix {
+			base = base[:63-suffix]
+		}
+		jobName = base + "-manual-" + utilrand.String(5)
+	}
+
+	annotations := map[string]string{ManualInstantiateAnnotation: "manual"}
+	for key, value := range cronJob.Spec.JobTemplate.Annotations {
+		annotations[key] = value
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            jobName,
+			Namespace:       namespace,
+			Labels:          cronJob.Spec.JobTemplate.Labels,
+			Annotations:     annotations,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(cronJob, batchv1.SchemeGroupVersion.WithKind("CronJob"))},
+		},
+		Spec: cronJob.Spec.JobTemplate.Spec,
+	}
+	return s.client.BatchV1().Jobs(namespace).Create(context.TODO(), job, s.createOptions())
+}
+
+// History 列出 CronJob 创建的 Job (包括手动触发的)，按创建时间倒序。
+// Job 的标签复制自 jobTemplate，先按这些标签缩小范围 (优先读取缓存)，再按 ownerReference 确认归属。
+// 保留数量由 successfulJobsHistoryLimit / failedJobsHistoryLimit 决定
+func (s *CronJobService) History(namespace, name string) ([]batchv1.Job, error) {
+	cronJob, err := s.Get(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.listJobs(namespace, labels.SelectorFromSet(cronJob.Spec.JobTemplate.Labels))
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]batchv1.Job, 0, len(list))
+	for _, job := range list {
+		if metav1.IsControlledBy(&job, cronJob) {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[j].CreationTimestamp.Before(&jobs[i].CreationTimestamp)
+	})
+	return jobs, nil
+}
+
+// listJobs 列出匹配选择器的 Job，优先读取缓存
+func (s *CronJobService) listJobs(namespace string, selector labels.Selector) ([]batchv1.Job, error) {
+	if s.cache != nil {
+		lister, err := s.cache.Jobs()
+		if err == nil {
+			items, err := lister.Jobs(namespace).List(selector)
+			if err != nil {
+				return nil, err
+			}
+			return cachedItems(items), nil
+		}
+		cacheUnavailable(k8s.CachedJobs, err)
+	}
+	list, err := s.client.BatchV1().Jobs(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
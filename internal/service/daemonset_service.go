@@ -3,23 +3,36 @@ package service
 import (
 	"context"
 
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+
 	appsv1 "k8s.io/api/apps/v1"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 )
 
 type DaemonSetService struct {
+	writeOptions
 	client kubernetes.Interface
+	cache  *k8s.ResourceCache // nil 时直接读取 API Server
 }
 
-func NewDaemonSetService(client kubernetes.Interface) *DaemonSetService {
-	return &DaemonSetService{client: client}
+func NewDaemonSetService(client kubernetes.Interface, cache *k8s.ResourceCache) *DaemonSetService {
+	return &DaemonSetService{client: client, cache: cache}
 }
 
 // 获取单个DaemonSet
 func (s *DaemonSetService) Get(namespace, name string) (*appsv1.DaemonSet, error) {
+	if s.cache != nil {
+		if lister, err := s.cache.DaemonSets(); err == nil {
+			if obj, err := lister.DaemonSets(namespace).Get(name); err == nil {
+				return obj.DeepCopy(), nil
+			}
+		}
+	}
 	return s.client.AppsV1().DaemonSets(namespace).Get(
 		context.TODO(),
 		name,
@@ -36,7 +49,7 @@ func (s *DaemonSetService) Create(namespace string, daemonset *appsv1.DaemonSet)
 	return s.client.AppsV1().DaemonSets(namespace).Create(
 		context.TODO(),
 		daemonset,
-		metav1.CreateOptions{},
+		s.createOptions(),
 	)
 }
 
@@ -45,34 +58,55 @@ func (s *DaemonSetService) Update(namespace string, daemonset *appsv1.DaemonSet)
 	return s.client.AppsV1().DaemonSets(namespace).Update(
 		context.TODO(),
 		daemonset,
-		metav1.UpdateOptions{},
+		s.updateOptions(),
 	)
 }
 
+// Patch 按 patchType 修改DaemonSet
+func (s *DaemonSetService) Patch(namespace, name string, patchType types.PatchType, data []byte) (*appsv1.DaemonSet, error) {
+	return s.client.AppsV1().DaemonSets(namespace).Patch(context.TODO(), name, patchType, data, s.patchOptions(patchType))
+}
+
 // 删除DaemonSet
 func (s *DaemonSetService) Delete(namespace, name string) error {
 	return s.client.AppsV1().DaemonSets(namespace).Delete(
 		context.TODO(),
 		name,
-		metav1.DeleteOptions{},
+		s.deleteOptions(),
 	)
 }
 
-// 列表查询（支持分页和标签过滤）
-func (s *DaemonSetService) List(namespace, selector string) (*appsv1.DaemonSetList, error) {
-	if namespace == "" {
-		namespace = corev1.NamespaceAll
+// List 按统一查询参数 (分页、选择器、排序、名称搜索) 列出 DaemonSet
+func (s *DaemonSetService) List(namespace string, query models.ListQuery) (*appsv1.DaemonSetList, models.ListMeta, error) {
+	fromCache := cacheList(s.cache, k8s.CachedDaemonSets, func(selector labels.Selector) ([]*appsv1.DaemonSet, error) {
+		lister, err := s.cache.DaemonSets()
+		if err != nil {
+			return nil, err
+		}
+		return lister.DaemonSets(namespace).List(selector)
+	})
+	items, meta, err := listPage(query, fromCache, func(opts metav1.ListOptions) ([]appsv1.DaemonSet, metav1.ListMeta, error) {
+		list, err := s.client.AppsV1().DaemonSets(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
+	if err != nil {
+		return nil, models.ListMeta{}, err
 	}
-	return s.client.AppsV1().DaemonSets(namespace).List(
-		context.TODO(),
-		metav1.ListOptions{
-			LabelSelector: selector,
-		},
-	)
+	return &appsv1.DaemonSetList{ListMeta: k8sListMeta(meta), Items: items}, meta, nil
 }
 
 // Watch机制实现
 func (s *DaemonSetService) Watch(namespace, selector string) (watch.Interface, error) {
+	if s.cache != nil {
+		w, err := s.cache.Watch(k8s.CachedDaemonSets, namespace, selector)
+		if err == nil {
+			return w, nil
+		}
+		cacheUnavailable(k8s.CachedDaemonSets, err)
+	}
 	return s.client.AppsV1().DaemonSets(namespace).Watch(
 		context.TODO(),
 		metav1.ListOptions{
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// RevisionAnnotation Deployment 控制器写在 Deployment 和 ReplicaSet 上的版本号
+	RevisionAnnotation = "deployment.kubernetes.io/revision"
+	// ChangeCauseAnnotation 记录变更原因，kubectl 的 --record 或用户手动设置
+	ChangeCauseAnnotation = "kubernetes.io/change-cause"
+)
+
+var (
+	ErrNoRolloutHistory = errors.New("没有可回滚的历史版本")
+	ErrRevisionNotFound = errors.New("找不到指定的版本")
+	ErrDeploymentPaused = errors.New("Deployment 已暂停，请先执行 resume")
+)
+
+// rollbackSkippedAnnotations 回滚时保留 Deployment 自身的值、不从 ReplicaSet 复制的注解，与 kubectl rollout undo 一致
+var rollbackSkippedAnnotations = map[string]bool{
+	corev1.LastAppliedConfigAnnotation:          true,
+	RevisionAnnotation:                          true,
+	"deployment.kubernetes.io/revision-history": true,
+	"deployment.kubernetes.io/desired-replicas": true,
+	"deployment.kubernetes.io/max-replicas":     true,
+	appsv1.DeprecatedRollbackTo:                 true,
+}
+
+// History 列出 Deployment 的历史版本，每个由它创建的 ReplicaSet 对应一个版本，按版本号升序
+func (s *DeploymentService) History(namespace, name string) ([]models.DeploymentRevision, error) {
+	deployment, err := s.Get(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	replicaSets, err := s.ownedReplicaSets(deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	current := revisionOf(&deployment.ObjectMeta)
+	revisions := make([]models.DeploymentRevision, 0, len(replicaSets))
+	var previous []models.ContainerImage
+	for i := range replicaSets {
+		rs := &replicaSets[i]
+		images := containerImages(&rs.Spec.Template)
+		revision := models.DeploymentRevision{
+			Revision:    revisionOf(&rs.ObjectMeta),
+			ReplicaSet:  rs.Name,
+			ChangeCause: rs.Annotations[ChangeCauseAnnotation],
+			Images:      images,
+			Current:     revisionOf(&rs.ObjectMeta) == current,
+			CreatedAt:   rs.CreationTimestamp,
+		}
+		if rs.Spec.Replicas != nil {
+			revision.Replicas = *rs.Spec.Replicas
+		}
+		if i > 0 {
+			revision.ImageChanges = diffImages(previous, images)
+		}
+		revisions = append(revisions, revision)
+		previous = images
+	}
+	return revisions, nil
+}
+
+// Rollback 把 Pod 模板恢复为 toRevision 对应 ReplicaSet 的模板，toRevision 为 0 时回滚到上一个版本。
+// 行为与 kubectl rollout undo 一致：模板已相同时不做修改 (skipped 为 true)，
+// 注解同时从目标 ReplicaSet 复制，控制器随后为其分配新的版本号
+func (s *DeploymentService) Rollback(namespace, name string, toRevision int64) (deployment *appsv1.Deployment, revision int64, skipped bool, err error) {
+	deployment, err = s.getLive(namespace, name)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if deployment.Spec.Paused {
+		return nil, 0, false, ErrDeploymentPaused
+	}
+	replicaSets, err := s.ownedReplicaSets(deployment)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	if toRevision == 0 {
+		if toRevision = previousRevision(replicaSets); toRevision == 0 {
+			return nil, 0, false, ErrNoRolloutHistory
+		}
+	}
+	var target *appsv1.ReplicaSet
+	for i := range replicaSets {
+		if revisionOf(&replicaSets[i].ObjectMeta) == toRevision {
+			target = &replicaSets[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, 0, false, ErrRevisionNotFound
+	}
+
+	template := target.Spec.Template.DeepCopy()
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	if templatesEqualIgnoreHash(template, &deployment.Spec.Template) {
+		return deployment, toRevision, true, nil
+	}
+
+	annotations := map[string]string{}
+	for key := range rollbackSkippedAnnotations {
+		if value, ok := deployment.Annotations[key]; ok {
+			annotations[key] = value
+		}
+	}
+	for key, value := range target.Annotations {
+		if !rollbackSkippedAnnotations[key] {
+			annotations[key] = value
+		}
+	}
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "replace", "path": "/spec/template", "value": template},
+		{"op": "replace", "path": "/metadata/annotations", "value": annotations},
+	})
+	if err != nil {
+		return nil, 0, false, err
+	}
+	deployment, err = s.Patch(namespace, name, types.JSONPatchType, patch)
+	return deployment, toRevision, false, err
+}
+
+// ownedReplicaSets 返回由该 Deployment 控制的 ReplicaSet，按版本号升序；没有版本号的 ReplicaSet 被忽略
+func (s *DeploymentService) ownedReplicaSets(deployment *appsv1.Deployment) ([]appsv1.ReplicaSet, error) {
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.listReplicaSets(deployment.Namespace, selector)
+	if err != nil {
+		return nil, err
+	}
+	owned := make([]appsv1.ReplicaSet, 0, len(list.Items))
+	for _, rs := range list.Items {
+		if metav1.IsControlledBy(&rs, deployment) && revisionOf(&rs.ObjectMeta) > 0 {
+			owned = append(owned, rs)
+		}
+	}
+	sort.Slice(owned, func(i, j int) bool {
+		return revisionOf(&owned[i].ObjectMeta) < revisionOf(&owned[j].ObjectMeta)
+	})
+	return owned, nil
+}
+
+// previousRevision 第二大的版本号，即当前版本之前的版本；只有一个版本时返回 0
+func previousRevision(sorted []appsv1.ReplicaSet) int64 {
+	if len(sorted) < 2 {
+		return 0
+	}
+	return revisionOf(&sorted[len(sorted)-2].ObjectMeta)
+}
+
+func revisionOf(meta *metav1.ObjectMeta) int64 {
+	revision, err := strconv.ParseInt(meta.Annotations[RevisionAnnotation], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return revision
+}
+
+// templatesEqualIgnoreHash 比较 Pod 模板，忽略控制器添加的 pod-template-hash 标签
+func templatesEqualIgnoreHash(a, b *corev1.PodTemplateSpec) bool {
+	a, b = a.DeepCopy(), b.DeepCopy()
+	delete(a.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	delete(b.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	return equality.Semantic.DeepEqual(a, b)
+}
+
+func containerImages(template *corev1.PodTemplateSpec) []models.ContainerImage {
+	images := make([]models.ContainerImage, 0, len(template.Spec.InitContainers)+len(template.Spec.Containers))
+	for _, containers := range [][]corev1.Container{template.Spec.InitContainers, template.Spec.Containers} {
+		for _, container := range containers {
+			images = append(images, models.ContainerImage{Container: container.Name, Image: container.Image})
+		}
+	}
+	return images
+}
+
+// diffImages 按容器名比较两个版本的镜像
+func diffImages(from, to []models.ContainerImage) []models.ImageChange {
+	before := make(map[string]string, len(from))
+	for _, image := range from {
+		before[image.Container] = image.Image
+	}
+	var changes []models.ImageChange
+	for _, image := range to {
+		old, ok := before[image.Container]
+		if !ok || old != image.Image {
+			changes = append(changes, models.ImageChange{Container: image.Container, From: old, To: image.Image})
+		}
+		delete(before, image.Container)
+	}
+	for _, image := range from {
+		if _, removed := before[image.Container]; removed {
+			changes = append(changes, models.ImageChange{Container: image.Container, From: image.Image})
+		}
+	}
+	return changes
+}
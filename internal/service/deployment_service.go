@@ -2,25 +2,49 @@ package service
 
 import (
 	"context"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 )
 
 type DeploymentService struct {
+	writeOptions
 	client kubernetes.Interface
+	cache  *k8s.ResourceCache // nil 时直接读取 API Server
 }
 
-func NewDeploymentService(client kubernetes.Interface) *DeploymentService {
-	return &DeploymentService{client: client}
+func NewDeploymentService(client kubernetes.Interface, cache *k8s.ResourceCache) *DeploymentService {
+	return &DeploymentService{client: client, cache: cache}
 }
 
 // 获取单个Deployment
 func (s *DeploymentService) Get(namespace, name string) (*appsv1.Deployment, error) {
+	if s.cache != nil {
+		if lister, err := s.cache.Deployments(); err == nil {
+			if obj, err := lister.Deployments(namespace).Get(name); err == nil {
+				return obj.DeepCopy(), nil
+			}
+		}
+	}
+	return s.client.AppsV1().Deployments(namespace).Get(
+		context.TODO(),
+		name,
+		metav1.GetOptions{},
+	)
+}
+
+// getLive 绕过缓存直接从 API Server 获取 Deployment，用于读-改-写，避免旧 resourceVersion 导致冲突
+func (s *DeploymentService) getLive(namespace, name string) (*appsv1.Deployment, error) {
 	return s.client.AppsV1().Deployments(namespace).Get(
 		context.TODO(),
 		name,
@@ -38,7 +62,7 @@ func (s *DeploymentService) Create(namespace string, deployment *appsv1.Deployme
 	return s.client.AppsV1().Deployments(namespace).Create(
 		context.TODO(),
 		deployment,
-		metav1.CreateOptions{},
+		s.createOptions(),
 	)
 }
 
@@ -61,7 +85,7 @@ func (s *DeploymentService) Update(namespace, name string, deployment *appsv1.De
 	return s.client.AppsV1().Deployments(namespace).Update(
 		context.TODO(),
 		deployment,
-		metav1.UpdateOptions{},
+		s.updateOptions(),
 	)
 }
 
@@ -70,20 +94,49 @@ func (s *DeploymentService) Delete(namespace, name string) error {
 	return s.client.AppsV1().Deployments(namespace).Delete(
 		context.TODO(),
 		name,
-		metav1.DeleteOptions{},
+		s.deleteOptions(),
 	)
 }
 
-// ListDeployments 列出所有Deployment
-func (s *DeploymentService) List(namespace string) (*appsv1.DeploymentList, error) {
-	return s.client.AppsV1().Deployments(namespace).List(
-		context.TODO(),
-		metav1.ListOptions{},
-	)
+// List 按统一查询参数 (分页、选择器、排序、名称搜索) 列出 Deployment
+func (s *DeploymentService) List(namespace string, query models.ListQuery) (*appsv1.DeploymentList, models.ListMeta, error) {
+	fromCache := cacheList(s.cache, k8s.CachedDeployments, func(selector labels.Selector) ([]*appsv1.Deployment, error) {
+		lister, err := s.cache.Deployments()
+		if err != nil {
+			return nil, err
+		}
+		return lister.Deployments(namespace).List(selector)
+	})
+	items, meta, err := listPage(query, fromCache, func(opts metav1.ListOptions) ([]appsv1.Deployment, metav1.ListMeta, error) {
+		list, err := s.client.AppsV1().Deployments(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
+	if err != nil {
+		return nil, models.ListMeta{}, err
+	}
+	return &appsv1.DeploymentList{ListMeta: k8sListMeta(meta), Items: items}, meta, nil
 }
 
 // ListDeploymentsByLabels 根据标签过滤列出Deployment
 func (s *DeploymentService) ListByLabels(namespace, selector string) (*appsv1.DeploymentList, error) {
+	if s.cache != nil {
+		lister, err := s.cache.Deployments()
+		if err == nil {
+			parsed, err := parseSelector(selector)
+			if err != nil {
+				return nil, err
+			}
+			items, err := lister.Deployments(namespace).List(parsed)
+			if err != nil {
+				return nil, err
+			}
+			return &appsv1.DeploymentList{Items: cachedItems(items)}, nil
+		}
+		cacheUnavailable(k8s.CachedDeployments, err)
+	}
 	return s.client.AppsV1().Deployments(namespace).List(
 		context.TODO(),
 		metav1.ListOptions{
@@ -94,6 +147,13 @@ func (s *DeploymentService) ListByLabels(namespace, selector string) (*appsv1.De
 
 // WatchDeployments 实现Watch机制
 func (s *DeploymentService) Watch(namespace, selector string) (watch.Interface, error) {
+	if s.cache != nil {
+		w, err := s.cache.Watch(k8s.CachedDeployments, namespace, selector)
+		if err == nil {
+			return w, nil
+		}
+		cacheUnavailable(k8s.CachedDeployments, err)
+	}
 	return s.client.AppsV1().Deployments(namespace).Watch(
 		context.TODO(),
 		metav1.ListOptions{
@@ -104,90 +164,71 @@ func (s *DeploymentService) Watch(namespace, selector string) (watch.Interface,
 	)
 }
 
-// PatchDeployment 实现Patch机制
-// func (s *DeploymentService) Patch(namespace, name string, patchData []byte) (*appsv1.Deployment, error) {
-// 	return s.client.AppsV1().Deployments(namespace).Patch(
-// 		context.TODO(),
-// 		name,
-// 		types.StrategicMergePatchType,
-// 		patchData,
-// 		metav1.PatchOptions{},
-// 	)
-// }
+// Patch 按 patchType 修改Deployment
+func (s *DeploymentService) Patch(namespace, name string, patchType types.PatchType, data []byte) (*appsv1.Deployment, error) {
+	return s.client.AppsV1().Deployments(namespace).Patch(context.TODO(), name, patchType, data, s.patchOptions(patchType))
+}
 
 // ReplaceDeployment 实现Replace机制
 func (s *DeploymentService) Replace(namespace, name string, deployment *appsv1.Deployment) (*appsv1.Deployment, error) {
 	return s.client.AppsV1().Deployments(namespace).Update(
 		context.TODO(),
 		deployment,
-		metav1.UpdateOptions{},
+		s.updateOptions(),
 	)
 }
 
-// RollbackDeployment 实现Deployment回滚
-
-// ScaleDeployment 实现Deployment扩缩容
+// Scale 通过 scale 子资源修改副本数，resourceVersion 冲突时重新读取后重试，
+// 不会覆盖并发修改的其他字段
 func (s *DeploymentService) Scale(namespace, name string, replicas int32) (*appsv1.Deployment, error) {
-	deployment, err := s.Get(namespace, name)
+	deployments := s.client.AppsV1().Deployments(namespace)
+	var updated *autoscalingv1.Scale
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		scale, err := deployments.GetScale(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		scale.Spec.Replicas = replicas
+		updated, err = deployments.UpdateScale(context.TODO(), name, scale, s.updateOptions())
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	deployment.Spec.Replicas = &replicas
-	return s.client.AppsV1().Deployments(namespace).Update(
-		context.TODO(),
-		deployment,
-		metav1.UpdateOptions{},
-	)
-}
-
-// PauseDeployment 实现Deployment暂停
-func (s *DeploymentService) Pause(namespace, name string) (*appsv1.Deployment, error) {
-	deployment, err := s.Get(namespace, name)
+	live, err := s.getLive(namespace, name)
 	if err != nil {
 		return nil, err
 	}
+	// dry-run 没有持久化，返回应用了 dry-run 结果的对象而不是集群中的现状
+	if s.IsDryRun() && updated != nil {
+		live.Spec.Replicas = &updated.Spec.Replicas
+	}
+	return live, nil
+}
 
-	deployment.Spec.Paused = true
-	return s.client.AppsV1().Deployments(namespace).Update(
-		context.TODO(),
-		deployment,
-		metav1.UpdateOptions{},
-	)
+// Pause 暂停 Deployment 的发布，与 kubectl rollout pause 相同使用 patch，不受并发修改影响
+func (s *DeploymentService) Pause(namespace, name string) (*appsv1.Deployment, error) {
+	return s.Patch(namespace, name, types.StrategicMergePatchType, []byte(`{"spec":{"paused":true}}`))
 }
 
-// ResumeDeployment 实现Deployment恢复
+// Resume 恢复已暂停的 Deployment
 func (s *DeploymentService) Resume(namespace, name string) (*appsv1.Deployment, error) {
-	deployment, err := s.Get(namespace, name)
-	if err != nil {
-		return nil, err
-	}
-
-	deployment.Spec.Paused = false
-	return s.client.AppsV1().Deployments(namespace).Update(
-		context.TODO(),
-		deployment,
-		metav1.UpdateOptions{},
-	)
+	return s.Patch(namespace, name, types.StrategicMergePatchType, []byte(`{"spec":{"paused":false}}`))
 }
 
-// PodList 实现获取Deployment关联的Pod列表查询（支持分页和标签过滤）
-func (s *DeploymentService) PodList(namespace, deploymentName string, limit int64) (*corev1.PodList, error) {
+// PodList 实现获取Deployment关联的Pod列表查询（支持分页、排序和标签过滤）
+// query 中的 labelSelector 与 ReplicaSet 的选择器取交集
+func (s *DeploymentService) PodList(namespace, deploymentName string, query models.ListQuery) (*corev1.PodList, models.ListMeta, error) {
 	// 1. 获取 Deployment
 	deployment, err := s.Get(namespace, deploymentName)
 	if err != nil {
-		return nil, err
+		return nil, models.ListMeta{}, err
 	}
 
 	// 2. 获取 ReplicaSet（Deployment 控制器会创建 ReplicaSet）
-	rsList, err := s.client.AppsV1().ReplicaSets(namespace).List(
-		context.TODO(),
-		metav1.ListOptions{
-			LabelSelector: labels.SelectorFromSet(deployment.Spec.Selector.MatchLabels).String(),
-		},
-	)
+	rsList, err := s.listReplicaSets(namespace, labels.SelectorFromSet(deployment.Spec.Selector.MatchLabels))
 	if err != nil {
-		return nil, err
+		return nil, models.ListMeta{}, err
 	}
 
 	// 3. 初始化一个空的 Selector
@@ -199,7 +240,7 @@ func (s *DeploymentService) PodList(namespace, deploymentName string, limit int6
 			// 5. 将 ReplicaSet 的 LabelSelector 转换为 Selector
 			selector, err := metav1.LabelSelectorAsSelector(rs.Spec.Selector)
 			if err != nil {
-				return nil, err
+				return nil, models.ListMeta{}, err
 			}
 
 			// 6. 将 Selector 转换为 Requirements
@@ -215,15 +256,35 @@ func (s *DeploymentService) PodList(namespace, deploymentName string, limit int6
 
 	// 8. 如果没有活跃的 ReplicaSet，返回空列表
 	if allSelectors.String() == "" {
-		return &corev1.PodList{}, nil
+		return &corev1.PodList{}, models.ListMeta{}, nil
 	}
 
 	// 9. 查询 Pod 列表
-	return s.client.CoreV1().Pods(namespace).List(
+	if query.LabelSelector != "" {
+		query.LabelSelector = allSelectors.String() + "," + query.LabelSelector
+	} else {
+		query.LabelSelector = allSelectors.String()
+	}
+	return NewPodService(s.client, nil, s.cache).List(namespace, query)
+}
+
+// listReplicaSets 列出匹配选择器的 ReplicaSet，优先读取缓存
+func (s *DeploymentService) listReplicaSets(namespace string, selector labels.Selector) (*appsv1.ReplicaSetList, error) {
+	if s.cache != nil {
+		lister, err := s.cache.ReplicaSets()
+		if err == nil {
+			items, err := lister.ReplicaSets(namespace).List(selector)
+			if err != nil {
+				return nil, err
+			}
+			return &appsv1.ReplicaSetList{Items: cachedItems(items)}, nil
+		}
+		cacheUnavailable(k8s.CachedReplicaSets, err)
+	}
+	return s.client.AppsV1().ReplicaSets(namespace).List(
 		context.TODO(),
 		metav1.ListOptions{
-			LabelSelector: allSelectors.String(),
-			Limit:         limit,
+			LabelSelector: selector.String(),
 		},
 	)
 }
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/pmezard/go-difflib/difflib"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// DiffService 通过服务端 dry-run 预览修改结果
+type DiffService struct {
+	dynamic dynamic.Interface
+}
+
+func NewDiffService(dynamicClient dynamic.Interface) *DiffService {
+	return &DiffService{dynamic: dynamicClient}
+}
+
+// Diff 预览对 gvr 下名为 name 的对象提交 obj 的结果；namespace 为空表示集群级资源
+func (s *DiffService) Diff(gvr schema.GroupVersionResource, namespace, name string, obj *unstructured.Unstructured, mode string) (*models.DiffResponse, error) {
+	var client dynamic.ResourceInterface = s.dynamic.Resource(gvr)
+	if namespace != "" {
+		client = s.dynamic.Resource(gvr).Namespace(namespace)
+	}
+	return diffObject(client, namespace, name, obj, mode)
+}
+
+// diffObject 读取当前对象，按 mode 做 dry-run，并比较两者
+func diffObject(client dynamic.ResourceInterface, namespace, name string, obj *unstructured.Unstructured, mode string) (*models.DiffResponse, error) {
+	if mode == "" {
+		mode = models.DiffModeUpdate
+	}
+	if mode != models.DiffModeUpdate && mode != models.DiffModeApply {
+		return nil, NewValidationError("无效的 mode: " + mode + "，可选值: update, apply")
+	}
+	if obj.GetName() == "" {
+		obj.SetName(name)
+	} else if obj.GetName() != name {
+		return nil, NewValidationError("metadata.name 与路径参数不一致")
+	}
+	if obj.GetNamespace() != "" && obj.GetNamespace() != namespace {
+		return nil, NewValidationError("metadata.namespace 与路径参数不一致")
+	}
+	obj.SetNamespace(namespace)
+
+	live, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		live = nil
+	}
+
+	dryRun := []string{metav1.DryRunAll}
+	var result *unstructured.Unstructured
+	switch {
+	case mode == models.DiffModeApply:
+		result, err = client.Apply(context.TODO(), name, obj, metav1.ApplyOptions{DryRun: dryRun, Force: true, FieldManager: FieldManager})
+	case live == nil:
+		result, err = client.Create(context.TODO(), obj, metav1.CreateOptions{DryRun: dryRun, FieldManager: FieldManager})
+	default:
+		if obj.GetResourceVersion() == "" {
+			obj.SetResourceVersion(live.GetResourceVersion())
+		}
+		result, err = client.Update(context.TODO(), obj, metav1.UpdateOptions{DryRun: dryRun, FieldManager: FieldManager})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.DiffResponse{
+		APIVersion: result.GetAPIVersion(),
+		Kind:       result.GetKind(),
+		Namespace:  namespace,
+		Name:       name,
+		Mode:       mode,
+		Exists:     live != nil,
+		Result:     diffContent(result),
+	}
+	if live != nil {
+		response.Live = diffContent(live)
+	}
+	response.Changes = diffValues("", response.Live, response.Result)
+	if live == nil {
+		// 对象不存在时整体视为新增
+		response.Changes = []models.DiffChange{{Path: "", Op: "add", New: response.Result}}
+	}
+	response.Changed = len(response.Changes) > 0
+	response.Unified, err = unifiedDiff(response.Live, response.Result)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// diffContent 去掉每次写入都会变化或与本次修改无关的字段
+func diffContent(obj *unstructured.Unstructured) map[string]interface{} {
+	content := comparableContent(obj)
+	delete(content, "status")
+	return content
+}
+
+// diffValues 递归比较两个 JSON 值，返回按路径排序的变化列表
+func diffValues(path string, oldValue, newValue interface{}) []models.DiffChange {
+	oldMap, oldIsMap := oldValue.(map[string]interface{})
+	newMap, newIsMap := newValue.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		keys := make([]string, 0, len(oldMap)+len(newMap))
+		for key := range oldMap {
+			keys = append(keys, key)
+		}
+		for key := range newMap {
+			if _, ok := oldMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		var changes []models.DiffChange
+		for _, key := range keys {
+			childPath := path + "/" + escapeJSONPointer(key)
+			oldChild, inOld := oldMap[key]
+			newChild, inNew := newMap[key]
+			switch {
+			case !inOld:
+				changes = append(changes, models.DiffChange{Path: childPath, Op: "add", New: newChild})
+			case !inNew:
+				changes = append(changes, models.DiffChange{Path: childPath, Op: "remove", Old: oldChild})
+			default:
+				changes = append(changes, diffValues(childPath, oldChild, newChild)...)
+			}
+		}
+		return changes
+	}
+
+	oldSlice, oldIsSlice := oldValue.([]interface{})
+	newSlice, newIsSlice := newValue.([]interface{})
+	if oldIsSlice && newIsSlice {
+		var changes []models.DiffChange
+		for i := 0; i < len(oldSlice) || i < len(newSlice); i++ {
+			childPath := fmt.Sprintf("%s/%d", path, i)
+			switch {
+			case i >= len(oldSlice):
+				changes = append(changes, models.DiffChange{Path: childPath, Op: "add", New: newSlice[i]})
+			case i >= len(newSlice):
+				changes = append(changes, models.DiffChange{Path: childPath, Op: "remove", Old: oldSlice[i]})
+			default:
+				changes = append(changes, diffValues(childPath, oldSlice[i], newSlice[i])...)
+			}
+		}
+		return changes
+	}
+
+	if reflect.DeepEqual(oldValue, newValue) {
+		return nil
+	}
+	return []models.DiffChange{{Path: path, Op: "replace", Old: oldValue, New: newValue}}
+}
+
+func escapeJSONPointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// unifiedDiff 以 YAML 形式生成 unified diff，对象不存在时左侧为空
+func unifiedDiff(live, result map[string]interface{}) (string, error) {
+	var liveYAML []byte
+	if live != nil {
+		var err error
+		if liveYAML, err = yaml.Marshal(live); err != nil {
+			return "", err
+		}
+	}
+	resultYAML, err := yaml.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(liveYAML)),
+		B:        difflib.SplitLines(string(resultYAML)),
+		FromFile: "live",
+		ToFile:   "dry-run",
+		Context:  3,
+	})
+}
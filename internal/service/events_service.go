@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"github.com/ciliverse/cilikube/api/v1/models"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"log"
@@ -18,21 +19,27 @@ func NewEventsService(client kubernetes.Interface) *EventsService {
 	}
 }
 
-func (s *EventsService) List(namespace string) *models.EventList {
-	results := &models.EventList{
-		Items: []models.Event{},
-		Total: 0,
-	}
-	events, err := s.client.CoreV1().Events(namespace).List(context.Background(), metav1.ListOptions{})
+// List 按统一查询参数列出事件
+func (s *EventsService) List(namespace string, query models.ListQuery) (*models.EventList, error) {
+	items, meta, err := listPage(query, nil, func(opts metav1.ListOptions) ([]corev1.Event, metav1.ListMeta, error) {
+		list, err := s.client.CoreV1().Events(namespace).List(context.Background(), opts)
+		if err != nil {
+			log.Printf("获取事件列表失败：%s", err)
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
 	if err != nil {
-		log.Printf("获取事件列表失败：%s", err)
-		return results
+		return nil, err
+	}
+	results := &models.EventList{
+		Items:    make([]models.Event, 0, len(items)),
+		ListMeta: meta,
 	}
-	for _, event := range events.Items {
+	for _, event := range items {
 		results.Items = append(results.Items, models.K8sEventToEvent(&event))
 	}
-	results.Total = len(results.Items)
-	return results
+	return results, nil
 }
 
 func (s *EventsService) Get(namespace, name string) models.Event {
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/database"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrExternalUserConflict = errors.New("用户名已被其他账号使用")
+	ErrUserInactive         = errors.New("用户已被禁用")
+)
+
+// ExternalIdentity 外部身份提供方认证通过的用户
+type ExternalIdentity struct {
+	Source     string // models.UserSourceOIDC 等
+	ExternalID string // 在该来源中唯一且不变的标识
+	Username   string
+	Email      string
+	Role       string   // 内置角色 admin/user
+	Roles      []string // 额外绑定的 Casbin 角色
+	// ManagedRoles 由该来源负责绑定的角色：不在 Roles 中的绑定会被移除，其余手工绑定不受影响
+	ManagedRoles []string
+}
+
+// LoginExternal 外部身份登录：首次登录时创建用户 (JIT)，之后同步邮箱与角色，并签发 CiliKube token
+func (s *AuthService) LoginExternal(id *ExternalIdentity) (*models.LoginResponse, error) {
+	user, err := s.provisionExternalUser(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueToken(user)
+}
+
+// provisionExternalUser 按来源和唯一标识查找用户，不存在时创建，存在时同步邮箱与角色
+func (s *AuthService) provisionExternalUser(id *ExternalIdentity) (*models.User, error) {
+	if id.Username == "" || id.ExternalID == "" {
+		return nil, errors.New("外部身份缺少用户名或唯一标识")
+	}
+	if err := validateUsername(id.Username); err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	err := database.DB.Where("source = ? AND external_id = ?", id.Source, id.ExternalID).First(&user).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		var count int64
+		database.DB.Unscoped().Model(&models.User{}).Where("username = ?", id.Username).Count(&count)
+		if count > 0 {
+			// 不与同名的本地账号或其他来源的账号合并，避免通过外部身份接管已有账号
+			return nil, fmt.Errorf("%w: %s", ErrExternalUserConflict, id.Username)
+		}
+		password, err := randomPassword()
+		if err != nil {
+			return nil, err
+		}
+		user = models.User{
+			Username:   id.Username,
+			Email:      s.externalEmail(id, 0),
+			Password:   password, // 外部账号不能使用密码登录，仅为满足非空约束
+			Role:       id.Role,
+			Source:     id.Source,
+			ExternalID: id.ExternalID,
+			IsActive:   true,
+		}
+		if err := database.DB.Create(&user).Error; err != nil {
+			return nil, fmt.Errorf("创建用户失败: %w", err)
+		}
+	case err != nil:
+		return nil, err
+	default:
+		if !user.IsActive {
+			return nil, ErrUserInactive
+		}
+		user.Email = s.externalEmail(id, user.ID)
+		user.Role = id.Role
+		if err := database.DB.Save(&user).Error; err != nil {
+			return nil, fmt.Errorf("更新用户失败: %w", err)
+		}
+	}
+
+	if err := s.bindRole(user.Username, user.Role); err != nil {
+		return nil, err
+	}
+	if err := s.syncExternalRoles(user.Username, id.Roles, id.ManagedRoles); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// mapGroupsToRoles 将外部组映射为角色：映射到 admin 的组使用户成为管理员，映射到 user 的组不改变默认角色，
+// 其他角色作为额外的 Casbin 角色绑定。managed 为 groupRoles 中出现的全部角色
+func mapGroupsToRoles(groupRoles map[string]string, defaultRole string, groups []string) (role string, roles, managed []string) {
+	role = defaultRole
+	for _, r := range groupRoles {
+		managed = append(managed, r)
+	}
+	for _, group := range groups {
+		r, ok := groupRoles[group]
+		if !ok {
+			continue
+		}
+		switch r {
+		case models.RoleAdmin:
+			role = models.RoleAdmin
+		case models.RoleUser:
+		default:
+			roles = append(roles, r)
+		}
+	}
+	return role, roles, managed
+}
+
+// externalEmail 邮箱唯一：身份提供方未提供或已被其他账号使用时使用占位地址
+func (s *AuthService) externalEmail(id *ExternalIdentity, userID uint) string {
+	if id.Email != "" {
+		var count int64
+		database.DB.Model(&models.User{}).Where("email = ? AND id != ?", id.Email, userID).Count(&count)
+		if count == 0 {
+			return id.Email
+		}
+	}
+	return id.Username + "@" + id.Source + ".invalid"
+}
+
+// syncExternalRoles 在所有集群上绑定 roles，并解除 managed 中其余角色的绑定
+func (s *AuthService) syncExternalRoles(username string, roles, managed []string) error {
+	if s.enforcer == nil {
+		return nil
+	}
+	want := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		want[role] = true
+	}
+	for _, role := range managed {
+		if isBuiltInRole(role) {
+			continue
+		}
+		has, err := s.enforcer.HasGroupingPolicy(username, auth.RoleSubject(role), auth.Wildcard)
+		if err != nil {
+			return err
+		}
+		switch {
+		case want[role] && !has:
+			if _, err := s.enforcer.AddGroupingPolicy(username, auth.RoleSubject(role), auth.Wildcard); err != nil {
+				return fmt.Errorf("绑定用户 %s 的角色 %s 失败: %w", username, role, err)
+			}
+		case !want[role] && has:
+			if _, err := s.enforcer.RemoveGroupingPolicy(username, auth.RoleSubject(role), auth.Wildcard); err != nil {
+				return fmt.Errorf("解除用户 %s 的角色 %s 失败: %w", username, role, err)
+			}
+		}
+	}
+	return nil
+}
+
+func randomPassword() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// HPAService 管理 autoscaling/v2 HorizontalPodAutoscaler
+type HPAService struct {
+	writeOptions
+	client kubernetes.Interface
+	cache  *k8s.ResourceCache // nil 时直接读取 API Server
+}
+
+func NewHPAService(client kubernetes.Interface, cache *k8s.ResourceCache) *HPAService {
+	return &HPAService{client: client, cache: cache}
+}
+
+// 获取单个HPA
+func (s *HPAService) Get(namespace, name string) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	return s.client.AutoscalingV2().HorizontalPodAutoscalers(namespace).Get(
+		context.TODO(),
+		name,
+		metav1.GetOptions{},
+	)
+}
+
+// 创建HPA
+func (s *HPAService) Create(namespace string, hpa *autoscalingv2.HorizontalPodAutoscaler) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+
+	if hpa.Namespace != "" && hpa.Namespace != namespace {
+		return nil, NewValidationError("hpa namespace conflicts with path parameter")
+	}
+
+	return s.client.AutoscalingV2().HorizontalPodAutoscalers(namespace).Create(
+		context.TODO(),
+		hpa,
+		s.createOptions(),
+	)
+}
+
+// 更新HPA
+func (s *HPAService) Update(namespace string, hpa *autoscalingv2.HorizontalPodAutoscaler) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	return s.client.AutoscalingV2().HorizontalPodAutoscalers(namespace).Update(
+		context.TODO(),
+		hpa,
+		s.updateOptions(),
+	)
+}
+
+// Patch 按 patchType 修改HPA
+func (s *HPAService) Patch(namespace, name string, patchType types.PatchType, data []byte) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	return s.client.AutoscalingV2().HorizontalPodAutoscalers(namespace).Patch(context.TODO(), name, patchType, data, s.patchOptions(patchType))
+}
+
+// 删除HPA
+func (s *HPAService) Delete(namespace, name string) error {
+	return s.client.AutoscalingV2().HorizontalPodAutoscalers(namespace).Delete(
+		context.TODO(),
+		name,
+		s.deleteOptions(),
+	)
+}
+
+// List 按统一查询参数 (分页、选择器、排序、名称搜索) 列出 HPA
+func (s *HPAService) List(namespace string, query models.ListQuery) (*autoscalingv2.HorizontalPodAutoscalerList, models.ListMeta, error) {
+	items, meta, err := listPage(query, nil, func(opts metav1.ListOptions) ([]autoscalingv2.HorizontalPodAutoscaler, metav1.ListMeta, error) {
+		list, err := s.client.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
+	if err != nil {
+		return nil, models.ListMeta{}, err
+	}
+	return &autoscalingv2.HorizontalPodAutoscalerList{ListMeta: k8sListMeta(meta), Items: items}, meta, nil
+}
+
+// Watch机制实现
+func (s *HPAService) Watch(namespace, selector string) (watch.Interface, error) {
+	return s.client.AutoscalingV2().HorizontalPodAutoscalers(namespace).Watch(
+		context.TODO(),
+		metav1.ListOptions{
+			LabelSelector:  selector,
+			Watch:          true,
+			TimeoutSeconds: int64ptr(1800),
+		},
+	)
+}
+
+// ForTarget 返回以 apps 组中 kind/name 工作负载为 scaleTargetRef 的 HPA，没有时返回 nil。
+// scaleTargetRef 未写组 (例如 apiVersion 为空) 时按 apps 处理。
+// 多个 HPA 指向同一对象时控制器会拒绝扩缩 (AmbiguousSelector)，这里按名称取第一个
+func (s *HPAService) ForTarget(namespace, kind, name string) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	hpas, err := s.listForTarget(namespace)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(hpas, func(i, j int) bool { return hpas[i].Name < hpas[j].Name })
+	for i := range hpas {
+		ref := hpas[i].Spec.ScaleTargetRef
+		if ref.Kind != kind || ref.Name != name {
+			continue
+		}
+		if group := scaleTargetGroup(ref.APIVersion); group == "" || group == "apps" {
+			return &hpas[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// listForTarget 工作负载的每次查询和扩缩都要查找 HPA，优先从缓存读取
+func (s *HPAService) listForTarget(namespace string) ([]autoscalingv2.HorizontalPodAutoscaler, error) {
+	if s.cache != nil {
+		lister, err := s.cache.HPAs()
+		if err == nil {
+			items, err := lister.HorizontalPodAutoscalers(namespace).List(labels.Everything())
+			if err != nil {
+				return nil, err
+			}
+			return cachedItems(items), nil
+		}
+		cacheUnavailable(k8s.CachedHPAs, err)
+	}
+	list, err := s.client.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// scaleTargetGroup 返回 scaleTargetRef.apiVersion 中的组，无法解析时视为未指定
+func scaleTargetGroup(apiVersion string) string {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return ""
+	}
+	return gv.Group
+}
@@ -3,22 +3,36 @@ package service
 import (
 	"context"
 
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 )
 
 type IngressService struct {
+	writeOptions
 	client kubernetes.Interface
+	cache  *k8s.ResourceCache // nil 时直接读取 API Server
 }
 
-func NewIngressService(client kubernetes.Interface) *IngressService {
-	return &IngressService{client: client}
+func NewIngressService(client kubernetes.Interface, cache *k8s.ResourceCache) *IngressService {
+	return &IngressService{client: client, cache: cache}
 }
 
 // 获取单个Ingress
 func (s *IngressService) Get(namespace, name string) (*networkingv1.Ingress, error) {
+	if s.cache != nil {
+		if lister, err := s.cache.Ingresses(); err == nil {
+			if obj, err := lister.Ingresses(namespace).Get(name); err == nil {
+				return obj.DeepCopy(), nil
+			}
+		}
+	}
 	return s.client.NetworkingV1().Ingresses(namespace).Get(
 		context.TODO(),
 		name,
@@ -36,7 +50,7 @@ func (s *IngressService) Create(namespace string, ingress *networkingv1.Ingress)
 	return s.client.NetworkingV1().Ingresses(namespace).Create(
 		context.TODO(),
 		ingress,
-		metav1.CreateOptions{},
+		s.createOptions(),
 	)
 }
 
@@ -45,32 +59,55 @@ func (s *IngressService) Update(namespace string, ingress *networkingv1.Ingress)
 	return s.client.NetworkingV1().Ingresses(namespace).Update(
 		context.TODO(),
 		ingress,
-		metav1.UpdateOptions{},
+		s.updateOptions(),
 	)
 }
 
+// Patch 按 patchType 修改Ingress
+func (s *IngressService) Patch(namespace, name string, patchType types.PatchType, data []byte) (*networkingv1.Ingress, error) {
+	return s.client.NetworkingV1().Ingresses(namespace).Patch(context.TODO(), name, patchType, data, s.patchOptions(patchType))
+}
+
 // 删除Ingress
 func (s *IngressService) Delete(namespace, name string) error {
 	return s.client.NetworkingV1().Ingresses(namespace).Delete(
 		context.TODO(),
 		name,
-		metav1.DeleteOptions{},
+		s.deleteOptions(),
 	)
 }
 
-// 列表查询（支持分页和标签过滤）
-func (s *IngressService) List(namespace, selector string, limit int64) (*networkingv1.IngressList, error) {
-	return s.client.NetworkingV1().Ingresses(namespace).List(
-		context.TODO(),
-		metav1.ListOptions{
-			LabelSelector: selector,
-			Limit:         limit,
-		},
-	)
+// List 按统一查询参数 (分页、选择器、排序、名称搜索) 列出 Ingress
+func (s *IngressService) List(namespace string, query models.ListQuery) (*networkingv1.IngressList, models.ListMeta, error) {
+	fromCache := cacheList(s.cache, k8s.CachedIngresses, func(selector labels.Selector) ([]*networkingv1.Ingress, error) {
+		lister, err := s.cache.Ingresses()
+		if err != nil {
+			return nil, err
+		}
+		return lister.Ingresses(namespace).List(selector)
+	})
+	items, meta, err := listPage(query, fromCache, func(opts metav1.ListOptions) ([]networkingv1.Ingress, metav1.ListMeta, error) {
+		list, err := s.client.NetworkingV1().Ingresses(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
+	if err != nil {
+		return nil, models.ListMeta{}, err
+	}
+	return &networkingv1.IngressList{ListMeta: k8sListMeta(meta), Items: items}, meta, nil
 }
 
 // Watch机制实现
 func (s *IngressService) Watch(namespace, selector string) (watch.Interface, error) {
+	if s.cache != nil {
+		w, err := s.cache.Watch(k8s.CachedIngresses, namespace, selector)
+		if err == nil {
+			return w, nil
+		}
+		cacheUnavailable(k8s.CachedIngresses, err)
+	}
 	return s.client.NetworkingV1().Ingresses(namespace).Watch(
 		context.TODO(),
 		metav1.ListOptions{
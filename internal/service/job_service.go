@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+type JobService struct {
+	writeOptions
+	client kubernetes.Interface
+}
+
+func NewJobService(client kubernetes.Interface) *JobService {
+	return &JobService{client: client}
+}
+
+// 获取单个Job
+func (s *JobService) Get(namespace, name string) (*batchv1.Job, error) {
+	return s.client.BatchV1().Jobs(namespace).Get(
+		context.TODO(),
+		name,
+		metav1.GetOptions{},
+	)
+}
+
+// 创建Job
+func (s *JobService) Create(namespace string, job *batchv1.Job) (*batchv1.Job, error) {
+
+	if job.Namespace != "" && job.Namespace != namespace {
+		return nil, NewValidationError("job namespace conflicts with path parameter")
+	}
+
+	return s.client.BatchV1().Jobs(namespace).Create(
+		context.TODO(),
+		job,
+		s.createOptions(),
+	)
+}
+
+// 更新Job，Pod 模板等字段创建后不可修改，由 API Server 校验
+func (s *JobService) Update(namespace string, job *batchv1.Job) (*batchv1.Job, error) {
+	return s.client.BatchV1().Jobs(namespace).Update(
+		context.TODO(),
+		job,
+		s.updateOptions(),
+	)
+}
+
+// Patch 按 patchType 修改Job
+func (s *JobService) Patch(namespace, name string, patchType types.PatchType, data []byte) (*batchv1.Job, error) {
+	return s.client.BatchV1().Jobs(namespace).Patch(context.TODO(), name, patchType, data, s.patchOptions(patchType))
+}
+
+// 删除Job。batch/v1 Job 默认孤立其 Pod，这里与 kubectl 一样使用后台级联删除
+func (s *JobService) Delete(namespace, name string) error {
+	return s.client.BatchV1().Jobs(namespace).Delete(
+		context.TODO(),
+		name,
+		backgroundDeleteOptions(s.deleteOptions()),
+	)
+}
+
+// List 按统一查询参数 (分页、选择器、排序、名称搜索) 列出 Job
+func (s *JobService) List(namespace string, query models.ListQuery) (*batchv1.JobList, models.ListMeta, error) {
+	items, meta, err := listPage(query, nil, func(opts metav1.ListOptions) ([]batchv1.Job, metav1.ListMeta, error) {
+		list, err := s.client.BatchV1().Jobs(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
+	if err != nil {
+		return nil, models.ListMeta{}, err
+	}
+	return &batchv1.JobList{ListMeta: k8sListMeta(meta), Items: items}, meta, nil
+}
+
+// Watch机制实现
+func (s *JobService) Watch(namespace, selector string) (watch.Interface, error) {
+	return s.client.BatchV1().Jobs(namespace).Watch(
+		context.TODO(),
+		metav1.ListOptions{
+			LabelSelector:  selector,
+			Watch:          true,
+			TimeoutSeconds: int64ptr(1800),
+		},
+	)
+}
+
+// SetSuspend 暂停或恢复 Job，暂停时控制器会删除正在运行的 Pod
+func (s *JobService) SetSuspend(namespace, name string, suspend bool) (*batchv1.Job, error) {
+	return s.Patch(namespace, name, types.StrategicMergePatchType, suspendPatch(suspend))
+}
+
+// Pods 列出 Job 创建的 Pod，按创建时间倒序 (最近一次尝试在前)
+func (s *JobService) Pods(namespace, name string) ([]corev1.Pod, error) {
+	job, err := s.Get(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	selector := "job-name=" + job.Name
+	if job.Spec.Selector != nil {
+		parsed, err := metav1.LabelSelectorAsSelector(job.Spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+		selector = parsed.String()
+	}
+	list, err := s.client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	pods := list.Items
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[j].CreationTimestamp.Before(&pods[i].CreationTimestamp)
+	})
+	return pods, nil
+}
+
+// Cleanup 删除已结束的 Job，返回被删除的名称；运行中和暂停的 Job 不受影响
+func (s *JobService) Cleanup(namespace string, query models.CleanupJobsQuery) ([]string, error) {
+	var olderThan time.Duration
+	if query.OlderThan != "" {
+		var err error
+		if olderThan, err = time.ParseDuration(query.OlderThan); err != nil || olderThan < 0 {
+			return nil, NewValidationError("olderThan 应为非负的时长，例如 24h")
+		}
+	}
+	list, err := s.client.BatchV1().Jobs(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: query.LabelSelector})
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	deleted := []string{}
+	for i := range list.Items {
+		job := &list.Items[i]
+		phase := models.JobPhase(job)
+		switch {
+		case phase != models.JobPhaseComplete && phase != models.JobPhaseFailed:
+			continue
+		case query.Status == "succeeded" && phase != models.JobPhaseComplete:
+			continue
+		case query.Status == "failed" && phase != models.JobPhaseFailed:
+			continue
+		}
+		if finishedAt := jobFinishedAt(job); finishedAt.After(cutoff) {
+			continue
+		}
+		err := s.client.BatchV1().Jobs(namespace).Delete(context.TODO(), job.Name, backgroundDeleteOptions(s.deleteOptions()))
+		if err != nil && !errors.IsNotFound(err) {
+			return deleted, fmt.Errorf("删除Job %s 失败: %w", job.Name, err)
+		}
+		deleted = append(deleted, job.Name)
+	}
+	return deleted, nil
+}
+
+// jobFinishedAt 终态条件的时间，旧版本集群上条件没有时间时回退到 completionTime 和创建时间
+func jobFinishedAt(job *batchv1.Job) time.Time {
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) &&
+			condition.Status == corev1.ConditionTrue && !condition.LastTransitionTime.IsZero() {
+			return condition.LastTransitionTime.Time
+		}
+	}
+	if job.Status.CompletionTime != nil {
+		return job.Status.CompletionTime.Time
+	}
+	return job.CreationTimestamp.Time
+}
+
+func suspendPatch(suspend bool) []byte {
+	return []byte(fmt.Sprintf(`{"spec":{"suspend":%t}}`, suspend))
+}
+
+func backgroundDeleteOptions(opts metav1.DeleteOptions) metav1.DeleteOptions {
+	policy := metav1.DeletePropagationBackground
+	opts.PropagationPolicy = &policy
+	return opts
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/pkg/database"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// LDAPAuthenticator LDAP / Active Directory 认证后端
+type LDAPAuthenticator struct {
+	cfg         configs.LDAPConfig
+	authService *AuthService
+}
+
+func NewLDAPAuthenticator(cfg configs.LDAPConfig, authService *AuthService) *LDAPAuthenticator {
+	if cfg.UserFilter == "" {
+		cfg.UserFilter = "(uid=%s)"
+	}
+	if cfg.EmailAttribute == "" {
+		cfg.EmailAttribute = "mail"
+	}
+	if cfg.GroupBaseDN == "" {
+		cfg.GroupBaseDN = cfg.BaseDN
+	}
+	if cfg.GroupFilter == "" {
+		cfg.GroupFilter = "(member=%s)"
+	}
+	if cfg.GroupNameAttribute == "" {
+		cfg.GroupNameAttribute = "cn"
+	}
+	if cfg.DefaultRole == "" {
+		cfg.DefaultRole = models.RoleUser
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &LDAPAuthenticator{cfg: cfg, authService: authService}
+}
+
+func (a *LDAPAuthenticator) Name() string { return models.UserSourceLDAP }
+
+// Authenticate 搜索用户 -> 以用户 DN 绑定校验密码 -> 读取组，然后创建或同步 CiliKube 用户
+func (a *LDAPAuthenticator) Authenticate(username, password string) (*models.User, error) {
+	// 空密码的简单绑定在多数服务器上会被当作匿名绑定而成功
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+	conn, err := a.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	entry, err := a.searchUser(conn, username)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("LDAP 用户绑定失败: %w", err)
+	}
+
+	identity, err := a.identity(conn, username, entry)
+	if err != nil {
+		return nil, err
+	}
+	user, err := a.authService.provisionExternalUser(identity)
+	if errors.Is(err, ErrExternalUserConflict) || errors.Is(err, ErrUserInactive) {
+		log.Printf("LDAP 用户 %s 登录被拒绝: %v", username, err)
+		return nil, ErrInvalidCredentials
+	}
+	return user, err
+}
+
+// SyncGroups 重新读取数据库中所有 LDAP 用户的组并同步角色；LDAP 中已不存在的用户被禁用
+func (a *LDAPAuthenticator) SyncGroups() error {
+	var users []models.User
+	if err := database.DB.Where("source = ? AND is_active = ?", models.UserSourceLDAP, true).Find(&users).Error; err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return nil
+	}
+
+	conn, err := a.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for i := range users {
+		user := &users[i]
+		result, err := conn.Search(ldap.NewSearchRequest(
+			user.ExternalID, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 1, int(a.cfg.Timeout.Seconds()), false,
+			"(objectClass=*)", []string{a.cfg.EmailAttribute}, nil,
+		))
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) || (err == nil && len(result.Entries) == 0) {
+			log.Printf("LDAP 用户 %s (%s) 已不存在，禁用该用户", user.Username, user.ExternalID)
+			if err := a.authService.UpdateUserStatus(user.ID, false); err != nil {
+				return err
+			}
+			if err := a.authService.syncExternalRoles(user.Username, nil, a.managedRoles()); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("搜索 LDAP 用户 %s 失败: %w", user.ExternalID, err)
+		}
+
+		identity, err := a.identity(conn, user.Username, result.Entries[0])
+		if err != nil {
+			return err
+		}
+		if _, err := a.authService.provisionExternalUser(identity); err != nil {
+			return fmt.Errorf("同步 LDAP 用户 %s 失败: %w", user.Username, err)
+		}
+	}
+	return nil
+}
+
+// StartGroupSync 在后台按 interval 周期执行 SyncGroups，直到 ctx 结束
+func (a *LDAPAuthenticator) StartGroupSync(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := a.SyncGroups(); err != nil {
+					log.Printf("同步 LDAP 组失败: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// connect 建立连接并以服务账号绑定 (未配置时匿名)
+func (a *LDAPAuthenticator) connect() (*ldap.Conn, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: a.cfg.InsecureSkipVerify}
+	conn, err := ldap.DialURL(a.cfg.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: a.cfg.Timeout}),
+		ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("连接 LDAP 服务器失败: %w", err)
+	}
+	conn.SetTimeout(a.cfg.Timeout)
+	if a.cfg.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("LDAP StartTLS 失败: %w", err)
+		}
+	}
+	if err := a.bindService(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (a *LDAPAuthenticator) bindService(conn *ldap.Conn) error {
+	var err error
+	if a.cfg.BindDN == "" {
+		err = conn.UnauthenticatedBind("")
+	} else {
+		err = conn.Bind(a.cfg.BindDN, a.cfg.BindPassword)
+	}
+	if err != nil {
+		return fmt.Errorf("LDAP 服务账号绑定失败: %w", err)
+	}
+	return nil
+}
+
+// searchUser 在 BaseDN 下按 UserFilter 查找唯一的用户条目
+func (a *LDAPAuthenticator) searchUser(conn *ldap.Conn, username string) (*ldap.Entry, error) {
+	result, err := conn.Search(ldap.NewSearchRequest(
+		a.cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, int(a.cfg.Timeout.Seconds()), false,
+		fmt.Sprintf(a.cfg.UserFilter, ldap.EscapeFilter(username)), []string{a.cfg.EmailAttribute}, nil,
+	))
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("搜索 LDAP 用户失败: %w", err)
+	}
+	if len(result.Entries) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+	return result.Entries[0], nil
+}
+
+// identity 读取用户所属的组 (以服务账号身份) 并组装外部身份
+func (a *LDAPAuthenticator) identity(conn *ldap.Conn, username string, entry *ldap.Entry) (*ExternalIdentity, error) {
+	// 用户绑定后连接的身份已变为该用户，组搜索使用服务账号
+	if err := a.bindService(conn); err != nil {
+		return nil, err
+	}
+	groups, err := a.searchGroups(conn, entry.DN)
+	if err != nil {
+		return nil, err
+	}
+	identity := &ExternalIdentity{
+		Source:     models.UserSourceLDAP,
+		ExternalID: entry.DN,
+		Username:   username,
+		Email:      entry.GetAttributeValue(a.cfg.EmailAttribute),
+	}
+	identity.Role, identity.Roles, identity.ManagedRoles = mapGroupsToRoles(a.cfg.GroupRoles, a.cfg.DefaultRole, groups)
+	return identity, nil
+}
+
+func (a *LDAPAuthenticator) searchGroups(conn *ldap.Conn, userDN string) ([]string, error) {
+	result, err := conn.Search(ldap.NewSearchRequest(
+		a.cfg.GroupBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, int(a.cfg.Timeout.Seconds()), false,
+		fmt.Sprintf(a.cfg.GroupFilter, ldap.EscapeFilter(userDN)), []string{a.cfg.GroupNameAttribute}, nil,
+	))
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("搜索 LDAP 组失败: %w", err)
+	}
+	groups := make([]string, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		if name := entry.GetAttributeValue(a.cfg.GroupNameAttribute); name != "" {
+			groups = append(groups, name)
+		} else if dn, err := ldap.ParseDN(entry.DN); err == nil && len(dn.RDNs) > 0 && len(dn.RDNs[0].Attributes) > 0 {
+			// 部分服务器不返回命名属性，从 DN 的第一个 RDN 取组名
+			groups = append(groups, dn.RDNs[0].Attributes[0].Value)
+		}
+	}
+	return groups, nil
+}
+
+func (a *LDAPAuthenticator) managedRoles() []string {
+	_, _, managed := mapGroupsToRoles(a.cfg.GroupRoles, a.cfg.DefaultRole, nil)
+	return managed
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// 列表分页约定:
+//   - 带 sortBy / search，或缓存可用时，取全量数据在内存中过滤、排序、分页，total 为准确总数，
+//     continue 为 "offset:<n>" 形式的内存游标
+//   - 否则 limit / continue / 选择器原样交给 API Server 分页，continue 为 API Server 的游标
+//   - fieldSelector 不走缓存 (lister 只支持标签选择器)
+
+// offsetContinuePrefix 内存分页游标前缀；API Server 的游标为 base64 字符串，不会以此开头
+const offsetContinuePrefix = "offset:"
+
+// listFromCacheFunc 从缓存读取全量列表；ok 为 false 表示缓存不可用，需回退到 API Server
+type listFromCacheFunc[T any] func(selector labels.Selector) (items []T, ok bool)
+
+// listFromAPIFunc 调用 API Server 列表接口
+type listFromAPIFunc[T any] func(opts metav1.ListOptions) ([]T, metav1.ListMeta, error)
+
+// listPage 按统一的查询参数返回一页数据，fromCache 为 nil 时直接读取 API Server
+func listPage[T any, PT interface {
+	*T
+	metav1.Object
+}](query models.ListQuery, fromCache listFromCacheFunc[T], fromAPI listFromAPIFunc[T]) ([]T, models.ListMeta, error) {
+	if err := ValidateListQuery(query); err != nil {
+		return nil, models.ListMeta{}, err
+	}
+	selector, err := parseSelector(query.LabelSelector)
+	if err != nil {
+		return nil, models.ListMeta{}, err
+	}
+
+	offset, offsetToken, err := parseOffsetContinue(query.Continue)
+	if err != nil {
+		return nil, models.ListMeta{}, err
+	}
+	apiToken := query.Continue != "" && !offsetToken
+
+	var items []T
+	loaded := false
+	if fromCache != nil && query.FieldSelector == "" && !apiToken {
+		items, loaded = fromCache(selector)
+	}
+
+	if !loaded {
+		opts := metav1.ListOptions{
+			LabelSelector: query.LabelSelector,
+			FieldSelector: query.FieldSelector,
+		}
+		if !offsetToken && query.SortBy == "" && query.Search == "" {
+			// 无需内存处理，交给 API Server 分页
+			opts.Limit = query.Limit
+			opts.Continue = query.Continue
+			items, listMeta, err := fromAPI(opts)
+			if err != nil {
+				return nil, models.ListMeta{}, err
+			}
+			meta := models.ListMeta{
+				Total:              len(items),
+				Continue:           listMeta.Continue,
+				RemainingItemCount: listMeta.RemainingItemCount,
+			}
+			if listMeta.RemainingItemCount != nil {
+				meta.Total += int(*listMeta.RemainingItemCount)
+			}
+			return items, meta, nil
+		}
+		if items, _, err = fromAPI(opts); err != nil {
+			return nil, models.ListMeta{}, err
+		}
+	}
+
+	items = filterByName[T, PT](items, query.Search)
+	sortItems[T, PT](items, query.SortBy, query.Order)
+	return paginate(items, offset, query.Limit)
+}
+
+// cacheList 将 lister 查询适配为 listFromCacheFunc；cache 为 nil 时返回 nil
+func cacheList[T any, PT interface {
+	*T
+	metav1.Object
+	DeepCopy() *T
+}](cache *k8s.ResourceCache, resource k8s.CachedResource, list func(selector labels.Selector) ([]PT, error)) listFromCacheFunc[T] {
+	if cache == nil {
+		return nil
+	}
+	return func(selector labels.Selector) ([]T, bool) {
+		objs, err := list(selector)
+		if err != nil {
+			cacheUnavailable(resource, err)
+			return nil, false
+		}
+		return cachedItems(objs), true
+	}
+}
+
+// k8sListMeta 将分页信息写回 Kubernetes 列表对象
+func k8sListMeta(meta models.ListMeta) metav1.ListMeta {
+	return metav1.ListMeta{Continue: meta.Continue, RemainingItemCount: meta.RemainingItemCount}
+}
+
+// ValidateListQuery 校验排序参数
+func ValidateListQuery(query models.ListQuery) error {
+	switch query.SortBy {
+	case "", models.SortByName, models.SortByNamespace, models.SortByCreationTimestamp:
+	default:
+		return NewValidationError("无效的 sortBy: " + query.SortBy + "，可选值: name, namespace, creationTimestamp")
+	}
+	switch query.Order {
+	case "", models.SortOrderAsc, models.SortOrderDesc:
+	default:
+		return NewValidationError("无效的 order: " + query.Order + "，可选值: asc, desc")
+	}
+	if query.Limit < 0 {
+		return NewValidationError("limit 不能为负数")
+	}
+	return nil
+}
+
+// parseOffsetContinue 解析内存分页游标
+func parseOffsetContinue(token string) (int, bool, error) {
+	if !strings.HasPrefix(token, offsetContinuePrefix) {
+		return 0, false, nil
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(token, offsetContinuePrefix))
+	if err != nil || offset < 0 {
+		return 0, true, NewValidationError("无效的 continue: " + token)
+	}
+	return offset, true, nil
+}
+
+// filterByName 按名称子串过滤 (不区分大小写)
+func filterByName[T any, PT interface {
+	*T
+	metav1.Object
+}](items []T, search string) []T {
+	search = strings.ToLower(strings.TrimSpace(search))
+	if search == "" {
+		return items
+	}
+	filtered := make([]T, 0, len(items))
+	for i := range items {
+		if strings.Contains(strings.ToLower(PT(&items[i]).GetName()), search) {
+			filtered = append(filtered, items[i])
+		}
+	}
+	return filtered
+}
+
+// sortItems 按 sortBy / order 排序；未指定 sortBy 时保持 namespace/name 顺序
+func sortItems[T any, PT interface {
+	*T
+	metav1.Object
+}](items []T, sortBy, order string) {
+	if sortBy == "" {
+		if order == models.SortOrderDesc {
+			for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+				items[i], items[j] = items[j], items[i]
+			}
+		}
+		return
+	}
+
+	less := func(a, b metav1.Object) bool {
+		switch sortBy {
+		case models.SortByNamespace:
+			if a.GetNamespace() != b.GetNamespace() {
+				return a.GetNamespace() < b.GetNamespace()
+			}
+		case models.SortByCreationTimestamp:
+			ta, tb := a.GetCreationTimestamp(), b.GetCreationTimestamp()
+			if !ta.Equal(&tb) {
+				return ta.Before(&tb)
+			}
+		}
+		if a.GetName() != b.GetName() {
+			return a.GetName() < b.GetName()
+		}
+		return a.GetNamespace() < b.GetNamespace()
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := PT(&items[i]), PT(&items[j])
+		if order == models.SortOrderDesc {
+			return less(b, a)
+		}
+		return less(a, b)
+	})
+}
+
+// paginate 按 offset / limit 截取一页，并生成下一页的内存游标
+func paginate[T any](items []T, offset int, limit int64) ([]T, models.ListMeta, error) {
+	total := len(items)
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if limit > 0 && offset+int(limit) < total {
+		end = offset + int(limit)
+	}
+
+	meta := models.ListMeta{Total: total}
+	if end < total {
+		remaining := int64(total - end)
+		meta.Continue = offsetContinuePrefix + strconv.Itoa(end)
+		meta.RemainingItemCount = &remaining
+	}
+	return items[offset:end], meta, nil
+}
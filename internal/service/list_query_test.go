@@ -0,0 +1,250 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+var listTestBase = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func listTestPod(namespace, name string, age int, podLabels map[string]string) corev1.Pod {
+	return corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace:         namespace,
+		Name:              name,
+		Labels:            podLabels,
+		CreationTimestamp: metav1.NewTime(listTestBase.Add(time.Duration(age) * time.Minute)),
+	}}
+}
+
+// listTestPods 已按 namespace/name 排序，与 lister 和 API Server 的返回顺序一致
+func listTestPods() []corev1.Pod {
+	return []corev1.Pod{
+		listTestPod("a", "api-1", 2, map[string]string{"app": "api"}),
+		listTestPod("a", "web-1", 1, map[string]string{"app": "web"}),
+		listTestPod("b", "api-2", 1, map[string]string{"app": "api"}),
+		listTestPod("b", "web-1", 1, map[string]string{"app": "web"}),
+		listTestPod("b", "worker", 0, map[string]string{"app": "worker"}),
+	}
+}
+
+// listSource 记录 listPage 读取了缓存还是 API Server，以及交给 API Server 的参数
+type listSource struct {
+	cacheAvailable bool
+	cacheCalls     int
+	apiCalls       []metav1.ListOptions
+	apiContinue    string
+}
+
+func (s *listSource) fromCache(selector labels.Selector) ([]corev1.Pod, bool) {
+	s.cacheCalls++
+	if !s.cacheAvailable {
+		return nil, false
+	}
+	var items []corev1.Pod
+	for _, pod := range listTestPods() {
+		if selector.Matches(labels.Set(pod.Labels)) {
+			items = append(items, pod)
+		}
+	}
+	return items, true
+}
+
+func (s *listSource) fromAPI(opts metav1.ListOptions) ([]corev1.Pod, metav1.ListMeta, error) {
+	s.apiCalls = append(s.apiCalls, opts)
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, metav1.ListMeta{}, err
+	}
+	var items []corev1.Pod
+	for _, pod := range listTestPods() {
+		if selector.Matches(labels.Set(pod.Labels)) && (opts.FieldSelector == "" || opts.FieldSelector == "metadata.namespace=b" && pod.Namespace == "b") {
+			items = append(items, pod)
+		}
+	}
+	if opts.Limit > 0 && int(opts.Limit) < len(items) {
+		items = items[:opts.Limit]
+		return items, metav1.ListMeta{Continue: s.apiContinue}, nil
+	}
+	return items, metav1.ListMeta{}, nil
+}
+
+func podKeys(pods []corev1.Pod) []string {
+	keys := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		keys = append(keys, pod.Namespace+"/"+pod.Name)
+	}
+	return keys
+}
+
+func TestListPage(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   models.ListQuery
+		noCache bool
+		want    []string
+		total   int
+		next    string
+		// fromCache 为 true 时数据来自缓存，否则来自 API Server
+		fromCache bool
+	}{
+		{
+			name:      "cache in lister order",
+			query:     models.ListQuery{},
+			want:      []string{"a/api-1", "a/web-1", "b/api-2", "b/web-1", "b/worker"},
+			total:     5,
+			fromCache: true,
+		},
+		{
+			name:      "limit larger than item count",
+			query:     models.ListQuery{Limit: 50},
+			want:      []string{"a/api-1", "a/web-1", "b/api-2", "b/web-1", "b/worker"},
+			total:     5,
+			fromCache: true,
+		},
+		{
+			name:      "first page with offset continue",
+			query:     models.ListQuery{Limit: 2},
+			want:      []string{"a/api-1", "a/web-1"},
+			total:     5,
+			next:      "offset:2",
+			fromCache: true,
+		},
+		{
+			name:      "last page",
+			query:     models.ListQuery{Limit: 2, Continue: "offset:4"},
+			want:      []string{"b/worker"},
+			total:     5,
+			fromCache: true,
+		},
+		{
+			name:      "offset past the end",
+			query:     models.ListQuery{Limit: 2, Continue: "offset:10"},
+			want:      []string{},
+			total:     5,
+			fromCache: true,
+		},
+		{
+			name:      "name sort is stable across namespaces",
+			query:     models.ListQuery{SortBy: models.SortByName},
+			want:      []string{"a/api-1", "b/api-2", "a/web-1", "b/web-1", "b/worker"},
+			total:     5,
+			fromCache: true,
+		},
+		{
+			name:      "equal creation time falls back to name then namespace",
+			query:     models.ListQuery{SortBy: models.SortByCreationTimestamp},
+			want:      []string{"b/worker", "b/api-2", "a/web-1", "b/web-1", "a/api-1"},
+			total:     5,
+			fromCache: true,
+		},
+		{
+			name:      "descending creation time reverses ties too",
+			query:     models.ListQuery{SortBy: models.SortByCreationTimestamp, Order: models.SortOrderDesc},
+			want:      []string{"a/api-1", "b/web-1", "a/web-1", "b/api-2", "b/worker"},
+			total:     5,
+			fromCache: true,
+		},
+		{
+			name:      "descending without sortBy reverses lister order",
+			query:     models.ListQuery{Order: models.SortOrderDesc, Limit: 2},
+			want:      []string{"b/worker", "b/web-1"},
+			total:     5,
+			next:      "offset:2",
+			fromCache: true,
+		},
+		{
+			name:      "search with label selector",
+			query:     models.ListQuery{Search: "API", LabelSelector: "app=api"},
+			want:      []string{"a/api-1", "b/api-2"},
+			total:     2,
+			fromCache: true,
+		},
+		{
+			name:      "search with label and field selector goes to the API server",
+			query:     models.ListQuery{Search: "web", LabelSelector: "app in (web,worker)", FieldSelector: "metadata.namespace=b"},
+			want:      []string{"b/web-1"},
+			total:     1,
+			fromCache: false,
+		},
+		{
+			name:      "cache unavailable falls back to the API server with in-memory paging",
+			query:     models.ListQuery{Limit: 2, SortBy: models.SortByName},
+			noCache:   true,
+			want:      []string{"a/api-1", "b/api-2"},
+			total:     5,
+			next:      "offset:2",
+			fromCache: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &listSource{cacheAvailable: !tt.noCache}
+			items, meta, err := listPage(tt.query, source.fromCache, source.fromAPI)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, podKeys(items))
+			assert.Equal(t, tt.total, meta.Total)
+			assert.Equal(t, tt.next, meta.Continue)
+			if tt.fromCache {
+				assert.Empty(t, source.apiCalls)
+			} else {
+				require.Len(t, source.apiCalls, 1)
+				// 内存分页时从 API Server 取全量数据
+				assert.Zero(t, source.apiCalls[0].Limit)
+				assert.Empty(t, source.apiCalls[0].Continue)
+				assert.Equal(t, tt.query.LabelSelector, source.apiCalls[0].LabelSelector)
+				assert.Equal(t, tt.query.FieldSelector, source.apiCalls[0].FieldSelector)
+			}
+		})
+	}
+}
+
+func TestListPage_APIServerPaging(t *testing.T) {
+	// 没有缓存、排序和搜索时，limit / continue 原样交给 API Server
+	source := &listSource{apiContinue: "eyJ2IjoibWV0YS5rOHMuaW8vdjEifQ"}
+	items, meta, err := listPage(models.ListQuery{Limit: 2}, nil, source.fromAPI)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a/api-1", "a/web-1"}, podKeys(items))
+	assert.Equal(t, source.apiContinue, meta.Continue)
+	require.Len(t, source.apiCalls, 1)
+	assert.EqualValues(t, 2, source.apiCalls[0].Limit)
+
+	// API Server 的游标不能交给缓存处理
+	source = &listSource{cacheAvailable: true}
+	_, _, err = listPage(models.ListQuery{Limit: 2, Continue: "eyJ2IjoibWV0YS5rOHMuaW8vdjEifQ"}, source.fromCache, source.fromAPI)
+	require.NoError(t, err)
+	assert.Zero(t, source.cacheCalls)
+	require.Len(t, source.apiCalls, 1)
+	assert.Equal(t, "eyJ2IjoibWV0YS5rOHMuaW8vdjEifQ", source.apiCalls[0].Continue)
+}
+
+func TestListPage_InvalidQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query models.ListQuery
+	}{
+		{"non-numeric offset", models.ListQuery{Continue: "offset:abc"}},
+		{"negative offset", models.ListQuery{Continue: "offset:-1"}},
+		{"empty offset", models.ListQuery{Continue: "offset:"}},
+		{"invalid label selector", models.ListQuery{LabelSelector: "app in (web"}},
+		{"unknown sortBy", models.ListQuery{SortBy: "size"}},
+		{"unknown order", models.ListQuery{Order: "up"}},
+		{"negative limit", models.ListQuery{Limit: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &listSource{cacheAvailable: true}
+			_, _, err := listPage(tt.query, source.fromCache, source.fromAPI)
+			var validationErr *ValidationError
+			assert.True(t, errors.As(err, &validationErr), "got %v", err)
+			assert.Empty(t, source.apiCalls)
+		})
+	}
+}
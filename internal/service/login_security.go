@@ -0,0 +1,255 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/pkg/database"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 登录审计中的失败原因
+const (
+	loginReasonInvalidCredentials = "invalid_credentials"
+	loginReasonLocked             = "locked"
+	loginReasonInvalidTwoFactor   = "invalid_two_factor_code"
+	loginReasonInvalidChallenge   = "invalid_challenge"
+)
+
+// bcryptMaxLength bcrypt 只使用密码的前 72 个字节
+const bcryptMaxLength = 72
+
+// LoginLockedError 登录失败次数过多，RetryAfter 后才能再次尝试
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("登录失败次数过多，请在 %s 后重试", e.RetryAfter.Round(time.Second))
+}
+
+func passwordPolicy() configs.PasswordPolicyConfig {
+	policy := configs.GlobalConfig.Auth.PasswordPolicy
+	if policy.MinLength == 0 {
+		policy.MinLength = 8
+	}
+	if policy.MinClasses == 0 {
+		policy.MinClasses = 2
+	}
+	if policy.History == 0 {
+		policy.History = 3
+	}
+	return policy
+}
+
+func lockoutConfig() configs.LockoutConfig {
+	cfg := configs.GlobalConfig.Auth.Lockout
+	if cfg.MaxAttempts == 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.IPMaxAttempts == 0 {
+		cfg.IPMaxAttempts = 20
+	}
+	if cfg.Window == 0 {
+		cfg.Window = 15 * time.Minute
+	}
+	if cfg.Duration == 0 {
+		cfg.Duration = time.Minute
+	}
+	if cfg.MaxDuration == 0 {
+		cfg.MaxDuration = time.Hour
+	}
+	return cfg
+}
+
+// ValidatePassword 按密码策略检查长度和字符种类
+func (s *AuthService) ValidatePassword(password string) error {
+	policy := passwordPolicy()
+	if len([]rune(password)) < policy.MinLength {
+		return fmt.Errorf("密码长度至少为 %d 位", policy.MinLength)
+	}
+	if len(password) > bcryptMaxLength {
+		return fmt.Errorf("密码长度不能超过 %d 个字节", bcryptMaxLength)
+	}
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	classes := 0
+	for _, has := range []bool{upper, lower, digit, symbol} {
+		if has {
+			classes++
+		}
+	}
+	if classes < policy.MinClasses {
+		return fmt.Errorf("密码需要至少包含大写字母、小写字母、数字、符号中的 %d 类", policy.MinClasses)
+	}
+	return nil
+}
+
+// checkPasswordHistory 新密码不能是当前密码或最近使用过的密码
+func (s *AuthService) checkPasswordHistory(user *models.User, password string) error {
+	if user.CheckPassword(password) {
+		return errors.New("新密码不能与当前密码相同")
+	}
+	var history []models.PasswordHistory
+	limit := passwordPolicy().History - 1
+	if limit <= 0 {
+		return nil
+	}
+	if err := database.DB.Where("user_id = ?", user.ID).Order("id DESC").Limit(limit).Find(&history).Error; err != nil {
+		return err
+	}
+	for _, h := range history {
+		if bcrypt.CompareHashAndPassword([]byte(h.PasswordHash), []byte(password)) == nil {
+			return fmt.Errorf("不能使用最近 %d 次使用过的密码", passwordPolicy().History)
+		}
+	}
+	return nil
+}
+
+// rememberPassword 记录被替换的密码哈希，只保留策略需要的条数
+func (s *AuthService) rememberPassword(userID uint, oldHash string) error {
+	if err := database.DB.Create(&models.PasswordHistory{UserID: userID, PasswordHash: oldHash}).Error; err != nil {
+		return err
+	}
+	keep := passwordPolicy().History - 1
+	var stale []uint
+	database.DB.Model(&models.PasswordHistory{}).Where("user_id = ?", userID).
+		Order("id DESC").Offset(keep).Pluck("id", &stale)
+	if len(stale) == 0 {
+		return nil
+	}
+	return database.DB.Delete(&models.PasswordHistory{}, stale).Error
+}
+
+// checkLockout 用户名或来源 IP 处于锁定期时记录审计并返回 *LoginLockedError
+func (s *AuthService) checkLockout(client models.LoginClient, method, username string) error {
+	now := time.Now()
+	for _, key := range lockoutKeys(username, client.IP) {
+		var failure models.LoginFailure
+		if err := database.DB.Where("lock_key = ?", key).Limit(1).Find(&failure).Error; err != nil {
+			return err
+		}
+		if failure.LockedUntil != nil && failure.LockedUntil.After(now) {
+			s.auditLogin(client, method, username, 0, loginReasonLocked)
+			return &LoginLockedError{RetryAfter: failure.LockedUntil.Sub(now)}
+		}
+	}
+	return nil
+}
+
+// loginFailed 记录审计并累计失败次数
+func (s *AuthService) loginFailed(client models.LoginClient, method, username string, userID uint, reason string) {
+	s.auditLogin(client, method, username, userID, reason)
+	s.recordFailure(username, client.IP)
+}
+
+// loginSucceeded 清除该用户名的失败记录。来源 IP 的记录保留，避免攻击者用自己的账号重置计数
+func (s *AuthService) loginSucceeded(client models.LoginClient, method string, user *models.User) {
+	database.DB.Where("lock_key = ?", "user:"+user.Username).Delete(&models.LoginFailure{})
+	s.auditLogin(client, method, user.Username, user.ID, "")
+}
+
+// recordFailure 累计失败次数。达到阈值后锁定，之后每次失败锁定时间翻倍
+func (s *AuthService) recordFailure(username, ip string) {
+	cfg := lockoutConfig()
+	now := time.Now()
+	for _, key := range lockoutKeys(username, ip) {
+		threshold := cfg.MaxAttempts
+		if strings.HasPrefix(key, "ip:") {
+			threshold = cfg.IPMaxAttempts
+		}
+		var failure models.LoginFailure
+		database.DB.Where("lock_key = ?", key).Limit(1).Find(&failure)
+		if failure.Key == "" || now.Sub(failure.LastFailureAt) > cfg.Window {
+			failure = models.LoginFailure{Key: key}
+		}
+		failure.Failures++
+		failure.LastFailureAt = now
+		if over := failure.Failures - threshold; over >= 0 {
+			duration := cfg.MaxDuration
+			if factor := math.Pow(2, float64(over)); float64(cfg.Duration)*factor < float64(cfg.MaxDuration) {
+				duration = time.Duration(float64(cfg.Duration) * factor)
+			}
+			until := now.Add(duration)
+			failure.LockedUntil = &until
+		}
+		if err := database.DB.Save(&failure).Error; err != nil {
+			log.Printf("记录登录失败次数失败: %v", err)
+		}
+	}
+}
+
+// lockoutKeys 挑战无效时不知道用户名，只按来源 IP 计数
+func lockoutKeys(username, ip string) []string {
+	var keys []string
+	if username != "" {
+		keys = append(keys, "user:"+username)
+	}
+	if ip != "" {
+		keys = append(keys, "ip:"+ip)
+	}
+	return keys
+}
+
+// auditLogin 写入登录审计，reason 为空表示成功
+func (s *AuthService) auditLogin(client models.LoginClient, method, username string, userID uint, reason string) {
+	event := &models.LoginEvent{
+		UserID:    userID,
+		Username:  truncate(username, 100),
+		Method:    method,
+		Success:   reason == "",
+		Reason:    truncate(reason, 255),
+		IP:        truncate(client.IP, 64),
+		UserAgent: truncate(client.UserAgent, 255),
+	}
+	if err := database.DB.Create(event).Error; err != nil {
+		log.Printf("写入登录审计失败: %v", err)
+	}
+}
+
+// ListLoginEvents 按条件分页查询登录审计（管理员功能）
+func (s *AuthService) ListLoginEvents(query *models.LoginEventQuery) ([]models.LoginEvent, int64, error) {
+	db := database.DB.Model(&models.LoginEvent{})
+	if query.Username != "" {
+		db = db.Where("username = ?", query.Username)
+	}
+	if query.IP != "" {
+		db = db.Where("ip = ?", query.IP)
+	}
+	if query.Success != nil {
+		db = db.Where("success = ?", *query.Success)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	events := []models.LoginEvent{}
+	err := db.Order("id DESC").Offset((query.Page - 1) * query.PageSize).Limit(query.PageSize).Find(&events).Error
+	return events, total, err
+}
+
+func truncate(value string, max int) string {
+	if len(value) <= max {
+		return value
+	}
+	return value[:max]
+}
@@ -3,22 +3,36 @@ package service
 import (
 	"context"
 
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 )
 
 type NamespaceService struct {
+	writeOptions
 	client kubernetes.Interface
+	cache  *k8s.ResourceCache // nil 时直接读取 API Server
 }
 
-func NewNamespaceService(client kubernetes.Interface) *NamespaceService {
-	return &NamespaceService{client: client}
+func NewNamespaceService(client kubernetes.Interface, cache *k8s.ResourceCache) *NamespaceService {
+	return &NamespaceService{client: client, cache: cache}
 }
 
 // 获取单个Namespace
 func (s *NamespaceService) Get(name string) (*corev1.Namespace, error) {
+	if s.cache != nil {
+		if lister, err := s.cache.Namespaces(); err == nil {
+			if obj, err := lister.Get(name); err == nil {
+				return obj.DeepCopy(), nil
+			}
+		}
+	}
 	return s.client.CoreV1().Namespaces().Get(
 		context.TODO(),
 		name,
@@ -31,7 +45,7 @@ func (s *NamespaceService) Create(namespace *corev1.Namespace) (*corev1.Namespac
 	return s.client.CoreV1().Namespaces().Create(
 		context.TODO(),
 		namespace,
-		metav1.CreateOptions{},
+		s.createOptions(),
 	)
 }
 
@@ -40,32 +54,55 @@ func (s *NamespaceService) Update(namespace *corev1.Namespace) (*corev1.Namespac
 	return s.client.CoreV1().Namespaces().Update(
 		context.TODO(),
 		namespace,
-		metav1.UpdateOptions{},
+		s.updateOptions(),
 	)
 }
 
+// Patch 按 patchType 修改Namespace
+func (s *NamespaceService) Patch(name string, patchType types.PatchType, data []byte) (*corev1.Namespace, error) {
+	return s.client.CoreV1().Namespaces().Patch(context.TODO(), name, patchType, data, s.patchOptions(patchType))
+}
+
 // 删除Namespace
 func (s *NamespaceService) Delete(name string) error {
 	return s.client.CoreV1().Namespaces().Delete(
 		context.TODO(),
 		name,
-		metav1.DeleteOptions{},
+		s.deleteOptions(),
 	)
 }
 
-// 列表查询（支持分页和标签过滤）
-func (s *NamespaceService) List(selector string, limit int64) (*corev1.NamespaceList, error) {
-	return s.client.CoreV1().Namespaces().List(
-		context.TODO(),
-		metav1.ListOptions{
-			LabelSelector: selector,
-			Limit:         limit,
-		},
-	)
+// List 按统一查询参数 (分页、选择器、排序、名称搜索) 列出 Namespace
+func (s *NamespaceService) List(query models.ListQuery) (*corev1.NamespaceList, models.ListMeta, error) {
+	fromCache := cacheList(s.cache, k8s.CachedNamespaces, func(selector labels.Selector) ([]*corev1.Namespace, error) {
+		lister, err := s.cache.Namespaces()
+		if err != nil {
+			return nil, err
+		}
+		return lister.List(selector)
+	})
+	items, meta, err := listPage(query, fromCache, func(opts metav1.ListOptions) ([]corev1.Namespace, metav1.ListMeta, error) {
+		list, err := s.client.CoreV1().Namespaces().List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
+	if err != nil {
+		return nil, models.ListMeta{}, err
+	}
+	return &corev1.NamespaceList{ListMeta: k8sListMeta(meta), Items: items}, meta, nil
 }
 
 // Watch机制实现
 func (s *NamespaceService) Watch(selector string) (watch.Interface, error) {
+	if s.cache != nil {
+		w, err := s.cache.Watch(k8s.CachedNamespaces, "", selector)
+		if err == nil {
+			return w, nil
+		}
+		cacheUnavailable(k8s.CachedNamespaces, err)
+	}
 	return s.client.CoreV1().Namespaces().Watch(
 		context.TODO(),
 		metav1.ListOptions{
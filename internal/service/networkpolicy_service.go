@@ -3,13 +3,17 @@ package service
 import (
 	"context"
 
+	"github.com/ciliverse/cilikube/api/v1/models"
+
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 )
 
 type NetworkPolicyService struct {
+	writeOptions
 	client kubernetes.Interface
 }
 
@@ -36,7 +40,7 @@ func (s *NetworkPolicyService) Create(namespace string, networkPolicy *networkin
 	return s.client.NetworkingV1().NetworkPolicies(namespace).Create(
 		context.TODO(),
 		networkPolicy,
-		metav1.CreateOptions{},
+		s.createOptions(),
 	)
 }
 
@@ -45,28 +49,37 @@ func (s *NetworkPolicyService) Update(namespace string, networkPolicy *networkin
 	return s.client.NetworkingV1().NetworkPolicies(namespace).Update(
 		context.TODO(),
 		networkPolicy,
-		metav1.UpdateOptions{},
+		s.updateOptions(),
 	)
 }
 
+// Patch 按 patchType 修改NetworkPolicy
+func (s *NetworkPolicyService) Patch(namespace, name string, patchType types.PatchType, data []byte) (*networkingv1.NetworkPolicy, error) {
+	return s.client.NetworkingV1().NetworkPolicies(namespace).Patch(context.TODO(), name, patchType, data, s.patchOptions(patchType))
+}
+
 // 删除NetworkPolicy
 func (s *NetworkPolicyService) Delete(namespace, name string) error {
 	return s.client.NetworkingV1().NetworkPolicies(namespace).Delete(
 		context.TODO(),
 		name,
-		metav1.DeleteOptions{},
+		s.deleteOptions(),
 	)
 }
 
-// 列表查询（支持分页和标签过滤）
-func (s *NetworkPolicyService) List(namespace, selector string, limit int64) (*networkingv1.NetworkPolicyList, error) {
-	return s.client.NetworkingV1().NetworkPolicies(namespace).List(
-		context.TODO(),
-		metav1.ListOptions{
-			LabelSelector: selector,
-			Limit:         limit,
-		},
-	)
+// List 按统一查询参数 (分页、选择器、排序、名称搜索) 列出 NetworkPolicy
+func (s *NetworkPolicyService) List(namespace string, query models.ListQuery) (*networkingv1.NetworkPolicyList, models.ListMeta, error) {
+	items, meta, err := listPage(query, nil, func(opts metav1.ListOptions) ([]networkingv1.NetworkPolicy, metav1.ListMeta, error) {
+		list, err := s.client.NetworkingV1().NetworkPolicies(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
+	if err != nil {
+		return nil, models.ListMeta{}, err
+	}
+	return &networkingv1.NetworkPolicyList{ListMeta: k8sListMeta(meta), Items: items}, meta, nil
 }
 
 // Watch机制实现
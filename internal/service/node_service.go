@@ -3,22 +3,36 @@ package service
 import (
 	"context"
 
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 )
 
 type NodeService struct {
+	writeOptions
 	client kubernetes.Interface
+	cache  *k8s.ResourceCache // nil 时直接读取 API Server
 }
 
-func NewNodeService(client kubernetes.Interface) *NodeService {
-	return &NodeService{client: client}
+func NewNodeService(client kubernetes.Interface, cache *k8s.ResourceCache) *NodeService {
+	return &NodeService{client: client, cache: cache}
 }
 
 // 获取单个Node
 func (s *NodeService) Get(name string) (*corev1.Node, error) {
+	if s.cache != nil {
+		if lister, err := s.cache.Nodes(); err == nil {
+			if obj, err := lister.Get(name); err == nil {
+				return obj.DeepCopy(), nil
+			}
+		}
+	}
 	return s.client.CoreV1().Nodes().Get(
 		context.TODO(),
 		name,
@@ -31,7 +45,7 @@ func (s *NodeService) Create(node *corev1.Node) (*corev1.Node, error) {
 	return s.client.CoreV1().Nodes().Create(
 		context.TODO(),
 		node,
-		metav1.CreateOptions{},
+		s.createOptions(),
 	)
 }
 
@@ -40,32 +54,55 @@ func (s *NodeService) Update(node *corev1.Node) (*corev1.Node, error) {
 	return s.client.CoreV1().Nodes().Update(
 		context.TODO(),
 		node,
-		metav1.UpdateOptions{},
+		s.updateOptions(),
 	)
 }
 
+// Patch 按 patchType 修改Node
+func (s *NodeService) Patch(name string, patchType types.PatchType, data []byte) (*corev1.Node, error) {
+	return s.client.CoreV1().Nodes().Patch(context.TODO(), name, patchType, data, s.patchOptions(patchType))
+}
+
 // 删除Node
 func (s *NodeService) Delete(name string) error {
 	return s.client.CoreV1().Nodes().Delete(
 		context.TODO(),
 		name,
-		metav1.DeleteOptions{},
+		s.deleteOptions(),
 	)
 }
 
-// 列表查询（支持分页和标签过滤）
-func (s *NodeService) List(selector string, limit int64) (*corev1.NodeList, error) {
-	return s.client.CoreV1().Nodes().List(
-		context.TODO(),
-		metav1.ListOptions{
-			LabelSelector: selector,
-			Limit:         limit,
-		},
-	)
+// List 按统一查询参数 (分页、选择器、排序、名称搜索) 列出 Node
+func (s *NodeService) List(query models.ListQuery) (*corev1.NodeList, models.ListMeta, error) {
+	fromCache := cacheList(s.cache, k8s.CachedNodes, func(selector labels.Selector) ([]*corev1.Node, error) {
+		lister, err := s.cache.Nodes()
+		if err != nil {
+			return nil, err
+		}
+		return lister.List(selector)
+	})
+	items, meta, err := listPage(query, fromCache, func(opts metav1.ListOptions) ([]corev1.Node, metav1.ListMeta, error) {
+		list, err := s.client.CoreV1().Nodes().List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
+	if err != nil {
+		return nil, models.ListMeta{}, err
+	}
+	return &corev1.NodeList{ListMeta: k8sListMeta(meta), Items: items}, meta, nil
 }
 
 // Watch机制实现
 func (s *NodeService) Watch(selector string) (watch.Interface, error) {
+	if s.cache != nil {
+		w, err := s.cache.Watch(k8s.CachedNodes, "", selector)
+		if err == nil {
+			return w, nil
+		}
+		cacheUnavailable(k8s.CachedNodes, err)
+	}
 	return s.client.CoreV1().Nodes().Watch(
 		context.TODO(),
 		metav1.ListOptions{
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// ErrOIDCLogin 授权码交换或 ID Token 校验失败
+var ErrOIDCLogin = errors.New("OIDC 登录失败")
+
+// OIDCLoginState 一次授权码登录的临时状态，发起登录时生成，回调时校验
+type OIDCLoginState struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"` // PKCE code_verifier
+}
+
+// OIDCService OIDC 授权码登录。Provider 在首次使用时通过 discovery 获取，
+// 失败后下次请求重试，因此身份提供方暂时不可用不会影响服务启动
+type OIDCService struct {
+	cfg         configs.OIDCConfig
+	authService *AuthService
+
+	mu       sync.Mutex
+	oauth2   *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+func NewOIDCService(cfg configs.OIDCConfig, authService *AuthService) *OIDCService {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{oidc.ScopeOpenID, "profile", "email", "groups"}
+	}
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = "preferred_username"
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	if cfg.DefaultRole == "" {
+		cfg.DefaultRole = models.RoleUser
+	}
+	return &OIDCService{cfg: cfg, authService: authService}
+}
+
+// PostLoginRedirect 登录成功后跳转的前端地址
+func (s *OIDCService) PostLoginRedirect() string {
+	return s.cfg.PostLoginRedirect
+}
+
+// discover 获取 (并缓存) Provider 元数据与签名密钥
+func (s *OIDCService) discover() (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.oauth2 != nil {
+		return s.oauth2, s.verifier, nil
+	}
+
+	// Provider 保存该 context 用于之后拉取签名密钥，不能使用请求的 context
+	provider, err := oidc.NewProvider(context.Background(), s.cfg.IssuerURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取 OIDC Provider 配置失败: %w", err)
+	}
+	s.oauth2 = &oauth2.Config{
+		ClientID:     s.cfg.ClientID,
+		ClientSecret: s.cfg.ClientSecret,
+		RedirectURL:  s.cfg.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       s.cfg.Scopes,
+	}
+	s.verifier = provider.Verifier(&oidc.Config{ClientID: s.cfg.ClientID})
+	return s.oauth2, s.verifier, nil
+}
+
+// BeginLogin 生成登录状态及跳转到身份提供方的授权地址
+func (s *OIDCService) BeginLogin() (*OIDCLoginState, string, error) {
+	config, _, err := s.discover()
+	if err != nil {
+		return nil, "", err
+	}
+	state, err := randomToken()
+	if err != nil {
+		return nil, "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return nil, "", err
+	}
+	loginState := &OIDCLoginState{State: state, Nonce: nonce, Verifier: oauth2.GenerateVerifier()}
+	url := config.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(loginState.Verifier))
+	return loginState, url, nil
+}
+
+// CompleteLogin 用授权码换取并校验 ID Token，创建或更新用户后签发 CiliKube token
+func (s *OIDCService) CompleteLogin(ctx context.Context, loginState *OIDCLoginState, code string, client models.LoginClient) (*models.LoginResponse, error) {
+	identity, err := s.verifyCallback(ctx, loginState, code)
+	if err != nil {
+		s.authService.auditLogin(client, models.LoginMethodOIDC, "", 0, err.Error())
+		return nil, err
+	}
+	response, err := s.authService.LoginExternal(identity)
+	if err != nil {
+		s.authService.auditLogin(client, models.LoginMethodOIDC, identity.Username, 0, err.Error())
+		return nil, err
+	}
+	s.authService.auditLogin(client, models.LoginMethodOIDC, identity.Username, response.User.ID, "")
+	return response, nil
+}
+
+// verifyCallback 交换授权码并校验 ID Token，返回映射后的外部身份
+func (s *OIDCService) verifyCallback(ctx context.Context, loginState *OIDCLoginState, code string) (*ExternalIdentity, error) {
+	config, verifier, err := s.discover()
+	if err != nil {
+		return nil, err
+	}
+	token, err := config.Exchange(ctx, code, oauth2.VerifierOption(loginState.Verifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: 授权码交换失败: %v", ErrOIDCLogin, err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, fmt.Errorf("%w: 响应中缺少 id_token", ErrOIDCLogin)
+	}
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: ID Token 校验失败: %v", ErrOIDCLogin, err)
+	}
+	if idToken.Nonce != loginState.Nonce {
+		return nil, fmt.Errorf("%w: nonce 不匹配", ErrOIDCLogin)
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: 解析 ID Token 声明失败: %v", ErrOIDCLogin, err)
+	}
+	return s.identityFromClaims(idToken.Issuer, idToken.Subject, claims)
+}
+
+// identityFromClaims 按配置的声明名取用户名、邮箱和组，并把组映射为角色
+func (s *OIDCService) identityFromClaims(issuer, subject string, claims map[string]interface{}) (*ExternalIdentity, error) {
+	username, _ := claims[s.cfg.UsernameClaim].(string)
+	if username == "" {
+		return nil, fmt.Errorf("%w: ID Token 中缺少用户名声明 %q", ErrOIDCLogin, s.cfg.UsernameClaim)
+	}
+	email, _ := claims["email"].(string)
+
+	identity := &ExternalIdentity{
+		Source:     models.UserSourceOIDC,
+		ExternalID: issuer + "#" + subject,
+		Username:   username,
+		Email:      email,
+	}
+	identity.Role, identity.Roles, identity.ManagedRoles = mapGroupsToRoles(s.cfg.GroupRoles, s.cfg.DefaultRole, claimStrings(claims[s.cfg.GroupsClaim]))
+	return identity, nil
+}
+
+// claimStrings 组声明可能是字符串数组，也可能是单个字符串
+func claimStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, strings.TrimSpace(s))
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
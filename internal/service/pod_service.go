@@ -4,9 +4,14 @@ import (
 	"context"
 	"io"
 
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+
 	// Import net/url - Not directly used here, but might be needed elsewhere or was from previous iteration
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1" // Used for Options
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme" // Required for Exec parameter encoding
@@ -16,18 +21,20 @@ import (
 )
 
 type PodService struct {
+	writeOptions
 	client kubernetes.Interface
-	config *rest.Config // Add rest.Config to handle Exec requests
+	config *rest.Config       // Add rest.Config to handle Exec requests
+	cache  *k8s.ResourceCache // nil 时直接读取 API Server
 }
 
 // NewPodService - Updated to accept rest.Config
-func NewPodService(client kubernetes.Interface, config *rest.Config) *PodService {
-	return &PodService{client: client, config: config}
+func NewPodService(client kubernetes.Interface, config *rest.Config, cache *k8s.ResourceCache) *PodService {
+	return &PodService{client: client, config: config, cache: cache}
 }
 
 // ListNamespaces 列出所有命名空间
 func (s *PodService) ListNamespaces() ([]string, error) {
-	namespaceList, err := s.client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	namespaceList, _, err := NewNamespaceService(s.client, s.cache).List(models.ListQuery{})
 	if err != nil {
 		return nil, err
 	}
@@ -40,6 +47,13 @@ func (s *PodService) ListNamespaces() ([]string, error) {
 
 // Get 获取单个Pod
 func (s *PodService) Get(namespace, name string) (*corev1.Pod, error) {
+	if s.cache != nil {
+		if lister, err := s.cache.Pods(); err == nil {
+			if obj, err := lister.Pods(namespace).Get(name); err == nil {
+				return obj.DeepCopy(), nil
+			}
+		}
+	}
 	return s.client.CoreV1().Pods(namespace).Get(
 		context.TODO(),
 		name,
@@ -62,7 +76,7 @@ func (s *PodService) Create(namespace string, pod *corev1.Pod) (*corev1.Pod, err
 	return s.client.CoreV1().Pods(namespace).Create(
 		context.TODO(),
 		pod, // 传递构造好的 Pod 对象
-		metav1.CreateOptions{},
+		s.createOptions(),
 	)
 }
 
@@ -91,7 +105,7 @@ func (s *PodService) CreateFromYAML(namespace string, yamlContent []byte) (*core
 	return s.client.CoreV1().Pods(pod.Namespace).Create(
 		context.TODO(),
 		&pod,
-		metav1.CreateOptions{},
+		s.createOptions(),
 	)
 }
 
@@ -107,7 +121,7 @@ func (s *PodService) Update(namespace string, pod *corev1.Pod) (*corev1.Pod, err
 	return s.client.CoreV1().Pods(namespace).Update(
 		context.TODO(),
 		pod, // 传递构造好的、待更新的 Pod 对象
-		metav1.UpdateOptions{},
+		s.updateOptions(),
 	)
 }
 
@@ -138,32 +152,55 @@ func (s *PodService) UpdateFromYAML(namespace, name string, yamlContent []byte)
 	return s.client.CoreV1().Pods(namespace).Update(
 		context.TODO(),
 		&updatedPod, // 传递反序列化后的 Pod 对象
-		metav1.UpdateOptions{},
+		s.updateOptions(),
 	)
 }
 
+// Patch 按 patchType 修改Pod
+func (s *PodService) Patch(namespace, name string, patchType types.PatchType, data []byte) (*corev1.Pod, error) {
+	return s.client.CoreV1().Pods(namespace).Patch(context.TODO(), name, patchType, data, s.patchOptions(patchType))
+}
+
 // Delete 删除Pod
 func (s *PodService) Delete(namespace, name string) error {
 	return s.client.CoreV1().Pods(namespace).Delete(
 		context.TODO(),
 		name,
-		metav1.DeleteOptions{},
+		s.deleteOptions(),
 	)
 }
 
-// List 列表查询（支持分页和标签过滤）
-func (s *PodService) List(namespace, selector string, limit int64) (*corev1.PodList, error) {
-	return s.client.CoreV1().Pods(namespace).List(
-		context.TODO(),
-		metav1.ListOptions{
-			LabelSelector: selector,
-			Limit:         limit,
-		},
-	)
+// List 按统一查询参数 (分页、选择器、排序、名称搜索) 列出 Pod
+func (s *PodService) List(namespace string, query models.ListQuery) (*corev1.PodList, models.ListMeta, error) {
+	fromCache := cacheList(s.cache, k8s.CachedPods, func(selector labels.Selector) ([]*corev1.Pod, error) {
+		lister, err := s.cache.Pods()
+		if err != nil {
+			return nil, err
+		}
+		return lister.Pods(namespace).List(selector)
+	})
+	items, meta, err := listPage(query, fromCache, func(opts metav1.ListOptions) ([]corev1.Pod, metav1.ListMeta, error) {
+		list, err := s.client.CoreV1().Pods(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
+	if err != nil {
+		return nil, models.ListMeta{}, err
+	}
+	return &corev1.PodList{ListMeta: k8sListMeta(meta), Items: items}, meta, nil
 }
 
 // Watch 机制实现
 func (s *PodService) Watch(namespace, selector string) (watch.Interface, error) {
+	if s.cache != nil {
+		w, err := s.cache.Watch(k8s.CachedPods, namespace, selector)
+		if err == nil {
+			return w, nil
+		}
+		cacheUnavailable(k8s.CachedPods, err)
+	}
 	return s.client.CoreV1().Pods(namespace).Watch(
 		context.TODO(),
 		metav1.ListOptions{
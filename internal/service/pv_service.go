@@ -3,39 +3,58 @@ package service
 import (
 	"context"
 
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 )
 
 type PVService struct {
+	writeOptions
 	client kubernetes.Interface
+	cache  *k8s.ResourceCache // nil 时直接读取 API Server
 }
 
-func NewPVService(client kubernetes.Interface) *PVService {
-	return &PVService{client: client}
+func NewPVService(client kubernetes.Interface, cache *k8s.ResourceCache) *PVService {
+	return &PVService{client: client, cache: cache}
 }
 
 // Get retrieves a single PersistentVolume by name.
 func (s *PVService) Get(name string) (*corev1.PersistentVolume, error) {
+	if s.cache != nil {
+		if lister, err := s.cache.PersistentVolumes(); err == nil {
+			if obj, err := lister.Get(name); err == nil {
+				return obj.DeepCopy(), nil
+			}
+		}
+	}
 	return s.client.CoreV1().PersistentVolumes().Get(context.TODO(), name, metav1.GetOptions{})
 }
 
-// List retrieves a list of PersistentVolumes.
-// Supports label selector filtering and limit.
-// Note: Pagination for cluster-scoped resources requires careful handling with 'continue' tokens
-//
-//	if dealing with very large numbers. For simplicity, limit is used here.
-func (s *PVService) List(labelSelector string, limit int64) (*corev1.PersistentVolumeList, error) {
-	listOptions := metav1.ListOptions{}
-	if labelSelector != "" {
-		listOptions.LabelSelector = labelSelector
-	}
-	if limit > 0 {
-		listOptions.Limit = limit
+// List 按统一查询参数 (分页、选择器、排序、名称搜索) 列出 PersistentVolume
+func (s *PVService) List(query models.ListQuery) (*corev1.PersistentVolumeList, models.ListMeta, error) {
+	fromCache := cacheList(s.cache, k8s.CachedPersistentVolumes, func(selector labels.Selector) ([]*corev1.PersistentVolume, error) {
+		lister, err := s.cache.PersistentVolumes()
+		if err != nil {
+			return nil, err
+		}
+		return lister.List(selector)
+	})
+	items, meta, err := listPage(query, fromCache, func(opts metav1.ListOptions) ([]corev1.PersistentVolume, metav1.ListMeta, error) {
+		list, err := s.client.CoreV1().PersistentVolumes().List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
+	if err != nil {
+		return nil, models.ListMeta{}, err
 	}
-
-	return s.client.CoreV1().PersistentVolumes().List(context.TODO(), listOptions)
+	return &corev1.PersistentVolumeList{ListMeta: k8sListMeta(meta), Items: items}, meta, nil
 }
 
 // Create creates a new PersistentVolume.
@@ -47,7 +66,7 @@ func (s *PVService) Create(pv *corev1.PersistentVolume) (*corev1.PersistentVolum
 	// Ensure namespace is not set for cluster-scoped resource
 	pv.Namespace = ""
 
-	return s.client.CoreV1().PersistentVolumes().Create(context.TODO(), pv, metav1.CreateOptions{})
+	return s.client.CoreV1().PersistentVolumes().Create(context.TODO(), pv, s.createOptions())
 }
 
 // Update updates an existing PersistentVolume.
@@ -68,12 +87,17 @@ func (s *PVService) Update(pv *corev1.PersistentVolume) (*corev1.PersistentVolum
 	// }
 	// pv.ResourceVersion = existing.ResourceVersion // Set for update
 
-	return s.client.CoreV1().PersistentVolumes().Update(context.TODO(), pv, metav1.UpdateOptions{})
+	return s.client.CoreV1().PersistentVolumes().Update(context.TODO(), pv, s.updateOptions())
+}
+
+// Patch 按 patchType 修改PV
+func (s *PVService) Patch(name string, patchType types.PatchType, data []byte) (*corev1.PersistentVolume, error) {
+	return s.client.CoreV1().PersistentVolumes().Patch(context.TODO(), name, patchType, data, s.patchOptions(patchType))
 }
 
 // Delete deletes a PersistentVolume by name.
 func (s *PVService) Delete(name string) error {
-	return s.client.CoreV1().PersistentVolumes().Delete(context.TODO(), name, metav1.DeleteOptions{})
+	return s.client.CoreV1().PersistentVolumes().Delete(context.TODO(), name, s.deleteOptions())
 }
 
 // --- Error Handling (reuse or define locally if not shared) ---
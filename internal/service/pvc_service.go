@@ -86,37 +86,59 @@ package service
 import (
 	"context"
 
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+
 	corev1 "k8s.io/api/core/v1"
 	// "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 )
 
 type PVCService struct {
+	writeOptions
 	client kubernetes.Interface
+	cache  *k8s.ResourceCache // nil 时直接读取 API Server
 }
 
-func NewPVCService(client kubernetes.Interface) *PVCService {
-	return &PVCService{client: client}
+func NewPVCService(client kubernetes.Interface, cache *k8s.ResourceCache) *PVCService {
+	return &PVCService{client: client, cache: cache}
 }
 
 // Get retrieves a single PersistentVolumeClaim by namespace and name.
 func (s *PVCService) Get(namespace, name string) (*corev1.PersistentVolumeClaim, error) {
+	if s.cache != nil {
+		if lister, err := s.cache.PersistentVolumeClaims(); err == nil {
+			if obj, err := lister.PersistentVolumeClaims(namespace).Get(name); err == nil {
+				return obj.DeepCopy(), nil
+			}
+		}
+	}
 	return s.client.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), name, metav1.GetOptions{})
 }
 
-// List retrieves a list of PersistentVolumeClaims in a specific namespace.
-// Supports label selector filtering and limit.
-func (s *PVCService) List(namespace, labelSelector string, limit int64) (*corev1.PersistentVolumeClaimList, error) {
-	listOptions := metav1.ListOptions{}
-	if labelSelector != "" {
-		listOptions.LabelSelector = labelSelector
-	}
-	if limit > 0 {
-		listOptions.Limit = limit
+// List 按统一查询参数 (分页、选择器、排序、名称搜索) 列出 PersistentVolumeClaim
+func (s *PVCService) List(namespace string, query models.ListQuery) (*corev1.PersistentVolumeClaimList, models.ListMeta, error) {
+	fromCache := cacheList(s.cache, k8s.CachedPersistentVolumeClaims, func(selector labels.Selector) ([]*corev1.PersistentVolumeClaim, error) {
+		lister, err := s.cache.PersistentVolumeClaims()
+		if err != nil {
+			return nil, err
+		}
+		return lister.PersistentVolumeClaims(namespace).List(selector)
+	})
+	items, meta, err := listPage(query, fromCache, func(opts metav1.ListOptions) ([]corev1.PersistentVolumeClaim, metav1.ListMeta, error) {
+		list, err := s.client.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
+	if err != nil {
+		return nil, models.ListMeta{}, err
 	}
-
-	return s.client.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), listOptions)
+	return &corev1.PersistentVolumeClaimList{ListMeta: k8sListMeta(meta), Items: items}, meta, nil
 }
 
 // Create creates a new PersistentVolumeClaim.
@@ -133,7 +155,7 @@ func (s *PVCService) Create(namespace string, pvc *corev1.PersistentVolumeClaim)
 	}
 	// Add more validation for spec if needed (e.g., required fields)
 
-	return s.client.CoreV1().PersistentVolumeClaims(namespace).Create(context.TODO(), pvc, metav1.CreateOptions{})
+	return s.client.CoreV1().PersistentVolumeClaims(namespace).Create(context.TODO(), pvc, s.createOptions())
 }
 
 // Update updates an existing PersistentVolumeClaim.
@@ -153,12 +175,17 @@ func (s *PVCService) Update(namespace string, pvc *corev1.PersistentVolumeClaim)
 		return nil, NewValidationError("PVC name cannot be empty for update")
 	}
 
-	return s.client.CoreV1().PersistentVolumeClaims(namespace).Update(context.TODO(), pvc, metav1.UpdateOptions{})
+	return s.client.CoreV1().PersistentVolumeClaims(namespace).Update(context.TODO(), pvc, s.updateOptions())
+}
+
+// Patch 按 patchType 修改PVC
+func (s *PVCService) Patch(namespace, name string, patchType types.PatchType, data []byte) (*corev1.PersistentVolumeClaim, error) {
+	return s.client.CoreV1().PersistentVolumeClaims(namespace).Patch(context.TODO(), name, patchType, data, s.patchOptions(patchType))
 }
 
 // Delete deletes a PersistentVolumeClaim by namespace and name.
 func (s *PVCService) Delete(namespace, name string) error {
-	return s.client.CoreV1().PersistentVolumeClaims(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	return s.client.CoreV1().PersistentVolumeClaims(namespace).Delete(context.TODO(), name, s.deleteOptions())
 }
 
 // --- Error Handling (reuse or define locally) ---
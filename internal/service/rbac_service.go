@@ -2,7 +2,10 @@ package service
 
 import (
 	"context"
+
 	"github.com/ciliverse/cilikube/api/v1/models"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
@@ -13,17 +16,23 @@ type RbacService struct {
 
 func NewRbacService(client kubernetes.Interface) *RbacService { return &RbacService{client: client} }
 
-// Roles
-func (s *RbacService) ListRoles(namespace string) ([]*models.RoleResponse, error) {
-	roleList, err := s.client.RbacV1().Roles(namespace).List(context.TODO(), metav1.ListOptions{})
+// ListRoles 按统一查询参数 (分页、选择器、排序、名称搜索) 列出 Role
+func (s *RbacService) ListRoles(namespace string, query models.ListQuery) ([]*models.RoleResponse, models.ListMeta, error) {
+	items, meta, err := listPage(query, nil, func(opts metav1.ListOptions) ([]rbacv1.Role, metav1.ListMeta, error) {
+		list, err := s.client.RbacV1().Roles(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
 	if err != nil {
-		return nil, err
+		return nil, models.ListMeta{}, err
 	}
-	var roles []*models.RoleResponse
-	for _, role := range roleList.Items {
-		roles = append(roles, models.ToRoleResponse(&role))
+	result := make([]*models.RoleResponse, 0, len(items))
+	for i := range items {
+		result = append(result, models.ToRoleResponse(&items[i]))
 	}
-	return roles, nil
+	return result, meta, nil
 }
 
 // GetRole retrieves a single Role by namespace and name.
@@ -35,17 +44,23 @@ func (s *RbacService) GetRole(namespace string, name string) (*models.RoleRespon
 	return models.ToRoleResponse(role), nil
 }
 
-// RoleBindings
-func (s *RbacService) ListRoleBindings(namespace string) ([]*models.RoleBindingResponse, error) {
-	roleBindingList, err := s.client.RbacV1().RoleBindings(namespace).List(context.TODO(), metav1.ListOptions{})
+// ListRoleBindings 按统一查询参数列出 RoleBinding
+func (s *RbacService) ListRoleBindings(namespace string, query models.ListQuery) ([]*models.RoleBindingResponse, models.ListMeta, error) {
+	items, meta, err := listPage(query, nil, func(opts metav1.ListOptions) ([]rbacv1.RoleBinding, metav1.ListMeta, error) {
+		list, err := s.client.RbacV1().RoleBindings(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
 	if err != nil {
-		return nil, err
+		return nil, models.ListMeta{}, err
 	}
-	var roleBindings []*models.RoleBindingResponse
-	for _, roleBinding := range roleBindingList.Items {
-		roleBindings = append(roleBindings, models.ToRoleBindingResponse(&roleBinding))
+	result := make([]*models.RoleBindingResponse, 0, len(items))
+	for i := range items {
+		result = append(result, models.ToRoleBindingResponse(&items[i]))
 	}
-	return roleBindings, nil
+	return result, meta, nil
 }
 
 func (s *RbacService) GetRoleBinding(namespace string, name string) (*models.RoleBindingResponse, error) {
@@ -56,17 +71,23 @@ func (s *RbacService) GetRoleBinding(namespace string, name string) (*models.Rol
 	return models.ToRoleBindingResponse(roleBinding), nil
 }
 
-// ClusterRoles
-func (s *RbacService) ListClusterRoles() ([]*models.ClusterRoleResponse, error) {
-	clusterRoleList, err := s.client.RbacV1().ClusterRoles().List(context.TODO(), metav1.ListOptions{})
+// ListClusterRoles 按统一查询参数列出 ClusterRole
+func (s *RbacService) ListClusterRoles(query models.ListQuery) ([]*models.ClusterRoleResponse, models.ListMeta, error) {
+	items, meta, err := listPage(query, nil, func(opts metav1.ListOptions) ([]rbacv1.ClusterRole, metav1.ListMeta, error) {
+		list, err := s.client.RbacV1().ClusterRoles().List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
 	if err != nil {
-		return nil, err
+		return nil, models.ListMeta{}, err
 	}
-	var clusterRoles []*models.ClusterRoleResponse
-	for _, clusterRole := range clusterRoleList.Items {
-		clusterRoles = append(clusterRoles, models.ToClusterRoleResponse(&clusterRole))
+	result := make([]*models.ClusterRoleResponse, 0, len(items))
+	for i := range items {
+		result = append(result, models.ToClusterRoleResponse(&items[i]))
 	}
-	return clusterRoles, nil
+	return result, meta, nil
 }
 
 func (s *RbacService) GetClusterRole(name string) (*models.ClusterRoleResponse, error) {
@@ -77,17 +98,23 @@ func (s *RbacService) GetClusterRole(name string) (*models.ClusterRoleResponse,
 	return models.ToClusterRoleResponse(clusterRole), nil
 }
 
-// ClusterRoleBindings
-func (s *RbacService) ListClusterRoleBindings() ([]*models.ClusterRoleBindingsResponse, error) {
-	clusterRoleBindingsList, err := s.client.RbacV1().ClusterRoleBindings().List(context.TODO(), metav1.ListOptions{})
+// ListClusterRoleBindings 按统一查询参数列出 ClusterRoleBinding
+func (s *RbacService) ListClusterRoleBindings(query models.ListQuery) ([]*models.ClusterRoleBindingsResponse, models.ListMeta, error) {
+	items, meta, err := listPage(query, nil, func(opts metav1.ListOptions) ([]rbacv1.ClusterRoleBinding, metav1.ListMeta, error) {
+		list, err := s.client.RbacV1().ClusterRoleBindings().List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
 	if err != nil {
-		return nil, err
+		return nil, models.ListMeta{}, err
 	}
-	var clusterRoleBindings []*models.ClusterRoleBindingsResponse
-	for _, clusterRoleBinding := range clusterRoleBindingsList.Items {
-		clusterRoleBindings = append(clusterRoleBindings, models.ToClusterRoleBindingsResponse(&clusterRoleBinding))
+	result := make([]*models.ClusterRoleBindingsResponse, 0, len(items))
+	for i := range items {
+		result = append(result, models.ToClusterRoleBindingsResponse(&items[i]))
 	}
-	return clusterRoleBindings, nil
+	return result, meta, nil
 }
 
 func (s *RbacService) GetClusterRoleBinding(name string) (*models.ClusterRoleBindingsResponse, error) {
@@ -98,17 +125,23 @@ func (s *RbacService) GetClusterRoleBinding(name string) (*models.ClusterRoleBin
 	return models.ToClusterRoleBindingsResponse(clusterRoleBinding), nil
 }
 
-// ServiceAccount
-func (s *RbacService) ListServiceAccounts(namespace string) ([]*models.ServiceAccountsResponse, error) {
-	serviceAccountList, err := s.client.CoreV1().ServiceAccounts(namespace).List(context.TODO(), metav1.ListOptions{})
+// ListServiceAccounts 按统一查询参数列出 ServiceAccount
+func (s *RbacService) ListServiceAccounts(namespace string, query models.ListQuery) ([]*models.ServiceAccountsResponse, models.ListMeta, error) {
+	items, meta, err := listPage(query, nil, func(opts metav1.ListOptions) ([]corev1.ServiceAccount, metav1.ListMeta, error) {
+		list, err := s.client.CoreV1().ServiceAccounts(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
 	if err != nil {
-		return nil, err
+		return nil, models.ListMeta{}, err
 	}
-	var serviceAccounts []*models.ServiceAccountsResponse
-	for _, serviceAccount := range serviceAccountList.Items {
-		serviceAccounts = append(serviceAccounts, models.ToServiceAccountsResponse(&serviceAccount))
+	result := make([]*models.ServiceAccountsResponse, 0, len(items))
+	for i := range items {
+		result = append(result, models.ToServiceAccountsResponse(&items[i]))
 	}
-	return serviceAccounts, nil
+	return result, meta, nil
 }
 
 func (s *RbacService) GetServiceAccounts(namespace string, name string) (*models.ServiceAccountsResponse, error) {
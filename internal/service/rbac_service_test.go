@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/ciliverse/cilikube/api/v1/models"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -39,7 +40,7 @@ func TestRbacService_ListRoles(t *testing.T) {
 	service := NewRbacService(fakeClient)
 
 	// 测试 ListRoles
-	roles, err := service.ListRoles("default")
+	roles, _, err := service.ListRoles("default", models.ListQuery{})
 	assert.NoError(t, err)
 	assert.Len(t, roles, 1)
 	assert.Equal(t, "test-role", roles[0].Name)
@@ -113,7 +114,7 @@ func TestRbacService_ListRoleBindings(t *testing.T) {
 	service := NewRbacService(fakeClient)
 
 	// 测试 ListRoleBindings
-	roleBindings, err := service.ListRoleBindings("default")
+	roleBindings, _, err := service.ListRoleBindings("default", models.ListQuery{})
 	assert.NoError(t, err)
 	assert.Len(t, roleBindings, 1)
 	assert.Equal(t, "test-rolebinding", roleBindings[0].Name)
@@ -188,12 +189,34 @@ func TestRbacService_ListClusterRoles(t *testing.T) {
 	service := NewRbacService(fakeClient)
 
 	// 测试 ListClusterRoles
-	clusterRoles, err := service.ListClusterRoles()
+	clusterRoles, _, err := service.ListClusterRoles(models.ListQuery{})
 	assert.NoError(t, err)
 	assert.Len(t, clusterRoles, 1)
 	assert.Equal(t, "test-clusterrole", clusterRoles[0].Name)
 }
 
+// 测试 ListClusterRoles 的分页、排序与名称搜索
+func TestRbacService_ListClusterRolesQuery(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "view"}},
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "system:aggregate-to-view"}},
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "edit"}},
+	)
+	service := NewRbacService(fakeClient)
+
+	clusterRoles, meta, err := service.ListClusterRoles(models.ListQuery{Search: "VIEW", SortBy: models.SortByName, Order: models.SortOrderDesc, Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, clusterRoles, 1)
+	assert.Equal(t, "view", clusterRoles[0].Name)
+	assert.Equal(t, 2, meta.Total)
+
+	clusterRoles, meta, err = service.ListClusterRoles(models.ListQuery{Search: "VIEW", SortBy: models.SortByName, Order: models.SortOrderDesc, Limit: 1, Continue: meta.Continue})
+	assert.NoError(t, err)
+	assert.Len(t, clusterRoles, 1)
+	assert.Equal(t, "system:aggregate-to-view", clusterRoles[0].Name)
+	assert.Empty(t, meta.Continue)
+}
+
 // 测试 GetClusterRole 方法
 func TestRbacService_GetClusterRole(t *testing.T) {
 	// 创建一个假的 Kubernetes 客户端
@@ -259,7 +282,7 @@ func TestRbacService_ListClusterRoleBindings(t *testing.T) {
 	service := NewRbacService(fakeClient)
 
 	// 测试 ListClusterRoleBindings
-	clusterRoleBindings, err := service.ListClusterRoleBindings()
+	clusterRoleBindings, _, err := service.ListClusterRoleBindings(models.ListQuery{})
 	assert.NoError(t, err)
 	assert.Len(t, clusterRoleBindings, 1)
 	assert.Equal(t, "test-clusterrolebinding", clusterRoleBindings[0].Name)
@@ -326,7 +349,7 @@ func TestRbacService_ListServiceAccounts(t *testing.T) {
 	service := NewRbacService(fakeClient)
 
 	// 测试 ListServiceAccounts
-	serviceAccounts, err := service.ListServiceAccounts("default")
+	serviceAccounts, _, err := service.ListServiceAccounts("default", models.ListQuery{})
 	assert.NoError(t, err)
 	assert.Len(t, serviceAccounts, 1)
 	assert.Equal(t, "test-sa", serviceAccounts[0].Name)
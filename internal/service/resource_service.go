@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// FieldManager CiliKube 写入对象时使用的字段管理者名称
+const FieldManager = "cilikube"
+
+// ErrResourceNotFound 集群未提供请求的 GroupVersionResource
+var ErrResourceNotFound = errors.New("resource not found")
+
+// ResourceService 基于 dynamic client 和 discovery 访问任意资源 (包括 CRD)
+type ResourceService struct {
+	writeOptions
+	dynamic   dynamic.Interface
+	discovery discovery.DiscoveryInterface
+}
+
+func NewResourceService(dynamicClient dynamic.Interface, discoveryClient discovery.DiscoveryInterface) *ResourceService {
+	return &ResourceService{dynamic: dynamicClient, discovery: discoveryClient}
+}
+
+// Discover 列出集群提供的全部 API 资源 (不含子资源)，按 group/resource/version 排序。
+// 部分聚合 API 不可用时仍返回其余资源，并在 FailedGroups 中给出原因
+func (s *ResourceService) Discover() (*models.APIResourceListResponse, error) {
+	groups, lists, err := s.discovery.ServerGroupsAndResources()
+	response := &models.APIResourceListResponse{Items: []models.APIResource{}}
+	if err != nil {
+		var groupErr *discovery.ErrGroupDiscoveryFailed
+		if !errors.As(err, &groupErr) {
+			return nil, err
+		}
+		response.FailedGroups = make(map[string]string, len(groupErr.Groups))
+		for gv, gvErr := range groupErr.Groups {
+			response.FailedGroups[gv.String()] = gvErr.Error()
+		}
+	}
+
+	preferred := make(map[string]string, len(groups))
+	for _, group := range groups {
+		preferred[group.Name] = group.PreferredVersion.Version
+	}
+
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if strings.Contains(r.Name, "/") {
+				continue // 子资源 (如 pods/log) 通过各自的专用接口访问
+			}
+			verbs := []string(r.Verbs)
+			if verbs == nil {
+				verbs = []string{}
+			}
+			response.Items = append(response.Items, models.APIResource{
+				Group:      gv.Group,
+				Version:    gv.Version,
+				Resource:   r.Name,
+				Kind:       r.Kind,
+				Namespaced: r.Namespaced,
+				Verbs:      verbs,
+				ShortNames: r.ShortNames,
+				Categories: r.Categories,
+				Preferred:  preferred[gv.Group] == gv.Version,
+			})
+		}
+	}
+
+	sort.Slice(response.Items, func(i, j int) bool {
+		a, b := response.Items[i], response.Items[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		return a.Version < b.Version
+	})
+	response.Total = len(response.Items)
+	return response, nil
+}
+
+// ResolveResource 返回 GroupVersionResource 在集群中的定义；
+// 找不到时刷新一次发现缓存再查，以便识别新安装的 CRD (刷新频率由 k8s.Client.Discovery 限制)
+func (s *ResourceService) ResolveResource(gvr schema.GroupVersionResource) (*metav1.APIResource, error) {
+	resource, err := s.findResource(gvr)
+	if errors.Is(err, ErrResourceNotFound) {
+		if cached, ok := s.discovery.(discovery.CachedDiscoveryInterface); ok {
+			cached.Invalidate()
+			resource, err = s.findResource(gvr)
+		}
+	}
+	return resource, err
+}
+
+func (s *ResourceService) findResource(gvr schema.GroupVersionResource) (*metav1.APIResource, error) {
+	list, err := s.discovery.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		if apierrors.IsNotFound(err) || errors.Is(err, memory.ErrCacheNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, gvr.GroupVersion().String())
+		}
+		return nil, err
+	}
+	for i := range list.APIResources {
+		if list.APIResources[i].Name == gvr.Resource {
+			resource := list.APIResources[i]
+			return &resource, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, gvr.String())
+}
+
+// resourceClient 按资源作用域返回 dynamic client；allNamespaces 为 true 时命名空间级资源允许不指定命名空间
+func (s *ResourceService) resourceClient(gvr schema.GroupVersionResource, namespace string, allNamespaces bool) (dynamic.ResourceInterface, *metav1.APIResource, error) {
+	resource, err := s.ResolveResource(gvr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !resource.Namespaced {
+		if namespace != "" {
+			return nil, nil, NewValidationError(fmt.Sprintf("%s 是集群级资源，不能指定命名空间", gvr.Resource))
+		}
+		return s.dynamic.Resource(gvr), resource, nil
+	}
+	if namespace == "" && !allNamespaces {
+		return nil, nil, NewValidationError(fmt.Sprintf("%s 是命名空间级资源，必须指定命名空间", gvr.Resource))
+	}
+	return s.dynamic.Resource(gvr).Namespace(namespace), resource, nil
+}
+
+// List 列出资源；命名空间级资源不指定命名空间时列出所有命名空间
+func (s *ResourceService) List(gvr schema.GroupVersionResource, namespace string, query models.ListQuery) (*unstructured.UnstructuredList, models.ListMeta, error) {
+	client, _, err := s.resourceClient(gvr, namespace, true)
+	if err != nil {
+		return nil, models.ListMeta{}, err
+	}
+
+	var apiVersion, kind string
+	items, meta, err := listPage(query, nil, func(opts metav1.ListOptions) ([]unstructured.Unstructured, metav1.ListMeta, error) {
+		list, err := client.List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		apiVersion, kind = list.GetAPIVersion(), list.GetKind()
+		return list.Items, metav1.ListMeta{Continue: list.GetContinue(), RemainingItemCount: list.GetRemainingItemCount()}, nil
+	})
+	if err != nil {
+		return nil, models.ListMeta{}, err
+	}
+
+	list := &unstructured.UnstructuredList{Object: map[string]interface{}{}, Items: items}
+	list.SetAPIVersion(apiVersion)
+	list.SetKind(kind)
+	list.SetContinue(meta.Continue)
+	list.SetRemainingItemCount(meta.RemainingItemCount)
+	return list, meta, nil
+}
+
+// Get 获取单个资源
+func (s *ResourceService) Get(gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	client, _, err := s.resourceClient(gvr, namespace, false)
+	if err != nil {
+		return nil, err
+	}
+	return client.Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// Create 创建资源
+func (s *ResourceService) Create(gvr schema.GroupVersionResource, namespace string, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	client, resource, err := s.resourceClient(gvr, namespace, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkObject(gvr, resource, namespace, obj); err != nil {
+		return nil, err
+	}
+	if obj.GetName() == "" && obj.GetGenerateName() == "" {
+		return nil, NewValidationError("metadata.name 不能为空")
+	}
+	return client.Create(context.TODO(), obj, s.createOptions())
+}
+
+// Update 整体更新资源，请求体中的名称必须与路径一致
+func (s *ResourceService) Update(gvr schema.GroupVersionResource, namespace, name string, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	client, resource, err := s.resourceClient(gvr, namespace, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkObject(gvr, resource, namespace, obj); err != nil {
+		return nil, err
+	}
+	if obj.GetName() == "" {
+		obj.SetName(name)
+	} else if obj.GetName() != name {
+		return nil, NewValidationError("metadata.name 与路径参数不一致")
+	}
+	return client.Update(context.TODO(), obj, s.updateOptions())
+}
+
+// Diff 服务端 dry-run 提交 obj，返回与当前对象的差异
+func (s *ResourceService) Diff(gvr schema.GroupVersionResource, namespace, name string, obj *unstructured.Unstructured, mode string) (*models.DiffResponse, error) {
+	client, resource, err := s.resourceClient(gvr, namespace, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkObject(gvr, resource, namespace, obj); err != nil {
+		return nil, err
+	}
+	return diffObject(client, namespace, name, obj, mode)
+}
+
+// Patch 按 patchType 修改资源
+func (s *ResourceService) Patch(gvr schema.GroupVersionResource, namespace, name string, patchType types.PatchType, data []byte) (*unstructured.Unstructured, error) {
+	client, _, err := s.resourceClient(gvr, namespace, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, NewValidationError("patch 内容不能为空")
+	}
+	return client.Patch(context.TODO(), name, patchType, data, s.patchOptions(patchType))
+}
+
+// Delete 删除资源，propagation 为空时使用 API Server 的默认策略
+func (s *ResourceService) Delete(gvr schema.GroupVersionResource, namespace, name string, propagation metav1.DeletionPropagation) error {
+	client, _, err := s.resourceClient(gvr, namespace, false)
+	if err != nil {
+		return err
+	}
+	opts := s.deleteOptions()
+	if propagation != "" {
+		opts.PropagationPolicy = &propagation
+	}
+	return client.Delete(context.TODO(), name, opts)
+}
+
+// Watch 监听资源变化
+func (s *ResourceService) Watch(gvr schema.GroupVersionResource, namespace, labelSelector, fieldSelector string) (watch.Interface, error) {
+	client, _, err := s.resourceClient(gvr, namespace, true)
+	if err != nil {
+		return nil, err
+	}
+	return client.Watch(context.TODO(), metav1.ListOptions{
+		LabelSelector:  labelSelector,
+		FieldSelector:  fieldSelector,
+		Watch:          true,
+		TimeoutSeconds: int64ptr(1800), // 30 minutes
+	})
+}
+
+// DecodeUnstructured 将 JSON 或 YAML 解码为 unstructured 对象。
+// 不要求包含 apiVersion/kind，由调用方按路径补全或校验
+func DecodeUnstructured(data []byte) (*unstructured.Unstructured, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, NewValidationError("无法解析请求体: " + err.Error())
+	}
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(jsonData, &obj.Object); err != nil {
+		return nil, NewValidationError("无法解析请求体: " + err.Error())
+	}
+	if len(obj.Object) == 0 {
+		return nil, NewValidationError("请求体不能为空")
+	}
+	return obj, nil
+}
+
+// checkObject 校验对象的 apiVersion/kind 与路径一致，并补全命名空间
+func checkObject(gvr schema.GroupVersionResource, resource *metav1.APIResource, namespace string, obj *unstructured.Unstructured) error {
+	if obj == nil {
+		return NewValidationError("请求体不能为空")
+	}
+	if obj.GetAPIVersion() != gvr.GroupVersion().String() {
+		return NewValidationError(fmt.Sprintf("apiVersion '%s' 与路径 '%s' 不一致", obj.GetAPIVersion(), gvr.GroupVersion().String()))
+	}
+	if resource.Kind != "" && obj.GetKind() != resource.Kind {
+		return NewValidationError(fmt.Sprintf("kind '%s' 与资源 '%s' (%s) 不一致", obj.GetKind(), gvr.Resource, resource.Kind))
+	}
+	if obj.GetNamespace() != "" && obj.GetNamespace() != namespace {
+		return NewValidationError("metadata.namespace 与路径参数不一致")
+	}
+	if namespace != "" {
+		obj.SetNamespace(namespace)
+	}
+	return nil
+}
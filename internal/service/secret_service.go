@@ -3,12 +3,16 @@ package service
 import (
 	"context"
 
+	"github.com/ciliverse/cilikube/api/v1/models"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 )
 
 type SecretService struct {
+	writeOptions
 	client kubernetes.Interface
 }
 
@@ -21,16 +25,20 @@ func (s *SecretService) Get(namespace, name string) (*corev1.Secret, error) {
 	return s.client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
 }
 
-// List retrieves Secrets within a specific namespace.
-func (s *SecretService) List(namespace, labelSelector string, limit int64) (*corev1.SecretList, error) {
-	listOptions := metav1.ListOptions{}
-	if labelSelector != "" {
-		listOptions.LabelSelector = labelSelector
-	}
-	if limit > 0 {
-		listOptions.Limit = limit
+// List 按统一查询参数 (分页、选择器、排序、名称搜索) 列出 Secret
+// Secret 不进入缓存，始终读取 API Server
+func (s *SecretService) List(namespace string, query models.ListQuery) (*corev1.SecretList, models.ListMeta, error) {
+	items, meta, err := listPage(query, nil, func(opts metav1.ListOptions) ([]corev1.Secret, metav1.ListMeta, error) {
+		list, err := s.client.CoreV1().Secrets(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
+	if err != nil {
+		return nil, models.ListMeta{}, err
 	}
-	return s.client.CoreV1().Secrets(namespace).List(context.TODO(), listOptions)
+	return &corev1.SecretList{ListMeta: k8sListMeta(meta), Items: items}, meta, nil
 }
 
 // Create creates a new Secret in the specified namespace.
@@ -46,7 +54,7 @@ func (s *SecretService) Create(namespace string, secret *corev1.Secret) (*corev1
 	}
 	// K8s automatically base64 encodes StringData into Data if Data[key] doesn't exist.
 	// No need for manual encoding here if receiving corev1.Secret object.
-	return s.client.CoreV1().Secrets(namespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+	return s.client.CoreV1().Secrets(namespace).Create(context.TODO(), secret, s.createOptions())
 }
 
 // Update updates an existing Secret.
@@ -61,12 +69,17 @@ func (s *SecretService) Update(namespace string, secret *corev1.Secret) (*corev1
 		return nil, NewValidationError("Secret name required for update")
 	}
 	// Fetch existing for ResourceVersion recommended
-	return s.client.CoreV1().Secrets(namespace).Update(context.TODO(), secret, metav1.UpdateOptions{})
+	return s.client.CoreV1().Secrets(namespace).Update(context.TODO(), secret, s.updateOptions())
+}
+
+// Patch 按 patchType 修改Secret
+func (s *SecretService) Patch(namespace, name string, patchType types.PatchType, data []byte) (*corev1.Secret, error) {
+	return s.client.CoreV1().Secrets(namespace).Patch(context.TODO(), name, patchType, data, s.patchOptions(patchType))
 }
 
 // Delete deletes a Secret by namespace and name.
 func (s *SecretService) Delete(namespace, name string) error {
-	return s.client.CoreV1().Secrets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	return s.client.CoreV1().Secrets(namespace).Delete(context.TODO(), name, s.deleteOptions())
 }
 
 // --- Re-use or define ValidationError ---
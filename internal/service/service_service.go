@@ -3,34 +3,58 @@ package service
 import (
 	"context"
 
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 )
 
 type ServiceService struct {
+	writeOptions
 	client kubernetes.Interface
+	cache  *k8s.ResourceCache // nil 时直接读取 API Server
 }
 
-func NewServiceService(client kubernetes.Interface) *ServiceService {
-	return &ServiceService{client: client}
+func NewServiceService(client kubernetes.Interface, cache *k8s.ResourceCache) *ServiceService {
+	return &ServiceService{client: client, cache: cache}
 }
 
-// 列表查询（支持分页和标签过滤）
-func (s *ServiceService) List(namespace string) (*corev1.ServiceList, error) {
-	if namespace == "" {
-		namespace = corev1.NamespaceAll
+// List 按统一查询参数 (分页、选择器、排序、名称搜索) 列出 Service
+func (s *ServiceService) List(namespace string, query models.ListQuery) (*corev1.ServiceList, models.ListMeta, error) {
+	fromCache := cacheList(s.cache, k8s.CachedServices, func(selector labels.Selector) ([]*corev1.Service, error) {
+		lister, err := s.cache.Services()
+		if err != nil {
+			return nil, err
+		}
+		return lister.Services(namespace).List(selector)
+	})
+	items, meta, err := listPage(query, fromCache, func(opts metav1.ListOptions) ([]corev1.Service, metav1.ListMeta, error) {
+		list, err := s.client.CoreV1().Services(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
+	if err != nil {
+		return nil, models.ListMeta{}, err
 	}
-
-	return s.client.CoreV1().Services(namespace).List(
-		context.TODO(),
-		metav1.ListOptions{},
-	)
+	return &corev1.ServiceList{ListMeta: k8sListMeta(meta), Items: items}, meta, nil
 }
 
 // 获取单个Service
 func (s *ServiceService) Get(namespace, name string) (*corev1.Service, error) {
+	if s.cache != nil {
+		if lister, err := s.cache.Services(); err == nil {
+			if obj, err := lister.Services(namespace).Get(name); err == nil {
+				return obj.DeepCopy(), nil
+			}
+		}
+	}
 	return s.client.CoreV1().Services(namespace).Get(
 		context.TODO(),
 		name,
@@ -48,7 +72,7 @@ func (s *ServiceService) Create(namespace string, service *corev1.Service) (*cor
 	return s.client.CoreV1().Services(namespace).Create(
 		context.TODO(),
 		service,
-		metav1.CreateOptions{},
+		s.createOptions(),
 	)
 }
 
@@ -57,21 +81,33 @@ func (s *ServiceService) Update(namespace string, service *corev1.Service) (*cor
 	return s.client.CoreV1().Services(namespace).Update(
 		context.TODO(),
 		service,
-		metav1.UpdateOptions{},
+		s.updateOptions(),
 	)
 }
 
+// Patch 按 patchType 修改Service
+func (s *ServiceService) Patch(namespace, name string, patchType types.PatchType, data []byte) (*corev1.Service, error) {
+	return s.client.CoreV1().Services(namespace).Patch(context.TODO(), name, patchType, data, s.patchOptions(patchType))
+}
+
 // 删除Service
 func (s *ServiceService) Delete(namespace, name string) error {
 	return s.client.CoreV1().Services(namespace).Delete(
 		context.TODO(),
 		name,
-		metav1.DeleteOptions{},
+		s.deleteOptions(),
 	)
 }
 
 // Watch机制实现
 func (s *ServiceService) Watch(namespace, selector string) (watch.Interface, error) {
+	if s.cache != nil {
+		w, err := s.cache.Watch(k8s.CachedServices, namespace, selector)
+		if err == nil {
+			return w, nil
+		}
+		cacheUnavailable(k8s.CachedServices, err)
+	}
 	return s.client.CoreV1().Services(namespace).Watch(
 		context.TODO(),
 		metav1.ListOptions{
@@ -3,22 +3,38 @@ package service
 import (
 	"context"
 
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 )
 
 type StatefulSetService struct {
+	writeOptions
 	client kubernetes.Interface
+	cache  *k8s.ResourceCache // nil 时直接读取 API Server
 }
 
-func NewStatefulSetService(client kubernetes.Interface) *StatefulSetService {
-	return &StatefulSetService{client: client}
+func NewStatefulSetService(client kubernetes.Interface, cache *k8s.ResourceCache) *StatefulSetService {
+	return &StatefulSetService{client: client, cache: cache}
 }
 
 // 获取单个StatefulSet
 func (s *StatefulSetService) Get(namespace, name string) (*appsv1.StatefulSet, error) {
+	if s.cache != nil {
+		if lister, err := s.cache.StatefulSets(); err == nil {
+			if obj, err := lister.StatefulSets(namespace).Get(name); err == nil {
+				return obj.DeepCopy(), nil
+			}
+		}
+	}
 	return s.client.AppsV1().StatefulSets(namespace).Get(
 		context.TODO(),
 		name,
@@ -36,7 +52,7 @@ func (s *StatefulSetService) Create(namespace string, statefulSet *appsv1.Statef
 	return s.client.AppsV1().StatefulSets(namespace).Create(
 		context.TODO(),
 		statefulSet,
-		metav1.CreateOptions{},
+		s.createOptions(),
 	)
 }
 
@@ -45,32 +61,55 @@ func (s *StatefulSetService) Update(namespace string, statefulSet *appsv1.Statef
 	return s.client.AppsV1().StatefulSets(namespace).Update(
 		context.TODO(),
 		statefulSet,
-		metav1.UpdateOptions{},
+		s.updateOptions(),
 	)
 }
 
+// Patch 按 patchType 修改StatefulSet
+func (s *StatefulSetService) Patch(namespace, name string, patchType types.PatchType, data []byte) (*appsv1.StatefulSet, error) {
+	return s.client.AppsV1().StatefulSets(namespace).Patch(context.TODO(), name, patchType, data, s.patchOptions(patchType))
+}
+
 // 删除StatefulSet
 func (s *StatefulSetService) Delete(namespace, name string) error {
 	return s.client.AppsV1().StatefulSets(namespace).Delete(
 		context.TODO(),
 		name,
-		metav1.DeleteOptions{},
+		s.deleteOptions(),
 	)
 }
 
-// 列表查询（支持分页和标签过滤）
-func (s *StatefulSetService) List(namespace, selector string, limit int64) (*appsv1.StatefulSetList, error) {
-	return s.client.AppsV1().StatefulSets(namespace).List(
-		context.TODO(),
-		metav1.ListOptions{
-			LabelSelector: selector,
-			Limit:         limit,
-		},
-	)
+// List 按统一查询参数 (分页、选择器、排序、名称搜索) 列出 StatefulSet
+func (s *StatefulSetService) List(namespace string, query models.ListQuery) (*appsv1.StatefulSetList, models.ListMeta, error) {
+	fromCache := cacheList(s.cache, k8s.CachedStatefulSets, func(selector labels.Selector) ([]*appsv1.StatefulSet, error) {
+		lister, err := s.cache.StatefulSets()
+		if err != nil {
+			return nil, err
+		}
+		return lister.StatefulSets(namespace).List(selector)
+	})
+	items, meta, err := listPage(query, fromCache, func(opts metav1.ListOptions) ([]appsv1.StatefulSet, metav1.ListMeta, error) {
+		list, err := s.client.AppsV1().StatefulSets(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
+	if err != nil {
+		return nil, models.ListMeta{}, err
+	}
+	return &appsv1.StatefulSetList{ListMeta: k8sListMeta(meta), Items: items}, meta, nil
 }
 
 // Watch机制实现
 func (s *StatefulSetService) Watch(namespace, selector string) (watch.Interface, error) {
+	if s.cache != nil {
+		w, err := s.cache.Watch(k8s.CachedStatefulSets, namespace, selector)
+		if err == nil {
+			return w, nil
+		}
+		cacheUnavailable(k8s.CachedStatefulSets, err)
+	}
 	return s.client.AppsV1().StatefulSets(namespace).Watch(
 		context.TODO(),
 		metav1.ListOptions{
@@ -80,3 +119,30 @@ func (s *StatefulSetService) Watch(namespace, selector string) (watch.Interface,
 		},
 	)
 }
+
+// Scale 通过 scale 子资源修改副本数，冲突时重试
+func (s *StatefulSetService) Scale(namespace, name string, replicas int32) (*appsv1.StatefulSet, error) {
+	statefulSets := s.client.AppsV1().StatefulSets(namespace)
+	var updated *autoscalingv1.Scale
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		scale, err := statefulSets.GetScale(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		scale.Spec.Replicas = replicas
+		updated, err = statefulSets.UpdateScale(context.TODO(), name, scale, s.updateOptions())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	live, err := statefulSets.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// dry-run 没有持久化，返回应用了 dry-run 结果的对象而不是集群中的现状
+	if s.IsDryRun() && updated != nil {
+		live.Spec.Replicas = &updated.Spec.Replicas
+	}
+	return live, nil
+}
@@ -14,6 +14,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/ciliverse/cilikube/api/v1/models" // Adjust import path
+	"github.com/ciliverse/cilikube/pkg/k8s"
 
 	// Import for robust mod file parsing (optional but recommended)
 	"golang.org/x/mod/modfile"
@@ -22,10 +23,11 @@ import (
 // Existing SummaryService struct...
 type SummaryService struct {
 	client kubernetes.Interface
+	cache  *k8s.ResourceCache // nil 时直接访问 API Server 计数
 }
 
-func NewSummaryService(client kubernetes.Interface) *SummaryService {
-	return &SummaryService{client: client}
+func NewSummaryService(client kubernetes.Interface, cache *k8s.ResourceCache) *SummaryService {
+	return &SummaryService{client: client, cache: cache}
 }
 
 // Existing GetResourceSummary function ...
@@ -270,6 +272,45 @@ func (s *SummaryService) GetResourceSummary() (*models.ResourceSummary, map[stri
 
 		// ... add other resource fetch funcs from previous example ...
 	}
+
+	// 优先从 informer 缓存计数，缓存不可用时再使用上面的 List 请求 (Secret 不缓存，始终走 API Server)
+	if s.cache != nil {
+		cachedCounters := map[string]struct {
+			resource k8s.CachedResource
+			target   **int
+		}{
+			"nodes":             {k8s.CachedNodes, &summary.Nodes},
+			"namespaces":        {k8s.CachedNamespaces, &summary.Namespaces},
+			"pods":              {k8s.CachedPods, &summary.Pods},
+			"deployments":       {k8s.CachedDeployments, &summary.Deployments},
+			"services":          {k8s.CachedServices, &summary.Services},
+			"persistentVolumes": {k8s.CachedPersistentVolumes, &summary.PersistentVolumes},
+			"pvcs":              {k8s.CachedPersistentVolumeClaims, &summary.Pvcs},
+			"statefulSets":      {k8s.CachedStatefulSets, &summary.StatefulSets},
+			"daemonSets":        {k8s.CachedDaemonSets, &summary.DaemonSets},
+			"configMaps":        {k8s.CachedConfigMaps, &summary.ConfigMaps},
+			"ingresses":         {k8s.CachedIngresses, &summary.Ingresses},
+		}
+		for key, counter := range cachedCounters {
+			fetchFromAPI, ok := fetchFuncs[key]
+			if !ok {
+				continue
+			}
+			resource, target := counter.resource, counter.target
+			fetchFuncs[key] = func() {
+				count, err := s.cache.Count(resource)
+				if err != nil {
+					cacheUnavailable(resource, err)
+					fetchFromAPI()
+					return
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				*target = &count
+			}
+		}
+	}
+
 	wg.Add(len(fetchFuncs))
 	for _, fn := range fetchFuncs {
 		go func(f func()) { defer wg.Done(); f() }(fn)
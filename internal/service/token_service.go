@@ -0,0 +1,203 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("刷新令牌无效或已过期")
+	ErrTokenRevoked        = errors.New("token 已失效")
+)
+
+// issueToken 记录登录时间，开启新的登录会话并签发 access token 与刷新令牌
+func (s *AuthService) issueToken(user *models.User) (*models.LoginResponse, error) {
+	// 更新最后登录时间
+	now := time.Now()
+	user.LastLogin = &now
+	database.DB.Save(user)
+
+	// 顺便清理已过期的刷新令牌
+	database.DB.Where("expires_at < ?", now).Delete(&models.RefreshToken{})
+
+	return s.issueSession(user, uuid.NewString())
+}
+
+// issueSession 在 sessionID 会话中签发一对新的 access token 与刷新令牌
+func (s *AuthService) issueSession(user *models.User, sessionID string) (*models.LoginResponse, error) {
+	token, claims, err := auth.GenerateToken(user, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	record := &models.RefreshToken{
+		UserID:          user.ID,
+		SessionID:       sessionID,
+		TokenHash:       hashToken(refreshToken),
+		AccessTokenID:   claims.ID,
+		AccessExpiresAt: claims.ExpiresAt.Time,
+		ExpiresAt:       time.Now().Add(configs.GlobalConfig.JWT.RefreshExpireDuration),
+	}
+	if err := database.DB.Create(record).Error; err != nil {
+		return nil, fmt.Errorf("保存刷新令牌失败: %w", err)
+	}
+
+	return &models.LoginResponse{
+		Token:            token,
+		ExpiresAt:        claims.ExpiresAt.Time,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: record.ExpiresAt,
+		User:             user.ToResponse(),
+	}, nil
+}
+
+// Refresh 用刷新令牌换取新的 access token 与刷新令牌，旧刷新令牌随即失效。
+// 已失效的刷新令牌被再次使用说明它可能已泄露，此时吊销整个会话
+func (s *AuthService) Refresh(refreshToken string) (*models.LoginResponse, error) {
+	var record models.RefreshToken
+	if err := database.DB.Where("token_hash = ?", hashToken(refreshToken)).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if record.RevokedAt != nil {
+		log.Printf("用户 %d 的刷新令牌被重复使用，吊销会话 %s", record.UserID, record.SessionID)
+		if err := s.revokeSessions("session_id = ?", record.SessionID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+	if time.Now().After(record.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	var user models.User
+	if err := database.DB.Where("id = ? AND is_active = ?", record.UserID, true).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	// 条件更新保证并发刷新时只有一个请求成功轮换
+	result := database.DB.Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", record.ID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidRefreshToken
+	}
+	return s.issueSession(&user, record.SessionID)
+}
+
+// Logout 吊销当前 access token 及其所在会话的刷新令牌
+func (s *AuthService) Logout(claims *auth.JWTClaims) error {
+	if err := s.revokeAccessToken(claims.ID, claims.ExpiresAt.Time); err != nil {
+		return err
+	}
+	if claims.SessionID == "" {
+		return nil
+	}
+	return s.revokeSessions("user_id = ? AND session_id = ?", claims.UserID, claims.SessionID)
+}
+
+// LogoutAll 使用户在所有设备上的登录失效
+func (s *AuthService) LogoutAll(userID uint) error {
+	return s.revokeUserTokens(userID)
+}
+
+// ValidateToken 供 JWTAuthMiddleware 使用：拒绝已加入黑名单、已被整体吊销或用户已禁用、删除的 token
+func (s *AuthService) ValidateToken(claims *auth.JWTClaims) error {
+	var count int64
+	if err := database.DB.Model(&models.RevokedToken{}).Where("jti = ?", claims.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrTokenRevoked
+	}
+
+	var user models.User
+	err := database.DB.Select("id", "is_active", "token_version").First(&user, claims.UserID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTokenRevoked
+		}
+		return err
+	}
+	if !user.IsActive || user.TokenVersion != claims.TokenVersion {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// revokeUserTokens 递增用户的 token 版本 (此前签发的 access token 全部失效) 并吊销其所有刷新令牌
+func (s *AuthService) revokeUserTokens(userID uint) error {
+	err := database.DB.Model(&models.User{}).Where("id = ?", userID).
+		Update("token_version", gorm.Expr("token_version + ?", 1)).Error
+	if err != nil {
+		return fmt.Errorf("吊销用户 %d 的 token 失败: %w", userID, err)
+	}
+	return database.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// revokeSessions 吊销匹配条件的会话：刷新令牌失效，仍未过期的 access token 加入黑名单
+func (s *AuthService) revokeSessions(query string, args ...interface{}) error {
+	var records []models.RefreshToken
+	if err := database.DB.Where(query, args...).Find(&records).Error; err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, record := range records {
+		if record.AccessTokenID != "" && record.AccessExpiresAt.After(now) {
+			if err := s.revokeAccessToken(record.AccessTokenID, record.AccessExpiresAt); err != nil {
+				return err
+			}
+		}
+	}
+	return database.DB.Model(&models.RefreshToken{}).
+		Where(query, args...).Where("revoked_at IS NULL").
+		Update("revoked_at", now).Error
+}
+
+// revokeAccessToken 将 jti 加入黑名单，并清理已过期的黑名单记录
+func (s *AuthService) revokeAccessToken(jti string, expiresAt time.Time) error {
+	if jti == "" {
+		return nil
+	}
+	now := time.Now()
+	database.DB.Where("expires_at < ?", now).Delete(&models.RevokedToken{})
+	var count int64
+	database.DB.Model(&models.RevokedToken{}).Where("jti = ?", jti).Count(&count)
+	if count > 0 {
+		return nil
+	}
+	if err := database.DB.Create(&models.RevokedToken{JTI: jti, ExpiresAt: expiresAt}).Error; err != nil {
+		return fmt.Errorf("吊销 token 失败: %w", err)
+	}
+	return nil
+}
+
+// hashToken 刷新令牌是高熵随机串，使用 SHA-256 即可安全存储
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,366 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/database"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidTwoFactorCode = errors.New("验证码错误")
+	ErrInvalidChallenge     = errors.New("登录挑战无效或已过期，请重新登录")
+	ErrTwoFactorLocalOnly   = errors.New("只有本地账号可以启用两步验证")
+	ErrTwoFactorEnabled     = errors.New("已启用两步验证")
+	ErrTwoFactorNotEnabled  = errors.New("未启用两步验证")
+	ErrTwoFactorMandatory   = errors.New("当前角色要求启用两步验证，不能关闭")
+)
+
+const (
+	// challengeTTL 密码校验通过后完成第二步验证的时限
+	challengeTTL      = 5 * time.Minute
+	recoveryCodeCount = 10
+)
+
+// twoFactorChallenge 本地账号已启用两步验证，或其角色要求两步验证时，登录先返回挑战而不是 token
+func (s *AuthService) twoFactorChallenge(user *models.User) (*models.LoginResponse, error) {
+	if !user.IsLocal() {
+		return nil, nil
+	}
+	required, err := s.twoFactorRequired(user)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled && !required {
+		return nil, nil
+	}
+	token, expiresAt, err := auth.GenerateChallengeToken(user.ID, user.TokenVersion, challengeTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LoginResponse{
+		User: user.ToResponse(),
+		TwoFactor: &models.TwoFactorChallenge{
+			ChallengeToken:     token,
+			ExpiresAt:          expiresAt,
+			EnrollmentRequired: !user.TOTPEnabled,
+		},
+	}, nil
+}
+
+// CompleteTwoFactorLogin 登录第二步：校验验证码或恢复码后签发 token。
+// 角色要求两步验证而用户尚未启用时，验证码用于确认 EnrollWithChallenge 生成的密钥，并返回恢复码
+func (s *AuthService) CompleteTwoFactorLogin(req *models.TwoFactorLoginRequest, client models.LoginClient) (*models.LoginResponse, error) {
+	user, err := s.challengeUser(req.ChallengeToken)
+	if err != nil {
+		if errors.Is(err, ErrInvalidChallenge) {
+			s.loginFailed(client, models.LoginMethodTwoFactor, "", 0, loginReasonInvalidChallenge)
+		}
+		return nil, err
+	}
+	if err := s.checkLockout(client, models.LoginMethodTwoFactor, user.Username); err != nil {
+		return nil, err
+	}
+
+	var recoveryCodes []string
+	if user.TOTPEnabled {
+		err = s.verifySecondFactor(user, req.Code, req.RecoveryCode)
+	} else {
+		recoveryCodes, err = s.confirmEnrollment(user, req.Code)
+	}
+	if err != nil {
+		// 验证码同样计入失败次数，防止持有密码的攻击者穷举验证码
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			s.loginFailed(client, models.LoginMethodTwoFactor, user.Username, user.ID, loginReasonInvalidTwoFactor)
+		}
+		return nil, err
+	}
+	s.loginSucceeded(client, models.LoginMethodTwoFactor, user)
+
+	response, err := s.issueToken(user)
+	if err != nil {
+		return nil, err
+	}
+	response.RecoveryCodes = recoveryCodes
+	return response, nil
+}
+
+// EnrollWithChallenge 角色要求两步验证但尚未启用的用户，在登录过程中凭挑战生成密钥
+func (s *AuthService) EnrollWithChallenge(challengeToken string) (*models.TwoFactorEnrollment, error) {
+	user, err := s.challengeUser(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.beginEnrollment(user)
+}
+
+// BeginTwoFactorEnrollment 已登录用户生成新的 TOTP 密钥，调用 ConfirmTwoFactorEnrollment 后生效
+func (s *AuthService) BeginTwoFactorEnrollment(userID uint) (*models.TwoFactorEnrollment, error) {
+	user, err := findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.beginEnrollment(user)
+}
+
+// ConfirmTwoFactorEnrollment 校验认证器应用生成的验证码，启用两步验证并返回恢复码
+func (s *AuthService) ConfirmTwoFactorEnrollment(userID uint, code string) ([]string, error) {
+	user, err := findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.confirmEnrollment(user, code)
+}
+
+// DisableTwoFactor 关闭两步验证，需要密码以及验证码或恢复码
+func (s *AuthService) DisableTwoFactor(userID uint, req *models.DisableTwoFactorRequest) error {
+	user, err := findUser(userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+	if !user.CheckPassword(req.Password) {
+		return errors.New("密码错误")
+	}
+	required, err := s.twoFactorRequired(user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrTwoFactorMandatory
+	}
+	if err := s.verifySecondFactor(user, req.Code, req.RecoveryCode); err != nil {
+		return err
+	}
+	return s.ResetTwoFactor(userID)
+}
+
+// RegenerateRecoveryCodes 作废旧的恢复码并生成一组新的
+func (s *AuthService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	user, err := findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	if err := s.verifyTOTP(user, code); err != nil {
+		return nil, err
+	}
+	return s.generateRecoveryCodes(user.ID)
+}
+
+// ResetTwoFactor 清除用户的两步验证 (管理员为丢失设备的用户重置时也使用)
+func (s *AuthService) ResetTwoFactor(userID uint) error {
+	err := database.DB.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"totp_secret":    "",
+		"totp_enabled":   false,
+		"totp_last_step": 0,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return database.DB.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+}
+
+// GetTwoFactorPolicy 返回必须启用两步验证的角色
+func (s *AuthService) GetTwoFactorPolicy() (*models.TwoFactorPolicy, error) {
+	var rows []models.TwoFactorRequiredRole
+	if err := database.DB.Order("role").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	policy := &models.TwoFactorPolicy{RequiredRoles: make([]string, 0, len(rows))}
+	for _, row := range rows {
+		policy.RequiredRoles = append(policy.RequiredRoles, row.Role)
+	}
+	return policy, nil
+}
+
+// SetTwoFactorPolicy 替换必须启用两步验证的角色列表，对之后的登录生效
+func (s *AuthService) SetTwoFactorPolicy(policy *models.TwoFactorPolicy) (*models.TwoFactorPolicy, error) {
+	roles := map[string]bool{}
+	for _, role := range policy.RequiredRoles {
+		if role = strings.TrimSpace(role); role != "" {
+			roles[role] = true
+		}
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.TwoFactorRequiredRole{}).Error; err != nil {
+			return err
+		}
+		for role := range roles {
+			if err := tx.Create(&models.TwoFactorRequiredRole{Role: role}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetTwoFactorPolicy()
+}
+
+// twoFactorRequired 用户的内置角色或任一 Casbin 角色在策略中时返回 true
+func (s *AuthService) twoFactorRequired(user *models.User) (bool, error) {
+	roles := []string{user.Role}
+	if s.enforcer != nil {
+		bindings, err := s.enforcer.GetFilteredGroupingPolicy(0, user.Username)
+		if err != nil {
+			return false, err
+		}
+		for _, binding := range bindings {
+			if role, ok := auth.RoleName(binding[1]); ok {
+				roles = append(roles, role)
+			}
+		}
+	}
+	var count int64
+	err := database.DB.Model(&models.TwoFactorRequiredRole{}).Where("role IN ?", roles).Count(&count).Error
+	return count > 0, err
+}
+
+func (s *AuthService) challengeUser(challengeToken string) (*models.User, error) {
+	claims, err := auth.ParseChallengeToken(challengeToken)
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+	var user models.User
+	if err := database.DB.Where("id = ? AND is_active = ?", claims.UserID, true).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidChallenge
+		}
+		return nil, err
+	}
+	// 挑战签发后修改过密码或被吊销过 token
+	if user.TokenVersion != claims.TokenVersion {
+		return nil, ErrInvalidChallenge
+	}
+	return &user, nil
+}
+
+func (s *AuthService) beginEnrollment(user *models.User) (*models.TwoFactorEnrollment, error) {
+	if !user.IsLocal() {
+		return nil, ErrTwoFactorLocalOnly
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorEnabled
+	}
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := database.DB.Model(user).Update("totp_secret", secret).Error; err != nil {
+		return nil, err
+	}
+	return &models.TwoFactorEnrollment{
+		Secret: secret,
+		URI:    auth.TOTPURI(configs.GlobalConfig.JWT.Issuer, user.Username, secret),
+	}, nil
+}
+
+func (s *AuthService) confirmEnrollment(user *models.User, code string) ([]string, error) {
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, errors.New("请先生成两步验证密钥")
+	}
+	if err := s.verifyTOTP(user, code); err != nil {
+		return nil, err
+	}
+	if err := database.DB.Model(user).Update("totp_enabled", true).Error; err != nil {
+		return nil, err
+	}
+	return s.generateRecoveryCodes(user.ID)
+}
+
+// verifySecondFactor 校验验证码，未提供验证码时校验并消耗恢复码
+func (s *AuthService) verifySecondFactor(user *models.User, code, recoveryCode string) error {
+	if code != "" {
+		return s.verifyTOTP(user, code)
+	}
+	if recoveryCode == "" {
+		return ErrInvalidTwoFactorCode
+	}
+	result := database.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashToken(normalizeRecoveryCode(recoveryCode))).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// verifyTOTP 校验验证码，同一时间窗口及更早的验证码不能再次使用
+func (s *AuthService) verifyTOTP(user *models.User, code string) error {
+	step, ok := auth.ValidateTOTP(user.TOTPSecret, strings.TrimSpace(code), time.Now())
+	if !ok || step <= user.TOTPLastStep {
+		return ErrInvalidTwoFactorCode
+	}
+	result := database.DB.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", user.ID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+	user.TOTPLastStep = step
+	return nil
+}
+
+func (s *AuthService) generateRecoveryCodes(userID uint) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	records := make([]models.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(base32.StdEncoding.EncodeToString(buf))
+		codes[i] = raw[:4] + "-" + raw[4:]
+		records[i] = models.RecoveryCode{UserID: userID, CodeHash: hashToken(raw)}
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&records).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("保存恢复码失败: %w", err)
+	}
+	return codes, nil
+}
+
+// normalizeRecoveryCode 忽略大小写、空白与连字符
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
+
+func findUser(userID uint) (*models.User, error) {
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("用户不存在")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// RestartedAtAnnotation kubectl rollout restart 写在 Pod 模板上的注解，值变化即触发滚动更新
+const RestartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// 重新建立 rollout watch 前的等待时间：从 rewatchMinDelay 开始连续翻倍，最长 rewatchMaxDelay；
+// 上一个 watch 持续超过 rewatchMaxDelay (正常的超时关闭) 时重新从 rewatchMinDelay 开始
+var (
+	rewatchMinDelay = 200 * time.Millisecond
+	rewatchMaxDelay = 5 * time.Second
+)
+
+// restartPatch 生成设置 restartedAt 注解的 strategic merge patch
+func restartPatch() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{RestartedAtAnnotation: time.Now().Format(time.RFC3339)},
+				},
+			},
+		},
+	})
+}
+
+// Restart 滚动重启 Deployment，已暂停的 Deployment 不会发布新版本，因此直接拒绝
+func (s *DeploymentService) Restart(namespace, name string) (*appsv1.Deployment, error) {
+	deployment, err := s.getLive(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if deployment.Spec.Paused {
+		return nil, ErrDeploymentPaused
+	}
+	patch, err := restartPatch()
+	if err != nil {
+		return nil, err
+	}
+	return s.Patch(namespace, name, types.StrategicMergePatchType, patch)
+}
+
+// Restart 滚动重启 StatefulSet
+func (s *StatefulSetService) Restart(namespace, name string) (*appsv1.StatefulSet, error) {
+	patch, err := restartPatch()
+	if err != nil {
+		return nil, err
+	}
+	return s.Patch(namespace, name, types.StrategicMergePatchType, patch)
+}
+
+// Restart 滚动重启 DaemonSet
+func (s *DaemonSetService) Restart(namespace, name string) (*appsv1.DaemonSet, error) {
+	patch, err := restartPatch()
+	if err != nil {
+		return nil, err
+	}
+	return s.Patch(namespace, name, types.StrategicMergePatchType, patch)
+}
+
+// FollowRollout 跟踪 Deployment 的 rollout，每次状态变化调用 send，直到完成、失败或 ctx 结束
+func (s *DeploymentService) FollowRollout(ctx context.Context, namespace, name string, send func(models.RolloutStatus)) error {
+	deployments := s.client.AppsV1().Deployments(namespace)
+	return followRollout(ctx, name,
+		func() (runtime.Object, error) { return deployments.Get(ctx, name, metav1.GetOptions{}) },
+		func(opts metav1.ListOptions) (watch.Interface, error) { return deployments.Watch(ctx, opts) },
+		func(obj runtime.Object) (models.RolloutStatus, bool) {
+			deployment, ok := obj.(*appsv1.Deployment)
+			if !ok {
+				return models.RolloutStatus{}, false
+			}
+			return DeploymentRolloutStatus(deployment), true
+		},
+		send)
+}
+
+// FollowRollout 跟踪 StatefulSet 的 rollout
+func (s *StatefulSetService) FollowRollout(ctx context.Context, namespace, name string, send func(models.RolloutStatus)) error {
+	statefulSets := s.client.AppsV1().StatefulSets(namespace)
+	return followRollout(ctx, name,
+		func() (runtime.Object, error) { return statefulSets.Get(ctx, name, metav1.GetOptions{}) },
+		func(opts metav1.ListOptions) (watch.Interface, error) { return statefulSets.Watch(ctx, opts) },
+		func(obj runtime.Object) (models.RolloutStatus, bool) {
+			statefulSet, ok := obj.(*appsv1.StatefulSet)
+			if !ok {
+				return models.RolloutStatus{}, false
+			}
+			return StatefulSetRolloutStatus(statefulSet), true
+		},
+		send)
+}
+
+// FollowRollout 跟踪 DaemonSet 的 rollout
+func (s *DaemonSetService) FollowRollout(ctx context.Context, namespace, name string, send func(models.RolloutStatus)) error {
+	daemonSets := s.client.AppsV1().DaemonSets(namespace)
+	return followRollout(ctx, name,
+		func() (runtime.Object, error) { return daemonSets.Get(ctx, name, metav1.GetOptions{}) },
+		func(opts metav1.ListOptions) (watch.Interface, error) { return daemonSets.Watch(ctx, opts) },
+		func(obj runtime.Object) (models.RolloutStatus, bool) {
+			daemonSet, ok := obj.(*appsv1.DaemonSet)
+			if !ok {
+				return models.RolloutStatus{}, false
+			}
+			return DaemonSetRolloutStatus(daemonSet), true
+		},
+		send)
+}
+
+// followRollout 先读取一次当前状态，再从该 resourceVersion 开始 watch 单个对象。
+// API Server 关闭 watch (超时或 resourceVersion 过期) 时按退避间隔重新读取并继续；
+// 其他 watch 错误直接返回，对象被删除视为失败。首次读取失败时直接返回错误，调用方据此区分普通错误响应和流内事件
+func followRollout(
+	ctx context.Context,
+	name string,
+	get func() (runtime.Object, error),
+	watchFn func(metav1.ListOptions) (watch.Interface, error),
+	evaluate func(runtime.Object) (models.RolloutStatus, bool),
+	send func(models.RolloutStatus),
+) error {
+	var last *models.RolloutStatus
+	emit := func(status models.RolloutStatus) bool {
+		if last == nil || *last != status {
+			send(status)
+			last = &status
+		}
+		return status.Done || status.Failed
+	}
+
+	delay := rewatchMinDelay
+	for {
+		obj, err := get()
+		if err != nil {
+			return err
+		}
+		status, _ := evaluate(obj)
+		if emit(status) {
+			return nil
+		}
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+
+		w, err := watchFn(metav1.ListOptions{
+			FieldSelector:   fields.OneTermEqualSelector("metadata.name", name).String(),
+			ResourceVersion: accessor.GetResourceVersion(),
+		})
+		if err != nil {
+			return err
+		}
+		started := time.Now()
+		done, err := followEvents(ctx, w, name, evaluate, emit)
+		w.Stop()
+		if done || err != nil {
+			return err
+		}
+
+		if time.Since(started) >= rewatchMaxDelay {
+			delay = rewatchMinDelay
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, rewatchMaxDelay)
+	}
+}
+
+// followEvents 处理一个 watch 的事件；返回 done=false 且无错误表示 watch 已关闭或 resourceVersion 已过期，需要重新建立
+func followEvents(ctx context.Context, w watch.Interface, name string, evaluate func(runtime.Object) (models.RolloutStatus, bool), emit func(models.RolloutStatus) bool) (bool, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return false, nil
+			}
+			switch event.Type {
+			case watch.Error:
+				err := apierrors.FromObject(event.Object)
+				if apierrors.IsGone(err) || apierrors.IsResourceExpired(err) {
+					return false, nil
+				}
+				return true, err
+			case watch.Deleted:
+				if accessor, err := meta.Accessor(event.Object); err == nil && accessor.GetName() == name {
+					emit(models.RolloutStatus{Message: fmt.Sprintf("%q 已被删除", name), Failed: true})
+					return true, nil
+				}
+			default:
+				// fake 客户端等实现可能忽略字段选择器，这里再按名称过滤一次
+				if accessor, err := meta.Accessor(event.Object); err != nil || accessor.GetName() != name {
+					continue
+				}
+				if status, ok := evaluate(event.Object); ok && emit(status) {
+					return true, nil
+				}
+			}
+		}
+	}
+}
+
+// DeploymentRolloutStatus 按 kubectl rollout status 的规则评估 Deployment
+func DeploymentRolloutStatus(deployment *appsv1.Deployment) models.RolloutStatus {
+	status := models.RolloutStatus{
+		Generation:         deployment.Generation,
+		ObservedGeneration: deployment.Status.ObservedGeneration,
+		Replicas:           replicasOrDefault(deployment.Spec.Replicas),
+		UpdatedReplicas:    deployment.Status.UpdatedReplicas,
+		ReadyReplicas:      deployment.Status.ReadyReplicas,
+		AvailableReplicas:  deployment.Status.AvailableReplicas,
+	}
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		status.Message = "Waiting for deployment spec update to be observed..."
+		return status
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			status.Message = fmt.Sprintf("deployment %q exceeded its progress deadline", deployment.Name)
+			status.Failed = true
+			return status
+		}
+	}
+	switch {
+	case deployment.Status.UpdatedReplicas < status.Replicas:
+		status.Message = fmt.Sprintf("Waiting for deployment %q rollout to finish: %d out of %d new replicas have been updated...",
+			deployment.Name, deployment.Status.UpdatedReplicas, status.Replicas)
+	case deployment.Status.Replicas > deployment.Status.UpdatedReplicas:
+		status.Message = fmt.Sprintf("Waiting for deployment %q rollout to finish: %d old replicas are pending termination...",
+			deployment.Name, deployment.Status.Replicas-deployment.Status.UpdatedReplicas)
+	case deployment.Status.AvailableReplicas < deployment.Status.UpdatedReplicas:
+		status.Message = fmt.Sprintf("Waiting for deployment %q rollout to finish: %d of %d updated replicas are available...",
+			deployment.Name, deployment.Status.AvailableReplicas, deployment.Status.UpdatedReplicas)
+	default:
+		status.Message = fmt.Sprintf("deployment %q successfully rolled out", deployment.Name)
+		status.Done = true
+	}
+	return status
+}
+
+// StatefulSetRolloutStatus 按 kubectl rollout status 的规则评估 StatefulSet，只支持 RollingUpdate 策略
+func StatefulSetRolloutStatus(statefulSet *appsv1.StatefulSet) models.RolloutStatus {
+	replicas := replicasOrDefault(statefulSet.Spec.Replicas)
+	status := models.RolloutStatus{
+		Generation:         statefulSet.Generation,
+		ObservedGeneration: statefulSet.Status.ObservedGeneration,
+		Replicas:           replicas,
+		UpdatedReplicas:    statefulSet.Status.UpdatedReplicas,
+		ReadyReplicas:      statefulSet.Status.ReadyReplicas,
+		AvailableReplicas:  statefulSet.Status.AvailableReplicas,
+	}
+	strategy := statefulSet.Spec.UpdateStrategy
+	if strategy.Type != "" && strategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		status.Message = "rollout status is only available for RollingUpdate strategy type"
+		status.Failed = true
+		return status
+	}
+	if statefulSet.Status.ObservedGeneration == 0 || statefulSet.Generation > statefulSet.Status.ObservedGeneration {
+		status.Message = "Waiting for statefulset spec update to be observed..."
+		return status
+	}
+	if statefulSet.Status.ReadyReplicas < replicas {
+		status.Message = fmt.Sprintf("Waiting for %d pods to be ready...", replicas-statefulSet.Status.ReadyReplicas)
+		return status
+	}
+	if strategy.RollingUpdate != nil && strategy.RollingUpdate.Partition != nil {
+		if updating := replicas - *strategy.RollingUpdate.Partition; statefulSet.Status.UpdatedReplicas < updating {
+			status.Message = fmt.Sprintf("Waiting for partitioned roll out to finish: %d out of %d new pods have been updated...",
+				statefulSet.Status.UpdatedReplicas, updating)
+			return status
+		}
+		status.Message = fmt.Sprintf("partitioned roll out complete: %d new pods have been updated...", statefulSet.Status.UpdatedReplicas)
+		status.Done = true
+		return status
+	}
+	if statefulSet.Status.UpdateRevision != statefulSet.Status.CurrentRevision {
+		status.Message = fmt.Sprintf("waiting for statefulset rolling update to complete %d pods at revision %s...",
+			statefulSet.Status.UpdatedReplicas, statefulSet.Status.UpdateRevision)
+		return status
+	}
+	status.Message = fmt.Sprintf("statefulset rolling update complete %d pods at revision %s...",
+		statefulSet.Status.CurrentReplicas, statefulSet.Status.CurrentRevision)
+	status.Done = true
+	return status
+}
+
+// DaemonSetRolloutStatus 按 kubectl rollout status 的规则评估 DaemonSet，只支持 RollingUpdate 策略
+func DaemonSetRolloutStatus(daemonSet *appsv1.DaemonSet) models.RolloutStatus {
+	status := models.RolloutStatus{
+		Generation:         daemonSet.Generation,
+		ObservedGeneration: daemonSet.Status.ObservedGeneration,
+		Replicas:           daemonSet.Status.DesiredNumberScheduled,
+		UpdatedReplicas:    daemonSet.Status.UpdatedNumberScheduled,
+		ReadyReplicas:      daemonSet.Status.NumberReady,
+		AvailableReplicas:  daemonSet.Status.NumberAvailable,
+	}
+	if strategy := daemonSet.Spec.UpdateStrategy.Type; strategy != "" && strategy != appsv1.RollingUpdateDaemonSetStrategyType {
+		status.Message = "rollout status is only available for RollingUpdate strategy type"
+		status.Failed = true
+		return status
+	}
+	if daemonSet.Generation > daemonSet.Status.ObservedGeneration {
+		status.Message = "Waiting for daemon set spec update to be observed..."
+		return status
+	}
+	switch {
+	case daemonSet.Status.UpdatedNumberScheduled < daemonSet.Status.DesiredNumberScheduled:
+		status.Message = fmt.Sprintf("Waiting for daemon set %q rollout to finish: %d out of %d new pods have been updated...",
+			daemonSet.Name, daemonSet.Status.UpdatedNumberScheduled, daemonSet.Status.DesiredNumberScheduled)
+	case daemonSet.Status.NumberAvailable < daemonSet.Status.DesiredNumberScheduled:
+		status.Message = fmt.Sprintf("Waiting for daemon set %q rollout to finish: %d of %d updated pods are available...",
+			daemonSet.Name, daemonSet.Status.NumberAvailable, daemonSet.Status.DesiredNumberScheduled)
+	default:
+		status.Message = fmt.Sprintf("daemon set %q successfully rolled out", daemonSet.Name)
+		status.Done = true
+	}
+	return status
+}
+
+// replicasOrDefault spec.replicas 未设置时 API Server 默认为 1
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// writeOptions 写操作的公共选项，嵌入到各 service 中，由 handler 按请求设置
+type writeOptions struct {
+	dryRun []string
+	force  bool
+}
+
+// SetDryRun 设置 dry-run (目前 API Server 只支持 "All")；非空时只做校验和准入，不会持久化
+func (o *writeOptions) SetDryRun(dryRun []string) {
+	o.dryRun = dryRun
+}
+
+// SetForce 设置 server-side apply 时是否强制获取冲突字段的所有权，只对 apply patch 生效
+func (o *writeOptions) SetForce(force bool) {
+	o.force = force
+}
+
+// IsDryRun 当前 service 的写操作是否为 dry-run
+func (o *writeOptions) IsDryRun() bool {
+	return len(o.dryRun) > 0
+}
+
+func (o *writeOptions) createOptions() metav1.CreateOptions {
+	return metav1.CreateOptions{DryRun: o.dryRun, FieldManager: FieldManager}
+}
+
+func (o *writeOptions) updateOptions() metav1.UpdateOptions {
+	return metav1.UpdateOptions{DryRun: o.dryRun, FieldManager: FieldManager}
+}
+
+// patchOptions API Server 只允许 apply patch 携带 force
+func (o *writeOptions) patchOptions(patchType types.PatchType) metav1.PatchOptions {
+	opts := metav1.PatchOptions{DryRun: o.dryRun, FieldManager: FieldManager}
+	if patchType == types.ApplyPatchType && o.force {
+		opts.Force = &o.force
+	}
+	return opts
+}
+
+func (o *writeOptions) deleteOptions() metav1.DeleteOptions {
+	return metav1.DeleteOptions{DryRun: o.dryRun}
+}
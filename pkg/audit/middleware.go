@@ -0,0 +1,133 @@
+// Package audit 记录经由 CiliKube 对集群执行的操作
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ciliverse/cilikube/api/v1/models"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// Recorder 保存审计记录。Record 在请求处理完成后同步调用，不应返回错误而影响响应
+type Recorder interface {
+	Record(event *models.AuditEvent)
+}
+
+// MaxBodySize 被审计请求的请求体上限，不小于任何 handler 自身的上限 (如 apply 清单的 10MiB)
+const MaxBodySize = 10 << 20
+
+// sensitiveReads 虽然是读操作但同样需要审计的子资源
+var sensitiveReads = map[string]bool{
+	"pods/exec": true,
+	"pods/logs": true,
+	"jobs/logs": true,
+}
+
+// Middleware 审计所有非只读请求以及 sensitiveReads 中的访问。
+// 应放在认证和 ClusterMiddleware 之后、授权之前，这样被拒绝的操作也会留下记录
+func Middleware(recorder Recorder, clusterResolver func(*gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		attrs := auth.ResolveRequestAttributes(c, clusterResolver(c))
+		if !shouldAudit(c.Request.Method, attrs) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		var body *hashingBody
+		if c.Request.ContentLength > MaxBodySize {
+			// 声明的长度已超限，不读取请求体直接拒绝
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"code": http.StatusRequestEntityTooLarge, "message": "请求体过大"})
+		} else {
+			body = wrapBody(c)
+			c.Next()
+		}
+
+		event := &models.AuditEvent{
+			Cluster:   attrs.Cluster,
+			Namespace: attrs.Namespace,
+			Resource:  attrs.Resource,
+			Name:      c.Param("name"),
+			Verb:      attrs.Verb,
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			BodyHash:  body.sum(),
+			Status:    c.Writer.Status(),
+			LatencyMs: time.Since(start).Milliseconds(),
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}
+		event.UserID, event.Username, _, _ = auth.GetCurrentUser(c)
+		if claims, ok := auth.GetClaims(c); ok {
+			event.APITokenID = claims.APITokenID
+		}
+		recorder.Record(event)
+	}
+}
+
+func shouldAudit(method string, attrs auth.RequestAttributes) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return sensitiveReads[attrs.Resource]
+	}
+	return true
+}
+
+// hashingBody 在 handler 读取请求体的同时计算 SHA-256，不在内存中保留请求体；
+// 超过 MaxBodySize 时读取返回 *http.MaxBytesError
+type hashingBody struct {
+	body io.ReadCloser
+	hash hash.Hash
+	read int64
+	eof  bool
+	err  error
+}
+
+// wrapBody 替换请求体，没有请求体时返回 nil
+func wrapBody(c *gin.Context) *hashingBody {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return nil
+	}
+	body := &hashingBody{body: http.MaxBytesReader(c.Writer, c.Request.Body, MaxBodySize), hash: sha256.New()}
+	c.Request.Body = body
+	return body
+}
+
+func (b *hashingBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.hash.Write(p[:n])
+	b.read += int64(n)
+	switch {
+	case errors.Is(err, io.EOF):
+		b.eof = true
+	case err != nil && b.err == nil:
+		b.err = err
+	}
+	return n, err
+}
+
+func (b *hashingBody) Close() error {
+	return b.body.Close()
+}
+
+// sum 返回请求体的 SHA-256；handler 没有读完时继续读取剩余部分 (同样受 MaxBodySize 限制)，
+// 没有请求体或请求体超限、读取失败时返回空字符串
+func (b *hashingBody) sum() string {
+	if b == nil {
+		return ""
+	}
+	if !b.eof && b.err == nil {
+		_, _ = io.Copy(io.Discard, b)
+	}
+	if b.err != nil || b.read == 0 {
+		return ""
+	}
+	return hex.EncodeToString(b.hash.Sum(nil))
+}
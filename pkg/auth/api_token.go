@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/casbin/casbin/v2/util"
+)
+
+// APITokenPrefix API token 的固定前缀，JWTAuthMiddleware 据此区分 API token 与 JWT
+const APITokenPrefix = "ckp_"
+
+// APITokenResolver 校验 API token 并返回其所属用户的声明 (APITokenID 与 Scopes 已填写)
+type APITokenResolver func(token string) (*JWTClaims, error)
+
+// ParseScope 解析 "<resource>:<verb>" 形式的 scope，两部分都可以是 "*"
+func ParseScope(scope string) (resource, verb string, err error) {
+	resource, verb, ok := strings.Cut(strings.TrimSpace(scope), ":")
+	if !ok || resource == "" || verb == "" {
+		return "", "", fmt.Errorf("scope %q 格式应为 <resource>:<verb>", scope)
+	}
+	if verb == Wildcard {
+		return resource, verb, nil
+	}
+	for _, v := range Verbs {
+		if v == verb {
+			return resource, verb, nil
+		}
+	}
+	return "", "", fmt.Errorf("scope %q 中的动词 %q 无效", scope, verb)
+}
+
+// ScopesAllow 任一 scope 覆盖请求的资源和动作时返回 true，资源与策略一样支持后缀通配
+func ScopesAllow(scopes []string, attrs RequestAttributes) bool {
+	for _, scope := range scopes {
+		resource, verb, err := ParseScope(scope)
+		if err != nil {
+			continue
+		}
+		if (verb == Wildcard || verb == attrs.Verb) && util.KeyMatch(attrs.Resource, resource) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 授权使用的动词，与 Kubernetes RBAC 保持一致
+const (
+	VerbGet    = "get"
+	VerbList   = "list"
+	VerbWatch  = "watch"
+	VerbCreate = "create"
+	VerbUpdate = "update"
+	VerbPatch  = "patch"
+	VerbDelete = "delete"
+
+	// Wildcard 策略中匹配任意值；也可作为后缀，例如命名空间 "a-*"
+	Wildcard = "*"
+)
+
+// Verbs 所有可授权的动词
+var Verbs = []string{VerbGet, VerbList, VerbWatch, VerbCreate, VerbUpdate, VerbPatch, VerbDelete}
+
+// apiPrefix 授权属性从该前缀之后的路由模板推导
+const apiPrefix = "/api/v1/"
+
+// resourceAliases 路由中的资源段与策略中资源名不一致时的映射
+var resourceAliases = map[string]string{
+	"namespace": "namespaces",
+	"pvs":       "persistentvolumes",
+	"pvcs":      "persistentvolumeclaims",
+}
+
+// connectSubresources 建立交互式连接的子资源。与 Kubernetes RBAC 一致，
+// 无论请求方法如何都需要 create 权限，只读角色的 get 不能匹配
+var connectSubresources = map[string]bool{
+	"exec":        true,
+	"attach":      true,
+	"portforward": true,
+}
+
+// groupSegments 只用于给路由分组、不表示资源的路径段
+var groupSegments = map[string]bool{
+	"rbac": true,
+}
+
+// RequestAttributes 授权请求：谁 (由调用方提供) 在哪个集群、哪个命名空间，对哪种资源执行什么动作。
+// Namespace 为空表示集群级资源或与命名空间无关的接口；Cluster 为空表示与集群无关的系统接口
+type RequestAttributes struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Resource  string `json:"resource"`
+	Verb      string `json:"verb"`
+}
+
+// ResolveRequestAttributes 根据匹配到的路由模板 (c.FullPath()) 和路径参数推导授权属性，
+// 而不是直接比较 URL，因此 /api/v1/namespaces/a/deployments 与
+// /api/v1/clusters/prod/namespaces/a/deployments 得到相同的资源和动作。
+//
+//   - 资源: 路由中的资源段，子资源以 "/" 连接，例如 deployments/scale、pods/logs；
+//     通用资源接口使用 :resource 参数，CRD 实例使用 CRD 的复数名
+//   - 动作: GET 按是否带名称区分 get/list，watch 路由为 watch；
+//     POST/PUT/PATCH/DELETE 分别为 create/update/patch/delete；
+//     exec、attach、portforward 子资源 (WebSocket 使用 GET) 固定为 create
+//
+// cluster 为 ClusterMiddleware 解析出的集群；为空时使用路径参数 :cluster (集群登记接口)
+func ResolveRequestAttributes(c *gin.Context, cluster string) RequestAttributes {
+	attrs := RequestAttributes{Cluster: cluster, Namespace: c.Param("namespace")}
+	fullPath := strings.TrimPrefix(c.FullPath(), apiPrefix)
+	segments := strings.Split(strings.Trim(fullPath, "/"), "/")
+
+	if attrs.Cluster == "" {
+		attrs.Cluster = c.Param("cluster")
+	} else if len(segments) > 2 && segments[0] == "clusters" && segments[1] == ":cluster" {
+		// 集群前缀下的 Kubernetes 路由，与不带前缀的路由等价
+		segments = segments[2:]
+	}
+
+	var resource, subresources []string
+	named, watch := false, false
+	for i := 0; i < len(segments); i++ {
+		segment := segments[i]
+		switch {
+		case segment == "":
+		case segment == "watch":
+			watch = true
+		case segment == "namespaces" && i+1 < len(segments) && segments[i+1] == ":namespace":
+			i++
+		case segment == "resources" && i+3 < len(segments) && segments[i+1] == ":group":
+			// /resources/:group/:version/:resource
+			resource = []string{strings.ToLower(c.Param("resource"))}
+			i += 3
+		case strings.HasPrefix(segment, ":"):
+			if len(resource) > 0 && !named {
+				named = true
+			}
+		case groupSegments[segment]:
+		default:
+			if named {
+				subresources = append(subresources, strings.ToLower(segment))
+			} else {
+				resource = append(resource, strings.ToLower(segment))
+			}
+		}
+	}
+
+	// /crds/:name/resources 列出的是 CRD 定义的资源实例
+	if len(resource) == 1 && resource[0] == "crds" && len(subresources) == 1 && subresources[0] == "resources" {
+		resource = []string{strings.SplitN(c.Param("name"), ".", 2)[0]}
+		subresources, named = nil, false
+	}
+	if len(resource) > 0 {
+		if alias, ok := resourceAliases[resource[0]]; ok {
+			resource[0] = alias
+		}
+	}
+	attrs.Resource = strings.Join(append(resource, subresources...), "/")
+
+	if len(subresources) > 0 && connectSubresources[subresources[len(subresources)-1]] {
+		attrs.Verb = VerbCreate
+		return attrs
+	}
+
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead:
+		switch {
+		case watch:
+			attrs.Verb = VerbWatch
+		case named:
+			attrs.Verb = VerbGet
+		default:
+			attrs.Verb = VerbList
+		}
+	case http.MethodPost:
+		attrs.Verb = VerbCreate
+	case http.MethodPut:
+		attrs.Verb = VerbUpdate
+	case http.MethodPatch:
+		attrs.Verb = VerbPatch
+	case http.MethodDelete:
+		attrs.Verb = VerbDelete
+	default:
+		attrs.Verb = strings.ToLower(c.Request.Method)
+	}
+	return attrs
+}
@@ -2,19 +2,65 @@
 package auth
 
 import (
+	_ "embed"
 	"fmt"
 	"log"
 	"net/http"
 	"path/filepath" // 引入 path/filepath
+	"strings"
 
 	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/util"
 	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"github.com/ciliverse/cilikube/api/v1/models"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
+// modelConf 编译进二进制，不再依赖进程的工作目录
+//
+//go:embed model.conf
+var modelConf string
+
+// CasbinTableName 策略表。模型从 (角色, 路径, 方法) 改为按集群/命名空间/资源/动作授权后字段数不同，
+// 使用新表，旧表中的规则不再加载；用户的内置角色绑定在启动时由 SyncRoleBindings 重新写入
+const CasbinTableName = "casbin_domain_rule"
+
+// RolePrefix Casbin 中角色 subject 的前缀。用户名与角色名因此不在同一个命名空间，
+// 注册或由外部身份源创建的用户即使与某个角色同名也不会继承该角色的规则
+const RolePrefix = "role:"
+
+// RoleSubject 角色在 Casbin 规则中的 subject
+func RoleSubject(role string) string {
+	return RolePrefix + role
+}
+
+// RoleName 从 Casbin subject 中取出角色名，不是角色时返回 false
+func RoleName(subject string) (string, bool) {
+	return strings.CutPrefix(subject, RolePrefix)
+}
+
+// authorizerKey 上下文中保存当前用户 Authorizer 的键
+const authorizerKey = "authorizer"
+
+// Authorizer 以当前用户的身份检查一组授权属性
+type Authorizer func(attrs RequestAttributes) (bool, error)
+
+// Authorize 检查当前用户能否执行 attrs 描述的操作，用于一个请求中需要逐个授权的对象。
+// 请求未经过 CasbinMiddleware (认证关闭) 时放行，与路由本身的行为一致
+func Authorize(c *gin.Context, attrs RequestAttributes) (bool, error) {
+	value, ok := c.Get(authorizerKey)
+	if !ok {
+		return true, nil
+	}
+	return value.(Authorizer)(attrs)
+}
+
 type CasbinBuilder struct {
 	IgnorePaths []string
+
+	clusterResolver func(*gin.Context) string
 }
 
 func NewCasbinBuilder() *CasbinBuilder {
@@ -27,7 +73,13 @@ func (r *CasbinBuilder) IgnorePath(path string) *CasbinBuilder {
 	return r
 }
 
-// CasbinMiddleware 返回一个 Gin 中间件处理函数
+// ClusterResolver 设置获取当前请求目标集群的函数，Kubernetes 路由上应在 ClusterMiddleware 之后使用
+func (r *CasbinBuilder) ClusterResolver(resolver func(*gin.Context) string) *CasbinBuilder {
+	r.clusterResolver = resolver
+	return r
+}
+
+// CasbinMiddleware 返回一个 Gin 中间件处理函数，按 ResolveRequestAttributes 推导的属性授权
 func (r *CasbinBuilder) CasbinMiddleware(e *casbin.Enforcer) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		reqPath := c.Request.URL.Path
@@ -41,60 +93,72 @@ func (r *CasbinBuilder) CasbinMiddleware(e *casbin.Enforcer) gin.HandlerFunc {
 			}
 		}
 
-		// 从上下文中获取角色 (由 JWT 中间件设置)
-		roleVal, exist := c.Get("role")
-		if !exist {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "无法获取用户角色信息，请先登录"})
+		// 以认证中间件写入的用户名作为 subject，用户所属角色由 g 规则决定
+		_, username, _, ok := GetCurrentUser(c)
+		if !ok || username == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"code": http.StatusUnauthorized, "message": "无法获取用户信息，请先登录"})
 			return
 		}
 
-		role, ok := roleVal.(string)
-		if !ok || role == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "用户角色信息格式不正确"})
+		if claims, ok := GetClaims(c); ok && claims.PasswordChangeRequired {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"code": http.StatusForbidden, "message": "请先修改密码"})
 			return
 		}
 
-		obj := reqPath
-		act := c.Request.Method
-
-		log.Printf("权限验证 - 角色: %v, 路径: %v, 方法: %v", role, obj, act)
+		cluster := ""
+		if r.clusterResolver != nil {
+			cluster = r.clusterResolver(c)
+		}
+		attrs := ResolveRequestAttributes(c, cluster)
 
 		// 使用 Casbin Enforcer 验证权限
-		allowed, err := e.Enforce(role, obj, act)
+		allowed, err := e.Enforce(username, attrs.Cluster, attrs.Namespace, attrs.Resource, attrs.Verb)
 		if err != nil {
 			log.Printf("Casbin Enforce 错误: %v", err)
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "权限检查时发生内部错误"})
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "权限检查时发生内部错误"})
+			return
+		}
+
+		// API token 只能在其 scope 范围内使用用户的权限
+		claims, hasClaims := GetClaims(c)
+		if allowed && hasClaims && claims.APITokenID != 0 && !ScopesAllow(claims.Scopes, attrs) {
+			log.Printf("权限验证失败 - 用户: %s 的 API token %d 不包含 %s:%s", username, claims.APITokenID, attrs.Resource, attrs.Verb)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"code": http.StatusForbidden, "message": "API token 的 scope 不允许此操作"})
 			return
 		}
 
 		if allowed {
-			log.Printf("权限验证通过 - 角色: %s, 路径: %s, 方法: %s", role, obj, act)
+			// 一个请求涉及多个对象时 (例如 apply)，handler 通过 Authorize 按同样的规则逐个检查
+			c.Set(authorizerKey, Authorizer(func(attrs RequestAttributes) (bool, error) {
+				allowed, err := e.Enforce(username, attrs.Cluster, attrs.Namespace, attrs.Resource, attrs.Verb)
+				if err != nil || !allowed {
+					return false, err
+				}
+				return !hasClaims || claims.APITokenID == 0 || ScopesAllow(claims.Scopes, attrs), nil
+			}))
 			c.Next()
 		} else {
-			log.Printf("权限验证失败 - 角色: %s 无权访问 %s %s", role, act, obj)
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "您没有权限执行此操作"}) // 使用 403 Forbidden
+			log.Printf("权限验证失败 - 用户: %s 无权在集群 %q 命名空间 %q 对 %s 执行 %s", username, attrs.Cluster, attrs.Namespace, attrs.Resource, attrs.Verb)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"code": http.StatusForbidden, "message": "您没有权限执行此操作"}) // 使用 403 Forbidden
 		}
 	}
 }
 
 // addPolicyIfNotExists 辅助函数，检查策略是否存在，不存在则添加
-func addPolicyIfNotExists(e *casbin.Enforcer, sub, obj, act string) {
-	has, err := e.HasPolicy(sub, obj, act)
+func addPolicyIfNotExists(e *casbin.Enforcer, rule ...string) {
+	params := make([]interface{}, len(rule))
+	for i, v := range rule {
+		params[i] = v
+	}
+	has, err := e.HasPolicy(params...)
 	if err != nil {
-		log.Fatalf("检查策略是否存在时出错 (%s, %s, %s): %v", sub, obj, act, err)
+		log.Fatalf("检查策略是否存在时出错 %v: %v", rule, err)
 	}
 	if !has {
-		added, err := e.AddPolicy(sub, obj, act)
-		if err != nil {
-			log.Fatalf("添加策略失败 (%s, %s, %s): %v", sub, obj, act, err)
-		}
-		if added {
-			log.Printf("成功添加默认策略: %s, %s, %s", sub, obj, act)
-		} else {
-			log.Printf("策略已存在，未添加: %s, %s, %s", sub, obj, act)
+		if _, err := e.AddPolicy(params...); err != nil {
+			log.Fatalf("添加策略失败 %v: %v", rule, err)
 		}
-	} else {
-		log.Printf("策略已存在，跳过添加: %s, %s, %s", sub, obj, act)
+		log.Printf("成功添加默认策略: %v", rule)
 	}
 }
 
@@ -105,17 +169,22 @@ func InitCasbin(db *gorm.DB) (*casbin.Enforcer, error) {
 	}
 
 	log.Println("初始化 Casbin Adapter...")
-	adapter, err := gormadapter.NewAdapterByDB(db)
+	adapter, err := gormadapter.NewAdapterByDBUseTableName(db, "", CasbinTableName)
 	if err != nil {
 		return nil, fmt.Errorf("创建 Casbin GORM Adapter 失败: %w", err)
 	}
 
 	log.Println("初始化 Casbin Enforcer...")
-	// 确保 model.conf 路径正确
-	e, err := casbin.NewEnforcer("./pkg/auth/model.conf", adapter)
+	m, err := model.NewModelFromString(modelConf)
+	if err != nil {
+		return nil, fmt.Errorf("解析 Casbin 模型失败: %w", err)
+	}
+	e, err := casbin.NewEnforcer(m, adapter)
 	if err != nil {
 		return nil, fmt.Errorf("创建 Casbin Enforcer 失败: %w", err)
 	}
+	// 角色绑定的集群 (域) 支持通配
+	e.AddNamedDomainMatchingFunc("g", "keyMatch", util.KeyMatch)
 
 	// 启用日志记录 (可选, 但调试时有用)
 	e.EnableLog(true)
@@ -129,14 +198,19 @@ func InitCasbin(db *gorm.DB) (*casbin.Enforcer, error) {
 		// 这里不应该 Fatal，因为首次运行时没有策略是正常的
 	}
 
+	if err := migrateRoleSubjects(e); err != nil {
+		return nil, err
+	}
+
 	log.Println("添加或验证默认策略...")
 	// 添加默认权限 (检查是否存在)
-	addPolicyIfNotExists(e, "super_admin", "/api/v1/*", "*")   // 管理员拥有所有 v1 接口的所有权限
-	addPolicyIfNotExists(e, "normal_user", "/api/v1/*", "GET") // 普通用户只有 GET 权限
+	addPolicyIfNotExists(e, RoleSubject(models.RoleAdmin), Wildcard, Wildcard, Wildcard, Wildcard) // 管理员拥有所有集群、所有资源的所有权限
+	// 普通用户对所有资源只读
+	for _, verb := range []string{VerbGet, VerbList, VerbWatch} {
+		addPolicyIfNotExists(e, RoleSubject(models.RoleUser), Wildcard, Wildcard, Wildcard, verb)
+	}
 
-	// 你可能还需要添加用户到角色的映射 (g 规则)
-	// 例如: e.AddGroupingPolicy("admin", "super_admin")
-	// 这通常在用户创建或角色分配时处理，但可以添加默认的。
+	// 用户到角色的映射 (g 规则) 由 AuthService 在创建用户时写入，启动时通过 SyncRoleBindings 补齐
 
 	// 保存所有可能的新增策略 (如果 AutoSave 不够可靠或需要批量添加)
 	// if err := e.SavePolicy(); err != nil {
@@ -146,3 +220,77 @@ func InitCasbin(db *gorm.DB) (*casbin.Enforcer, error) {
 	log.Printf("初始化 RBAC 权限控制完成！")
 	return e, nil
 }
+
+// migrateRoleSubjects 为旧版本写入的规则和绑定中的角色补上 RolePrefix。
+// p 规则的 subject 与 g 规则的第二项都只会是角色
+func migrateRoleSubjects(e *casbin.Enforcer) error {
+	policies, err := e.GetPolicy()
+	if err != nil {
+		return err
+	}
+	var oldPolicies, newPolicies [][]string
+	for _, rule := range policies {
+		if _, ok := RoleName(rule[0]); !ok {
+			oldPolicies = append(oldPolicies, rule)
+			newPolicies = append(newPolicies, append([]string{RoleSubject(rule[0])}, rule[1:]...))
+		}
+	}
+	if len(oldPolicies) > 0 {
+		if _, err := e.UpdatePolicies(oldPolicies, newPolicies); err != nil {
+			return fmt.Errorf("迁移 Casbin 规则失败: %w", err)
+		}
+		log.Printf("已为 %d 条 Casbin 规则的角色添加前缀 %q", len(oldPolicies), RolePrefix)
+	}
+
+	bindings, err := e.GetGroupingPolicy()
+	if err != nil {
+		return err
+	}
+	var oldBindings, newBindings [][]string
+	for _, rule := range bindings {
+		if _, ok := RoleName(rule[1]); !ok {
+			oldBindings = append(oldBindings, rule)
+			newBindings = append(newBindings, append([]string{rule[0], RoleSubject(rule[1])}, rule[2:]...))
+		}
+	}
+	if len(oldBindings) > 0 {
+		if _, err := e.UpdateGroupingPolicies(oldBindings, newBindings); err != nil {
+			return fmt.Errorf("迁移 Casbin 角色绑定失败: %w", err)
+		}
+		log.Printf("已为 %d 条 Casbin 角色绑定的角色添加前缀 %q", len(oldBindings), RolePrefix)
+	}
+	return nil
+}
+
+// RenameClusterRules 将 p 规则和 g 规则中精确等于 oldName 的集群改为 newName。
+// 带通配的集群 (例如 "prod-*") 不改写；返回改写的规则条数
+func RenameClusterRules(e *casbin.Enforcer, oldName, newName string) (int, error) {
+	policies, err := e.GetFilteredPolicy(1, oldName)
+	if err != nil {
+		return 0, err
+	}
+	if len(policies) > 0 {
+		newPolicies := make([][]string, 0, len(policies))
+		for _, rule := range policies {
+			newPolicies = append(newPolicies, append([]string{rule[0], newName}, rule[2:]...))
+		}
+		if _, err := e.UpdatePolicies(policies, newPolicies); err != nil {
+			return 0, fmt.Errorf("改写集群 '%s' 的 Casbin 规则失败: %w", oldName, err)
+		}
+	}
+
+	bindings, err := e.GetFilteredGroupingPolicy(2, oldName)
+	if err != nil {
+		return len(policies), err
+	}
+	if len(bindings) > 0 {
+		newBindings := make([][]string, 0, len(bindings))
+		for _, rule := range bindings {
+			newBindings = append(newBindings, append([]string{rule[0], rule[1], newName}, rule[3:]...))
+		}
+		if _, err := e.UpdateGroupingPolicies(bindings, newBindings); err != nil {
+			return len(policies), fmt.Errorf("改写集群 '%s' 的 Casbin 角色绑定失败: %w", oldName, err)
+		}
+	}
+	return len(policies) + len(bindings), nil
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/gin-gonic/gin"
+)
+
+// ImpersonationIdentityResolver 根据当前请求的 JWTClaims 得到模拟身份：
+// 用户名加前缀，组为所有用户的公共组加上角色映射出的组。没有 token 声明的请求 (例如关闭认证) 不模拟
+func ImpersonationIdentityResolver(cfg configs.ImpersonationConfig) k8s.IdentityResolver {
+	return func(c *gin.Context) (k8s.Identity, bool) {
+		claims, ok := GetClaims(c)
+		if !ok || claims.Username == "" {
+			return k8s.Identity{}, false
+		}
+		return ImpersonationIdentity(cfg, claims), true
+	}
+}
+
+// ImpersonationIdentity 将 JWTClaims 映射为 Kubernetes 用户和组
+func ImpersonationIdentity(cfg configs.ImpersonationConfig, claims *JWTClaims) k8s.Identity {
+	groups := append([]string(nil), cfg.Groups...)
+	groups = append(groups, cfg.RoleGroups[claims.Role]...)
+	return k8s.Identity{
+		Username: cfg.UsernamePrefix + claims.Username,
+		Groups:   groups,
+	}
+}
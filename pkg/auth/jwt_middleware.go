@@ -9,26 +9,60 @@ import (
 	"github.com/ciliverse/cilikube/configs"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// 认证中间件写入 gin.Context 的键，授权中间件和 handler 通过 GetCurrentUser 读取
+const (
+	ContextUserIDKey   = "user_id"
+	ContextUsernameKey = "username"
+	ContextRoleKey     = "user_role"
+	ContextClaimsKey   = "jwt_claims"
+
+	// DevUsername 关闭认证 (auth.disabled) 时请求使用的身份
+	DevUsername = "dev"
+	// accessTokenQueryParam 浏览器的 WebSocket / EventSource 无法设置请求头，
+	// 流式路由 (见 streamingRoute) 没有 Authorization 头时从该查询参数读取 token
+	accessTokenQueryParam = "access_token"
+	// contextQueryTokenKey QueryTokenMiddleware 从 URL 中取出的 token
+	contextQueryTokenKey = "auth.queryToken"
 )
 
 type JWTClaims struct {
 	UserID   uint   `json:"user_id"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	// SessionID 登录会话，与该会话的刷新令牌相同，登出时据此吊销
+	SessionID string `json:"sid,omitempty"`
+	// TokenVersion 签发时用户的 token 版本，与当前版本不一致的 token 无效
+	TokenVersion uint `json:"ver"`
+	// PasswordChangeRequired 用户必须先修改密码，此前只能访问自己的账号接口
+	PasswordChangeRequired bool `json:"pcr,omitempty"`
+	// APITokenID 与 Scopes 仅在使用 API token 认证时设置，不会出现在签发的 JWT 中
+	APITokenID uint     `json:"-"`
+	Scopes     []string `json:"-"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken 生成JWT token
-func GenerateToken(user *models.User) (string, time.Time, error) {
-	expirationTime := time.Now().Add(configs.GlobalConfig.JWT.ExpireDuration)
+// TokenValidator 校验签名有效的 token 是否已被吊销，返回错误时请求以 401 拒绝
+type TokenValidator func(claims *JWTClaims) error
+
+// GenerateToken 为 sessionID 会话生成 JWT access token，RegisteredClaims.ID 为唯一的 jti
+func GenerateToken(user *models.User, sessionID string) (string, *JWTClaims, error) {
+	now := time.Now()
+	expirationTime := now.Add(configs.GlobalConfig.JWT.ExpireDuration)
 
 	claims := &JWTClaims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Role:     user.Role,
+		UserID:                 user.ID,
+		Username:               user.Username,
+		Role:                   user.Role,
+		SessionID:              sessionID,
+		TokenVersion:           user.TokenVersion,
+		PasswordChangeRequired: user.MustChangePassword,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(now),
 			Issuer:    configs.GlobalConfig.JWT.Issuer,
 			Subject:   user.Username,
 		},
@@ -37,7 +71,7 @@ func GenerateToken(user *models.User) (string, time.Time, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString([]byte(configs.GlobalConfig.JWT.SecretKey))
 
-	return tokenString, expirationTime, err
+	return tokenString, claims, err
 }
 
 // ParseToken 解析JWT token
@@ -57,30 +91,33 @@ func ParseToken(tokenString string) (*JWTClaims, error) {
 	return nil, jwt.ErrInvalidKey
 }
 
-// JWTAuthMiddleware JWT认证中间件
-func JWTAuthMiddleware() gin.HandlerFunc {
+// JWTAuthMiddleware JWT认证中间件。validate 不为 nil 时额外检查 token 是否已被吊销；
+// resolveAPIToken 不为 nil 时同时接受以 APITokenPrefix 开头的 API token
+func JWTAuthMiddleware(validate TokenValidator, resolveAPIToken APITokenResolver) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 从header中获取token
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
+		tokenString, message := bearerToken(c)
+		if tokenString == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"code":    401,
-				"message": "Authorization header is required",
+				"message": message,
 			})
 			c.Abort()
 			return
 		}
 
-		// 检查Bearer前缀
-		tokenString := ""
-		if strings.HasPrefix(authHeader, "Bearer ") {
-			tokenString = authHeader[7:] // 去掉"Bearer "前缀
-		} else {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "Invalid authorization header format",
-			})
-			c.Abort()
+		if resolveAPIToken != nil && strings.HasPrefix(tokenString, APITokenPrefix) {
+			claims, err := resolveAPIToken(tokenString)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"code":    401,
+					"message": "Invalid API token: " + err.Error(),
+				})
+				c.Abort()
+				return
+			}
+			setCurrentUser(c, claims.UserID, claims.Username, claims.Role)
+			c.Set(ContextClaimsKey, claims)
+			c.Next()
 			return
 		}
 
@@ -105,19 +142,99 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if validate != nil {
+			if err := validate(claims); err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"code":    401,
+					"message": "Token has been revoked: " + err.Error(),
+				})
+				c.Abort()
+				return
+			}
+		}
+
 		// 将用户信息存储到上下文中
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("user_role", claims.Role)
+		setCurrentUser(c, claims.UserID, claims.Username, claims.Role)
+		c.Set(ContextClaimsKey, claims)
 
 		c.Next()
 	}
 }
 
+// GetClaims 获取 JWTAuthMiddleware 校验通过的 token 声明；关闭认证时不存在
+func GetClaims(c *gin.Context) (*JWTClaims, bool) {
+	val, exists := c.Get(ContextClaimsKey)
+	if !exists {
+		return nil, false
+	}
+	claims, ok := val.(*JWTClaims)
+	return claims, ok && claims != nil
+}
+
+// DevAuthMiddleware 关闭认证时使用：不校验 token，以管理员身份 DevUsername 处理请求
+func DevAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		setCurrentUser(c, 0, DevUsername, models.RoleAdmin)
+		c.Next()
+	}
+}
+
+// bearerToken 读取 Authorization: Bearer <token>，流式路由没有该请求头时使用 ?access_token=；
+// token 为空时返回错误信息
+func bearerToken(c *gin.Context) (string, string) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		if token := c.GetString(contextQueryTokenKey); token != "" && streamingRoute(c.FullPath()) {
+			return token, ""
+		}
+		return "", "Authorization header is required"
+	}
+	// 检查Bearer前缀
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return "", "Invalid authorization header format"
+	}
+	return authHeader[7:], "" // 去掉"Bearer "前缀
+}
+
+// streamingRoute 浏览器只能通过 EventSource / WebSocket 访问的路由：watch、exec、日志和 rollout 状态流
+func streamingRoute(route string) bool {
+	if strings.Contains(route, "/watch/") {
+		return true
+	}
+	for _, suffix := range []string{"/exec", "/logs", "/rollout-status"} {
+		if strings.HasSuffix(route, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// QueryTokenMiddleware 从 URL 中移除 ?access_token= 并暂存到上下文，避免 token 出现在访问日志中。
+// 须放在 gin.Logger 之前；只有流式路由会使用该 token
+func QueryTokenMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+		if token := query.Get(accessTokenQueryParam); token != "" {
+			c.Set(contextQueryTokenKey, token)
+		}
+		if query.Has(accessTokenQueryParam) {
+			query.Del(accessTokenQueryParam)
+			c.Request.URL.RawQuery = query.Encode()
+		}
+		c.Next()
+	}
+}
+
+func setCurrentUser(c *gin.Context, userID uint, username, role string) {
+	c.Set(ContextUserIDKey, userID)
+	c.Set(ContextUsernameKey, username)
+	c.Set(ContextRoleKey, role)
+}
+
 // AdminRequiredMiddleware 管理员权限中间件
 func AdminRequiredMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("user_role")
+		role, exists := c.Get(ContextRoleKey)
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"code":    401,
@@ -127,7 +244,7 @@ func AdminRequiredMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if role != "admin" {
+		if role != models.RoleAdmin {
 			c.JSON(http.StatusForbidden, gin.H{
 				"code":    403,
 				"message": "Admin privileges required",
@@ -169,9 +286,7 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 		}
 
 		// 设置用户信息到上下文
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("user_role", claims.Role)
+		setCurrentUser(c, claims.UserID, claims.Username, claims.Role)
 
 		c.Next()
 	}
@@ -179,9 +294,9 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 
 // GetCurrentUser 从上下文中获取当前用户信息
 func GetCurrentUser(c *gin.Context) (uint, string, string, bool) {
-	userID, exists1 := c.Get("user_id")
-	username, exists2 := c.Get("username")
-	role, exists3 := c.Get("user_role")
+	userID, exists1 := c.Get(ContextUserIDKey)
+	username, exists2 := c.Get(ContextUsernameKey)
+	role, exists3 := c.Get(ContextRoleKey)
 
 	if !exists1 || !exists2 || !exists3 {
 		return 0, "", "", false
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TOTP 参数 (RFC 6238)，与 Google Authenticator 等应用的默认值一致
+const (
+	totpPeriod = 30
+	totpDigits = 6
+	// totpSkew 允许前后各一个时间窗口的时钟偏差
+	totpSkew = 1
+
+	challengeAudience = "cilikube-2fa"
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret 生成 160 位随机密钥，base32 编码
+func GenerateTOTPSecret() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(buf), nil
+}
+
+// TOTPURI 生成认证器应用扫码使用的 otpauth:// 地址
+func TOTPURI(issuer, account, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// GenerateTOTPCode 计算 t 所在时间窗口的验证码
+func GenerateTOTPCode(secret string, t time.Time) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("TOTP 密钥无效: %w", err)
+	}
+	return totpCode(key, uint64(t.Unix())/totpPeriod), nil
+}
+
+// ValidateTOTP 校验验证码，成功时返回匹配的时间窗口序号，调用方据此拒绝同一验证码的重放
+func ValidateTOTP(secret, code string, t time.Time) (uint64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+	current := uint64(t.Unix()) / totpPeriod
+	for i := -totpSkew; i <= totpSkew; i++ {
+		step := current + uint64(i)
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// totpCode HOTP (RFC 4226) 动态截断
+func totpCode(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// ChallengeClaims 密码校验通过、等待第二步验证的登录挑战
+type ChallengeClaims struct {
+	UserID       uint `json:"user_id"`
+	TokenVersion uint `json:"ver"`
+	jwt.RegisteredClaims
+}
+
+// challengeKey 挑战使用独立的签名密钥，使其无法被 ParseToken 当作 access token 接受
+func challengeKey() []byte {
+	return []byte(configs.GlobalConfig.JWT.SecretKey + "#" + challengeAudience)
+}
+
+// GenerateChallengeToken 签发有效期为 ttl 的登录挑战
+func GenerateChallengeToken(userID, tokenVersion uint, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	claims := &ChallengeClaims{
+		UserID:       userID,
+		TokenVersion: tokenVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{challengeAudience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    configs.GlobalConfig.JWT.Issuer,
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(challengeKey())
+	return token, expiresAt, err
+}
+
+// ParseChallengeToken 校验登录挑战的签名、用途与有效期
+func ParseChallengeToken(tokenString string) (*ChallengeClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &ChallengeClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return challengeKey(), nil
+	}, jwt.WithAudience(challengeAudience), jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(*ChallengeClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid challenge token")
+	}
+	return claims, nil
+}
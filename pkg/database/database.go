@@ -72,6 +72,16 @@ func AutoMigrate() error {
 	log.Println("开始数据库自动迁移...") // 添加日志
 	err := DB.AutoMigrate(
 		&models.User{},
+		&models.Cluster{},
+		&models.RefreshToken{},
+		&models.RevokedToken{},
+		&models.APIToken{},
+		&models.RecoveryCode{},
+		&models.TwoFactorRequiredRole{},
+		&models.LoginEvent{},
+		&models.LoginFailure{},
+		&models.PasswordHistory{},
+		&models.AuditEvent{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to migrate database: %v", err)
@@ -92,15 +102,17 @@ func CreateDefaultAdmin() error {
 			Username: "admin",
 			Email:    "admin@cilikube.com",
 			Password: "admin123", // 这个密码会在BeforeCreate钩子中被加密
-			Role:     "admin",
+			Role:     models.RoleAdmin,
 			IsActive: true,
+			// 默认密码是公开的，首次登录后必须修改
+			MustChangePassword: true,
 		}
 
 		if err := DB.Create(admin).Error; err != nil {
 			return fmt.Errorf("failed to create default admin: %v", err)
 		}
 
-		log.Println("Default admin user created: username=admin, password=admin123")
+		log.Println("Default admin user created: username=admin, password=admin123 (must be changed on first login)")
 	}
 
 	return nil
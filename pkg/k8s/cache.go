@@ -0,0 +1,379 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	autoscalinglisters "k8s.io/client-go/listers/autoscaling/v2"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	networkinglisters "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// CachedResource 可以由 ResourceCache 提供的资源类型。
+// Secret 不在其中：避免把所有 Secret 长期保存在内存里。
+type CachedResource string
+
+const (
+	CachedPods                   CachedResource = "pods"
+	CachedDeployments            CachedResource = "deployments"
+	CachedStatefulSets           CachedResource = "statefulsets"
+	CachedDaemonSets             CachedResource = "daemonsets"
+	CachedReplicaSets            CachedResource = "replicasets"
+	CachedServices               CachedResource = "services"
+	CachedConfigMaps             CachedResource = "configmaps"
+	CachedNamespaces             CachedResource = "namespaces"
+	CachedNodes                  CachedResource = "nodes"
+	CachedPersistentVolumes      CachedResource = "persistentvolumes"
+	CachedPersistentVolumeClaims CachedResource = "persistentvolumeclaims"
+	CachedIngresses              CachedResource = "ingresses"
+	CachedJobs                   CachedResource = "jobs"
+	CachedHPAs                   CachedResource = "horizontalpodautoscalers"
+)
+
+const (
+	// defaultCacheSyncTimeout 首次读取某类资源时等待 informer 同步的最长时间，超时后回退为直接访问 API Server
+	defaultCacheSyncTimeout = 10 * time.Second
+	// defaultCacheResync informer 的全量 resync 周期
+	defaultCacheResync = 10 * time.Minute
+)
+
+// ResourceCache 是单个集群基于 SharedInformerFactory 的只读缓存。
+// informer 在第一次读取对应资源时才启动，未使用的资源不会产生 watch 连接。
+type ResourceCache struct {
+	factory     informers.SharedInformerFactory
+	stopCh      chan struct{}
+	syncTimeout time.Duration
+
+	mu        sync.Mutex
+	attempted map[CachedResource]bool // 已经等待过同步的资源，未同步时不再重复等待
+	stopOnce  sync.Once
+}
+
+// NewResourceCache 为 clientset 创建缓存，此时不会启动任何 informer
+func NewResourceCache(clientset kubernetes.Interface) *ResourceCache {
+	return &ResourceCache{
+		factory:     informers.NewSharedInformerFactory(clientset, defaultCacheResync),
+		stopCh:      make(chan struct{}),
+		syncTimeout: defaultCacheSyncTimeout,
+		attempted:   make(map[CachedResource]bool),
+	}
+}
+
+// Stop 停止所有 informer，集群被移除或替换时调用
+func (rc *ResourceCache) Stop() {
+	rc.stopOnce.Do(func() {
+		close(rc.stopCh)
+		rc.factory.Shutdown()
+	})
+}
+
+// Informer 返回资源对应的 informer，并确保其已完成首次同步。
+// 返回错误时调用方应直接访问 API Server。
+func (rc *ResourceCache) Informer(resource CachedResource) (cache.SharedIndexInformer, error) {
+	informer := rc.informerFor(resource)
+	if informer == nil {
+		return nil, fmt.Errorf("资源 %s 不支持缓存", resource)
+	}
+	if informer.HasSynced() {
+		return informer, nil
+	}
+
+	rc.mu.Lock()
+	select {
+	case <-rc.stopCh:
+		rc.mu.Unlock()
+		return nil, fmt.Errorf("缓存已停止")
+	default:
+	}
+	rc.factory.Start(rc.stopCh)
+	if rc.attempted[resource] {
+		rc.mu.Unlock()
+		// 已有请求在等待或之前等待超时 (例如没有 list/watch 权限)，不再阻塞请求
+		return nil, fmt.Errorf("资源 %s 的缓存尚未同步", resource)
+	}
+	rc.attempted[resource] = true
+	rc.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), rc.syncTimeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return nil, fmt.Errorf("等待资源 %s 的缓存同步超时", resource)
+	}
+	return informer, nil
+}
+
+// Count 返回缓存中资源的数量
+func (rc *ResourceCache) Count(resource CachedResource) (int, error) {
+	informer, err := rc.Informer(resource)
+	if err != nil {
+		return 0, err
+	}
+	return len(informer.GetIndexer().ListKeys()), nil
+}
+
+// Pods 返回 Pod lister
+func (rc *ResourceCache) Pods() (corelisters.PodLister, error) {
+	informer, err := rc.Informer(CachedPods)
+	if err != nil {
+		return nil, err
+	}
+	return corelisters.NewPodLister(informer.GetIndexer()), nil
+}
+
+// Deployments 返回 Deployment lister
+func (rc *ResourceCache) Deployments() (appslisters.DeploymentLister, error) {
+	informer, err := rc.Informer(CachedDeployments)
+	if err != nil {
+		return nil, err
+	}
+	return appslisters.NewDeploymentLister(informer.GetIndexer()), nil
+}
+
+// StatefulSets 返回 StatefulSet lister
+func (rc *ResourceCache) StatefulSets() (appslisters.StatefulSetLister, error) {
+	informer, err := rc.Informer(CachedStatefulSets)
+	if err != nil {
+		return nil, err
+	}
+	return appslisters.NewStatefulSetLister(informer.GetIndexer()), nil
+}
+
+// DaemonSets 返回 DaemonSet lister
+func (rc *ResourceCache) DaemonSets() (appslisters.DaemonSetLister, error) {
+	informer, err := rc.Informer(CachedDaemonSets)
+	if err != nil {
+		return nil, err
+	}
+	return appslisters.NewDaemonSetLister(informer.GetIndexer()), nil
+}
+
+// ReplicaSets 返回 ReplicaSet lister
+func (rc *ResourceCache) ReplicaSets() (appslisters.ReplicaSetLister, error) {
+	informer, err := rc.Informer(CachedReplicaSets)
+	if err != nil {
+		return nil, err
+	}
+	return appslisters.NewReplicaSetLister(informer.GetIndexer()), nil
+}
+
+// Services 返回 Service lister
+func (rc *ResourceCache) Services() (corelisters.ServiceLister, error) {
+	informer, err := rc.Informer(CachedServices)
+	if err != nil {
+		return nil, err
+	}
+	return corelisters.NewServiceLister(informer.GetIndexer()), nil
+}
+
+// ConfigMaps 返回 ConfigMap lister
+func (rc *ResourceCache) ConfigMaps() (corelisters.ConfigMapLister, error) {
+	informer, err := rc.Informer(CachedConfigMaps)
+	if err != nil {
+		return nil, err
+	}
+	return corelisters.NewConfigMapLister(informer.GetIndexer()), nil
+}
+
+// Namespaces 返回 Namespace lister
+func (rc *ResourceCache) Namespaces() (corelisters.NamespaceLister, error) {
+	informer, err := rc.Informer(CachedNamespaces)
+	if err != nil {
+		return nil, err
+	}
+	return corelisters.NewNamespaceLister(informer.GetIndexer()), nil
+}
+
+// Nodes 返回 Node lister
+func (rc *ResourceCache) Nodes() (corelisters.NodeLister, error) {
+	informer, err := rc.Informer(CachedNodes)
+	if err != nil {
+		return nil, err
+	}
+	return corelisters.NewNodeLister(informer.GetIndexer()), nil
+}
+
+// PersistentVolumes 返回 PV lister
+func (rc *ResourceCache) PersistentVolumes() (corelisters.PersistentVolumeLister, error) {
+	informer, err := rc.Informer(CachedPersistentVolumes)
+	if err != nil {
+		return nil, err
+	}
+	return corelisters.NewPersistentVolumeLister(informer.GetIndexer()), nil
+}
+
+// PersistentVolumeClaims 返回 PVC lister
+func (rc *ResourceCache) PersistentVolumeClaims() (corelisters.PersistentVolumeClaimLister, error) {
+	informer, err := rc.Informer(CachedPersistentVolumeClaims)
+	if err != nil {
+		return nil, err
+	}
+	return corelisters.NewPersistentVolumeClaimLister(informer.GetIndexer()), nil
+}
+
+// Ingresses 返回 Ingress lister
+func (rc *ResourceCache) Ingresses() (networkinglisters.IngressLister, error) {
+	informer, err := rc.Informer(CachedIngresses)
+	if err != nil {
+		return nil, err
+	}
+	return networkinglisters.NewIngressLister(informer.GetIndexer()), nil
+}
+
+// Jobs 返回 Job lister
+func (rc *ResourceCache) Jobs() (batchlisters.JobLister, error) {
+	informer, err := rc.Informer(CachedJobs)
+	if err != nil {
+		return nil, err
+	}
+	return batchlisters.NewJobLister(informer.GetIndexer()), nil
+}
+
+// HPAs 返回 autoscaling/v2 HorizontalPodAutoscaler lister
+func (rc *ResourceCache) HPAs() (autoscalinglisters.HorizontalPodAutoscalerLister, error) {
+	informer, err := rc.Informer(CachedHPAs)
+	if err != nil {
+		return nil, err
+	}
+	return autoscalinglisters.NewHorizontalPodAutoscalerLister(informer.GetIndexer()), nil
+}
+
+// Watch 基于 informer 事件提供 watch.Interface，多个请求共享同一条 API Server watch 连接。
+// 与直接 watch 一样，开始时会为已有对象发送 Added 事件。
+func (rc *ResourceCache) Watch(resource CachedResource, namespace, selector string) (watch.Interface, error) {
+	labelSelector, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("无效的标签选择器: %w", err)
+	}
+	informer, err := rc.Informer(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &informerWatcher{
+		result:    make(chan watch.Event),
+		stopCh:    make(chan struct{}),
+		informer:  informer,
+		namespace: namespace,
+		selector:  labelSelector,
+	}
+	registration, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if w.matches(obj) {
+				w.send(watch.Added, obj)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			switch {
+			case w.matches(newObj):
+				w.send(watch.Modified, newObj)
+			case w.matches(oldObj):
+				// 标签变化后不再匹配选择器，对订阅方而言等同于删除
+				w.send(watch.Deleted, newObj)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if w.matches(obj) {
+				w.send(watch.Deleted, obj)
+			}
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	w.registration = registration
+	return w, nil
+}
+
+// informerFor 返回资源对应的 informer (首次调用时注册到 factory)
+func (rc *ResourceCache) informerFor(resource CachedResource) cache.SharedIndexInformer {
+	switch resource {
+	case CachedPods:
+		return rc.factory.Core().V1().Pods().Informer()
+	case CachedDeployments:
+		return rc.factory.Apps().V1().Deployments().Informer()
+	case CachedStatefulSets:
+		return rc.factory.Apps().V1().StatefulSets().Informer()
+	case CachedDaemonSets:
+		return rc.factory.Apps().V1().DaemonSets().Informer()
+	case CachedReplicaSets:
+		return rc.factory.Apps().V1().ReplicaSets().Informer()
+	case CachedServices:
+		return rc.factory.Core().V1().Services().Informer()
+	case CachedConfigMaps:
+		return rc.factory.Core().V1().ConfigMaps().Informer()
+	case CachedNamespaces:
+		return rc.factory.Core().V1().Namespaces().Informer()
+	case CachedNodes:
+		return rc.factory.Core().V1().Nodes().Informer()
+	case CachedPersistentVolumes:
+		return rc.factory.Core().V1().PersistentVolumes().Informer()
+	case CachedPersistentVolumeClaims:
+		return rc.factory.Core().V1().PersistentVolumeClaims().Informer()
+	case CachedIngresses:
+		return rc.factory.Networking().V1().Ingresses().Informer()
+	case CachedJobs:
+		return rc.factory.Batch().V1().Jobs().Informer()
+	case CachedHPAs:
+		return rc.factory.Autoscaling().V2().HorizontalPodAutoscalers().Informer()
+	}
+	return nil
+}
+
+// informerWatcher 将 informer 事件转换为 watch.Event
+type informerWatcher struct {
+	result       chan watch.Event
+	stopCh       chan struct{}
+	stopOnce     sync.Once
+	informer     cache.SharedIndexInformer
+	registration cache.ResourceEventHandlerRegistration
+	namespace    string
+	selector     labels.Selector
+}
+
+func (w *informerWatcher) ResultChan() <-chan watch.Event { return w.result }
+
+func (w *informerWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		if w.registration != nil {
+			_ = w.informer.RemoveEventHandler(w.registration)
+		}
+	})
+}
+
+func (w *informerWatcher) matches(obj interface{}) bool {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return false
+	}
+	if w.namespace != "" && accessor.GetNamespace() != w.namespace {
+		return false
+	}
+	return w.selector.Matches(labels.Set(accessor.GetLabels()))
+}
+
+func (w *informerWatcher) send(eventType watch.EventType, obj interface{}) {
+	runtimeObj, ok := obj.(runtime.Object)
+	if !ok {
+		return
+	}
+	// 缓存中的对象是共享的，交给订阅方之前先拷贝
+	select {
+	case w.result <- watch.Event{Type: eventType, Object: runtimeObj.DeepCopyObject()}:
+	case <-w.stopCh:
+	}
+}
@@ -4,16 +4,92 @@ import (
 	"fmt" // Import fmt for errors
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
+// discoveryInvalidateInterval 未知资源触发的发现缓存刷新的最短间隔，
+// 避免反复请求不存在的资源时每次都重新发现全部 API 组
+const discoveryInvalidateInterval = 10 * time.Second
+
 // Client struct now holds both Clientset and the Config
 type Client struct {
 	Clientset kubernetes.Interface
-	Config    *rest.Config // <-- 添加 Config 字段来存储 rest.Config
+	Dynamic   dynamic.Interface // 访问 CRD 及任意 GroupVersionResource
+	Config    *rest.Config      // <-- 添加 Config 字段来存储 rest.Config
+
+	cacheOnce sync.Once
+	cache     *ResourceCache
+
+	discoveryOnce sync.Once
+	discovery     discovery.CachedDiscoveryInterface
+	mapper        *restmapper.DeferredDiscoveryRESTMapper
+}
+
+// Discovery returns a memory-cached discovery client of this cluster.
+// Call Invalidate() on it when a resource is not found, e.g. after a CRD was installed;
+// invalidations closer together than discoveryInvalidateInterval are ignored.
+func (c *Client) Discovery() discovery.CachedDiscoveryInterface {
+	c.discoveryOnce.Do(c.initDiscovery)
+	return c.discovery
+}
+
+// RESTMapper maps kinds to resources using the cached discovery of this cluster.
+// Call Reset() on it when a kind is not found, e.g. after a CRD was applied.
+func (c *Client) RESTMapper() *restmapper.DeferredDiscoveryRESTMapper {
+	c.discoveryOnce.Do(c.initDiscovery)
+	return c.mapper
+}
+
+func (c *Client) initDiscovery() {
+	cached := memory.NewMemCacheClient(c.Clientset.Discovery())
+	c.discovery = &throttledDiscovery{CachedDiscoveryInterface: cached, interval: discoveryInvalidateInterval}
+	// apply 在同一清单中先创建 CRD 再使用，RESTMapper 的 Reset 不限制频率
+	c.mapper = restmapper.NewDeferredDiscoveryRESTMapper(cached)
+}
+
+// throttledDiscovery 限制 Invalidate 频率的发现缓存
+type throttledDiscovery struct {
+	discovery.CachedDiscoveryInterface
+
+	mu             sync.Mutex
+	interval       time.Duration
+	lastInvalidate time.Time
+}
+
+// Invalidate 距上次刷新不足 interval 时不做任何事
+func (d *throttledDiscovery) Invalidate() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.lastInvalidate.IsZero() && time.Since(d.lastInvalidate) < d.interval {
+		return
+	}
+	d.lastInvalidate = time.Now()
+	d.CachedDiscoveryInterface.Invalidate()
+}
+
+// Cache returns the informer cache of this cluster, created on first use.
+func (c *Client) Cache() *ResourceCache {
+	c.cacheOnce.Do(func() {
+		c.cache = NewResourceCache(c.Clientset)
+	})
+	return c.cache
+}
+
+// StopCache stops the informers started for this client, if any.
+func (c *Client) StopCache() {
+	c.cacheOnce.Do(func() {}) // No cache may be created after the client is stopped
+	if c.cache != nil {
+		c.cache.Stop()
+	}
 }
 
 // NewClient creates a new Kubernetes client instance.
@@ -56,15 +132,35 @@ func NewClient(kubeconfig string) (*Client, error) {
 		}
 	}
 
+	return newClientForConfig(config)
+}
+
+// NewClientFromKubeconfig creates a client from raw kubeconfig content,
+// e.g. a kubeconfig uploaded through the cluster registry API.
+func NewClientFromKubeconfig(content []byte) (*Client, error) {
+	config, err := clientcmd.RESTConfigFromKubeConfig(content)
+	if err != nil {
+		return nil, fmt.Errorf("解析 kubeconfig 内容失败: %w", err)
+	}
+	return newClientForConfig(config)
+}
+
+// newClientForConfig builds the clientset for an already resolved rest.Config.
+func newClientForConfig(config *rest.Config) (*Client, error) {
 	// Create the clientset using the configuration
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("创建 Kubernetes clientset 失败: %w", err)
 	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("创建 Kubernetes dynamic client 失败: %w", err)
+	}
 
 	// Return the Client struct containing BOTH clientset and config
 	return &Client{
 		Clientset: clientset,
+		Dynamic:   dynamicClient,
 		Config:    config, // <-- 将加载的 config 存储在结构体中
 	}, nil
 }
@@ -1,25 +1,44 @@
 package k8s
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
+// ErrClientNotFound is returned when no client is registered under a cluster name.
+var ErrClientNotFound = errors.New("cluster client not found")
+
 // ClientManager manages multiple Kubernetes client instances and the active client.
 type ClientManager struct {
 	mu           sync.RWMutex
 	clients      map[string]*Client // Map of cluster name to Client
 	activeClient *Client
 	activeName   string
+	health       map[string]*ClusterHealth // Map of cluster name to latest probe result
+	cacheEnabled bool                      // Whether reads may be served from the informer cache
+
+	identityResolver  IdentityResolver                          // Non-nil when requests impersonate the CiliKube user
+	impersonated      map[*Client]map[string]*impersonatedEntry // Impersonating clients per cluster client and identity
+	impersonatedLimit int                                       // Max cached impersonating clients per cluster client
+	impersonatedTTL   time.Duration                             // Idle time after which an impersonating client is pruned
 }
 
 // NewClientManager creates a new ClientManager.
 func NewClientManager() *ClientManager {
 	return &ClientManager{
 		clients: make(map[string]*Client),
+		health:  make(map[string]*ClusterHealth),
+
+		cacheEnabled:      true,
+		impersonated:      make(map[*Client]map[string]*impersonatedEntry),
+		impersonatedLimit: defaultImpersonatedLimit,
+		impersonatedTTL:   defaultImpersonatedTTL,
 	}
 }
 
@@ -34,7 +53,12 @@ func (cm *ClientManager) AddOrReplaceClient(clusterName, kubeconfigPath string)
 		return nil, fmt.Errorf("failed to create client for cluster '%s' with path '%s': %w", clusterName, kubeconfigPath, err)
 	}
 
+	if old, exists := cm.clients[clusterName]; exists && old != k8sClient {
+		old.StopCache()
+		cm.dropImpersonatedLocked(old)
+	}
 	cm.clients[clusterName] = k8sClient
+	delete(cm.health, clusterName) // New client, previous probe results no longer apply
 	fmt.Printf("Client for cluster '%s' added/updated.\n", clusterName)
 
 	// If no active client or replacing the active one, set this as active
@@ -46,6 +70,24 @@ func (cm *ClientManager) AddOrReplaceClient(clusterName, kubeconfigPath string)
 	return k8sClient, nil
 }
 
+// AddClient registers an already constructed client under the given cluster name.
+// If this is the first client being added, it becomes the active client.
+func (cm *ClientManager) AddClient(clusterName string, k8sClient *Client) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if old, exists := cm.clients[clusterName]; exists && old != k8sClient {
+		old.StopCache()
+		cm.dropImpersonatedLocked(old)
+	}
+	cm.clients[clusterName] = k8sClient
+	delete(cm.health, clusterName)
+	if cm.activeClient == nil || cm.activeName == clusterName {
+		cm.activeClient = k8sClient
+		cm.activeName = clusterName
+	}
+}
+
 // SetActiveClient sets the active Kubernetes client.
 func (cm *ClientManager) SetActiveClient(clusterName string) error {
 	cm.mu.Lock()
@@ -100,7 +142,7 @@ func (cm *ClientManager) GetClientByName(clusterName string) (*Client, error) {
 	defer cm.mu.RUnlock()
 	client, exists := cm.clients[clusterName]
 	if !exists {
-		return nil, fmt.Errorf("client for cluster '%s' not found", clusterName)
+		return nil, fmt.Errorf("%w: %s", ErrClientNotFound, clusterName)
 	}
 	return client, nil
 }
@@ -117,12 +159,105 @@ func (cm *ClientManager) RemoveClient(clusterName string) {
 		cm.activeName = ""
 		fmt.Printf("Active cluster '%s' removed. No active cluster set.\n", clusterName)
 	}
+	if client, exists := cm.clients[clusterName]; exists {
+		client.StopCache()
+		cm.dropImpersonatedLocked(client)
+	}
 	delete(cm.clients, clusterName)
+	delete(cm.health, clusterName)
 	fmt.Printf("Client for cluster '%s' removed.\n", clusterName)
 }
 
+// RenameClient moves a client to a new cluster name, keeping it active if it was.
+func (cm *ClientManager) RenameClient(oldName, newName string) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	client, exists := cm.clients[oldName]
+	if !exists {
+		return fmt.Errorf("client for cluster '%s' not found", oldName)
+	}
+	if _, taken := cm.clients[newName]; taken {
+		return fmt.Errorf("client for cluster '%s' already exists", newName)
+	}
+	delete(cm.clients, oldName)
+	cm.clients[newName] = client
+	if health, ok := cm.health[oldName]; ok {
+		health.Cluster = newName
+		cm.health[newName] = health
+		delete(cm.health, oldName)
+	}
+	if cm.activeName == oldName {
+		cm.activeName = newName
+	}
+	fmt.Printf("Cluster '%s' renamed to '%s'.\n", oldName, newName)
+	return nil
+}
+
+// SetCacheEnabled controls whether ClusterMiddleware hands the informer cache to handlers.
+func (cm *ClientManager) SetCacheEnabled(enabled bool) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.cacheEnabled = enabled
+}
+
+// CacheEnabled reports whether reads may be served from the informer cache.
+func (cm *ClientManager) CacheEnabled() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.cacheEnabled
+}
+
+// IdentityResolver returns the resolver set by SetIdentityResolver, nil when impersonation is off.
+func (cm *ClientManager) IdentityResolver() IdentityResolver {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.identityResolver
+}
+
+// HasClient reports whether a client is registered for the cluster name.
+func (cm *ClientManager) HasClient(clusterName string) bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	_, exists := cm.clients[clusterName]
+	return exists
+}
+
 func (cm *ClientManager) GetActiveClusterName() string {
 	cm.mu.RLock()
 	defer cm.mu.RUnlock()
 	return cm.activeName
 }
+
+// ListClusterNames returns the names of all registered clusters in sorted order.
+func (cm *ClientManager) ListClusterNames() []string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	names := make([]string, 0, len(cm.clients))
+	for name := range cm.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveClient returns the client for the named cluster, falling back to the
+// active cluster when clusterName is empty. The resolved cluster name is returned
+// alongside the client.
+func (cm *ClientManager) ResolveClient(clusterName string) (*Client, string, error) {
+	if clusterName == "" {
+		cm.mu.RLock()
+		activeClient, activeName := cm.activeClient, cm.activeName
+		cm.mu.RUnlock()
+		if activeClient == nil {
+			return nil, "", fmt.Errorf("no active Kubernetes client configured")
+		}
+		return activeClient, activeName, nil
+	}
+	client, err := cm.GetClientByName(clusterName)
+	if err != nil {
+		return nil, "", err
+	}
+	return client, clusterName, nil
+}
@@ -0,0 +1,32 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/discovery"
+)
+
+// countingDiscovery 只统计 Invalidate 的调用次数
+type countingDiscovery struct {
+	discovery.CachedDiscoveryInterface
+	invalidations int
+}
+
+func (d *countingDiscovery) Invalidate() { d.invalidations++ }
+
+func TestThrottledDiscoveryInvalidate(t *testing.T) {
+	cached := &countingDiscovery{}
+	throttled := &throttledDiscovery{CachedDiscoveryInterface: cached, interval: time.Hour}
+
+	// 第一次立即刷新，间隔内的其余调用被忽略
+	for i := 0; i < 5; i++ {
+		throttled.Invalidate()
+	}
+	assert.Equal(t, 1, cached.invalidations)
+
+	throttled.lastInvalidate = time.Now().Add(-time.Hour)
+	throttled.Invalidate()
+	assert.Equal(t, 2, cached.invalidations)
+}
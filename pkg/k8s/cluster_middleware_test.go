@@ -0,0 +1,69 @@
+package k8s
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestClusterMiddleware_ResolvesCluster(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	withClusters := func(active string, names ...string) *ClientManager {
+		cm := NewClientManager()
+		cm.SetCacheEnabled(false)
+		for _, name := range names {
+			cm.AddClient(name, &Client{Clientset: fake.NewSimpleClientset()})
+		}
+		if active != "" {
+			require.NoError(t, cm.SetActiveClient(active))
+		}
+		return cm
+	}
+
+	tests := []struct {
+		name    string
+		manager *ClientManager
+		path    string
+		header  string
+		status  int
+		cluster string
+	}{
+		{"path parameter beats header", withClusters("prod", "prod", "staging", "qa"), "/clusters/staging/ping", "qa", http.StatusOK, "staging"},
+		{"header beats active cluster", withClusters("prod", "prod", "qa"), "/ping", "qa", http.StatusOK, "qa"},
+		{"active cluster by default", withClusters("prod", "prod", "qa"), "/ping", "", http.StatusOK, "prod"},
+		{"unknown path cluster", withClusters("prod", "prod"), "/clusters/missing/ping", "prod", http.StatusNotFound, ""},
+		{"unknown header cluster", withClusters("prod", "prod"), "/ping", "missing", http.StatusNotFound, ""},
+		{"no active cluster", withClusters(""), "/ping", "", http.StatusServiceUnavailable, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			handler := func(c *gin.Context) {
+				client, ok := ClientFromContext(c)
+				require.True(t, ok)
+				expected, err := tt.manager.GetClientByName(ClusterNameFromContext(c))
+				require.NoError(t, err)
+				assert.Same(t, expected, client)
+				c.String(http.StatusOK, ClusterNameFromContext(c))
+			}
+			router.GET("/ping", tt.manager.ClusterMiddleware(), handler)
+			router.GET("/clusters/:"+ClusterParam+"/ping", tt.manager.ClusterMiddleware(), handler)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(ClusterHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+			if tt.status == http.StatusOK {
+				assert.Equal(t, tt.cluster, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/version"
+)
+
+// ClusterStatus 集群健康状态
+type ClusterStatus string
+
+const (
+	// ClusterStatusUnknown 尚未探测
+	ClusterStatusUnknown ClusterStatus = "unknown"
+	// ClusterStatusHealthy API Server 可达
+	ClusterStatusHealthy ClusterStatus = "healthy"
+	// ClusterStatusUnavailable 连续探测失败，请求将直接返回 503
+	ClusterStatusUnavailable ClusterStatus = "unavailable"
+
+	// healthFailureThreshold 健康集群连续失败多少次后判定为不可用，避免偶发超时导致抖动
+	healthFailureThreshold = 2
+)
+
+// ClusterHealth 记录一个集群最近一次探测的结果
+type ClusterHealth struct {
+	Cluster             string        `json:"cluster"`
+	Status              ClusterStatus `json:"status"`
+	Version             string        `json:"version,omitempty"`
+	LatencyMs           int64         `json:"latencyMs"`
+	Error               string        `json:"error,omitempty"`
+	ConsecutiveFailures int           `json:"consecutiveFailures"`
+	LastProbeTime       *time.Time    `json:"lastProbeTime,omitempty"`
+	LastTransitionTime  *time.Time    `json:"lastTransitionTime,omitempty"`
+}
+
+// StartHealthChecks 同步探测一次所有集群，然后在后台按 interval 周期探测，直到 ctx 结束。
+// 集群恢复后会自动重新变为可用，无需重启进程。
+func (cm *ClientManager) StartHealthChecks(ctx context.Context, interval, timeout time.Duration) {
+	cm.ProbeAll(ctx, timeout)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				cm.ProbeAll(ctx, timeout)
+			}
+		}
+	}()
+}
+
+// ProbeAll 并发探测所有已注册集群
+func (cm *ClientManager) ProbeAll(ctx context.Context, timeout time.Duration) {
+	var wg sync.WaitGroup
+	for _, name := range cm.ListClusterNames() {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			cm.ProbeCluster(probeCtx, name)
+		}(name)
+	}
+	wg.Wait()
+}
+
+// ProbeCluster 立即探测指定集群并记录结果
+func (cm *ClientManager) ProbeCluster(ctx context.Context, clusterName string) (ClusterHealth, error) {
+	client, err := cm.GetClientByName(clusterName)
+	if err != nil {
+		return ClusterHealth{}, err
+	}
+
+	start := time.Now()
+	info, probeErr := client.ServerVersion(ctx)
+	latency := time.Since(start)
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	// 探测期间集群可能已被移除或替换
+	if current, exists := cm.clients[clusterName]; !exists || current != client {
+		return ClusterHealth{}, fmt.Errorf("client for cluster '%s' changed during probe", clusterName)
+	}
+
+	health := cm.healthLocked(clusterName)
+	now := time.Now()
+	health.LastProbeTime = &now
+	health.LatencyMs = latency.Milliseconds()
+
+	previous := health.Status
+	if probeErr != nil {
+		health.ConsecutiveFailures++
+		health.Error = probeErr.Error()
+		if previous != ClusterStatusHealthy || health.ConsecutiveFailures >= healthFailureThreshold {
+			health.Status = ClusterStatusUnavailable
+		}
+	} else {
+		health.ConsecutiveFailures = 0
+		health.Error = ""
+		health.Version = info.GitVersion
+		health.Status = ClusterStatusHealthy
+	}
+
+	if health.Status != previous {
+		health.LastTransitionTime = &now
+		if health.Status == ClusterStatusHealthy {
+			log.Printf("集群 '%s' 可用 (版本 %s, 延迟 %dms)。", clusterName, health.Version, health.LatencyMs)
+		} else if health.Status == ClusterStatusUnavailable {
+			log.Printf("警告: 集群 '%s' 不可用: %s", clusterName, health.Error)
+		}
+	}
+	return *health, nil
+}
+
+// GetClusterHealth 返回集群最近一次探测结果
+func (cm *ClientManager) GetClusterHealth(clusterName string) (ClusterHealth, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if _, exists := cm.clients[clusterName]; !exists {
+		return ClusterHealth{}, false
+	}
+	if health, ok := cm.health[clusterName]; ok {
+		return *health, true
+	}
+	return ClusterHealth{Cluster: clusterName, Status: ClusterStatusUnknown}, true
+}
+
+// ListClusterHealth 返回所有集群的健康状态，按集群名排序
+func (cm *ClientManager) ListClusterHealth() []ClusterHealth {
+	names := cm.ListClusterNames()
+	result := make([]ClusterHealth, 0, len(names))
+	for _, name := range names {
+		if health, ok := cm.GetClusterHealth(name); ok {
+			result = append(result, health)
+		}
+	}
+	return result
+}
+
+// HasHealthyCluster 判断是否至少有一个集群可用
+func (cm *ClientManager) HasHealthyCluster() bool {
+	for _, health := range cm.ListClusterHealth() {
+		if health.Status == ClusterStatusHealthy {
+			return true
+		}
+	}
+	return false
+}
+
+// healthLocked 返回集群的健康记录，不存在时创建；调用方需持有写锁
+func (cm *ClientManager) healthLocked(clusterName string) *ClusterHealth {
+	health, ok := cm.health[clusterName]
+	if !ok {
+		health = &ClusterHealth{Cluster: clusterName, Status: ClusterStatusUnknown}
+		cm.health[clusterName] = health
+	}
+	return health
+}
+
+// ServerVersion 请求 /version，带上下文超时；RESTClient 不可用时退回 ServerVersion
+func (c *Client) ServerVersion(ctx context.Context) (*version.Info, error) {
+	discovery := c.Clientset.Discovery()
+	restClient := discovery.RESTClient()
+	if restClient == nil {
+		return discovery.ServerVersion()
+	}
+
+	body, err := restClient.Get().AbsPath("/version").Do(ctx).Raw()
+	if err != nil {
+		return nil, err
+	}
+	var info version.Info
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("解析 /version 响应失败: %w", err)
+	}
+	return &info, nil
+}
@@ -0,0 +1,133 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+// newFlakyClient 返回连接到模拟 API Server 的客户端，failing 为 true 时 /version 返回 503
+func newFlakyClient(t *testing.T) (*Client, *atomic.Bool) {
+	t.Helper()
+	failing := &atomic.Bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			http.Error(w, "etcd unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"major":"1","minor":"33","gitVersion":"v1.33.0"}`)
+	}))
+	t.Cleanup(server.Close)
+	client, err := newClientForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+	return client, failing
+}
+
+func probe(t *testing.T, cm *ClientManager, name string) ClusterHealth {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	health, err := cm.ProbeCluster(ctx, name)
+	require.NoError(t, err)
+	return health
+}
+
+func TestProbeCluster_Transitions(t *testing.T) {
+	client, failing := newFlakyClient(t)
+	cm := NewClientManager()
+	cm.AddClient("prod", client)
+
+	health, ok := cm.GetClusterHealth("prod")
+	require.True(t, ok)
+	assert.Equal(t, ClusterStatusUnknown, health.Status)
+
+	health = probe(t, cm, "prod")
+	assert.Equal(t, ClusterStatusHealthy, health.Status)
+	assert.Equal(t, "v1.33.0", health.Version)
+	require.NotNil(t, health.LastTransitionTime)
+	healthySince := *health.LastTransitionTime
+
+	// 健康集群偶发一次失败不切换状态
+	failing.Store(true)
+	for i := 1; i < healthFailureThreshold; i++ {
+		health = probe(t, cm, "prod")
+		assert.Equal(t, ClusterStatusHealthy, health.Status)
+		assert.Equal(t, i, health.ConsecutiveFailures)
+		assert.NotEmpty(t, health.Error)
+		assert.Equal(t, healthySince, *health.LastTransitionTime)
+	}
+	assert.True(t, cm.HasHealthyCluster())
+
+	// 连续失败达到阈值后判定为不可用
+	health = probe(t, cm, "prod")
+	assert.Equal(t, ClusterStatusUnavailable, health.Status)
+	assert.Equal(t, healthFailureThreshold, health.ConsecutiveFailures)
+	assert.True(t, health.LastTransitionTime.After(healthySince))
+	assert.False(t, cm.HasHealthyCluster())
+
+	// 恢复后一次成功即重新可用
+	failing.Store(false)
+	health = probe(t, cm, "prod")
+	assert.Equal(t, ClusterStatusHealthy, health.Status)
+	assert.Zero(t, health.ConsecutiveFailures)
+	assert.Empty(t, health.Error)
+	assert.True(t, cm.HasHealthyCluster())
+}
+
+func TestProbeCluster_UnknownClusterFailsImmediately(t *testing.T) {
+	client, failing := newFlakyClient(t)
+	failing.Store(true)
+	cm := NewClientManager()
+	cm.AddClient("prod", client)
+
+	// 从未探测成功的集群第一次失败即不可用
+	health := probe(t, cm, "prod")
+	assert.Equal(t, ClusterStatusUnavailable, health.Status)
+	assert.Equal(t, 1, health.ConsecutiveFailures)
+
+	_, err := cm.ProbeCluster(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrClientNotFound)
+}
+
+func TestClusterMiddleware_UnavailableClusterRecovers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	client, failing := newFlakyClient(t)
+	cm := NewClientManager()
+	cm.SetCacheEnabled(false)
+	cm.AddClient("prod", client)
+
+	router := gin.New()
+	router.GET("/ping", cm.ClusterMiddleware(), func(c *gin.Context) {
+		c.String(http.StatusOK, ClusterNameFromContext(c))
+	})
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+		return w
+	}
+
+	// 尚未探测的集群照常放行
+	assert.Equal(t, http.StatusOK, get().Code)
+
+	failing.Store(true)
+	probe(t, cm, "prod")
+	w := get()
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"unavailable"`)
+
+	failing.Store(false)
+	probe(t, cm, "prod")
+	w = get()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "prod", w.Body.String())
+}
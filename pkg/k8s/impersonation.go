@@ -0,0 +1,131 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// defaultImpersonatedLimit 每个集群客户端缓存的模拟客户端上限，超出时淘汰最久未使用的
+	defaultImpersonatedLimit = 256
+	// defaultImpersonatedTTL 模拟客户端闲置超过该时间后，在下一次写入缓存时清除
+	defaultImpersonatedTTL = 30 * time.Minute
+)
+
+// impersonatedEntry 缓存中的模拟客户端及其最近一次使用时间 (UnixNano)，读路径只持有读锁，因此用原子变量更新
+type impersonatedEntry struct {
+	client   *Client
+	lastUsed atomic.Int64
+}
+
+// Identity 以该身份模拟 (impersonate) 请求 Kubernetes API，集群 RBAC 按它授权，审计日志中也记录它
+type Identity struct {
+	Username string
+	Groups   []string
+}
+
+// IdentityResolver 返回当前请求需要模拟的身份；ok 为 false 时使用服务凭据
+type IdentityResolver func(c *gin.Context) (Identity, bool)
+
+// key 用户名与排序后的组，作为模拟客户端的缓存键
+func (id Identity) key() string {
+	groups := append([]string(nil), id.Groups...)
+	sort.Strings(groups)
+	return id.Username + "\x00" + strings.Join(groups, "\x00")
+}
+
+// Impersonate 基于当前客户端的配置创建一个以 id 身份请求的新客户端。
+// 新客户端与原客户端共用发现缓存，但不共用 informer 缓存，因为后者以服务凭据读取，会绕过集群 RBAC
+func (c *Client) Impersonate(id Identity) (*Client, error) {
+	if c.Config == nil {
+		return nil, fmt.Errorf("客户端缺少 rest.Config，无法模拟用户")
+	}
+	config := rest.CopyConfig(c.Config)
+	config.Impersonate = rest.ImpersonationConfig{
+		UserName: id.Username,
+		Groups:   id.Groups,
+	}
+	client, err := newClientForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	client.discoveryOnce.Do(func() {
+		client.discovery, client.mapper = c.Discovery(), c.RESTMapper()
+	})
+	return client, nil
+}
+
+// SetIdentityResolver 开启用户模拟：ClusterMiddleware 为每个请求换用以 resolver 返回身份请求的客户端。
+// 传入 nil 关闭模拟
+func (cm *ClientManager) SetIdentityResolver(resolver IdentityResolver) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.identityResolver = resolver
+}
+
+// ImpersonatedClient 返回 base 以 id 身份请求的客户端，按集群客户端和身份缓存。
+// 每个集群客户端最多缓存 impersonatedLimit 个身份，闲置超过 impersonatedTTL 的在写入时清除
+func (cm *ClientManager) ImpersonatedClient(base *Client, id Identity) (*Client, error) {
+	key := id.key()
+	cm.mu.RLock()
+	entry, ok := cm.impersonated[base][key]
+	if ok {
+		entry.lastUsed.Store(time.Now().UnixNano())
+	}
+	cm.mu.RUnlock()
+	if ok {
+		return entry.client, nil
+	}
+
+	client, err := base.Impersonate(id)
+	if err != nil {
+		return nil, err
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	now := time.Now()
+	if cached, ok := cm.impersonated[base][key]; ok {
+		cached.lastUsed.Store(now.UnixNano())
+		return cached.client, nil
+	}
+	if cm.impersonated[base] == nil {
+		cm.impersonated[base] = make(map[string]*impersonatedEntry)
+	}
+	cm.pruneImpersonatedLocked(base, now)
+	entry = &impersonatedEntry{client: client}
+	entry.lastUsed.Store(now.UnixNano())
+	cm.impersonated[base][key] = entry
+	return client, nil
+}
+
+// pruneImpersonatedLocked 清除 base 下闲置过期的模拟客户端，并为新条目腾出位置，调用方须持有写锁
+func (cm *ClientManager) pruneImpersonatedLocked(base *Client, now time.Time) {
+	entries := cm.impersonated[base]
+	expired := now.Add(-cm.impersonatedTTL).UnixNano()
+	for key, entry := range entries {
+		if entry.lastUsed.Load() < expired {
+			delete(entries, key)
+		}
+	}
+	for len(entries) >= cm.impersonatedLimit && len(entries) > 0 {
+		oldestKey, oldest := "", int64(0)
+		for key, entry := range entries {
+			if used := entry.lastUsed.Load(); oldestKey == "" || used < oldest {
+				oldestKey, oldest = key, used
+			}
+		}
+		delete(entries, oldestKey)
+	}
+}
+
+// dropImpersonatedLocked 清除某个集群客户端派生的模拟客户端，调用方须持有写锁
+func (cm *ClientManager) dropImpersonatedLocked(base *Client) {
+	delete(cm.impersonated, base)
+}
@@ -0,0 +1,58 @@
+package k8s
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+func TestImpersonatedClientCacheIsBounded(t *testing.T) {
+	base, err := newClientForConfig(&rest.Config{Host: "http://127.0.0.1:1"})
+	require.NoError(t, err)
+	cm := NewClientManager()
+	cm.AddClient("test", base)
+	cm.impersonatedLimit = 2
+
+	get := func(user string) *Client {
+		t.Helper()
+		client, err := cm.ImpersonatedClient(base, Identity{Username: user})
+		require.NoError(t, err)
+		return client
+	}
+	cached := func() []string {
+		cm.mu.RLock()
+		defer cm.mu.RUnlock()
+		var users []string
+		for _, entry := range cm.impersonated[base] {
+			users = append(users, entry.client.Config.Impersonate.UserName)
+		}
+		return users
+	}
+
+	alice := get("alice")
+	assert.Same(t, alice, get("alice"), "同一身份复用缓存的客户端")
+	get("bob")
+	// alice 比 bob 更近使用，超出上限时淘汰 bob
+	time.Sleep(time.Millisecond)
+	get("alice")
+	get("carol")
+	assert.ElementsMatch(t, []string{"alice", "carol"}, cached())
+
+	// 闲置超过 TTL 的客户端在下一次写入时清除
+	cm.impersonatedLimit = 100
+	cm.impersonatedTTL = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	get("dave")
+	assert.ElementsMatch(t, []string{"dave"}, cached())
+
+	// 集群移除时一并清除
+	for i := 0; i < 3; i++ {
+		get(fmt.Sprintf("user-%d", i))
+	}
+	cm.RemoveClient("test")
+	assert.Empty(t, cached())
+}